@@ -0,0 +1,311 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"alarket/internal/application/usecases"
+	"alarket/internal/domain/entities"
+	"alarket/internal/infrastructure/binance"
+	"alarket/internal/infrastructure/clickhouse"
+)
+
+var (
+	backfillSymbol    string
+	backfillDays      int // Trades and klines
+	backfillFrom      string
+	backfillTo        string
+	backfillResume    bool
+	backfillForward   bool
+	backfillAggTrades bool
+	backfillIntervals []string
+	backfillMarket    string
+
+	backfillFundingRateDays int
+)
+
+var backfillCmd = &cobra.Command{
+	Use:   "backfill",
+	Short: "Backfill history from the exchange REST APIs",
+}
+
+var backfillTradesCmd = &cobra.Command{
+	Use:   "trades",
+	Short: "Fetch historical trades for a specific symbol",
+	Long: `Fetches historical trade data from Binance for a specific symbol.
+It checks existing data in the database and only fetches older trades as needed.
+
+With --agg-trades it backfills aggregate trades for the last --days instead,
+using the public aggTrades endpoint which needs no API key.`,
+	Args: cobra.NoArgs,
+	RunE: runBackfillTrades,
+}
+
+var backfillKlinesCmd = &cobra.Command{
+	Use:   "klines",
+	Short: "Backfill klines for a specific symbol",
+	Long: `Backfills closed klines (candlesticks) from the public Binance REST API
+for a specific symbol and one or more intervals. No API key is required.
+
+By default it resumes after the newest kline already stored for each interval,
+so an interrupted run can simply be started again.`,
+	Args: cobra.NoArgs,
+	RunE: runBackfillKlines,
+}
+
+var backfillFundingRatesCmd = &cobra.Command{
+	Use:   "funding-rates",
+	Short: "Backfill funding rates for a specific perpetual contract",
+	Long: `Backfills settled funding rates from the public Binance futures REST API for a
+specific perpetual contract, on either the USD-M (usdm) or COIN-M (coinm)
+market. No API key is required.
+
+By default it resumes after the newest funding rate already stored, so an
+interrupted run can simply be started again.`,
+	Args: cobra.NoArgs,
+	RunE: runBackfillFundingRates,
+}
+
+func init() {
+	backfillTradesCmd.Flags().StringVarP(&backfillSymbol, "symbol", "s", "", "Trading pair symbol (e.g., BTCUSDT)")
+	backfillTradesCmd.Flags().IntVarP(&backfillDays, "days", "d", 7, "Number of days of historical data to fetch")
+	backfillTradesCmd.Flags().BoolVarP(&backfillForward, "forward", "f", false, "Fetch trades forward from newest ID (fill gaps)")
+	backfillTradesCmd.Flags().BoolVarP(&backfillAggTrades, "agg-trades", "a", false, "Fetch aggregate trades instead of individual trades")
+	backfillTradesCmd.MarkFlagsMutuallyExclusive("forward", "agg-trades")
+
+	backfillKlinesCmd.Flags().StringVarP(&backfillSymbol, "symbol", "s", "", "Trading pair symbol (e.g., BTCUSDT)")
+	backfillKlinesCmd.Flags().StringSliceVarP(&backfillIntervals, "interval", "i", []string{"1m"}, "Kline intervals to fetch (e.g., 1m,1h,1d)")
+	backfillKlinesCmd.Flags().IntVarP(&backfillDays, "days", "d", 7, "Number of days of history to fetch when --from is not set")
+	backfillKlinesCmd.Flags().StringVar(&backfillFrom, "from", "", "Start of the range (YYYY-MM-DD or RFC3339, default: now - days)")
+	backfillKlinesCmd.Flags().StringVar(&backfillTo, "to", "", "End of the range, exclusive (YYYY-MM-DD or RFC3339, default: now)")
+	backfillKlinesCmd.Flags().BoolVar(&backfillResume, "resume", true, "Continue after the newest stored kline instead of refetching the range")
+
+	backfillFundingRatesCmd.Flags().StringVarP(&backfillSymbol, "symbol", "s", "", "Perpetual contract symbol (e.g., BTCUSDT or BTCUSD_PERP)")
+	backfillFundingRatesCmd.Flags().StringVarP(&backfillMarket, "market", "m", string(entities.MarketUSDM), "Futures market: usdm or coinm")
+	backfillFundingRatesCmd.Flags().IntVarP(&backfillFundingRateDays, "days", "d", 30, "Number of days of history to fetch when --from is not set")
+	backfillFundingRatesCmd.Flags().StringVar(&backfillFrom, "from", "", "Start of the range (YYYY-MM-DD or RFC3339, default: now - days)")
+	backfillFundingRatesCmd.Flags().StringVar(&backfillTo, "to", "", "End of the range, exclusive (YYYY-MM-DD or RFC3339, default: now)")
+	backfillFundingRatesCmd.Flags().BoolVar(&backfillResume, "resume", true, "Continue after the newest stored funding rate instead of refetching the range")
+
+	for _, cmd := range []*cobra.Command{backfillTradesCmd, backfillKlinesCmd, backfillFundingRatesCmd} {
+		if err := cmd.MarkFlagRequired("symbol"); err != nil {
+			panic(fmt.Sprintf("failed to mark flag as required: %v", err))
+		}
+	}
+
+	backfillCmd.AddCommand(backfillTradesCmd, backfillKlinesCmd, backfillFundingRatesCmd)
+}
+
+func runBackfillTrades(cmd *cobra.Command, args []string) error {
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	c, err := bootstrap(cmd, os.Stdout)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+	logger := c.Logger
+
+	if err := c.MigrateDatabase(ctx); err != nil {
+		return err
+	}
+
+	// Normalize symbol
+	symbol := strings.ToUpper(backfillSymbol)
+
+	if backfillAggTrades {
+		fetchHistoricalAggTradesUseCase := usecases.NewFetchHistoricalAggTradesUseCase(
+			clickhouse.NewAggTradeRepository(c.DB, entities.ExchangeBinance, entities.MarketSpot),
+			binance.NewAggTradesService(c.Config.Binance.UseTestnet, logger),
+			logger,
+		)
+
+		to := time.Now()
+		from := to.AddDate(0, 0, -backfillDays)
+
+		logger.Info("Starting historical aggregate trades collection",
+			"symbol", symbol,
+			"days", backfillDays)
+
+		if err := fetchHistoricalAggTradesUseCase.Execute(ctx, symbol, from, to); err != nil {
+			logger.Error("Failed to fetch historical aggregate trades", "error", err)
+			return err
+		}
+
+		logger.Info("Historical aggregate trades collection completed successfully")
+		return nil
+	}
+
+	fetchHistoricalTradesUseCase := usecases.NewFetchHistoricalTradesUseCase(
+		clickhouse.NewTradeRepository(c.DB, entities.ExchangeBinance, entities.MarketSpot),
+		binance.NewHistoricalTradesService(
+			c.Config.Binance.APIKey,
+			c.Config.Binance.SecretKey,
+			c.Config.Binance.UseTestnet,
+			logger,
+		),
+		logger,
+	)
+
+	logger.Info("Starting historical trades collection",
+		"symbol", symbol,
+		"days", backfillDays,
+		"forward", backfillForward)
+
+	if err := fetchHistoricalTradesUseCase.Execute(ctx, symbol, backfillDays, backfillForward); err != nil {
+		logger.Error("Failed to fetch historical trades", "error", err)
+		return err
+	}
+
+	logger.Info("Historical trades collection completed successfully")
+	return nil
+}
+
+func runBackfillKlines(cmd *cobra.Command, args []string) error {
+	// Validate input before touching the database
+	klineIntervals := make([]entities.KlineInterval, 0, len(backfillIntervals))
+	for _, value := range backfillIntervals {
+		interval, err := entities.ParseKlineInterval(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		klineIntervals = append(klineIntervals, interval)
+	}
+
+	from, to, err := timeRange(backfillDays, backfillFrom, backfillTo)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	c, err := bootstrap(cmd, os.Stdout)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+	logger := c.Logger
+
+	if err := c.MigrateDatabase(ctx); err != nil {
+		return err
+	}
+
+	fetchHistoricalKlinesUseCase := usecases.NewFetchHistoricalKlinesUseCase(
+		clickhouse.NewKlineRepository(c.DB, entities.ExchangeBinance, entities.MarketSpot),
+		binance.NewKlinesService(c.Config.Binance.UseTestnet, logger),
+		logger,
+	)
+
+	// Normalize symbol
+	symbol := strings.ToUpper(backfillSymbol)
+
+	logger.Info("Starting historical klines collection",
+		"symbol", symbol,
+		"intervals", klineIntervals,
+		"from", from.Format(time.RFC3339),
+		"to", to.Format(time.RFC3339),
+		"resume", backfillResume)
+
+	for _, interval := range klineIntervals {
+		if err := fetchHistoricalKlinesUseCase.Execute(ctx, symbol, interval, from, to, backfillResume); err != nil {
+			logger.Error("Failed to fetch historical klines", "interval", interval, "error", err)
+			return err
+		}
+	}
+
+	logger.Info("Historical klines collection completed successfully")
+	return nil
+}
+
+func runBackfillFundingRates(cmd *cobra.Command, args []string) error {
+	// Validate input before touching the database
+	market, err := entities.ParseMarket(backfillMarket)
+	if err != nil {
+		return err
+	}
+	if !market.IsFutures() {
+		return fmt.Errorf("funding rates are only available for futures markets, got %q", market)
+	}
+
+	from, to, err := timeRange(backfillFundingRateDays, backfillFrom, backfillTo)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	c, err := bootstrap(cmd, os.Stdout)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+	logger := c.Logger
+
+	fundingRateService, err := binance.NewFundingRateService(market, c.Config.Binance.UseTestnet, logger)
+	if err != nil {
+		return err
+	}
+
+	if err := c.MigrateDatabase(ctx); err != nil {
+		return err
+	}
+
+	fetchHistoricalFundingRatesUseCase := usecases.NewFetchHistoricalFundingRatesUseCase(
+		clickhouse.NewFundingRateRepository(c.DB, entities.ExchangeBinance, market),
+		fundingRateService,
+		logger,
+	)
+
+	// Normalize symbol
+	symbol := strings.ToUpper(backfillSymbol)
+
+	logger.Info("Starting historical funding rates collection",
+		"symbol", symbol,
+		"market", market,
+		"from", from.Format(time.RFC3339),
+		"to", to.Format(time.RFC3339),
+		"resume", backfillResume)
+
+	if err := fetchHistoricalFundingRatesUseCase.Execute(ctx, symbol, from, to, backfillResume); err != nil {
+		logger.Error("Failed to fetch historical funding rates", "error", err)
+		return err
+	}
+
+	logger.Info("Historical funding rates collection completed successfully")
+	return nil
+}
+
+// timeRange returns the range [from, to) given by the --from and --to flags.
+// to defaults to now, from to days before to.
+func timeRange(days int, fromFlag, toFlag string) (from, to time.Time, err error) {
+	to = time.Now()
+	if toFlag != "" {
+		if to, err = parseTime(toFlag); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --to: %w", err)
+		}
+	}
+
+	from = to.AddDate(0, 0, -days)
+	if fromFlag != "" {
+		if from, err = parseTime(fromFlag); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --from: %w", err)
+		}
+	}
+
+	return from, to, nil
+}
+
+// parseTime accepts a plain date (UTC midnight) or an RFC3339 timestamp.
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
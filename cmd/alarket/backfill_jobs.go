@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"alarket/internal/application/usecases"
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+	"alarket/internal/infrastructure/binance"
+	"alarket/internal/infrastructure/clickhouse"
+	"alarket/internal/infrastructure/container"
+	"alarket/internal/infrastructure/filestore"
+	"alarket/internal/infrastructure/ratelimit"
+)
+
+var (
+	jobSymbols []string
+	jobDays    int
+	jobFrom    string
+	jobTo      string
+	jobForward bool
+)
+
+var backfillAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Queue trade backfill jobs, one per symbol",
+	Long: `Queues a job per symbol that backfills the trades of the range. Backward jobs
+fetch history before the oldest stored trade, forward jobs continue after the
+newest one. The jobs are run by "alarket backfill run".`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         addBackfillJobs,
+}
+
+var backfillListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "Print the queued backfill jobs",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         listBackfillJobs,
+}
+
+var backfillPauseCmd = &cobra.Command{
+	Use:          "pause <id>",
+	Short:        "Pause a backfill job, a running one stops after its current batch",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         changeBackfillJob((*usecases.ManageBackfillJobsUseCase).Pause),
+}
+
+var backfillResumeCmd = &cobra.Command{
+	Use:          "resume <id>",
+	Short:        "Queue a paused or failed backfill job again",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         changeBackfillJob((*usecases.ManageBackfillJobsUseCase).Resume),
+}
+
+var backfillCancelCmd = &cobra.Command{
+	Use:          "cancel <id>",
+	Short:        "Cancel a backfill job, a running one stops after its current batch",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         changeBackfillJob((*usecases.ManageBackfillJobsUseCase).Cancel),
+}
+
+var backfillRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the queued backfill jobs",
+	Long: `Runs the pending backfill jobs until none is left, several symbols at a time
+(BACKFILL_CONCURRENCY). All jobs share one request weight budget per minute
+(BINANCE_REQUEST_WEIGHT_LIMIT).
+
+Every job stores its cursor after each batch. Jobs interrupted by a crash or
+Ctrl+C are continued from there by the next run.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runBackfillJobs,
+}
+
+func init() {
+	backfillAddCmd.Flags().StringSliceVarP(&jobSymbols, "symbols", "s", nil, "Trading pair symbols (e.g., BTCUSDT,ETHUSDT)")
+	backfillAddCmd.Flags().IntVarP(&jobDays, "days", "d", 7, "Number of days of history to fetch when --from is not set")
+	backfillAddCmd.Flags().StringVar(&jobFrom, "from", "", "Start of the range (YYYY-MM-DD or RFC3339, default: now - days)")
+	backfillAddCmd.Flags().StringVar(&jobTo, "to", "", "End of the range, exclusive (YYYY-MM-DD or RFC3339, default: now)")
+	backfillAddCmd.Flags().BoolVarP(&jobForward, "forward", "f", false, "Walk the range forward from --from instead of backward from --to")
+	if err := backfillAddCmd.MarkFlagRequired("symbols"); err != nil {
+		panic(fmt.Sprintf("failed to mark flag as required: %v", err))
+	}
+
+	backfillCmd.AddCommand(backfillAddCmd, backfillListCmd, backfillPauseCmd, backfillResumeCmd,
+		backfillCancelCmd, backfillRunCmd)
+}
+
+func addBackfillJobs(cmd *cobra.Command, args []string) error {
+	from, to, err := timeRange(jobDays, jobFrom, jobTo)
+	if err != nil {
+		return err
+	}
+	direction := entities.BackfillBackward
+	if jobForward {
+		direction = entities.BackfillForward
+	}
+
+	return withBackfillJobs(cmd, os.Stderr, func(c *container.Container, jobs repositories.BackfillJobRepository) error {
+		added, err := usecases.NewManageBackfillJobsUseCase(jobs, c.Logger).
+			Add(cmd.Context(), jobSymbols, direction, from, to)
+		if err != nil {
+			return err
+		}
+		return printBackfillJobs(cmd.OutOrStdout(), added)
+	})
+}
+
+func listBackfillJobs(cmd *cobra.Command, args []string) error {
+	return withBackfillJobs(cmd, os.Stderr, func(c *container.Container, jobs repositories.BackfillJobRepository) error {
+		all, err := usecases.NewManageBackfillJobsUseCase(jobs, c.Logger).List(cmd.Context())
+		if err != nil {
+			return err
+		}
+		return printBackfillJobs(cmd.OutOrStdout(), all)
+	})
+}
+
+// changeBackfillJob returns a command that applies change to the job named
+// by the first argument and prints the result.
+func changeBackfillJob(
+	change func(*usecases.ManageBackfillJobsUseCase, context.Context, string) (*entities.BackfillJob, error),
+) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		return withBackfillJobs(cmd, os.Stderr, func(c *container.Container, jobs repositories.BackfillJobRepository) error {
+			job, err := change(usecases.NewManageBackfillJobsUseCase(jobs, c.Logger), cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printBackfillJobs(cmd.OutOrStdout(), []*entities.BackfillJob{job})
+		})
+	}
+}
+
+func runBackfillJobs(cmd *cobra.Command, args []string) error {
+	return withBackfillJobs(cmd, os.Stdout, func(c *container.Container, jobs repositories.BackfillJobRepository) error {
+		ctx := cmd.Context()
+		logger := c.Logger
+
+		if err := c.MigrateDatabase(ctx); err != nil {
+			return err
+		}
+
+		historicalTradesService := binance.NewHistoricalTradesService(
+			c.Config.Binance.APIKey,
+			c.Config.Binance.SecretKey,
+			c.Config.Binance.UseTestnet,
+			logger,
+		)
+		historicalTradesService.SetRateLimiter(ratelimit.NewLimiter(c.Config.Binance.RequestWeight, time.Minute))
+
+		runBackfillJobsUseCase := usecases.NewRunBackfillJobsUseCase(
+			jobs,
+			clickhouse.NewTradeRepository(c.DB, entities.ExchangeBinance, entities.MarketSpot),
+			historicalTradesService,
+			c.Config.App.BackfillConcurrency,
+			logger,
+		)
+
+		logger.Info("Starting backfill jobs",
+			"concurrency", c.Config.App.BackfillConcurrency,
+			"request_weight_limit", c.Config.Binance.RequestWeight)
+
+		if err := runBackfillJobsUseCase.Execute(ctx); err != nil {
+			if errors.Is(err, context.Canceled) {
+				logger.Info("Backfill jobs interrupted, run again to continue")
+				return nil
+			}
+			logger.Error("Failed to run backfill jobs", "error", err)
+			return err
+		}
+
+		logger.Info("Backfill jobs completed")
+		return nil
+	})
+}
+
+// withBackfillJobs opens the backfill job store and runs run. Jobs are kept
+// in BACKFILL_JOBS_DIR when it is set, in ClickHouse otherwise.
+func withBackfillJobs(
+	cmd *cobra.Command,
+	logOutput io.Writer,
+	run func(c *container.Container, jobs repositories.BackfillJobRepository) error,
+) error {
+	ctx, cancel := signalContext()
+	defer cancel()
+	cmd.SetContext(ctx)
+
+	c, err := bootstrap(cmd, logOutput)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	var jobs repositories.BackfillJobRepository
+	if dir := c.Config.App.BackfillJobsDir; dir != "" {
+		if jobs, err = filestore.NewBackfillJobRepository(dir); err != nil {
+			return err
+		}
+	} else {
+		if err := c.MigrateDatabase(ctx); err != nil {
+			return err
+		}
+		jobs = clickhouse.NewBackfillJobRepository(c.DB)
+	}
+
+	return run(c, jobs)
+}
+
+func printBackfillJobs(out io.Writer, jobs []*entities.BackfillJob) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tSYMBOL\tDIRECTION\tSTATUS\tFROM\tTO\tCURSOR\tFETCHED\tUPDATED\tERROR")
+	now := time.Now()
+	for _, job := range jobs {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			job.ID, job.Symbol, job.Direction, job.State(now),
+			job.From.UTC().Format(time.RFC3339), job.To.UTC().Format(time.RFC3339),
+			job.Cursor, job.Fetched, job.UpdatedAt.UTC().Format(time.RFC3339), job.Error)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"alarket/internal/application/usecases"
+	"alarket/internal/infrastructure/config"
+	"alarket/internal/infrastructure/container"
+	"alarket/internal/infrastructure/health"
+)
+
+var (
+	collectExchanges         []string
+	collectSymbols           []string
+	collectQuoteAssets       []string
+	collectTopN              int
+	collectStreams           []string
+	collectKlineIntervals    []string
+	collectBatchSize         int
+	collectBatchFlushTimeout time.Duration
+	showStreams              bool
+)
+
+var collectCmd = &cobra.Command{
+	Use:   "collect",
+	Short: "Subscribe to the selected symbols and collect until stopped",
+	Long: `Subscribes to the live streams of the configured exchanges and stores what
+they push in ClickHouse until stopped. SIGHUP reloads the configuration.
+
+The flags override the configuration file and environment, also on reload.
+Use "collect symbols" and "collect dry-run" to check what a deployment would
+collect before pointing it at production.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+}
+
+var collectSymbolsCmd = &cobra.Command{
+	Use:   "symbols",
+	Short: "List the symbols each stream would be subscribed to",
+	Long: `Fetches the symbols of every configured exchange and prints, per stream, the
+active symbols left after the symbol selection. Nothing is stored and no
+stream is opened.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         listSymbols,
+}
+
+var collectDryRunCmd = &cobra.Command{
+	Use:   "dry-run",
+	Short: "Print the connections and streams collect would open, without connecting",
+	Long: `Fetches the symbols of every configured exchange, selects them and spreads
+their streams over connections the way "collect" would, then prints the
+connections instead of opening them.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         dryRunStreams,
+}
+
+func init() {
+	// Set here, the loader refers back to collectCmd
+	collectCmd.RunE = runCollector
+
+	addSelectionFlags(collectCmd.PersistentFlags())
+	addBatchFlags(collectCmd.Flags())
+	addDryRunFlags(collectDryRunCmd.Flags())
+
+	collectCmd.AddCommand(collectSymbolsCmd, collectDryRunCmd)
+}
+
+func addSelectionFlags(flags *pflag.FlagSet) {
+	flags.StringSliceVar(&collectExchanges, "exchanges", nil, "Exchanges to collect from, e.g. binance,bybit (overrides EXCHANGES)")
+	flags.StringSliceVar(&collectSymbols, "symbols", nil, "Only these symbols, e.g. BTCUSDT,ETHUSDT (overrides SYMBOLS for every stream)")
+	flags.StringSliceVar(&collectQuoteAssets, "quote-assets", nil, "Only symbols quoted in these assets (overrides SYMBOL_QUOTE_ASSETS for every stream)")
+	flags.IntVar(&collectTopN, "top-n", 0, "Only the N symbols with the highest 24h volume, 0 = all (overrides SYMBOL_TOP_N for every stream)")
+	flags.StringSliceVar(&collectStreams, "streams", nil, "Streams to collect: trades, aggTrades, klines, bookTickers, orderBooks, markPrices (overrides SUBSCRIBE_*)")
+	flags.StringSliceVar(&collectKlineIntervals, "kline-intervals", nil, "Kline intervals, e.g. 1m,1h (overrides KLINE_INTERVALS)")
+}
+
+func addBatchFlags(flags *pflag.FlagSet) {
+	flags.IntVar(&collectBatchSize, "batch-size", 0, "Rows per batch insert (overrides BATCH_SIZE)")
+	flags.DurationVar(&collectBatchFlushTimeout, "batch-flush-timeout", 0, "Flush incomplete batches after this long, e.g. 500ms (overrides BATCH_FLUSH_TIMEOUT_MS)")
+}
+
+func addDryRunFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&showStreams, "show-streams", false, "List the streams of every connection")
+}
+
+// takesCollectFlags reports whether cmd is collect, one of its subcommands or
+// one of their deprecated aliases.
+func takesCollectFlags(cmd *cobra.Command) bool {
+	for ; cmd != nil; cmd = cmd.Parent() {
+		if cmd == collectCmd || cmd == legacyRunCmd || cmd == legacySymbolsCmd || cmd == legacyStreamsCmd {
+			return true
+		}
+	}
+	return false
+}
+
+func runCollector(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := bootstrap(cmd, os.Stdout)
+	if err != nil {
+		return err
+	}
+	logger := c.Logger
+
+	// Register the signals before setup, an early SIGHUP would terminate the
+	// process otherwise. Reloads wait until the collector runs, a shutdown
+	// signal cancels the setup.
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigChan)
+
+	reload := make(chan struct{}, 1)
+	go func() {
+		for sig := range sigChan {
+			if sig != syscall.SIGHUP {
+				logger.Info("Shutdown signal received")
+				cancel()
+				return
+			}
+			select {
+			case reload <- struct{}{}:
+			default: // A reload is already pending
+			}
+		}
+	}()
+
+	// Close container resources, flushing what is still batched
+	defer func() {
+		if err := c.Close(); err != nil {
+			logger.Error("Error during shutdown", "error", err)
+		}
+		logger.Info("Trade Collector stopped")
+	}()
+
+	if err := c.SetupCollector(ctx); err != nil {
+		return fmt.Errorf("failed to setup collector: %w", err)
+	}
+	logger.Info("Trade Collector started",
+		"version", version,
+		"exchanges", c.Config.App.Exchanges,
+		"subscribeTrades", c.Config.App.SubscribeTrades,
+		"subscribeAggTrades", c.Config.App.SubscribeAggTrades,
+		"subscribeBookTickers", c.Config.App.SubscribeBookTickers,
+		"subscribeOrderBooks", c.Config.App.SubscribeOrderBooks,
+		"subscribeMarkPrices", c.Config.App.SubscribeMarkPrices,
+		"klineIntervals", c.KlineIntervals,
+	)
+
+	// Subscribe to symbols on every exchange
+	for _, exchange := range c.Exchanges {
+		if err := exchange.SubscribeToSymbolsUseCase.Execute(ctx, c.Streams()); err != nil {
+			return fmt.Errorf("failed to subscribe to symbols on %s: %w", exchange.Name, err)
+		}
+		c.Health.Done(health.SubscriptionsStep(exchange.Name))
+
+		if exchange.SymbolRefresher != nil {
+			exchange.SymbolRefresher.Start()
+		}
+	}
+
+	// Run until a shutdown signal cancels the context, SIGHUP reloads the
+	// configuration
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-reload:
+			logger.Info("Reload signal received")
+			if err := c.Reload(ctx); err != nil {
+				logger.Error("Failed to reload configuration", "error", err)
+			}
+		}
+	}
+}
+
+func listSymbols(cmd *cobra.Command, args []string) error {
+	c, plans, err := planSubscriptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "EXCHANGE\tSTREAM\tCOUNT\tSYMBOLS")
+	for _, plan := range plans {
+		for _, stream := range c.Streams().Enabled() {
+			names := plan.Selected[stream]
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%d/%d\t%s\n", plan.Name, stream, len(names), plan.Active, strings.Join(names, ","))
+		}
+	}
+	return w.Flush()
+}
+
+func dryRunStreams(cmd *cobra.Command, args []string) error {
+	_, plans, err := planSubscriptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	for _, plan := range plans {
+		connections := plan.Client.Connections()
+		total := 0
+		for _, connection := range connections {
+			total += len(connection.Streams)
+		}
+		_, _ = fmt.Fprintf(out, "%s: %d streams on %d connections\n", plan.Name, total, len(connections))
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, connection := range connections {
+			// Combined stream URLs list every stream, show the endpoint only
+			endpoint, _, _ := strings.Cut(connection.URL, "?")
+			_, _ = fmt.Fprintf(w, "  %s\t%d streams\t%s\n", connection.ID, len(connection.Streams), endpoint)
+			if showStreams {
+				for _, stream := range connection.Streams {
+					_, _ = fmt.Fprintf(w, "    %s\n", stream)
+				}
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// planSubscriptions plans the subscriptions of every exchange without
+// touching the database or opening a stream.
+func planSubscriptions(cmd *cobra.Command) (*container.Container, []*container.SubscriptionPlan, error) {
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	c, err := bootstrap(cmd, os.Stderr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plans, err := c.PlanSubscriptions(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, plans, nil
+}
+
+// applyCollectFlags overrides the configuration with the collect flags that
+// were set.
+func applyCollectFlags(flags *pflag.FlagSet, cfg *config.Config) error {
+	if flags.Changed("exchanges") {
+		cfg.App.Exchanges = collectExchanges
+	}
+
+	if flags.Changed("kline-intervals") {
+		cfg.App.KlineIntervals = collectKlineIntervals
+	}
+	if flags.Changed("streams") {
+		enabled := make(map[usecases.Stream]bool, len(collectStreams))
+		for _, name := range collectStreams {
+			stream := usecases.Stream(strings.TrimSpace(name))
+			switch stream {
+			case usecases.StreamTrades, usecases.StreamAggTrades, usecases.StreamKlines,
+				usecases.StreamBookTickers, usecases.StreamOrderBooks, usecases.StreamMarkPrices:
+				enabled[stream] = true
+			default:
+				return fmt.Errorf("unknown stream %q in --streams", name)
+			}
+		}
+
+		cfg.App.SubscribeTrades = enabled[usecases.StreamTrades]
+		cfg.App.SubscribeAggTrades = enabled[usecases.StreamAggTrades]
+		cfg.App.SubscribeBookTickers = enabled[usecases.StreamBookTickers]
+		cfg.App.SubscribeOrderBooks = enabled[usecases.StreamOrderBooks]
+		cfg.App.SubscribeMarkPrices = enabled[usecases.StreamMarkPrices]
+
+		switch {
+		case !enabled[usecases.StreamKlines] && flags.Changed("kline-intervals"):
+			return errors.New("--kline-intervals needs klines in --streams")
+		case !enabled[usecases.StreamKlines]:
+			cfg.App.KlineIntervals = nil
+		case len(cfg.App.KlineIntervals) == 0:
+			return errors.New("klines in --streams need kline intervals, set --kline-intervals")
+		}
+	}
+
+	overrideSelection(flags, &cfg.App.SymbolSelection)
+	for stream, selection := range cfg.App.StreamSymbolSelections {
+		overrideSelection(flags, &selection)
+		cfg.App.StreamSymbolSelections[stream] = selection
+	}
+
+	if flags.Changed("batch-size") {
+		cfg.App.BatchSize = collectBatchSize
+	}
+	if flags.Changed("batch-flush-timeout") {
+		cfg.App.BatchFlushTimeoutMs = int(collectBatchFlushTimeout / time.Millisecond)
+	}
+	return nil
+}
+
+func overrideSelection(flags *pflag.FlagSet, selection *config.SymbolSelectionConfig) {
+	if flags.Changed("symbols") {
+		selection.Symbols = collectSymbols
+	}
+	if flags.Changed("quote-assets") {
+		selection.QuoteAssets = collectQuoteAssets
+	}
+	if flags.Changed("top-n") {
+		selection.TopN = collectTopN
+	}
+}
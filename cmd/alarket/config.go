@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:          "validate",
+	Short:        "Load the configuration and report every invalid setting",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loader(cmd)()
+		if err != nil {
+			return fmt.Errorf("invalid configuration:\n%w", err)
+		}
+
+		source := "environment"
+		if path := configPath(); path != "" {
+			source = path + " and environment"
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Configuration is valid (%s), exchanges: %s\n",
+			source, strings.Join(cfg.App.Exchanges, ", "))
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
@@ -2,104 +2,75 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"encoding/csv"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
-	"os/signal"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
-	_ "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 
 	"alarket/internal/domain/entities"
 	"alarket/internal/infrastructure/clickhouse"
-	"alarket/internal/infrastructure/config"
 )
 
 var (
-	filePath string
-	symbol   string
+	importFile   string
+	importSymbol string
 )
 
-var rootCmd = &cobra.Command{
-	Use:   "file-import",
+var importCmd = &cobra.Command{
+	Use:   "import",
 	Short: "Import trade data from CSV files",
-	Long: `This tool imports trade data from CSV files into ClickHouse database.
+	Long: `Imports trade data from CSV files into ClickHouse.
 It supports large files with streaming processing and batch saves.
 
 CSV format expected:
 ID,Price,Quantity,QuoteQuantity,Timestamp,IsBuyerMaker,IsBestMatch`,
+	Args: cobra.NoArgs,
 	RunE: runFileImport,
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to CSV file to import")
-	rootCmd.Flags().StringVarP(&symbol, "symbol", "s", "", "Trading pair symbol (e.g., ETHUSDT)")
+	importCmd.Flags().StringVarP(&importFile, "file", "f", "", "Path to CSV file to import")
+	importCmd.Flags().StringVarP(&importSymbol, "symbol", "s", "", "Trading pair symbol (e.g., ETHUSDT)")
 
-	if err := rootCmd.MarkFlagRequired("file"); err != nil {
+	if err := importCmd.MarkFlagRequired("file"); err != nil {
 		panic(fmt.Sprintf("failed to mark flag as required: %v", err))
 	}
-	if err := rootCmd.MarkFlagRequired("symbol"); err != nil {
+	if err := importCmd.MarkFlagRequired("symbol"); err != nil {
 		panic(fmt.Sprintf("failed to mark flag as required: %v", err))
 	}
 }
 
 func runFileImport(cmd *cobra.Command, args []string) error {
-	// Setup context with cancellation
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := signalContext()
 	defer cancel()
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		cancel()
-	}()
-
-	cfg, err := config.Load()
+	c, err := bootstrap(cmd, os.Stdout)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
-	}
-
-	logLevel := slog.LevelInfo
-	switch cfg.App.LogLevel {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
+		return err
 	}
+	defer func() { _ = c.Close() }()
+	logger := c.Logger
 
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
-
-	db, err := setupDatabase(ctx, cfg, logger)
-	if err != nil {
+	if err := c.MigrateDatabase(ctx); err != nil {
 		return err
 	}
-	defer func() {
-		if err := db.Close(); err != nil {
-			logger.Error("Failed to close database", "error", err)
-		}
-	}()
 
-	tradeRepository := clickhouse.NewTradeRepository(db)
+	tradeRepository := clickhouse.NewTradeRepository(c.DB, entities.ExchangeBinance, entities.MarketSpot)
 
-	symbol = strings.ToUpper(symbol)
+	symbol := strings.ToUpper(importSymbol)
 
 	logger.Info("Starting CSV import",
-		"file", filePath,
+		"file", importFile,
 		"symbol", symbol)
 
-	if err := importCSVFile(ctx, filePath, symbol, tradeRepository.(*clickhouse.TradeRepository), logger); err != nil {
+	if err := importCSVFile(ctx, importFile, symbol, tradeRepository.(*clickhouse.TradeRepository), logger); err != nil {
 		logger.Error("Failed to import CSV file", "error", err)
 		return err
 	}
@@ -212,20 +183,29 @@ func parseCSVRecord(record []string, symbol string) (*entities.Trade, error) {
 	}
 
 	// Parse ID
-	id := strings.TrimSpace(record[0])
+	id, err := strconv.ParseUint(strings.TrimSpace(record[0]), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse id: %w", err)
+	}
 
 	// Parse Price
-	price, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+	price, err := decimal.NewFromString(strings.TrimSpace(record[1]))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse price: %w", err)
 	}
 
 	// Parse Quantity
-	quantity, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+	quantity, err := decimal.NewFromString(strings.TrimSpace(record[2]))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse quantity: %w", err)
 	}
 
+	// Parse QuoteQuantity
+	quoteQuantity, err := decimal.NewFromString(strings.TrimSpace(record[3]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse quote quantity: %w", err)
+	}
+
 	// Parse Timestamp (microseconds)
 	timestampMicros, err := strconv.ParseInt(strings.TrimSpace(record[4]), 10, 64)
 	if err != nil {
@@ -241,6 +221,12 @@ func parseCSVRecord(record []string, symbol string) (*entities.Trade, error) {
 		return nil, fmt.Errorf("failed to parse is_buyer_maker: %w", err)
 	}
 
+	// Parse IsBestMatch
+	isBestMatch, err := strconv.ParseBool(strings.TrimSpace(record[6]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse is_best_match: %w", err)
+	}
+
 	// Create trade entity
 	trade := entities.NewTrade(
 		id,
@@ -250,47 +236,12 @@ func parseCSVRecord(record []string, symbol string) (*entities.Trade, error) {
 		tradeTime,
 		isBuyerMaker,
 		tradeTime, // Using trade time as event time for CSV data
+		entities.TradeSourceCSV,
 	)
+	trade.QuoteQuantity = quoteQuantity
+	trade.IsBestMatch = isBestMatch
+	trade.Exchange = entities.ExchangeBinance
+	trade.Market = entities.MarketSpot
 
 	return trade, nil
 }
-
-func setupDatabase(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*sql.DB, error) {
-	dsn := fmt.Sprintf("clickhouse://%s:%s@%s:%d/%s?debug=%t",
-		cfg.ClickHouse.Username,
-		cfg.ClickHouse.Password,
-		cfg.ClickHouse.Host,
-		cfg.ClickHouse.Port,
-		cfg.ClickHouse.Database,
-		cfg.ClickHouse.Debug,
-	)
-
-	db, err := sql.Open("clickhouse", dsn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
-	}
-
-	if err := db.PingContext(ctx); err != nil {
-		if closeErr := db.Close(); closeErr != nil {
-			logger.Error("Failed to close database after ping error", "error", closeErr)
-		}
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	// Run migrations
-	migrator := clickhouse.NewMigrator(db, logger)
-	if err := migrator.Migrate(ctx); err != nil {
-		if closeErr := db.Close(); closeErr != nil {
-			logger.Error("Failed to close database after migration error", "error", closeErr)
-		}
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
-	}
-
-	return db, nil
-}
-
-func main() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
-	}
-}
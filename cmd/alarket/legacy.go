@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// The commands of the former trade-collector binary, kept working under
+// their old paths until the next major release.
+
+var legacyRunCmd = &cobra.Command{
+	Use:          "run",
+	Short:        "Subscribe to the selected symbols and collect until stopped",
+	Deprecated:   `use "alarket collect" instead`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+}
+
+var legacySymbolsCmd = &cobra.Command{
+	Use:    "symbols",
+	Short:  "Inspect the symbol selection",
+	Hidden: true,
+}
+
+var legacySymbolsListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List the symbols each stream would be subscribed to",
+	Deprecated:   `use "alarket collect symbols" instead`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+}
+
+var legacyStreamsCmd = &cobra.Command{
+	Use:    "streams",
+	Short:  "Inspect the stream allocation",
+	Hidden: true,
+}
+
+var legacyStreamsDryRunCmd = &cobra.Command{
+	Use:          "dry-run",
+	Short:        "Print the connections and streams a run would open, without connecting",
+	Deprecated:   `use "alarket collect dry-run" instead`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+}
+
+func init() {
+	// Set here, the loader refers back to these commands
+	legacyRunCmd.RunE = runCollector
+	legacySymbolsListCmd.RunE = listSymbols
+	legacyStreamsDryRunCmd.RunE = dryRunStreams
+
+	addSelectionFlags(legacyRunCmd.Flags())
+	addBatchFlags(legacyRunCmd.Flags())
+	addSelectionFlags(legacySymbolsCmd.PersistentFlags())
+	addSelectionFlags(legacyStreamsCmd.PersistentFlags())
+	addDryRunFlags(legacyStreamsDryRunCmd.Flags())
+
+	legacySymbolsCmd.AddCommand(legacySymbolsListCmd)
+	legacyStreamsCmd.AddCommand(legacyStreamsDryRunCmd)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+
+	_ "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/spf13/cobra"
+
+	"alarket/internal/infrastructure/config"
+	"alarket/internal/infrastructure/container"
+)
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+var configFile string
+
+var rootCmd = &cobra.Command{
+	Use:   "alarket",
+	Short: "Collect, backfill and query crypto market data in ClickHouse",
+	Long: `Alarket collects live market data from the configured exchanges, backfills
+history from their REST APIs, imports CSV dumps and manages the ClickHouse
+schema they are stored in.
+
+Every command reads the same configuration: the YAML file named by --config or
+CONFIG_FILE with the environment variables layered on top.`,
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "alarket %s (%s %s/%s)\n",
+			version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "YAML config file (defaults to CONFIG_FILE)")
+
+	rootCmd.AddCommand(collectCmd, backfillCmd, importCmd, migrateCmd, queryCmd, configCmd, versionCmd)
+	rootCmd.AddCommand(legacyRunCmd, legacySymbolsCmd, legacyStreamsCmd)
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// loader returns a container.Loader that reads the configuration file and
+// environment and, for collect, layers its flags on top.
+func loader(cmd *cobra.Command) container.Loader {
+	return func() (*config.Config, error) {
+		cfg, err := config.LoadFile(configPath())
+		if err != nil {
+			return nil, err
+		}
+		if takesCollectFlags(cmd) {
+			if err := applyCollectFlags(cmd.Flags(), cfg); err != nil {
+				return nil, err
+			}
+		}
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+		return cfg, nil
+	}
+}
+
+// configPath returns the config file named by --config or CONFIG_FILE.
+func configPath() string {
+	if configFile != "" {
+		return configFile
+	}
+	return os.Getenv("CONFIG_FILE")
+}
+
+// bootstrap loads the configuration and sets up logging to logOutput.
+// Commands that print results log to stderr to keep stdout clean.
+func bootstrap(cmd *cobra.Command, logOutput io.Writer) (*container.Container, error) {
+	return container.New(loader(cmd), logOutput)
+}
+
+// signalContext is cancelled on SIGINT or SIGTERM.
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"alarket/internal/infrastructure/clickhouse"
+)
+
+var migrateDryRun bool
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage the ClickHouse schema",
+	Long: `Applies and rolls back the versioned schema migrations embedded in the
+binary. Applied migrations are tracked in the schema_migrations table.`,
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply all pending migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(cmd, func(ctx context.Context, migrator *clickhouse.Migrator) error {
+			return migrator.Up(ctx)
+		})
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Roll back the most recently applied migration",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(cmd, func(ctx context.Context, migrator *clickhouse.Migrator) error {
+			return migrator.Down(ctx)
+		})
+	},
+}
+
+var migrateToCmd = &cobra.Command{
+	Use:   "to <version>",
+	Short: "Migrate up or down to the given version (0 rolls back everything)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+
+		return withMigrator(cmd, func(ctx context.Context, migrator *clickhouse.Migrator) error {
+			return migrator.To(ctx, version)
+		})
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which migrations have been applied",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(cmd, func(ctx context.Context, migrator *clickhouse.Migrator) error {
+			statuses, err := migrator.Status(ctx)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+			for _, status := range statuses {
+				state, appliedAt := "pending", "-"
+				if status.Applied {
+					state, appliedAt = "applied", status.AppliedAt.Format("2006-01-02 15:04:05")
+				}
+				_, _ = fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+			}
+			return w.Flush()
+		})
+	},
+}
+
+func init() {
+	migrateCmd.PersistentFlags().BoolVar(&migrateDryRun, "dry-run", false, "Print the statements that would run without executing them")
+
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateToCmd, migrateStatusCmd)
+}
+
+// withMigrator connects to ClickHouse, leaving the schema as it is, and runs
+// run with a migrator.
+func withMigrator(cmd *cobra.Command, run func(ctx context.Context, migrator *clickhouse.Migrator) error) error {
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	c, err := bootstrap(cmd, os.Stdout)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	if err := c.OpenDatabase(ctx); err != nil {
+		return err
+	}
+
+	migrator, err := clickhouse.NewMigrator(c.DB, c.Logger)
+	if err != nil {
+		return err
+	}
+	migrator.SetDryRun(migrateDryRun)
+
+	return run(ctx, migrator)
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/infrastructure/clickhouse"
+	"alarket/internal/infrastructure/container"
+)
+
+var (
+	querySymbol   string
+	queryExchange string
+	queryMarket   string
+	queryDays     int
+	queryFrom     string
+	queryTo       string
+	queryLimit    int
+	queryInterval string
+	queryActive   bool
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Print stored market data",
+	Long: `Reads what the collector and backfills stored in ClickHouse and prints it as
+a table. Only the database is used, the exchanges are never contacted.`,
+}
+
+var queryTradesCmd = &cobra.Command{
+	Use:          "trades",
+	Short:        "Print the stored trades of a symbol",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         queryTrades,
+}
+
+var queryKlinesCmd = &cobra.Command{
+	Use:          "klines",
+	Short:        "Print the stored klines of a symbol",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         queryKlines,
+}
+
+var queryGapsCmd = &cobra.Command{
+	Use:          "gaps",
+	Short:        "Print the trade ID gaps detected on the live stream of a symbol",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         queryGaps,
+}
+
+var querySymbolsCmd = &cobra.Command{
+	Use:          "symbols",
+	Short:        "Print the stored symbols of an exchange and market",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         querySymbols,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{queryTradesCmd, queryKlinesCmd, queryGapsCmd} {
+		cmd.Flags().StringVarP(&querySymbol, "symbol", "s", "", "Trading pair symbol (e.g., BTCUSDT)")
+		cmd.Flags().IntVarP(&queryDays, "days", "d", 1, "Number of days to print when --from is not set")
+		cmd.Flags().StringVar(&queryFrom, "from", "", "Start of the range (YYYY-MM-DD or RFC3339, default: now - days)")
+		cmd.Flags().StringVar(&queryTo, "to", "", "End of the range, exclusive (YYYY-MM-DD or RFC3339, default: now)")
+		if err := cmd.MarkFlagRequired("symbol"); err != nil {
+			panic(fmt.Sprintf("failed to mark flag as required: %v", err))
+		}
+	}
+	for _, cmd := range []*cobra.Command{queryTradesCmd, queryKlinesCmd} {
+		cmd.Flags().IntVarP(&queryLimit, "limit", "n", 100, "Print at most this many rows, 0 = all")
+	}
+	for _, cmd := range []*cobra.Command{queryTradesCmd, queryKlinesCmd, querySymbolsCmd} {
+		cmd.Flags().StringVarP(&queryExchange, "exchange", "e", string(entities.ExchangeBinance), "Exchange: binance or bybit")
+		cmd.Flags().StringVarP(&queryMarket, "market", "m", string(entities.MarketSpot), "Market: spot, usdm or coinm")
+	}
+
+	queryKlinesCmd.Flags().StringVarP(&queryInterval, "interval", "i", "1m", "Kline interval (e.g., 1m, 1h, 1d)")
+	querySymbolsCmd.Flags().BoolVar(&queryActive, "active", false, "Only symbols that are trading")
+
+	queryCmd.AddCommand(queryTradesCmd, queryKlinesCmd, queryGapsCmd, querySymbolsCmd)
+}
+
+func queryTrades(cmd *cobra.Command, args []string) error {
+	exchange, market, err := exchangeMarket()
+	if err != nil {
+		return err
+	}
+	from, to, err := timeRange(queryDays, queryFrom, queryTo)
+	if err != nil {
+		return err
+	}
+
+	return withDatabase(cmd, func(c *container.Container) error {
+		trades, err := clickhouse.NewTradeRepository(c.DB, exchange, market).
+			GetBySymbol(cmd.Context(), strings.ToUpper(querySymbol), from, to)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "TIME\tID\tPRICE\tQUANTITY\tSIDE")
+		for _, trade := range limit(trades, queryLimit) {
+			side := "buy"
+			if trade.IsBuyerMaker {
+				side = "sell"
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n",
+				trade.Time.UTC().Format(time.RFC3339Nano), trade.ID, trade.Price, trade.Quantity, side)
+		}
+		return flushCounted(w, cmd.ErrOrStderr(), len(trades), queryLimit)
+	})
+}
+
+func queryKlines(cmd *cobra.Command, args []string) error {
+	exchange, market, err := exchangeMarket()
+	if err != nil {
+		return err
+	}
+	interval, err := entities.ParseKlineInterval(queryInterval)
+	if err != nil {
+		return err
+	}
+	from, to, err := timeRange(queryDays, queryFrom, queryTo)
+	if err != nil {
+		return err
+	}
+
+	return withDatabase(cmd, func(c *container.Container) error {
+		klines, err := clickhouse.NewKlineRepository(c.DB, exchange, market).
+			GetBySymbol(cmd.Context(), strings.ToUpper(querySymbol), interval, from, to)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "OPEN TIME\tOPEN\tHIGH\tLOW\tCLOSE\tVOLUME\tTRADES")
+		for _, kline := range limit(klines, queryLimit) {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+				kline.OpenTime.UTC().Format(time.RFC3339), kline.Open, kline.High, kline.Low, kline.Close,
+				kline.Volume, kline.TradeCount)
+		}
+		return flushCounted(w, cmd.ErrOrStderr(), len(klines), queryLimit)
+	})
+}
+
+func queryGaps(cmd *cobra.Command, args []string) error {
+	from, to, err := timeRange(queryDays, queryFrom, queryTo)
+	if err != nil {
+		return err
+	}
+
+	return withDatabase(cmd, func(c *container.Container) error {
+		gaps, err := clickhouse.NewTradeGapRepository(c.DB).
+			GetBySymbol(cmd.Context(), strings.ToUpper(querySymbol), from, to)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "DETECTED AT\tFROM ID\tTO ID\tMISSING")
+		for _, gap := range gaps {
+			_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%d\n",
+				gap.DetectedAt.UTC().Format(time.RFC3339), gap.FromID, gap.ToID, gap.Size())
+		}
+		return w.Flush()
+	})
+}
+
+func querySymbols(cmd *cobra.Command, args []string) error {
+	exchange, market, err := exchangeMarket()
+	if err != nil {
+		return err
+	}
+
+	return withDatabase(cmd, func(c *container.Container) error {
+		repository := clickhouse.NewSymbolRepository(c.DB, exchange, market)
+
+		var symbols []*entities.Symbol
+		if queryActive {
+			symbols, err = repository.GetActive(cmd.Context())
+		} else {
+			symbols, err = repository.GetAll(cmd.Context())
+		}
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "SYMBOL\tBASE\tQUOTE\tSTATUS\tTICK SIZE\tSTEP SIZE\tMIN NOTIONAL")
+		for _, symbol := range symbols {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				symbol.Name, symbol.BaseAsset, symbol.QuoteAsset, symbol.Status,
+				symbol.TickSize, symbol.StepSize, symbol.MinNotional)
+		}
+		return w.Flush()
+	})
+}
+
+// withDatabase connects to ClickHouse, logging to stderr so stdout only
+// carries the result, and runs run. The schema is left as it is.
+func withDatabase(cmd *cobra.Command, run func(c *container.Container) error) error {
+	ctx, cancel := signalContext()
+	defer cancel()
+	cmd.SetContext(ctx)
+
+	c, err := bootstrap(cmd, os.Stderr)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	if err := c.OpenDatabase(ctx); err != nil {
+		return err
+	}
+	return run(c)
+}
+
+func exchangeMarket() (entities.Exchange, entities.Market, error) {
+	exchange, err := entities.ParseExchange(queryExchange)
+	if err != nil {
+		return "", "", err
+	}
+	market, err := entities.ParseMarket(queryMarket)
+	if err != nil {
+		return "", "", err
+	}
+	return exchange, market, nil
+}
+
+// limit returns the first n rows, all of them when n is 0.
+func limit[T any](rows []T, n int) []T {
+	if n > 0 && len(rows) > n {
+		return rows[:n]
+	}
+	return rows
+}
+
+// flushCounted flushes w and notes on errOut when rows were left out.
+func flushCounted(w *tabwriter.Writer, errOut io.Writer, total, n int) error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if n > 0 && total > n {
+		_, _ = fmt.Fprintf(errOut, "%d of %d rows shown, raise --limit to see more\n", n, total)
+	}
+	return nil
+}
@@ -1,18 +1,79 @@
 package dto
 
+import "encoding/json"
+
 type TradeEventDTO struct {
 	EventType          string `json:"e"`
 	EventTime          int64  `json:"E"`
 	Symbol             string `json:"s"`
-	TradeID            int64  `json:"t"`
+	TradeID            uint64 `json:"t"`
 	Price              string `json:"p"`
 	Quantity           string `json:"q"`
+	BuyerOrderID       uint64 `json:"b"` // Not sent by every stream version, 0 when missing
+	SellerOrderID      uint64 `json:"a"` // Not sent by every stream version, 0 when missing
 	TradeTime          int64  `json:"T"`
 	IsBuyerMarketMaker bool   `json:"m"`
-	Ignore             bool   `json:"M"` // Ignore the uppercase M field
+	IsBestMatch        bool   `json:"M"`
+}
+
+type AggTradeEventDTO struct {
+	EventType          string `json:"e"`
+	EventTime          int64  `json:"E"`
+	Symbol             string `json:"s"`
+	AggTradeID         uint64 `json:"a"`
+	Price              string `json:"p"`
+	Quantity           string `json:"q"`
+	FirstTradeID       uint64 `json:"f"`
+	LastTradeID        uint64 `json:"l"`
+	TradeTime          int64  `json:"T"`
+	IsBuyerMarketMaker bool   `json:"m"`
+	IsBestMatch        bool   `json:"M"`
+}
+
+type KlineEventDTO struct {
+	EventType string   `json:"e"`
+	EventTime int64    `json:"E"`
+	Symbol    string   `json:"s"`
+	Kline     KlineDTO `json:"k"`
+}
+
+type KlineDTO struct {
+	OpenTime            int64  `json:"t"`
+	CloseTime           int64  `json:"T"`
+	Symbol              string `json:"s"`
+	Interval            string `json:"i"`
+	FirstTradeID        int64  `json:"f"`
+	LastTradeID         int64  `json:"L"`
+	Open                string `json:"o"`
+	Close               string `json:"c"`
+	High                string `json:"h"`
+	Low                 string `json:"l"`
+	Volume              string `json:"v"`
+	TradeCount          uint64 `json:"n"`
+	IsClosed            bool   `json:"x"`
+	QuoteVolume         string `json:"q"`
+	TakerBuyVolume      string `json:"V"`
+	TakerBuyQuoteVolume string `json:"Q"`
 }
 
+// DepthUpdateEventDTO is a diff depth event. Each level is a [price, quantity]
+// pair of strings.
+type DepthUpdateEventDTO struct {
+	EventType     string      `json:"e"`
+	EventTime     int64       `json:"E"`
+	Symbol        string      `json:"s"`
+	FirstUpdateID uint64      `json:"U"`
+	FinalUpdateID uint64      `json:"u"`
+	Bids          [][2]string `json:"b"`
+	Asks          [][2]string `json:"a"`
+}
+
+// BookTickerEventDTO is a best bid/ask update. Spot frames carry only the
+// update ID; futures frames also carry the event type and both timestamps.
 type BookTickerEventDTO struct {
+	EventType       string `json:"e,omitempty"`
+	EventTime       int64  `json:"E,omitempty"`
+	TransactionTime int64  `json:"T,omitempty"`
 	UpdateID        int64  `json:"u"`
 	Symbol          string `json:"s"`
 	BestBidPrice    string `json:"b"`
@@ -21,6 +82,19 @@ type BookTickerEventDTO struct {
 	BestAskQuantity string `json:"A"`
 }
 
+// MarkPriceEventDTO is a futures markPriceUpdate event. Delivery contracts
+// send an empty funding rate and a zero next funding time.
+type MarkPriceEventDTO struct {
+	EventType            string `json:"e"`
+	EventTime            int64  `json:"E"`
+	Symbol               string `json:"s"`
+	MarkPrice            string `json:"p"`
+	IndexPrice           string `json:"i"`
+	EstimatedSettlePrice string `json:"P"`
+	FundingRate          string `json:"r"`
+	NextFundingTime      int64  `json:"T"`
+}
+
 type SubscriptionRequest struct {
 	Method string   `json:"method"`
 	Params []string `json:"params"`
@@ -28,6 +102,19 @@ type SubscriptionRequest struct {
 }
 
 type SubscriptionResponse struct {
-	Result interface{} `json:"result"`
-	ID     int         `json:"id"`
+	Result interface{}        `json:"result"`
+	Error  *SubscriptionError `json:"error"`
+	ID     int                `json:"id"`
+}
+
+// CombinedStreamMessage wraps every payload received on a combined stream
+// connection (/stream?streams=...).
+type CombinedStreamMessage struct {
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type SubscriptionError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
 }
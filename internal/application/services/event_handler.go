@@ -1,136 +1,143 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"strconv"
-	"time"
 
 	"alarket/internal/application/dto"
 	"alarket/internal/application/usecases"
 	"alarket/internal/domain/entities"
+	"alarket/internal/domain/services"
 )
 
+// EventHandler decodes frames of one exchange and routes the events to their
+// use cases.
 type EventHandler struct {
+	decoder             services.MessageDecoder
 	processTradeUC      *usecases.ProcessTradeEventUseCase
+	processAggTradeUC   *usecases.ProcessAggTradeEventUseCase // nil = aggregate trades are ignored
+	processKlineUC      *usecases.ProcessKlineEventUseCase    // nil = klines are ignored
 	processBookTickerUC *usecases.ProcessBookTickerEventUseCase
+	processMarkPriceUC  *usecases.ProcessMarkPriceEventUseCase // nil = mark prices are ignored
+	gapDetector         *TradeGapDetector                      // nil = gap detection disabled
+	orderBookManager    *OrderBookManager                      // nil = order books are ignored
 	logger              *slog.Logger
 }
 
 func NewEventHandler(
+	decoder services.MessageDecoder,
 	processTradeUC *usecases.ProcessTradeEventUseCase,
+	processAggTradeUC *usecases.ProcessAggTradeEventUseCase,
+	processKlineUC *usecases.ProcessKlineEventUseCase,
 	processBookTickerUC *usecases.ProcessBookTickerEventUseCase,
+	processMarkPriceUC *usecases.ProcessMarkPriceEventUseCase,
+	gapDetector *TradeGapDetector,
+	orderBookManager *OrderBookManager,
 	logger *slog.Logger,
 ) *EventHandler {
 	return &EventHandler{
+		decoder:             decoder,
 		processTradeUC:      processTradeUC,
+		processAggTradeUC:   processAggTradeUC,
+		processKlineUC:      processKlineUC,
 		processBookTickerUC: processBookTickerUC,
+		processMarkPriceUC:  processMarkPriceUC,
+		gapDetector:         gapDetector,
+		orderBookManager:    orderBookManager,
 		logger:              logger,
 	}
 }
 
 func (h *EventHandler) HandleMessage(ctx context.Context, message []byte) error {
-	var baseEvent map[string]interface{}
-	if err := json.Unmarshal(message, &baseEvent); err != nil {
-		h.logger.Error("Failed to parse message", "error", err)
+	events, err := h.decode(message)
+	if err != nil {
+		h.logger.Error("Failed to decode message", "error", err)
 		return err
 	}
 
-	// Check if it's a trade event (has "e" field)
-	eventType, hasEventType := baseEvent["e"].(string)
-	if hasEventType {
-		switch eventType {
-		case "trade":
-			if baseEvent["m"] == false {
-				fmt.Println(baseEvent)
-			}
-			return h.handleTradeEvent(ctx, message)
-		default:
-			h.logger.Debug("Unknown event type", "type", eventType)
-			return nil
-		}
+	if len(events) == 0 {
+		h.logger.Debug("Received non-event message", "message", string(message))
+		return nil
 	}
 
-	// Check if it's a book ticker (has "u" field for updateId)
-	if _, isBookTicker := baseEvent["u"]; isBookTicker {
-		return h.handleBookTickerEvent(ctx, message)
+	for _, event := range events {
+		if err := h.handleEvent(ctx, event); err != nil {
+			return err
+		}
 	}
-
-	h.logger.Debug("Received non-event message", "message", string(message))
 	return nil
 }
 
-func (h *EventHandler) handleTradeEvent(ctx context.Context, message []byte) error {
-	var event dto.TradeEventDTO
-	if err := json.Unmarshal(message, &event); err != nil {
-		h.logger.Error("Failed to parse trade event", "error", err)
-		return err
+// decode unwraps frames of combined stream connections and, when the decoder
+// supports it, decodes the payload by its stream name.
+func (h *EventHandler) decode(message []byte) ([]any, error) {
+	if !bytes.HasPrefix(message, []byte(`{"stream"`)) {
+		return h.decoder.Decode(message)
 	}
 
-	price, err := strconv.ParseFloat(event.Price, 64)
-	if err != nil {
-		return fmt.Errorf("invalid price: %w", err)
+	var envelope dto.CombinedStreamMessage
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse combined stream message: %w", err)
 	}
 
-	quantity, err := strconv.ParseFloat(event.Quantity, 64)
-	if err != nil {
-		return fmt.Errorf("invalid quantity: %w", err)
+	if decoder, ok := h.decoder.(services.StreamDecoder); ok {
+		return decoder.DecodeStream(envelope.Stream, envelope.Data)
 	}
-
-	trade := entities.NewTrade(
-		strconv.FormatInt(event.TradeID, 10),
-		event.Symbol,
-		price,
-		quantity,
-		time.UnixMilli(event.TradeTime),
-		event.IsBuyerMarketMaker,
-		time.UnixMilli(event.EventTime),
-	)
-
-	return h.processTradeUC.Execute(ctx, trade)
+	return h.decoder.Decode(envelope.Data)
 }
 
-func (h *EventHandler) handleBookTickerEvent(ctx context.Context, message []byte) error {
-	var event dto.BookTickerEventDTO
-	if err := json.Unmarshal(message, &event); err != nil {
-		h.logger.Error("Failed to parse book ticker event", "error", err)
-		return err
-	}
-
-	bidPrice, err := strconv.ParseFloat(event.BestBidPrice, 64)
-	if err != nil {
-		return fmt.Errorf("invalid bid price: %w", err)
-	}
-
-	bidQuantity, err := strconv.ParseFloat(event.BestBidQuantity, 64)
-	if err != nil {
-		return fmt.Errorf("invalid bid quantity: %w", err)
+func (h *EventHandler) handleEvent(ctx context.Context, event any) error {
+	switch e := event.(type) {
+	case *entities.Trade:
+		return h.handleTrade(ctx, e)
+	case *entities.AggTrade:
+		if h.processAggTradeUC == nil {
+			h.logger.Debug("Aggregate trade collection disabled, skipping event")
+			return nil
+		}
+		return h.processAggTradeUC.Execute(ctx, e)
+	case *entities.Kline:
+		if h.processKlineUC == nil {
+			h.logger.Debug("Kline collection disabled, skipping event")
+			return nil
+		}
+		// Binance pushes the open candle every couple of seconds, only the
+		// final update is stored
+		if !e.IsClosed {
+			return nil
+		}
+		return h.processKlineUC.Execute(ctx, e)
+	case *entities.DepthUpdate:
+		if h.orderBookManager == nil {
+			h.logger.Debug("Order books disabled, skipping depth update")
+			return nil
+		}
+		h.orderBookManager.HandleDepthUpdate(e)
+		return nil
+	case *entities.BookTicker:
+		return h.processBookTickerUC.Execute(ctx, e)
+	case *entities.MarkPrice:
+		if h.processMarkPriceUC == nil {
+			h.logger.Debug("Mark price collection disabled, skipping event")
+			return nil
+		}
+		return h.processMarkPriceUC.Execute(ctx, e)
+	default:
+		h.logger.Debug("Unknown event type", "type", e)
+		return nil
 	}
+}
 
-	askPrice, err := strconv.ParseFloat(event.BestAskPrice, 64)
-	if err != nil {
-		return fmt.Errorf("invalid ask price: %w", err)
+func (h *EventHandler) handleTrade(ctx context.Context, trade *entities.Trade) error {
+	if err := h.processTradeUC.Execute(ctx, trade); err != nil {
+		return err
 	}
 
-	askQuantity, err := strconv.ParseFloat(event.BestAskQuantity, 64)
-	if err != nil {
-		return fmt.Errorf("invalid ask quantity: %w", err)
+	if h.gapDetector != nil {
+		h.gapDetector.Observe(trade.Symbol, trade.ID)
 	}
-
-	// Book ticker events don't have timestamps, so we use current time
-	now := time.Now()
-	bookTicker := entities.NewBookTicker(
-		event.UpdateID,
-		event.Symbol,
-		bidPrice,
-		bidQuantity,
-		askPrice,
-		askQuantity,
-		now, // transaction time
-		now, // event time
-	)
-
-	return h.processBookTickerUC.Execute(ctx, bookTicker)
+	return nil
 }
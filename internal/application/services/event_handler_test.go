@@ -3,13 +3,17 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"strings"
 	"testing"
 	"time"
 
 	"alarket/internal/application/dto"
 	"alarket/internal/application/usecases"
+	"alarket/internal/domain/entities"
 	"alarket/internal/domain/mocks"
+	"alarket/internal/infrastructure/binance"
 	"alarket/internal/infrastructure/clickhouse"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -29,6 +33,8 @@ func TestNewEventHandler(t *testing.T) {
 		logger,
 		10,                  // small batch size
 		10*time.Millisecond, // short timeout
+		clickhouse.DefaultFlushPoolConfig(),
+		nil,
 	)
 	defer func() { _ = tradeBatchProcessor.Close() }()
 
@@ -37,6 +43,8 @@ func TestNewEventHandler(t *testing.T) {
 		logger,
 		10,
 		10*time.Millisecond,
+		clickhouse.DefaultFlushPoolConfig(),
+		nil,
 	)
 	defer func() { _ = bookTickerBatchProcessor.Close() }()
 
@@ -44,7 +52,7 @@ func TestNewEventHandler(t *testing.T) {
 	processTradeUC := usecases.NewProcessTradeEventUseCase(tradeBatchProcessor, logger)
 	processBookTickerUC := usecases.NewProcessBookTickerEventUseCase(bookTickerBatchProcessor, logger)
 
-	handler := NewEventHandler(processTradeUC, processBookTickerUC, logger)
+	handler := NewEventHandler(binance.NewDecoder(entities.MarketSpot), processTradeUC, nil, nil, processBookTickerUC, nil, nil, nil, logger)
 
 	assert.NotNil(t, handler)
 	assert.NotNil(t, handler.processTradeUC)
@@ -70,6 +78,8 @@ func TestEventHandler_HandleMessage_TradeEvent(t *testing.T) {
 			logger,
 			1, // batch size of 1 for immediate flush
 			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
 		)
 		defer func() { _ = tradeBatchProcessor.Close() }()
 
@@ -78,13 +88,15 @@ func TestEventHandler_HandleMessage_TradeEvent(t *testing.T) {
 			logger,
 			10,
 			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
 		)
 		defer func() { _ = bookTickerBatchProcessor.Close() }()
 
 		// Create use cases and handler
 		processTradeUC := usecases.NewProcessTradeEventUseCase(tradeBatchProcessor, logger)
 		processBookTickerUC := usecases.NewProcessBookTickerEventUseCase(bookTickerBatchProcessor, logger)
-		handler := NewEventHandler(processTradeUC, processBookTickerUC, logger)
+		handler := NewEventHandler(binance.NewDecoder(entities.MarketSpot), processTradeUC, nil, nil, processBookTickerUC, nil, nil, nil, logger)
 
 		// Create trade event
 		tradeEvent := dto.TradeEventDTO{
@@ -96,7 +108,7 @@ func TestEventHandler_HandleMessage_TradeEvent(t *testing.T) {
 			Quantity:           "0.01",
 			TradeTime:          time.Now().UnixMilli(),
 			IsBuyerMarketMaker: true,
-			Ignore:             false,
+			IsBestMatch:        true,
 		}
 
 		message, err := json.Marshal(tradeEvent)
@@ -111,6 +123,58 @@ func TestEventHandler_HandleMessage_TradeEvent(t *testing.T) {
 		mockTradeRepo.AssertExpectations(t)
 	})
 
+	t.Run("full payload is kept exact", func(t *testing.T) {
+		mockTradeRepo := new(mocks.MockTradeRepository)
+		mockBookTickerRepo := new(mocks.MockBookTickerRepository)
+
+		saved := make(chan *entities.Trade, 1)
+		mockTradeRepo.On("SaveBatch", mock.Anything, mock.AnythingOfType("[]*entities.Trade")).Return(nil).Run(func(args mock.Arguments) {
+			saved <- args.Get(1).([]*entities.Trade)[0]
+		}).Once()
+
+		tradeBatchProcessor := clickhouse.NewTradeBatchProcessor(
+			mockTradeRepo,
+			logger,
+			1,
+			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
+		)
+		defer func() { _ = tradeBatchProcessor.Close() }()
+
+		bookTickerBatchProcessor := clickhouse.NewBookTickerBatchProcessor(
+			mockBookTickerRepo,
+			logger,
+			10,
+			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
+		)
+		defer func() { _ = bookTickerBatchProcessor.Close() }()
+
+		processTradeUC := usecases.NewProcessTradeEventUseCase(tradeBatchProcessor, logger)
+		processBookTickerUC := usecases.NewProcessBookTickerEventUseCase(bookTickerBatchProcessor, logger)
+		handler := NewEventHandler(binance.NewDecoder(entities.MarketSpot), processTradeUC, nil, nil, processBookTickerUC, nil, nil, nil, logger)
+
+		message := []byte(`{"e":"trade","E":1700000000000,"s":"SHIBUSDT","t":18446744073709551000,"p":"0.00000123","q":"12345678.9","b":88,"a":99,"T":1700000000000,"m":true,"M":true}`)
+		require.NoError(t, handler.HandleMessage(ctx, message))
+
+		select {
+		case trade := <-saved:
+			assert.Equal(t, uint64(18446744073709551000), trade.ID)
+			assert.Equal(t, "0.00000123", trade.Price.String())
+			assert.Equal(t, "12345678.9", trade.Quantity.String())
+			assert.Equal(t, "15.185185047", trade.QuoteQuantity.String())
+			assert.Equal(t, uint64(88), trade.BuyerOrderID)
+			assert.Equal(t, uint64(99), trade.SellerOrderID)
+			assert.True(t, trade.IsBestMatch)
+			assert.Equal(t, entities.TradeSourceLive, trade.Source)
+			assert.Equal(t, entities.ExchangeBinance, trade.Exchange)
+		case <-time.After(time.Second):
+			t.Fatal("trade was not saved")
+		}
+	})
+
 	t.Run("invalid price in trade event", func(t *testing.T) {
 		handler := createTestHandler()
 
@@ -154,6 +218,268 @@ func TestEventHandler_HandleMessage_TradeEvent(t *testing.T) {
 	})
 }
 
+func TestEventHandler_HandleMessage_AggTradeEvent(t *testing.T) {
+	logger := slog.Default()
+	ctx := context.Background()
+	message := []byte(`{"e":"aggTrade","E":1700000000001,"s":"BTCUSDT","a":26129,"p":"0.01633102","q":"4.70443515","f":27781,"l":27783,"T":1700000000000,"m":true,"M":true}`)
+
+	t.Run("valid aggregate trade event", func(t *testing.T) {
+		mockAggTradeRepo := new(mocks.MockAggTradeRepository)
+
+		saved := make(chan *entities.AggTrade, 1)
+		mockAggTradeRepo.On("SaveBatch", mock.Anything, mock.AnythingOfType("[]*entities.AggTrade")).Return(nil).Run(func(args mock.Arguments) {
+			saved <- args.Get(1).([]*entities.AggTrade)[0]
+		}).Once()
+
+		aggTradeBatchProcessor := clickhouse.NewAggTradeBatchProcessor(
+			mockAggTradeRepo,
+			logger,
+			1, // batch size of 1 for immediate flush
+			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
+		)
+		defer func() { _ = aggTradeBatchProcessor.Close() }()
+
+		processAggTradeUC := usecases.NewProcessAggTradeEventUseCase(aggTradeBatchProcessor, logger)
+		handler := NewEventHandler(binance.NewDecoder(entities.MarketSpot), nil, processAggTradeUC, nil, nil, nil, nil, nil, logger)
+
+		require.NoError(t, handler.HandleMessage(ctx, message))
+
+		select {
+		case aggTrade := <-saved:
+			assert.Equal(t, uint64(26129), aggTrade.ID)
+			assert.Equal(t, "BTCUSDT", aggTrade.Symbol)
+			assert.Equal(t, "0.01633102", aggTrade.Price.String())
+			assert.Equal(t, "4.70443515", aggTrade.Quantity.String())
+			assert.Equal(t, uint64(27781), aggTrade.FirstTradeID)
+			assert.Equal(t, uint64(27783), aggTrade.LastTradeID)
+			assert.Equal(t, time.UnixMilli(1700000000000), aggTrade.Time)
+			assert.Equal(t, time.UnixMilli(1700000000001), aggTrade.EventTime)
+			assert.True(t, aggTrade.IsBuyerMaker)
+			assert.True(t, aggTrade.IsBestMatch)
+			assert.Equal(t, entities.TradeSourceLive, aggTrade.Source)
+		case <-time.After(time.Second):
+			t.Fatal("aggregate trade was not saved")
+		}
+	})
+
+	t.Run("aggregate trades ignored when disabled", func(t *testing.T) {
+		handler := createTestHandler()
+		assert.NoError(t, handler.HandleMessage(ctx, message))
+	})
+
+	t.Run("invalid price in aggregate trade event", func(t *testing.T) {
+		mockAggTradeRepo := new(mocks.MockAggTradeRepository)
+		aggTradeBatchProcessor := clickhouse.NewAggTradeBatchProcessor(
+			mockAggTradeRepo,
+			logger,
+			10,
+			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
+		)
+		defer func() { _ = aggTradeBatchProcessor.Close() }()
+
+		processAggTradeUC := usecases.NewProcessAggTradeEventUseCase(aggTradeBatchProcessor, logger)
+		handler := NewEventHandler(binance.NewDecoder(entities.MarketSpot), nil, processAggTradeUC, nil, nil, nil, nil, nil, logger)
+
+		err := handler.HandleMessage(ctx, []byte(`{"e":"aggTrade","s":"BTCUSDT","a":1,"p":"invalid","q":"1"}`))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid price")
+	})
+}
+
+func TestEventHandler_HandleMessage_KlineEvent(t *testing.T) {
+	logger := slog.Default()
+	ctx := context.Background()
+	kline := func(closed bool) []byte {
+		return []byte(fmt.Sprintf(`{"e":"kline","E":1700000060005,"s":"BTCUSDT","k":{"t":1700000000000,"T":1700000059999,"s":"BTCUSDT","i":"1m","f":100,"L":200,"o":"0.0010","c":"0.0020","h":"0.0025","l":"0.0015","v":"1000","n":101,"x":%t,"q":"1.0000","V":"500","Q":"0.500"}}`, closed))
+	}
+
+	newHandler := func(t *testing.T, repo *mocks.MockKlineRepository) *EventHandler {
+		klineBatchProcessor := clickhouse.NewKlineBatchProcessor(
+			repo,
+			logger,
+			1, // batch size of 1 for immediate flush
+			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
+		)
+		t.Cleanup(func() { _ = klineBatchProcessor.Close() })
+
+		processKlineUC := usecases.NewProcessKlineEventUseCase(klineBatchProcessor, logger)
+		return NewEventHandler(binance.NewDecoder(entities.MarketSpot), nil, nil, processKlineUC, nil, nil, nil, nil, logger)
+	}
+
+	t.Run("closed kline is stored", func(t *testing.T) {
+		mockKlineRepo := new(mocks.MockKlineRepository)
+
+		saved := make(chan *entities.Kline, 1)
+		mockKlineRepo.On("SaveBatch", mock.Anything, mock.AnythingOfType("[]*entities.Kline")).Return(nil).Run(func(args mock.Arguments) {
+			saved <- args.Get(1).([]*entities.Kline)[0]
+		}).Once()
+
+		handler := newHandler(t, mockKlineRepo)
+		require.NoError(t, handler.HandleMessage(ctx, kline(true)))
+
+		select {
+		case k := <-saved:
+			assert.Equal(t, "BTCUSDT", k.Symbol)
+			assert.Equal(t, entities.KlineInterval("1m"), k.Interval)
+			assert.Equal(t, time.UnixMilli(1700000000000), k.OpenTime)
+			assert.Equal(t, time.UnixMilli(1700000059999), k.CloseTime)
+			assert.Equal(t, "0.001", k.Open.String())
+			assert.Equal(t, "0.0025", k.High.String())
+			assert.Equal(t, "0.0015", k.Low.String())
+			assert.Equal(t, "0.002", k.Close.String())
+			assert.Equal(t, "1000", k.Volume.String())
+			assert.Equal(t, "1", k.QuoteVolume.String())
+			assert.Equal(t, "500", k.TakerBuyVolume.String())
+			assert.Equal(t, "0.5", k.TakerBuyQuoteVolume.String())
+			assert.Equal(t, uint64(101), k.TradeCount)
+			assert.True(t, k.IsClosed)
+			assert.Equal(t, entities.TradeSourceLive, k.Source)
+		case <-time.After(time.Second):
+			t.Fatal("kline was not saved")
+		}
+	})
+
+	t.Run("open kline is skipped", func(t *testing.T) {
+		mockKlineRepo := new(mocks.MockKlineRepository)
+
+		handler := newHandler(t, mockKlineRepo)
+		require.NoError(t, handler.HandleMessage(ctx, kline(false)))
+
+		time.Sleep(200 * time.Millisecond)
+		mockKlineRepo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown interval", func(t *testing.T) {
+		handler := newHandler(t, new(mocks.MockKlineRepository))
+
+		err := handler.HandleMessage(ctx, []byte(`{"e":"kline","s":"BTCUSDT","k":{"i":"7m","x":true,"o":"1","c":"1","h":"1","l":"1","v":"1","q":"1","V":"1","Q":"1"}}`))
+		assert.ErrorIs(t, err, entities.ErrInvalidInterval)
+	})
+
+	t.Run("klines ignored when disabled", func(t *testing.T) {
+		handler := createTestHandler()
+		assert.NoError(t, handler.HandleMessage(ctx, kline(true)))
+	})
+}
+
+func TestEventHandler_HandleMessage_MarkPriceEvent(t *testing.T) {
+	logger := slog.Default()
+	ctx := context.Background()
+
+	markPrice := []byte(`{"e":"markPriceUpdate","E":1700000000000,"s":"BTCUSDT","p":"37012.50000000","i":"37001.12345678","P":"37005.00000000","r":"-0.00012500","T":1700006400000}`)
+
+	t.Run("mark price is stored with its market", func(t *testing.T) {
+		mockMarkPriceRepo := new(mocks.MockMarkPriceRepository)
+
+		saved := make(chan *entities.MarkPrice, 1)
+		mockMarkPriceRepo.On("SaveBatch", mock.Anything, mock.AnythingOfType("[]*entities.MarkPrice")).Return(nil).Run(func(args mock.Arguments) {
+			saved <- args.Get(1).([]*entities.MarkPrice)[0]
+		}).Once()
+
+		markPriceBatchProcessor := clickhouse.NewMarkPriceBatchProcessor(
+			mockMarkPriceRepo,
+			logger,
+			1, // batch size of 1 for immediate flush
+			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
+		)
+		defer func() { _ = markPriceBatchProcessor.Close() }()
+
+		processMarkPriceUC := usecases.NewProcessMarkPriceEventUseCase(markPriceBatchProcessor, logger)
+		handler := NewEventHandler(binance.NewDecoder(entities.MarketUSDM), nil, nil, nil, nil, processMarkPriceUC, nil, nil, logger)
+		require.NoError(t, handler.HandleMessage(ctx, markPrice))
+
+		select {
+		case m := <-saved:
+			assert.Equal(t, "BTCUSDT", m.Symbol)
+			assert.Equal(t, "37012.5", m.MarkPrice.String())
+			assert.Equal(t, "37001.12345678", m.IndexPrice.String())
+			assert.Equal(t, "37005", m.EstimatedSettlePrice.String())
+			assert.Equal(t, "-0.000125", m.FundingRate.String())
+			assert.Equal(t, time.UnixMilli(1700006400000), m.NextFundingTime)
+			assert.Equal(t, time.UnixMilli(1700000000000), m.EventTime)
+			assert.Equal(t, entities.ExchangeBinance, m.Exchange)
+			assert.Equal(t, entities.MarketUSDM, m.Market)
+		case <-time.After(time.Second):
+			t.Fatal("mark price was not saved")
+		}
+	})
+
+	t.Run("mark prices ignored when disabled", func(t *testing.T) {
+		handler := NewEventHandler(binance.NewDecoder(entities.MarketUSDM), nil, nil, nil, nil, nil, nil, nil, logger)
+		assert.NoError(t, handler.HandleMessage(ctx, markPrice))
+	})
+}
+
+// plainDecoder only decodes whole frames, without stream names.
+type plainDecoder struct {
+	received chan []byte
+}
+
+func (d plainDecoder) Decode(message []byte) ([]any, error) {
+	d.received <- message
+	return nil, nil
+}
+
+func TestEventHandler_HandleMessage_CombinedStream(t *testing.T) {
+	logger := slog.Default()
+	ctx := context.Background()
+
+	combined := []byte(`{"stream":"btcusdt@markPrice@1s","data":{"e":"markPriceUpdate","E":1700000000000,"s":"BTCUSDT","p":"37012.5","i":"37001.1","P":"37005","r":"0.0001","T":1700006400000}}`)
+
+	t.Run("payload is decoded by stream name", func(t *testing.T) {
+		mockMarkPriceRepo := new(mocks.MockMarkPriceRepository)
+
+		saved := make(chan *entities.MarkPrice, 1)
+		mockMarkPriceRepo.On("SaveBatch", mock.Anything, mock.AnythingOfType("[]*entities.MarkPrice")).Return(nil).Run(func(args mock.Arguments) {
+			saved <- args.Get(1).([]*entities.MarkPrice)[0]
+		}).Once()
+
+		markPriceBatchProcessor := clickhouse.NewMarkPriceBatchProcessor(
+			mockMarkPriceRepo,
+			logger,
+			1, // batch size of 1 for immediate flush
+			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
+		)
+		defer func() { _ = markPriceBatchProcessor.Close() }()
+
+		processMarkPriceUC := usecases.NewProcessMarkPriceEventUseCase(markPriceBatchProcessor, logger)
+		handler := NewEventHandler(binance.NewDecoder(entities.MarketUSDM), nil, nil, nil, nil, processMarkPriceUC, nil, nil, logger)
+		require.NoError(t, handler.HandleMessage(ctx, combined))
+
+		select {
+		case m := <-saved:
+			assert.Equal(t, "BTCUSDT", m.Symbol)
+			assert.Equal(t, "37012.5", m.MarkPrice.String())
+			assert.Equal(t, entities.MarketUSDM, m.Market)
+		case <-time.After(time.Second):
+			t.Fatal("mark price was not saved")
+		}
+	})
+
+	t.Run("decoders without stream support get the payload", func(t *testing.T) {
+		decoder := plainDecoder{received: make(chan []byte, 1)}
+		handler := NewEventHandler(decoder, nil, nil, nil, nil, nil, nil, nil, logger)
+		require.NoError(t, handler.HandleMessage(ctx, combined))
+
+		assert.True(t, strings.HasPrefix(string(<-decoder.received), `{"e":"markPriceUpdate"`))
+	})
+
+	t.Run("invalid envelope", func(t *testing.T) {
+		handler := NewEventHandler(binance.NewDecoder(entities.MarketUSDM), nil, nil, nil, nil, nil, nil, nil, logger)
+		assert.ErrorContains(t, handler.HandleMessage(ctx, []byte(`{"stream":"btcusdt@trade","data":`)), "combined stream message")
+	})
+}
+
 func TestEventHandler_HandleMessage_BookTickerEvent(t *testing.T) {
 	logger := slog.Default()
 	ctx := context.Background()
@@ -172,6 +498,8 @@ func TestEventHandler_HandleMessage_BookTickerEvent(t *testing.T) {
 			logger,
 			10,
 			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
 		)
 		defer func() { _ = tradeBatchProcessor.Close() }()
 
@@ -180,13 +508,15 @@ func TestEventHandler_HandleMessage_BookTickerEvent(t *testing.T) {
 			logger,
 			1, // batch size of 1 for immediate flush
 			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
 		)
 		defer func() { _ = bookTickerBatchProcessor.Close() }()
 
 		// Create use cases and handler
 		processTradeUC := usecases.NewProcessTradeEventUseCase(tradeBatchProcessor, logger)
 		processBookTickerUC := usecases.NewProcessBookTickerEventUseCase(bookTickerBatchProcessor, logger)
-		handler := NewEventHandler(processTradeUC, processBookTickerUC, logger)
+		handler := NewEventHandler(binance.NewDecoder(entities.MarketSpot), processTradeUC, nil, nil, processBookTickerUC, nil, nil, nil, logger)
 
 		// Create book ticker event
 		bookTickerEvent := dto.BookTickerEventDTO{
@@ -304,6 +634,8 @@ func createTestHandler() *EventHandler {
 		logger,
 		100,
 		1*time.Second,
+		clickhouse.DefaultFlushPoolConfig(),
+		nil,
 	)
 
 	bookTickerBatchProcessor := clickhouse.NewBookTickerBatchProcessor(
@@ -311,6 +643,8 @@ func createTestHandler() *EventHandler {
 		logger,
 		100,
 		1*time.Second,
+		clickhouse.DefaultFlushPoolConfig(),
+		nil,
 	)
 
 	// Create use cases
@@ -318,7 +652,7 @@ func createTestHandler() *EventHandler {
 	processBookTickerUC := usecases.NewProcessBookTickerEventUseCase(bookTickerBatchProcessor, logger)
 
 	// Create handler
-	handler := NewEventHandler(processTradeUC, processBookTickerUC, logger)
+	handler := NewEventHandler(binance.NewDecoder(entities.MarketSpot), processTradeUC, nil, nil, processBookTickerUC, nil, nil, nil, logger)
 
 	// Close processors after a delay to ensure cleanup
 	go func() {
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+	"alarket/internal/domain/services"
+)
+
+const (
+	// orderBookSnapshotLimit is the number of levels requested per side when
+	// seeding a book over REST
+	orderBookSnapshotLimit = 1000
+	// maxBufferedDepthUpdates caps the diffs held per symbol while its
+	// snapshot is being fetched; the oldest are dropped first
+	maxBufferedDepthUpdates = 10000
+	// A symbol is queued at most once, so this bounds the symbols resyncing
+	// at the same time
+	orderBookResyncQueueSize = 1000
+	// defaultOrderBookInterval replaces a persist interval that is not
+	// positive, which time.NewTicker would panic on
+	defaultOrderBookInterval = time.Second
+)
+
+// orderBookState is the sync state of one symbol. book is nil until a
+// snapshot has been fetched and the buffered diffs replayed onto it.
+type orderBookState struct {
+	book    *entities.OrderBook
+	buffer  []*entities.DepthUpdate
+	syncing bool
+}
+
+// OrderBookManager keeps a local order book per symbol from the diff depth
+// stream. Diffs are buffered while a REST snapshot is fetched and replayed
+// onto it, following the Binance U/u sequencing rules; a sequence break
+// starts the process again. The best depth levels of every synced book are
+// persisted each interval.
+type OrderBookManager struct {
+	snapshotService    services.OrderBookDataService
+	snapshotRepository repositories.OrderBookSnapshotRepository
+	logger             *slog.Logger
+	depth              int
+	interval           time.Duration
+	snapshotDelay      time.Duration // pause between REST snapshots
+	retryDelay         time.Duration
+
+	mu    sync.Mutex
+	books map[string]*orderBookState
+
+	resyncs chan string
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewOrderBookManager(
+	snapshotService services.OrderBookDataService,
+	snapshotRepository repositories.OrderBookSnapshotRepository,
+	logger *slog.Logger,
+	depth int,
+	interval time.Duration,
+) *OrderBookManager {
+	if interval <= 0 {
+		logger.Warn("Invalid order book snapshot interval, using the default",
+			"interval", interval, "default", defaultOrderBookInterval)
+		interval = defaultOrderBookInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m := &OrderBookManager{
+		snapshotService:    snapshotService,
+		snapshotRepository: snapshotRepository,
+		logger:             logger,
+		depth:              depth,
+		interval:           interval,
+		snapshotDelay:      time.Second, // a 1000 level snapshot weighs 50, keep to half the 6000 per minute
+		retryDelay:         time.Second,
+		books:              make(map[string]*orderBookState),
+		resyncs:            make(chan string, orderBookResyncQueueSize),
+		ctx:                ctx,
+		cancel:             cancel,
+	}
+
+	m.wg.Add(2)
+	go m.resyncRoutine()
+	go m.persistRoutine()
+
+	return m
+}
+
+// HandleDepthUpdate applies a diff to the symbol's book, or buffers it while
+// the book is being (re)built.
+func (m *OrderBookManager) HandleDepthUpdate(update *entities.DepthUpdate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.books[update.Symbol]
+	if !ok {
+		state = &orderBookState{}
+		m.books[update.Symbol] = state
+	}
+
+	if state.book != nil {
+		err := state.book.Apply(update)
+		if err == nil {
+			return
+		}
+
+		m.logger.Warn("Order book out of sync, fetching a new snapshot",
+			"symbol", update.Symbol,
+			"error", err)
+		state.book = nil
+	}
+
+	state.buffer = append(state.buffer, update)
+	if len(state.buffer) > maxBufferedDepthUpdates {
+		state.buffer = state.buffer[len(state.buffer)-maxBufferedDepthUpdates:]
+	}
+
+	if !state.syncing {
+		state.syncing = true
+		m.queueResync(update.Symbol)
+	}
+}
+
+// Book returns the best depth levels of the symbol's book, or nil while it is
+// not in sync.
+func (m *OrderBookManager) Book(symbol string, depth int) *entities.OrderBookSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.books[symbol]
+	if !ok || state.book == nil {
+		return nil
+	}
+	return state.book.Snapshot(depth, time.Now())
+}
+
+// Remove drops the books of symbols along with their buffered diffs, so
+// nothing is persisted for them after their depth stream is unsubscribed.
+func (m *OrderBookManager) Remove(symbols []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, symbol := range symbols {
+		delete(m.books, symbol)
+	}
+}
+
+func (m *OrderBookManager) queueResync(symbol string) {
+	select {
+	case m.resyncs <- symbol:
+	default:
+		m.logger.Error("Order book resync queue full, dropping symbol", "symbol", symbol)
+		if state, ok := m.books[symbol]; ok {
+			// Let the next diff queue it again
+			state.syncing = false
+		}
+	}
+}
+
+// resync seeds the symbol's book from a REST snapshot and replays the
+// buffered diffs onto it. On a sequence break the buffer is kept from the
+// offending diff onward so the next attempt starts from there.
+func (m *OrderBookManager) resync(ctx context.Context, symbol string) error {
+	book, err := m.snapshotService.FetchOrderBook(ctx, symbol, orderBookSnapshotLimit)
+	if err != nil {
+		return fmt.Errorf("failed to fetch order book snapshot: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.books[symbol]
+	if !ok {
+		// Removed while queued
+		return nil
+	}
+	for i, update := range state.buffer {
+		if err := book.Apply(update); err != nil {
+			state.buffer = state.buffer[i:]
+			return fmt.Errorf("failed to replay buffered depth updates: %w", err)
+		}
+	}
+
+	state.book = book
+	state.buffer = nil
+	state.syncing = false
+
+	m.logger.Info("Order book synced",
+		"symbol", symbol,
+		"last_update_id", book.LastUpdateID)
+	return nil
+}
+
+func (m *OrderBookManager) resyncRoutine() {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case symbol := <-m.resyncs:
+			if err := m.resync(m.ctx, symbol); err != nil {
+				if m.ctx.Err() != nil {
+					return
+				}
+
+				// A snapshot older than the buffered diffs is expected right
+				// after subscribing, anything else is worth a warning
+				level := slog.LevelWarn
+				if errors.Is(err, entities.ErrOrderBookGap) {
+					level = slog.LevelDebug
+				}
+				m.logger.Log(m.ctx, level, "Failed to sync order book, retrying", "symbol", symbol, "error", err)
+
+				if !m.sleep(m.retryDelay) {
+					return
+				}
+				m.mu.Lock()
+				m.queueResync(symbol)
+				m.mu.Unlock()
+				continue
+			}
+
+			if !m.sleep(m.snapshotDelay) {
+				return
+			}
+		}
+	}
+}
+
+func (m *OrderBookManager) persistRoutine() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := m.persist(m.ctx, now); err != nil {
+				if m.ctx.Err() != nil {
+					return
+				}
+				m.logger.Error("Failed to save order book snapshots", "error", err)
+			}
+		}
+	}
+}
+
+// persist saves the best depth levels of every synced book.
+func (m *OrderBookManager) persist(ctx context.Context, now time.Time) error {
+	m.mu.Lock()
+	snapshots := make([]*entities.OrderBookSnapshot, 0, len(m.books))
+	for _, state := range m.books {
+		if state.book != nil {
+			snapshots = append(snapshots, state.book.Snapshot(m.depth, now))
+		}
+	}
+	m.mu.Unlock()
+
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return m.snapshotRepository.SaveBatch(ctx, snapshots)
+}
+
+// sleep waits for d and reports false when the manager is closed meanwhile.
+func (m *OrderBookManager) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-m.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// Close stops syncing and persisting. Books are kept in memory only, nothing
+// is flushed on shutdown.
+func (m *OrderBookManager) Close() error {
+	m.cancel()
+	m.wg.Wait()
+	return nil
+}
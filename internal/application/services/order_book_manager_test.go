@@ -0,0 +1,282 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/mocks"
+	"alarket/internal/infrastructure/binance"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Recorded from the depth@100ms stream and the depth endpoint, trimmed to a
+// few levels. The diffs cover a sequence break between u=1010 and U=1015.
+const orderBookFixtures = "testdata/order_book"
+
+// newTestOrderBookManager returns a manager without its background routines,
+// so tests drive resync and persist themselves.
+func newTestOrderBookManager(service *mocks.MockOrderBookDataService, repo *mocks.MockOrderBookSnapshotRepository) (*OrderBookManager, *EventHandler) {
+	logger := slog.Default()
+	m := &OrderBookManager{
+		snapshotService:    service,
+		snapshotRepository: repo,
+		logger:             logger,
+		depth:              2,
+		books:              make(map[string]*orderBookState),
+		resyncs:            make(chan string, 10),
+	}
+	return m, NewEventHandler(binance.NewDecoder(entities.MarketSpot), nil, nil, nil, nil, nil, nil, m, logger)
+}
+
+func loadOrderBookSnapshot(t *testing.T, name string) *entities.OrderBook {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(orderBookFixtures, name))
+	require.NoError(t, err)
+
+	var snapshot struct {
+		LastUpdateID uint64      `json:"lastUpdateId"`
+		Bids         [][2]string `json:"bids"`
+		Asks         [][2]string `json:"asks"`
+	}
+	require.NoError(t, json.Unmarshal(data, &snapshot))
+
+	return entities.NewOrderBook("BTCUSDT", snapshot.LastUpdateID,
+		priceLevels(t, snapshot.Bids), priceLevels(t, snapshot.Asks), time.UnixMilli(1700000000000))
+}
+
+func priceLevels(t *testing.T, pairs [][2]string) []entities.PriceLevel {
+	t.Helper()
+	levels := make([]entities.PriceLevel, 0, len(pairs))
+	for _, pair := range pairs {
+		levels = append(levels, entities.PriceLevel{
+			Price:    decimal.RequireFromString(pair[0]),
+			Quantity: decimal.RequireFromString(pair[1]),
+		})
+	}
+	return levels
+}
+
+func loadDepthMessages(t *testing.T, name string) [][]byte {
+	t.Helper()
+	file, err := os.Open(filepath.Join(orderBookFixtures, name))
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+
+	var messages [][]byte
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		messages = append(messages, append([]byte(nil), scanner.Bytes()...))
+	}
+	require.NoError(t, scanner.Err())
+	return messages
+}
+
+func bookLevels(levels []entities.PriceLevel) []string {
+	result := make([]string, 0, len(levels))
+	for _, level := range levels {
+		result = append(result, level.Price.String()+"@"+level.Quantity.String())
+	}
+	return result
+}
+
+func expectResync(t *testing.T, m *OrderBookManager) {
+	t.Helper()
+	select {
+	case symbol := <-m.resyncs:
+		assert.Equal(t, "BTCUSDT", symbol)
+	default:
+		t.Fatal("no resync queued")
+	}
+}
+
+func TestOrderBookManager_RecordedDiffs(t *testing.T) {
+	ctx := context.Background()
+	mockService := new(mocks.MockOrderBookDataService)
+	m, handler := newTestOrderBookManager(mockService, new(mocks.MockOrderBookSnapshotRepository))
+	messages := loadDepthMessages(t, "btcusdt_diffs.jsonl")
+	require.Len(t, messages, 6)
+
+	// Diffs arriving before the snapshot are buffered, a single resync is queued
+	require.NoError(t, handler.HandleMessage(ctx, messages[0]))
+	require.NoError(t, handler.HandleMessage(ctx, messages[1]))
+	expectResync(t, m)
+	assert.Empty(t, m.resyncs)
+	assert.Nil(t, m.Book("BTCUSDT", 0))
+
+	// The stale diff is dropped, the one straddling lastUpdateId is applied
+	mockService.On("FetchOrderBook", ctx, "BTCUSDT", orderBookSnapshotLimit).
+		Return(loadOrderBookSnapshot(t, "btcusdt_snapshot_1000.json"), nil).Once()
+	require.NoError(t, m.resync(ctx, "BTCUSDT"))
+
+	book := m.Book("BTCUSDT", 0)
+	require.NotNil(t, book)
+	assert.Equal(t, uint64(1003), book.LastUpdateID)
+	assert.Equal(t, []string{"50000@1.2", "49999.5@2", "49999@0.5", "49998.5@4"}, bookLevels(book.Bids))
+	assert.Equal(t, []string{"50001@3", "50001.5@0.25"}, bookLevels(book.Asks))
+
+	// Once in sync, diffs apply directly
+	require.NoError(t, handler.HandleMessage(ctx, messages[2]))
+	require.NoError(t, handler.HandleMessage(ctx, messages[3]))
+
+	book = m.Book("BTCUSDT", 0)
+	require.NotNil(t, book)
+	assert.Equal(t, uint64(1010), book.LastUpdateID)
+	assert.Equal(t, []string{"50000.25@0.3", "50000@1.2", "49999.5@2", "49998.5@4"}, bookLevels(book.Bids))
+	assert.Equal(t, []string{"50000.75@0.8", "50001@2.5", "50001.5@0.25"}, bookLevels(book.Asks))
+	assert.Empty(t, m.resyncs)
+
+	// A sequence break drops the book and queues a new snapshot
+	require.NoError(t, handler.HandleMessage(ctx, messages[4]))
+	expectResync(t, m)
+	assert.Nil(t, m.Book("BTCUSDT", 0))
+
+	require.NoError(t, handler.HandleMessage(ctx, messages[5]))
+	assert.Empty(t, m.resyncs)
+
+	mockService.On("FetchOrderBook", ctx, "BTCUSDT", orderBookSnapshotLimit).
+		Return(loadOrderBookSnapshot(t, "btcusdt_snapshot_1020.json"), nil).Once()
+	require.NoError(t, m.resync(ctx, "BTCUSDT"))
+
+	book = m.Book("BTCUSDT", 0)
+	require.NotNil(t, book)
+	assert.Equal(t, uint64(1022), book.LastUpdateID)
+	assert.Equal(t, []string{"50000@1.2", "49999.5@2", "49998.5@3"}, bookLevels(book.Bids))
+	assert.Equal(t, []string{"50000.75@0.8", "50001@2.5"}, bookLevels(book.Asks))
+
+	mockService.AssertExpectations(t)
+}
+
+func TestOrderBookManager_Resync(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("snapshot older than the buffered diffs", func(t *testing.T) {
+		mockService := new(mocks.MockOrderBookDataService)
+		m, handler := newTestOrderBookManager(mockService, nil)
+		messages := loadDepthMessages(t, "btcusdt_diffs.jsonl")
+
+		require.NoError(t, handler.HandleMessage(ctx, messages[2]))
+		require.NoError(t, handler.HandleMessage(ctx, messages[3]))
+		expectResync(t, m)
+
+		mockService.On("FetchOrderBook", ctx, "BTCUSDT", orderBookSnapshotLimit).
+			Return(loadOrderBookSnapshot(t, "btcusdt_snapshot_1000.json"), nil).Once()
+		err := m.resync(ctx, "BTCUSDT")
+		assert.ErrorIs(t, err, entities.ErrOrderBookGap)
+		assert.Nil(t, m.Book("BTCUSDT", 0))
+
+		// Diffs stay buffered for the next attempt
+		newer := loadOrderBookSnapshot(t, "btcusdt_snapshot_1000.json")
+		newer.LastUpdateID = 1005
+		mockService.On("FetchOrderBook", ctx, "BTCUSDT", orderBookSnapshotLimit).Return(newer, nil).Once()
+		require.NoError(t, m.resync(ctx, "BTCUSDT"))
+
+		book := m.Book("BTCUSDT", 0)
+		require.NotNil(t, book)
+		assert.Equal(t, uint64(1010), book.LastUpdateID)
+	})
+
+	t.Run("fetch error keeps buffering", func(t *testing.T) {
+		mockService := new(mocks.MockOrderBookDataService)
+		m, handler := newTestOrderBookManager(mockService, nil)
+		messages := loadDepthMessages(t, "btcusdt_diffs.jsonl")
+
+		require.NoError(t, handler.HandleMessage(ctx, messages[1]))
+		expectResync(t, m)
+
+		mockService.On("FetchOrderBook", ctx, "BTCUSDT", orderBookSnapshotLimit).Return(nil, errors.New("API error"))
+		err := m.resync(ctx, "BTCUSDT")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch order book snapshot")
+
+		// Still syncing, so later diffs do not queue another resync
+		require.NoError(t, handler.HandleMessage(ctx, messages[2]))
+		assert.Empty(t, m.resyncs)
+		assert.Len(t, m.books["BTCUSDT"].buffer, 2)
+	})
+}
+
+func TestOrderBookManager_Persist(t *testing.T) {
+	ctx := context.Background()
+	mockService := new(mocks.MockOrderBookDataService)
+	mockRepo := new(mocks.MockOrderBookSnapshotRepository)
+	m, handler := newTestOrderBookManager(mockService, mockRepo)
+	now := time.UnixMilli(1700000001000)
+
+	// Nothing is saved before a book is in sync
+	require.NoError(t, m.persist(ctx, now))
+	mockRepo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+
+	messages := loadDepthMessages(t, "btcusdt_diffs.jsonl")
+	require.NoError(t, handler.HandleMessage(ctx, messages[1]))
+	mockService.On("FetchOrderBook", ctx, "BTCUSDT", orderBookSnapshotLimit).
+		Return(loadOrderBookSnapshot(t, "btcusdt_snapshot_1000.json"), nil)
+	require.NoError(t, m.resync(ctx, "BTCUSDT"))
+
+	var saved []*entities.OrderBookSnapshot
+	mockRepo.On("SaveBatch", ctx, mock.AnythingOfType("[]*entities.OrderBookSnapshot")).Return(nil).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]*entities.OrderBookSnapshot)
+	}).Once()
+
+	require.NoError(t, m.persist(ctx, now))
+
+	require.Len(t, saved, 1)
+	assert.Equal(t, "BTCUSDT", saved[0].Symbol)
+	assert.Equal(t, uint64(1003), saved[0].LastUpdateID)
+	assert.Equal(t, now, saved[0].SnapshotTime)
+	assert.Equal(t, []string{"50000@1.2", "49999.5@2"}, bookLevels(saved[0].Bids))
+	assert.Equal(t, []string{"50001@3", "50001.5@0.25"}, bookLevels(saved[0].Asks))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOrderBookManager_Remove(t *testing.T) {
+	ctx := context.Background()
+	mockService := new(mocks.MockOrderBookDataService)
+	mockRepo := new(mocks.MockOrderBookSnapshotRepository)
+	m, handler := newTestOrderBookManager(mockService, mockRepo)
+
+	messages := loadDepthMessages(t, "btcusdt_diffs.jsonl")
+	require.NoError(t, handler.HandleMessage(ctx, messages[1]))
+	mockService.On("FetchOrderBook", ctx, "BTCUSDT", orderBookSnapshotLimit).
+		Return(loadOrderBookSnapshot(t, "btcusdt_snapshot_1000.json"), nil)
+	require.NoError(t, m.resync(ctx, "BTCUSDT"))
+	require.NotNil(t, m.Book("BTCUSDT", 0))
+
+	m.Remove([]string{"BTCUSDT"})
+
+	assert.Nil(t, m.Book("BTCUSDT", 0))
+	require.NoError(t, m.persist(ctx, time.Now()))
+	mockRepo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+
+	// A resync queued before the removal does not bring the book back
+	require.NoError(t, m.resync(ctx, "BTCUSDT"))
+	assert.Nil(t, m.Book("BTCUSDT", 0))
+}
+
+func TestOrderBookManager_Close(t *testing.T) {
+	mockService := new(mocks.MockOrderBookDataService)
+	mockService.On("FetchOrderBook", mock.Anything, "BTCUSDT", orderBookSnapshotLimit).
+		Return(loadOrderBookSnapshot(t, "btcusdt_snapshot_1000.json"), nil)
+
+	m := NewOrderBookManager(mockService, new(mocks.MockOrderBookSnapshotRepository), slog.Default(), 10, time.Hour)
+	m.HandleDepthUpdate(entities.NewDepthUpdate("BTCUSDT", 996, 1003, nil, nil, time.Now()))
+
+	assert.Eventually(t, func() bool { return m.Book("BTCUSDT", 10) != nil }, time.Second, 10*time.Millisecond)
+	require.NoError(t, m.Close())
+}
+
+func TestOrderBookManager_DefaultsInvalidInterval(t *testing.T) {
+	m := NewOrderBookManager(new(mocks.MockOrderBookDataService), new(mocks.MockOrderBookSnapshotRepository), slog.Default(), 10, 0)
+	assert.Equal(t, defaultOrderBookInterval, m.interval)
+	require.NoError(t, m.Close())
+}
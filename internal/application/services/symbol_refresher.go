@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"alarket/internal/application/usecases"
+)
+
+// SymbolRefresher re-runs the symbol refresh of one exchange market on an
+// interval, so new listings are picked up and delisted or halted symbols are
+// dropped while the collector runs.
+type SymbolRefresher struct {
+	refreshUC *usecases.RefreshSymbolsUseCase
+	interval  time.Duration
+	logger    *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewSymbolRefresher(refreshUC *usecases.RefreshSymbolsUseCase, interval time.Duration, logger *slog.Logger) *SymbolRefresher {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &SymbolRefresher{
+		refreshUC: refreshUC,
+		interval:  interval,
+		logger:    logger,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start begins refreshing, the first refresh happens one interval from now.
+// Call it once the initial subscription is done.
+func (r *SymbolRefresher) Start() {
+	r.wg.Add(1)
+	go r.refreshRoutine()
+}
+
+func (r *SymbolRefresher) refreshRoutine() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.refreshUC.Execute(r.ctx); err != nil {
+				if r.ctx.Err() != nil {
+					return
+				}
+				r.logger.Error("Failed to refresh symbols", "error", err)
+			}
+		}
+	}
+}
+
+// Close stops refreshing and waits for a refresh in progress.
+func (r *SymbolRefresher) Close() error {
+	r.cancel()
+	r.wg.Wait()
+	return nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"alarket/internal/application/usecases"
+	"alarket/internal/domain/entities"
+)
+
+const gapQueueSize = 1000
+
+// TradeGapDetector tracks the last trade ID seen per symbol on the live
+// stream. Binance trade IDs are contiguous, so a jump means trades were
+// missed (dropped frames, reconnects) and the range is backfilled in the
+// background.
+type TradeGapDetector struct {
+	backfillUC *usecases.BackfillTradeGapUseCase
+	logger     *slog.Logger
+
+	mu      sync.Mutex
+	lastIDs map[string]uint64
+
+	queue  chan *entities.TradeGap
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewTradeGapDetector(backfillUC *usecases.BackfillTradeGapUseCase, logger *slog.Logger) *TradeGapDetector {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d := &TradeGapDetector{
+		backfillUC: backfillUC,
+		logger:     logger,
+		lastIDs:    make(map[string]uint64),
+		queue:      make(chan *entities.TradeGap, gapQueueSize),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	d.wg.Add(1)
+	go d.backfillRoutine()
+
+	return d
+}
+
+// Observe records a trade ID from the live stream and queues a backfill when
+// it does not directly follow the previous one.
+func (d *TradeGapDetector) Observe(symbol string, tradeID uint64) {
+	d.mu.Lock()
+	lastID, seen := d.lastIDs[symbol]
+	if seen && tradeID <= lastID {
+		// Duplicate or out of order, keep the highest ID
+		d.mu.Unlock()
+		return
+	}
+	d.lastIDs[symbol] = tradeID
+	d.mu.Unlock()
+
+	if !seen || tradeID == lastID+1 {
+		return
+	}
+
+	gap := entities.NewTradeGap(symbol, lastID+1, tradeID-1, time.Now())
+	d.logger.Warn("Trade sequence gap detected",
+		"symbol", symbol,
+		"from_id", gap.FromID,
+		"to_id", gap.ToID,
+		"missing", gap.Size())
+
+	select {
+	case d.queue <- gap:
+	default:
+		d.logger.Error("Gap backfill queue full, dropping gap",
+			"symbol", symbol,
+			"from_id", gap.FromID,
+			"to_id", gap.ToID)
+	}
+}
+
+func (d *TradeGapDetector) backfillRoutine() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case gap := <-d.queue:
+			if err := d.backfillUC.Execute(d.ctx, gap); err != nil {
+				if d.ctx.Err() != nil {
+					return
+				}
+				d.logger.Error("Failed to backfill trade gap",
+					"error", err,
+					"symbol", gap.Symbol,
+					"from_id", gap.FromID,
+					"to_id", gap.ToID)
+			}
+		}
+	}
+}
+
+// Close stops the backfill routine. Gaps still queued are not filled.
+func (d *TradeGapDetector) Close() error {
+	d.cancel()
+	d.wg.Wait()
+
+	if pending := len(d.queue); pending > 0 {
+		d.logger.Warn("Trade gaps left unfilled on shutdown", "gaps", pending)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"alarket/internal/application/dto"
+	"alarket/internal/application/usecases"
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/mocks"
+	"alarket/internal/infrastructure/binance"
+	"alarket/internal/infrastructure/clickhouse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGapDetector returns a detector whose backfills find nothing on the
+// exchange, and a channel receiving every gap it records.
+func newTestGapDetector(t *testing.T) (*TradeGapDetector, <-chan *entities.TradeGap, *mocks.MockHistoricalDataService) {
+	t.Helper()
+	mockTradeRepo := new(mocks.MockTradeRepository)
+	mockGapRepo := new(mocks.MockTradeGapRepository)
+	mockHistoricalService := new(mocks.MockHistoricalDataService)
+
+	gaps := make(chan *entities.TradeGap, 10)
+	mockGapRepo.On("Save", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		gaps <- args.Get(1).(*entities.TradeGap)
+	})
+	mockHistoricalService.On("FetchHistoricalTrades", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]*entities.Trade{}, nil)
+
+	backfillUC := usecases.NewBackfillTradeGapUseCase(mockTradeRepo, mockGapRepo, mockHistoricalService, slog.Default(), 0)
+	detector := NewTradeGapDetector(backfillUC, slog.Default())
+	t.Cleanup(func() { _ = detector.Close() })
+
+	return detector, gaps, mockHistoricalService
+}
+
+func receiveGap(t *testing.T, gaps <-chan *entities.TradeGap) *entities.TradeGap {
+	t.Helper()
+	select {
+	case gap := <-gaps:
+		return gap
+	case <-time.After(time.Second):
+		t.Fatal("no trade gap recorded")
+		return nil
+	}
+}
+
+func TestTradeGapDetector_Observe(t *testing.T) {
+	detector, gaps, mockHistoricalService := newTestGapDetector(t)
+
+	detector.Observe("BTCUSDT", 100) // first trade only sets the baseline
+	detector.Observe("BTCUSDT", 101)
+	detector.Observe("ETHUSDT", 500)
+	detector.Observe("BTCUSDT", 105)
+	detector.Observe("BTCUSDT", 103) // late frame, not a gap
+	detector.Observe("BTCUSDT", 105) // duplicate
+	detector.Observe("ETHUSDT", 501)
+
+	gap := receiveGap(t, gaps)
+	assert.Equal(t, "BTCUSDT", gap.Symbol)
+	assert.Equal(t, uint64(102), gap.FromID)
+	assert.Equal(t, uint64(104), gap.ToID)
+
+	// Give a wrongly detected second gap the chance to show up
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, gaps)
+
+	require.NoError(t, detector.Close())
+	mockHistoricalService.AssertCalled(t, "FetchHistoricalTrades", mock.Anything, "BTCUSDT", uint64(102), 3)
+}
+
+func TestEventHandler_ObservesTradeIDs(t *testing.T) {
+	logger := slog.Default()
+	detector, gaps, _ := newTestGapDetector(t)
+
+	mockTradeRepo := new(mocks.MockTradeRepository)
+	mockTradeRepo.On("SaveBatch", mock.Anything, mock.Anything).Return(nil)
+
+	tradeBatchProcessor := clickhouse.NewTradeBatchProcessor(
+		mockTradeRepo,
+		logger,
+		100,
+		100*time.Millisecond,
+		clickhouse.DefaultFlushPoolConfig(),
+		nil,
+	)
+	defer func() { _ = tradeBatchProcessor.Close() }()
+
+	processTradeUC := usecases.NewProcessTradeEventUseCase(tradeBatchProcessor, logger)
+	handler := NewEventHandler(binance.NewDecoder(entities.MarketSpot), processTradeUC, nil, nil, nil, nil, detector, nil, logger)
+
+	for _, id := range []uint64{10, 11, 14} {
+		message, err := json.Marshal(dto.TradeEventDTO{
+			EventType: "trade",
+			EventTime: time.Now().UnixMilli(),
+			Symbol:    "BTCUSDT",
+			TradeID:   id,
+			Price:     "50000.00",
+			Quantity:  "0.01",
+			TradeTime: time.Now().UnixMilli(),
+		})
+		require.NoError(t, err)
+		require.NoError(t, handler.HandleMessage(context.Background(), message))
+	}
+
+	gap := receiveGap(t, gaps)
+	assert.Equal(t, uint64(12), gap.FromID)
+	assert.Equal(t, uint64(13), gap.ToID)
+}
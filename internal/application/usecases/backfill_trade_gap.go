@@ -0,0 +1,129 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+	"alarket/internal/domain/services"
+)
+
+// BackfillTradeGapUseCase records a gap in the live trade stream and fills it
+// from the REST API.
+type BackfillTradeGapUseCase struct {
+	tradeRepository       repositories.TradeRepository
+	tradeGapRepository    repositories.TradeGapRepository
+	historicalDataService services.HistoricalDataService
+	logger                *slog.Logger
+	batchSize             int
+	rateLimitDelay        time.Duration
+
+	mu          sync.Mutex
+	lastRequest time.Time
+}
+
+func NewBackfillTradeGapUseCase(
+	tradeRepository repositories.TradeRepository,
+	tradeGapRepository repositories.TradeGapRepository,
+	historicalDataService services.HistoricalDataService,
+	logger *slog.Logger,
+	rateLimitDelay time.Duration,
+) *BackfillTradeGapUseCase {
+	return &BackfillTradeGapUseCase{
+		tradeRepository:       tradeRepository,
+		tradeGapRepository:    tradeGapRepository,
+		historicalDataService: historicalDataService,
+		logger:                logger,
+		batchSize:             1000,
+		rateLimitDelay:        rateLimitDelay,
+	}
+}
+
+func (uc *BackfillTradeGapUseCase) Execute(ctx context.Context, gap *entities.TradeGap) error {
+	if err := gap.Validate(); err != nil {
+		return fmt.Errorf("invalid trade gap: %w", err)
+	}
+
+	if err := uc.tradeGapRepository.Save(ctx, gap); err != nil {
+		return fmt.Errorf("failed to save trade gap: %w", err)
+	}
+
+	uc.logger.Info("Backfilling trade gap",
+		"symbol", gap.Symbol,
+		"from_id", gap.FromID,
+		"to_id", gap.ToID,
+		"missing", gap.Size())
+
+	fromID := gap.FromID
+	totalFetched := 0
+
+	for fromID <= gap.ToID {
+		if err := uc.wait(ctx); err != nil {
+			return err
+		}
+
+		limit := int(min(uint64(uc.batchSize), gap.ToID-fromID+1))
+		trades, err := uc.historicalDataService.FetchHistoricalTrades(ctx, gap.Symbol, fromID, limit)
+		if err != nil {
+			return fmt.Errorf("failed to fetch historical trades: %w", err)
+		}
+
+		// Drop anything past the gap, the live stream already has it
+		missing := make([]*entities.Trade, 0, len(trades))
+		var lastID uint64
+		for _, trade := range trades {
+			if trade.ID < fromID || trade.ID > gap.ToID {
+				continue
+			}
+			missing = append(missing, trade)
+			lastID = max(lastID, trade.ID)
+		}
+
+		if len(missing) == 0 {
+			uc.logger.Warn("No trades returned for gap, giving up",
+				"symbol", gap.Symbol,
+				"from_id", fromID,
+				"to_id", gap.ToID)
+			break
+		}
+
+		if err := uc.tradeRepository.SaveBatch(ctx, missing); err != nil {
+			return fmt.Errorf("failed to save trades batch: %w", err)
+		}
+
+		totalFetched += len(missing)
+		fromID = lastID + 1
+	}
+
+	uc.logger.Info("Trade gap backfilled",
+		"symbol", gap.Symbol,
+		"from_id", gap.FromID,
+		"to_id", gap.ToID,
+		"total_fetched", totalFetched)
+
+	return nil
+}
+
+// wait blocks until rateLimitDelay has passed since the previous REST request.
+func (uc *BackfillTradeGapUseCase) wait(ctx context.Context) error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if delay := time.Until(uc.lastRequest.Add(uc.rateLimitDelay)); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	uc.lastRequest = time.Now()
+	return nil
+}
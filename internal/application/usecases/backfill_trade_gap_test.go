@@ -0,0 +1,164 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/mocks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func gapTrades(ids ...uint64) []*entities.Trade {
+	trades := make([]*entities.Trade, 0, len(ids))
+	for _, id := range ids {
+		trades = append(trades, &entities.Trade{
+			ID:       id,
+			Symbol:   "BTCUSDT",
+			Price:    decimal.RequireFromString("50000.0"),
+			Quantity: decimal.RequireFromString("0.01"),
+			Time:     time.Now(),
+		})
+	}
+	return trades
+}
+
+func TestBackfillTradeGapUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+
+	t.Run("fills gap across several requests", func(t *testing.T) {
+		mockTradeRepo := new(mocks.MockTradeRepository)
+		mockGapRepo := new(mocks.MockTradeGapRepository)
+		mockHistoricalService := new(mocks.MockHistoricalDataService)
+
+		gap := entities.NewTradeGap("BTCUSDT", 101, 105, time.Now())
+		mockGapRepo.On("Save", ctx, gap).Return(nil)
+
+		first := gapTrades(101, 102)
+		second := gapTrades(103, 104)
+		third := gapTrades(105)
+		mockHistoricalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(101), 2).Return(first, nil)
+		mockHistoricalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(103), 2).Return(second, nil)
+		mockHistoricalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(105), 1).Return(third, nil)
+		mockTradeRepo.On("SaveBatch", ctx, first).Return(nil)
+		mockTradeRepo.On("SaveBatch", ctx, second).Return(nil)
+		mockTradeRepo.On("SaveBatch", ctx, third).Return(nil)
+
+		uc := NewBackfillTradeGapUseCase(mockTradeRepo, mockGapRepo, mockHistoricalService, logger, 0)
+		uc.batchSize = 2
+
+		err := uc.Execute(ctx, gap)
+		assert.NoError(t, err)
+
+		mockTradeRepo.AssertExpectations(t)
+		mockGapRepo.AssertExpectations(t)
+		mockHistoricalService.AssertExpectations(t)
+	})
+
+	t.Run("ignores trades outside the gap", func(t *testing.T) {
+		mockTradeRepo := new(mocks.MockTradeRepository)
+		mockGapRepo := new(mocks.MockTradeGapRepository)
+		mockHistoricalService := new(mocks.MockHistoricalDataService)
+
+		gap := entities.NewTradeGap("BTCUSDT", 101, 102, time.Now())
+		mockGapRepo.On("Save", ctx, gap).Return(nil)
+		mockHistoricalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(101), 2).
+			Return(gapTrades(101, 102, 103), nil)
+		mockTradeRepo.On("SaveBatch", ctx, mock.MatchedBy(func(trades []*entities.Trade) bool {
+			return len(trades) == 2 && trades[0].ID == 101 && trades[1].ID == 102
+		})).Return(nil)
+
+		uc := NewBackfillTradeGapUseCase(mockTradeRepo, mockGapRepo, mockHistoricalService, logger, 0)
+
+		err := uc.Execute(ctx, gap)
+		assert.NoError(t, err)
+
+		mockTradeRepo.AssertExpectations(t)
+		mockHistoricalService.AssertExpectations(t)
+	})
+
+	t.Run("stops when exchange returns nothing", func(t *testing.T) {
+		mockTradeRepo := new(mocks.MockTradeRepository)
+		mockGapRepo := new(mocks.MockTradeGapRepository)
+		mockHistoricalService := new(mocks.MockHistoricalDataService)
+
+		gap := entities.NewTradeGap("BTCUSDT", 101, 102, time.Now())
+		mockGapRepo.On("Save", ctx, gap).Return(nil)
+		mockHistoricalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(101), 2).
+			Return([]*entities.Trade{}, nil)
+
+		uc := NewBackfillTradeGapUseCase(mockTradeRepo, mockGapRepo, mockHistoricalService, logger, 0)
+
+		err := uc.Execute(ctx, gap)
+		assert.NoError(t, err)
+
+		mockTradeRepo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("fetch error", func(t *testing.T) {
+		mockTradeRepo := new(mocks.MockTradeRepository)
+		mockGapRepo := new(mocks.MockTradeGapRepository)
+		mockHistoricalService := new(mocks.MockHistoricalDataService)
+
+		gap := entities.NewTradeGap("BTCUSDT", 101, 102, time.Now())
+		mockGapRepo.On("Save", ctx, gap).Return(nil)
+		mockHistoricalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(101), 2).
+			Return(nil, errors.New("rate limited"))
+
+		uc := NewBackfillTradeGapUseCase(mockTradeRepo, mockGapRepo, mockHistoricalService, logger, 0)
+
+		err := uc.Execute(ctx, gap)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch historical trades")
+	})
+
+	t.Run("gap save error", func(t *testing.T) {
+		mockTradeRepo := new(mocks.MockTradeRepository)
+		mockGapRepo := new(mocks.MockTradeGapRepository)
+		mockHistoricalService := new(mocks.MockHistoricalDataService)
+
+		gap := entities.NewTradeGap("BTCUSDT", 101, 102, time.Now())
+		mockGapRepo.On("Save", ctx, gap).Return(errors.New("database error"))
+
+		uc := NewBackfillTradeGapUseCase(mockTradeRepo, mockGapRepo, mockHistoricalService, logger, 0)
+
+		err := uc.Execute(ctx, gap)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to save trade gap")
+		mockHistoricalService.AssertNotCalled(t, "FetchHistoricalTrades", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid gap", func(t *testing.T) {
+		uc := NewBackfillTradeGapUseCase(
+			new(mocks.MockTradeRepository),
+			new(mocks.MockTradeGapRepository),
+			new(mocks.MockHistoricalDataService),
+			logger,
+			0,
+		)
+
+		err := uc.Execute(ctx, entities.NewTradeGap("BTCUSDT", 105, 101, time.Now()))
+		assert.ErrorIs(t, err, entities.ErrInvalidTradeRange)
+	})
+}
+
+func TestBackfillTradeGapUseCase_RateLimit(t *testing.T) {
+	uc := NewBackfillTradeGapUseCase(nil, nil, nil, slog.Default(), 50*time.Millisecond)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, uc.wait(context.Background()))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, uc.wait(ctx), context.Canceled)
+}
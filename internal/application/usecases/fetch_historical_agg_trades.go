@@ -0,0 +1,156 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+	"alarket/internal/domain/services"
+)
+
+// Binance rejects aggTrades requests whose startTime and endTime are more
+// than an hour apart.
+const aggTradesMaxWindow = time.Hour
+
+// FetchHistoricalAggTradesUseCase backfills aggregate trades for a time range
+// from the public aggTrades endpoint, one window at a time.
+type FetchHistoricalAggTradesUseCase struct {
+	aggTradeRepository repositories.AggTradeRepository
+	aggTradeService    services.AggTradeDataService
+	logger             *slog.Logger
+	batchSize          int
+	window             time.Duration
+	rateLimitDelay     time.Duration
+}
+
+func NewFetchHistoricalAggTradesUseCase(
+	aggTradeRepository repositories.AggTradeRepository,
+	aggTradeService services.AggTradeDataService,
+	logger *slog.Logger,
+) *FetchHistoricalAggTradesUseCase {
+	return &FetchHistoricalAggTradesUseCase{
+		aggTradeRepository: aggTradeRepository,
+		aggTradeService:    aggTradeService,
+		logger:             logger,
+		batchSize:          1000,
+		window:             aggTradesMaxWindow,
+		rateLimitDelay:     100 * time.Millisecond, // Binance allows 1200 requests per minute
+	}
+}
+
+// Execute fetches every aggregate trade executed in [from, to). Rows that are
+// already stored are written again and collapsed by the table engine.
+func (uc *FetchHistoricalAggTradesUseCase) Execute(ctx context.Context, symbol string, from, to time.Time) error {
+	if !from.Before(to) {
+		return fmt.Errorf("invalid time range: %s is not before %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	uc.logger.Info("Starting aggregate trades collection",
+		"symbol", symbol,
+		"from", from.Format(time.RFC3339),
+		"to", to.Format(time.RFC3339))
+
+	totalFetched := 0
+	windowCount := 0
+
+	for windowStart := from; windowStart.Before(to); {
+		windowEnd := windowStart.Add(uc.window)
+		if windowEnd.After(to) {
+			windowEnd = to
+		}
+
+		fetched, err := uc.fetchWindow(ctx, symbol, windowStart, windowEnd)
+		if err != nil {
+			return err
+		}
+
+		totalFetched += fetched
+		windowCount++
+
+		uc.logger.Info("Saved aggregate trades window",
+			"window", windowCount,
+			"window_start", windowStart.Format(time.RFC3339),
+			"agg_trades_in_window", fetched,
+			"total_fetched", totalFetched)
+
+		windowStart = windowEnd
+	}
+
+	uc.logger.Info("Aggregate trades collection completed",
+		"symbol", symbol,
+		"total_fetched", totalFetched,
+		"windows", windowCount)
+
+	return nil
+}
+
+// fetchWindow pages through [start, end) by time. Several aggregate trades can
+// share the millisecond a page ends on, so the next page starts at that
+// millisecond again and skips IDs that were already saved.
+func (uc *FetchHistoricalAggTradesUseCase) fetchWindow(ctx context.Context, symbol string, start, end time.Time) (int, error) {
+	endTime := end.Add(-time.Millisecond) // endTime is inclusive
+	cursor := start
+	var lastID uint64
+	fetched := 0
+
+	for {
+		if err := uc.wait(ctx); err != nil {
+			return fetched, err
+		}
+
+		aggTrades, err := uc.aggTradeService.FetchAggTrades(ctx, symbol, cursor, endTime, uc.batchSize)
+		if err != nil {
+			return fetched, fmt.Errorf("failed to fetch aggregate trades: %w", err)
+		}
+
+		fresh := make([]*entities.AggTrade, 0, len(aggTrades))
+		for _, aggTrade := range aggTrades {
+			if fetched > 0 && aggTrade.ID <= lastID {
+				continue
+			}
+			fresh = append(fresh, aggTrade)
+		}
+
+		if len(fresh) > 0 {
+			if err := uc.aggTradeRepository.SaveBatch(ctx, fresh); err != nil {
+				return fetched, fmt.Errorf("failed to save aggregate trades batch: %w", err)
+			}
+			fetched += len(fresh)
+			lastID = fresh[len(fresh)-1].ID
+		}
+
+		// A short page means the window is exhausted
+		if len(aggTrades) < uc.batchSize {
+			return fetched, nil
+		}
+
+		next := aggTrades[len(aggTrades)-1].Time
+		if len(fresh) == 0 {
+			// A full page inside one millisecond, time paging cannot get past it
+			uc.logger.Warn("Too many aggregate trades in one millisecond, skipping ahead",
+				"symbol", symbol,
+				"time", next.Format(time.RFC3339Nano))
+			next = next.Add(time.Millisecond)
+		}
+		if next.After(endTime) {
+			return fetched, nil
+		}
+		cursor = next
+	}
+}
+
+// wait pauses between requests to stay within the Binance rate limit.
+func (uc *FetchHistoricalAggTradesUseCase) wait(ctx context.Context) error {
+	timer := time.NewTimer(uc.rateLimitDelay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
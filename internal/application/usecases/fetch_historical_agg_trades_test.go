@@ -0,0 +1,171 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/mocks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// aggTradeAt builds an aggregate trade executed at base plus offsetMs.
+func aggTradeAt(id uint64, base time.Time, offsetMs int64) *entities.AggTrade {
+	return &entities.AggTrade{
+		ID:           id,
+		Symbol:       "BTCUSDT",
+		Price:        decimal.RequireFromString("50000.0"),
+		Quantity:     decimal.RequireFromString("0.01"),
+		FirstTradeID: id * 10,
+		LastTradeID:  id * 10,
+		Time:         base.Add(time.Duration(offsetMs) * time.Millisecond),
+	}
+}
+
+func aggTradeIDs(aggTrades []*entities.AggTrade) []uint64 {
+	ids := make([]uint64, 0, len(aggTrades))
+	for _, aggTrade := range aggTrades {
+		ids = append(ids, aggTrade.ID)
+	}
+	return ids
+}
+
+func newTestAggTradesUseCase(repo *mocks.MockAggTradeRepository, service *mocks.MockAggTradeDataService) *FetchHistoricalAggTradesUseCase {
+	uc := NewFetchHistoricalAggTradesUseCase(repo, service, slog.Default())
+	uc.rateLimitDelay = 0
+	return uc
+}
+
+func TestFetchHistoricalAggTradesUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("splits the range into hour windows", func(t *testing.T) {
+		mockRepo := new(mocks.MockAggTradeRepository)
+		mockService := new(mocks.MockAggTradeDataService)
+
+		first := []*entities.AggTrade{aggTradeAt(1, base, 0)}
+		second := []*entities.AggTrade{aggTradeAt(2, base, int64(time.Hour/time.Millisecond))}
+		mockService.On("FetchAggTrades", ctx, "BTCUSDT", base, base.Add(time.Hour-time.Millisecond), 1000).
+			Return(first, nil).Once()
+		mockService.On("FetchAggTrades", ctx, "BTCUSDT", base.Add(time.Hour), base.Add(90*time.Minute-time.Millisecond), 1000).
+			Return(second, nil).Once()
+		mockRepo.On("SaveBatch", ctx, first).Return(nil).Once()
+		mockRepo.On("SaveBatch", ctx, second).Return(nil).Once()
+
+		uc := newTestAggTradesUseCase(mockRepo, mockService)
+
+		err := uc.Execute(ctx, "BTCUSDT", base, base.Add(90*time.Minute))
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("pages within a window without duplicates", func(t *testing.T) {
+		mockRepo := new(mocks.MockAggTradeRepository)
+		mockService := new(mocks.MockAggTradeDataService)
+		end := base.Add(time.Minute)
+		endTime := end.Add(-time.Millisecond)
+
+		// The first page ends on a millisecond shared with the next trade
+		mockService.On("FetchAggTrades", ctx, "BTCUSDT", base, endTime, 3).
+			Return([]*entities.AggTrade{aggTradeAt(1, base, 0), aggTradeAt(2, base, 5), aggTradeAt(3, base, 7)}, nil).Once()
+		mockService.On("FetchAggTrades", ctx, "BTCUSDT", base.Add(7*time.Millisecond), endTime, 3).
+			Return([]*entities.AggTrade{aggTradeAt(3, base, 7), aggTradeAt(4, base, 7), aggTradeAt(5, base, 9)}, nil).Once()
+		mockService.On("FetchAggTrades", ctx, "BTCUSDT", base.Add(9*time.Millisecond), endTime, 3).
+			Return([]*entities.AggTrade{aggTradeAt(5, base, 9)}, nil).Once()
+
+		var saved []uint64
+		mockRepo.On("SaveBatch", ctx, mock.AnythingOfType("[]*entities.AggTrade")).Return(nil).Run(func(args mock.Arguments) {
+			saved = append(saved, aggTradeIDs(args.Get(1).([]*entities.AggTrade))...)
+		})
+
+		uc := newTestAggTradesUseCase(mockRepo, mockService)
+		uc.batchSize = 3
+
+		err := uc.Execute(ctx, "BTCUSDT", base, end)
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint64{1, 2, 3, 4, 5}, saved)
+		mockService.AssertExpectations(t)
+		mockRepo.AssertNumberOfCalls(t, "SaveBatch", 2)
+	})
+
+	t.Run("skips a millisecond holding more than a page", func(t *testing.T) {
+		mockRepo := new(mocks.MockAggTradeRepository)
+		mockService := new(mocks.MockAggTradeDataService)
+		end := base.Add(time.Minute)
+		endTime := end.Add(-time.Millisecond)
+
+		crowded := []*entities.AggTrade{aggTradeAt(1, base, 3), aggTradeAt(2, base, 3)}
+		mockService.On("FetchAggTrades", ctx, "BTCUSDT", base, endTime, 2).Return(crowded, nil).Once()
+		mockService.On("FetchAggTrades", ctx, "BTCUSDT", base.Add(3*time.Millisecond), endTime, 2).Return(crowded, nil).Once()
+		mockService.On("FetchAggTrades", ctx, "BTCUSDT", base.Add(4*time.Millisecond), endTime, 2).
+			Return([]*entities.AggTrade{}, nil).Once()
+		mockRepo.On("SaveBatch", ctx, crowded).Return(nil).Once()
+
+		uc := newTestAggTradesUseCase(mockRepo, mockService)
+		uc.batchSize = 2
+
+		err := uc.Execute(ctx, "BTCUSDT", base, end)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("fetch error", func(t *testing.T) {
+		mockRepo := new(mocks.MockAggTradeRepository)
+		mockService := new(mocks.MockAggTradeDataService)
+
+		mockService.On("FetchAggTrades", ctx, "BTCUSDT", mock.Anything, mock.Anything, 1000).
+			Return(nil, errors.New("API error"))
+
+		uc := newTestAggTradesUseCase(mockRepo, mockService)
+
+		err := uc.Execute(ctx, "BTCUSDT", base, base.Add(time.Hour))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch aggregate trades")
+		mockRepo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("save error", func(t *testing.T) {
+		mockRepo := new(mocks.MockAggTradeRepository)
+		mockService := new(mocks.MockAggTradeDataService)
+
+		mockService.On("FetchAggTrades", ctx, "BTCUSDT", mock.Anything, mock.Anything, 1000).
+			Return([]*entities.AggTrade{aggTradeAt(1, base, 0)}, nil)
+		mockRepo.On("SaveBatch", ctx, mock.Anything).Return(errors.New("database error"))
+
+		uc := newTestAggTradesUseCase(mockRepo, mockService)
+
+		err := uc.Execute(ctx, "BTCUSDT", base, base.Add(time.Hour))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to save aggregate trades batch")
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		uc := newTestAggTradesUseCase(new(mocks.MockAggTradeRepository), new(mocks.MockAggTradeDataService))
+
+		err := uc.Execute(ctx, "BTCUSDT", base, base)
+		assert.Error(t, err)
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		uc := newTestAggTradesUseCase(new(mocks.MockAggTradeRepository), new(mocks.MockAggTradeDataService))
+		uc.rateLimitDelay = time.Second
+
+		err := uc.Execute(cancelCtx, "BTCUSDT", base, base.Add(time.Hour))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
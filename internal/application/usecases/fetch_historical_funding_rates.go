@@ -0,0 +1,125 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"alarket/internal/domain/repositories"
+	"alarket/internal/domain/services"
+)
+
+// FetchHistoricalFundingRatesUseCase backfills settled funding rates for a
+// time range over REST, optionally resuming after the newest rate stored.
+type FetchHistoricalFundingRatesUseCase struct {
+	fundingRateRepository  repositories.FundingRateRepository
+	fundingRateDataService services.FundingRateDataService
+	logger                 *slog.Logger
+	batchSize              int
+	rateLimitDelay         time.Duration
+}
+
+func NewFetchHistoricalFundingRatesUseCase(
+	fundingRateRepository repositories.FundingRateRepository,
+	fundingRateDataService services.FundingRateDataService,
+	logger *slog.Logger,
+) *FetchHistoricalFundingRatesUseCase {
+	return &FetchHistoricalFundingRatesUseCase{
+		fundingRateRepository:  fundingRateRepository,
+		fundingRateDataService: fundingRateDataService,
+		logger:                 logger,
+		batchSize:              1000,
+		rateLimitDelay:         600 * time.Millisecond, // the endpoint allows 500 requests per 5 minutes
+	}
+}
+
+// Execute stores every funding rate settled in [from, to).
+func (uc *FetchHistoricalFundingRatesUseCase) Execute(ctx context.Context, symbol string, from, to time.Time, resume bool) error {
+	if !from.Before(to) {
+		return fmt.Errorf("invalid time range: %s is not before %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	start := from
+	if resume {
+		newest, err := uc.fundingRateRepository.GetNewestFundingTime(ctx, symbol)
+		if err != nil {
+			return fmt.Errorf("failed to get newest funding rate: %w", err)
+		}
+		if newest != nil && !newest.Before(from) {
+			start = newest.Add(time.Millisecond)
+			uc.logger.Info("Resuming after newest stored funding rate",
+				"symbol", symbol,
+				"newest_funding_time", newest.Format(time.RFC3339))
+		}
+	}
+
+	if !start.Before(to) {
+		uc.logger.Info("Funding rates already up to date", "symbol", symbol)
+		return nil
+	}
+
+	uc.logger.Info("Starting funding rates collection",
+		"symbol", symbol,
+		"from", start.Format(time.RFC3339),
+		"to", to.Format(time.RFC3339))
+
+	endTime := to.Add(-time.Millisecond) // endTime is inclusive
+	totalFetched := 0
+	batchCount := 0
+
+	for {
+		if err := uc.wait(ctx); err != nil {
+			return err
+		}
+
+		rates, err := uc.fundingRateDataService.FetchFundingRates(ctx, symbol, start, endTime, uc.batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to fetch funding rates: %w", err)
+		}
+
+		if len(rates) > 0 {
+			if err := uc.fundingRateRepository.SaveBatch(ctx, rates); err != nil {
+				return fmt.Errorf("failed to save funding rates batch: %w", err)
+			}
+
+			totalFetched += len(rates)
+			batchCount++
+
+			uc.logger.Info("Saved funding rates batch",
+				"batch", batchCount,
+				"rates_in_batch", len(rates),
+				"total_fetched", totalFetched,
+				"newest_in_batch", rates[len(rates)-1].FundingTime.Format(time.RFC3339))
+		}
+
+		if len(rates) < uc.batchSize {
+			break
+		}
+
+		start = rates[len(rates)-1].FundingTime.Add(time.Millisecond)
+		if start.After(endTime) {
+			break
+		}
+	}
+
+	uc.logger.Info("Funding rates collection completed",
+		"symbol", symbol,
+		"total_fetched", totalFetched,
+		"batches", batchCount)
+
+	return nil
+}
+
+// wait pauses between requests to stay within the Binance rate limit.
+func (uc *FetchHistoricalFundingRatesUseCase) wait(ctx context.Context) error {
+	timer := time.NewTimer(uc.rateLimitDelay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
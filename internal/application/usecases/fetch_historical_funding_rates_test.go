@@ -0,0 +1,119 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/mocks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// eightHourlyFundingRates builds consecutive funding rates eight hours apart,
+// the first settled at start.
+func eightHourlyFundingRates(start time.Time, count int) []*entities.FundingRate {
+	rates := make([]*entities.FundingRate, 0, count)
+	for i := 0; i < count; i++ {
+		rates = append(rates, entities.NewFundingRate("BTCUSDT", start.Add(time.Duration(i)*8*time.Hour),
+			decimal.RequireFromString("0.0001"), decimal.RequireFromString("50000"), entities.TradeSourceREST))
+	}
+	return rates
+}
+
+func newTestFundingRatesUseCase(repo *mocks.MockFundingRateRepository, service *mocks.MockFundingRateDataService) *FetchHistoricalFundingRatesUseCase {
+	uc := NewFetchHistoricalFundingRatesUseCase(repo, service, slog.Default())
+	uc.rateLimitDelay = 0
+	return uc
+}
+
+func TestFetchHistoricalFundingRatesUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := base.Add(7 * 24 * time.Hour)
+	endTime := to.Add(-time.Millisecond)
+
+	t.Run("pages through the range", func(t *testing.T) {
+		mockRepo := new(mocks.MockFundingRateRepository)
+		mockService := new(mocks.MockFundingRateDataService)
+
+		first := eightHourlyFundingRates(base, 2)
+		second := eightHourlyFundingRates(base.Add(16*time.Hour), 1)
+		mockService.On("FetchFundingRates", ctx, "BTCUSDT", base, endTime, 2).Return(first, nil).Once()
+		mockService.On("FetchFundingRates", ctx, "BTCUSDT", base.Add(8*time.Hour+time.Millisecond), endTime, 2).
+			Return(second, nil).Once()
+		mockRepo.On("SaveBatch", ctx, first).Return(nil).Once()
+		mockRepo.On("SaveBatch", ctx, second).Return(nil).Once()
+
+		uc := newTestFundingRatesUseCase(mockRepo, mockService)
+		uc.batchSize = 2
+
+		err := uc.Execute(ctx, "BTCUSDT", base, to, false)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockService.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "GetNewestFundingTime", mock.Anything, mock.Anything)
+	})
+
+	t.Run("resumes after the newest stored rate", func(t *testing.T) {
+		mockRepo := new(mocks.MockFundingRateRepository)
+		mockService := new(mocks.MockFundingRateDataService)
+
+		newest := base.Add(48 * time.Hour)
+		resumed := eightHourlyFundingRates(newest.Add(8*time.Hour), 3)
+		mockRepo.On("GetNewestFundingTime", ctx, "BTCUSDT").Return(&newest, nil)
+		mockService.On("FetchFundingRates", ctx, "BTCUSDT", newest.Add(time.Millisecond), endTime, 1000).
+			Return(resumed, nil).Once()
+		mockRepo.On("SaveBatch", ctx, resumed).Return(nil).Once()
+
+		uc := newTestFundingRatesUseCase(mockRepo, mockService)
+
+		err := uc.Execute(ctx, "BTCUSDT", base, to, true)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("already up to date", func(t *testing.T) {
+		mockRepo := new(mocks.MockFundingRateRepository)
+		mockService := new(mocks.MockFundingRateDataService)
+
+		newest := to
+		mockRepo.On("GetNewestFundingTime", ctx, "BTCUSDT").Return(&newest, nil)
+
+		uc := newTestFundingRatesUseCase(mockRepo, mockService)
+
+		err := uc.Execute(ctx, "BTCUSDT", base, to, true)
+		assert.NoError(t, err)
+
+		mockService.AssertNotCalled(t, "FetchFundingRates", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		uc := newTestFundingRatesUseCase(new(mocks.MockFundingRateRepository), new(mocks.MockFundingRateDataService))
+
+		err := uc.Execute(ctx, "BTCUSDT", to, base, false)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid time range")
+	})
+
+	t.Run("fetch error", func(t *testing.T) {
+		mockRepo := new(mocks.MockFundingRateRepository)
+		mockService := new(mocks.MockFundingRateDataService)
+
+		mockService.On("FetchFundingRates", ctx, "BTCUSDT", base, endTime, 1000).
+			Return(nil, errors.New("API error"))
+
+		uc := newTestFundingRatesUseCase(mockRepo, mockService)
+
+		err := uc.Execute(ctx, "BTCUSDT", base, to, false)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch funding rates")
+	})
+}
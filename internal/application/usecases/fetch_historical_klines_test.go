@@ -0,0 +1,164 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/mocks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// minuteKlines builds consecutive 1m candles, the first opening at start.
+func minuteKlines(start time.Time, count int, closed bool) []*entities.Kline {
+	klines := make([]*entities.Kline, 0, count)
+	for i := 0; i < count; i++ {
+		openTime := start.Add(time.Duration(i) * time.Minute)
+		price := decimal.RequireFromString("50000")
+		kline := entities.NewKline("BTCUSDT", "1m", openTime, openTime.Add(time.Minute-time.Millisecond),
+			price, price, price, price, decimal.RequireFromString("1"), openTime, entities.TradeSourceREST)
+		kline.IsClosed = closed
+		klines = append(klines, kline)
+	}
+	return klines
+}
+
+func newTestKlinesUseCase(repo *mocks.MockKlineRepository, service *mocks.MockKlineDataService) *FetchHistoricalKlinesUseCase {
+	uc := NewFetchHistoricalKlinesUseCase(repo, service, slog.Default())
+	uc.rateLimitDelay = 0
+	return uc
+}
+
+func TestFetchHistoricalKlinesUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := base.Add(10 * time.Minute)
+	endTime := to.Add(-time.Millisecond)
+
+	t.Run("pages through the range", func(t *testing.T) {
+		mockRepo := new(mocks.MockKlineRepository)
+		mockService := new(mocks.MockKlineDataService)
+
+		first := minuteKlines(base, 2, true)
+		second := minuteKlines(base.Add(2*time.Minute), 1, true)
+		mockService.On("FetchKlines", ctx, "BTCUSDT", entities.KlineInterval("1m"), base, endTime, 2).Return(first, nil).Once()
+		mockService.On("FetchKlines", ctx, "BTCUSDT", entities.KlineInterval("1m"), base.Add(time.Minute+time.Millisecond), endTime, 2).
+			Return(second, nil).Once()
+		mockRepo.On("SaveBatch", ctx, first).Return(nil).Once()
+		mockRepo.On("SaveBatch", ctx, second).Return(nil).Once()
+
+		uc := newTestKlinesUseCase(mockRepo, mockService)
+		uc.batchSize = 2
+
+		err := uc.Execute(ctx, "BTCUSDT", "1m", base, to, false)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockService.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "GetNewestOpenTime", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("resumes after the newest stored kline", func(t *testing.T) {
+		mockRepo := new(mocks.MockKlineRepository)
+		mockService := new(mocks.MockKlineDataService)
+
+		newest := base.Add(4 * time.Minute)
+		resumed := minuteKlines(base.Add(5*time.Minute), 5, true)
+		mockRepo.On("GetNewestOpenTime", ctx, "BTCUSDT", entities.KlineInterval("1m")).Return(&newest, nil)
+		mockService.On("FetchKlines", ctx, "BTCUSDT", entities.KlineInterval("1m"), newest.Add(time.Millisecond), endTime, 1000).
+			Return(resumed, nil).Once()
+		mockRepo.On("SaveBatch", ctx, resumed).Return(nil).Once()
+
+		uc := newTestKlinesUseCase(mockRepo, mockService)
+
+		err := uc.Execute(ctx, "BTCUSDT", "1m", base, to, true)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("ignores stored klines before the range", func(t *testing.T) {
+		mockRepo := new(mocks.MockKlineRepository)
+		mockService := new(mocks.MockKlineDataService)
+
+		newest := base.Add(-time.Hour)
+		mockRepo.On("GetNewestOpenTime", ctx, "BTCUSDT", entities.KlineInterval("1m")).Return(&newest, nil)
+		mockService.On("FetchKlines", ctx, "BTCUSDT", entities.KlineInterval("1m"), base, endTime, 1000).
+			Return([]*entities.Kline{}, nil).Once()
+
+		uc := newTestKlinesUseCase(mockRepo, mockService)
+
+		err := uc.Execute(ctx, "BTCUSDT", "1m", base, to, true)
+		assert.NoError(t, err)
+
+		mockService.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("already up to date", func(t *testing.T) {
+		mockRepo := new(mocks.MockKlineRepository)
+		mockService := new(mocks.MockKlineDataService)
+
+		newest := to
+		mockRepo.On("GetNewestOpenTime", ctx, "BTCUSDT", entities.KlineInterval("1m")).Return(&newest, nil)
+
+		uc := newTestKlinesUseCase(mockRepo, mockService)
+
+		err := uc.Execute(ctx, "BTCUSDT", "1m", base, to, true)
+		assert.NoError(t, err)
+
+		mockService.AssertNotCalled(t, "FetchKlines", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("skips the open candle", func(t *testing.T) {
+		mockRepo := new(mocks.MockKlineRepository)
+		mockService := new(mocks.MockKlineDataService)
+
+		closed := minuteKlines(base, 2, true)
+		open := minuteKlines(base.Add(2*time.Minute), 1, false)
+		mockService.On("FetchKlines", ctx, "BTCUSDT", entities.KlineInterval("1m"), base, endTime, 3).
+			Return(append(closed, open...), nil).Once()
+		mockRepo.On("SaveBatch", ctx, closed).Return(nil).Once()
+
+		uc := newTestKlinesUseCase(mockRepo, mockService)
+		uc.batchSize = 3
+
+		err := uc.Execute(ctx, "BTCUSDT", "1m", base, to, false)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("fetch error", func(t *testing.T) {
+		mockRepo := new(mocks.MockKlineRepository)
+		mockService := new(mocks.MockKlineDataService)
+
+		mockService.On("FetchKlines", ctx, "BTCUSDT", entities.KlineInterval("1m"), base, endTime, 1000).
+			Return(nil, errors.New("API error"))
+
+		uc := newTestKlinesUseCase(mockRepo, mockService)
+
+		err := uc.Execute(ctx, "BTCUSDT", "1m", base, to, false)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch klines")
+	})
+
+	t.Run("resume lookup error", func(t *testing.T) {
+		mockRepo := new(mocks.MockKlineRepository)
+
+		mockRepo.On("GetNewestOpenTime", ctx, "BTCUSDT", entities.KlineInterval("1m")).Return(nil, errors.New("database error"))
+
+		uc := newTestKlinesUseCase(mockRepo, new(mocks.MockKlineDataService))
+
+		err := uc.Execute(ctx, "BTCUSDT", "1m", base, to, true)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to get newest kline")
+	})
+}
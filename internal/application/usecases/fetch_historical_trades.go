@@ -4,9 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"strconv"
 	"time"
 
+	"alarket/internal/domain/entities"
 	"alarket/internal/domain/repositories"
 	"alarket/internal/domain/services"
 )
@@ -55,10 +55,12 @@ func (uc *FetchHistoricalTradesUseCase) executeForward(ctx context.Context, symb
 		return fmt.Errorf("failed to get newest trade ID: %w", err)
 	}
 
-	var fromID int64 = 0
+	var fromID uint64 = 0
+	latest := true
 	if newestID != nil {
 		// Start from the newest trade ID + 1
 		fromID = *newestID + 1
+		latest = false
 		uc.logger.Info("Starting from newest ID",
 			"newest_id", *newestID,
 			"starting_from_id", fromID)
@@ -79,7 +81,7 @@ func (uc *FetchHistoricalTradesUseCase) executeForward(ctx context.Context, symb
 		}
 
 		// Fetch trades from Binance
-		trades, err := uc.historicalDataService.FetchHistoricalTrades(ctx, symbol, fromID, uc.batchSize)
+		trades, err := uc.fetch(ctx, symbol, fromID, latest)
 		if err != nil {
 			return fmt.Errorf("failed to fetch historical trades: %w", err)
 		}
@@ -126,15 +128,12 @@ func (uc *FetchHistoricalTradesUseCase) executeForward(ctx context.Context, symb
 		}
 
 		// Move forward in ID for next batch
-		if len(trades) > 0 {
-			lastTradeID, err := strconv.ParseInt(trades[len(trades)-1].ID, 10, 64)
-			if err == nil {
-				fromID = lastTradeID + 1
-				uc.logger.Debug("Moving forward in history",
-					"last_id_in_batch", lastTradeID,
-					"next_from_id", fromID)
-			}
-		}
+		lastTradeID := trades[len(trades)-1].ID
+		fromID = lastTradeID + 1
+		latest = false
+		uc.logger.Debug("Moving forward in history",
+			"last_id_in_batch", lastTradeID,
+			"next_from_id", fromID)
 
 		// Rate limiting - Binance allows 1200 requests per minute
 		time.Sleep(uc.rateLimitDelay)
@@ -178,7 +177,8 @@ func (uc *FetchHistoricalTradesUseCase) executeBackward(ctx context.Context, sym
 	}
 
 	// Determine starting point for fetching
-	var fromID int64 = 0
+	var fromID uint64 = 0
+	latest := true
 	if oldestTime != nil {
 		// We have existing data, get the oldest ID and go backwards
 		oldestID, err := uc.tradeRepository.GetOldestTradeID(ctx, symbol)
@@ -186,7 +186,12 @@ func (uc *FetchHistoricalTradesUseCase) executeBackward(ctx context.Context, sym
 			return fmt.Errorf("failed to get oldest trade ID: %w", err)
 		}
 		if oldestID != nil {
-			fromID = *oldestID - int64(uc.batchSize)
+			var ok bool
+			if fromID, ok = uc.previousFromID(*oldestID); !ok {
+				uc.logger.Info("Already at the beginning of trade history", "oldest_id", *oldestID)
+				return nil
+			}
+			latest = false
 			uc.logger.Info("Starting from existing oldest ID",
 				"oldest_id", *oldestID,
 				"starting_from_id", fromID)
@@ -208,7 +213,7 @@ func (uc *FetchHistoricalTradesUseCase) executeBackward(ctx context.Context, sym
 		}
 
 		// Fetch trades from Binance
-		trades, err := uc.historicalDataService.FetchHistoricalTrades(ctx, symbol, fromID, uc.batchSize)
+		trades, err := uc.fetch(ctx, symbol, fromID, latest)
 		if err != nil {
 			return fmt.Errorf("failed to fetch historical trades: %w", err)
 		}
@@ -254,22 +259,18 @@ func (uc *FetchHistoricalTradesUseCase) executeBackward(ctx context.Context, sym
 			break
 		}
 
-		// Move backwards in ID for next batch
-		if len(trades) > 0 {
-			firstTradeID, err := strconv.ParseInt(trades[0].ID, 10, 64)
-			if err == nil {
-				fromID = firstTradeID - int64(uc.batchSize)
-				uc.logger.Debug("Moving backwards in history",
-					"first_id_in_batch", firstTradeID,
-					"next_from_id", fromID)
-			}
-		}
-
-		// Prevent going into negative IDs
-		if fromID < 0 {
-			uc.logger.Info("Reached beginning of trade history (ID < 0)")
+		// Move backwards in ID for next batch, stopping at the first trade
+		firstTradeID := trades[0].ID
+		nextFromID, ok := uc.previousFromID(firstTradeID)
+		if !ok {
+			uc.logger.Info("Reached beginning of trade history", "first_id_in_batch", firstTradeID)
 			break
 		}
+		fromID = nextFromID
+		latest = false
+		uc.logger.Debug("Moving backwards in history",
+			"first_id_in_batch", firstTradeID,
+			"next_from_id", fromID)
 
 		// Rate limiting - Binance allows 1200 requests per minute
 		time.Sleep(uc.rateLimitDelay)
@@ -283,3 +284,20 @@ func (uc *FetchHistoricalTradesUseCase) executeBackward(ctx context.Context, sym
 
 	return nil
 }
+
+// fetch fetches a batch from fromID, or the latest trades when latest is set.
+func (uc *FetchHistoricalTradesUseCase) fetch(ctx context.Context, symbol string, fromID uint64, latest bool) ([]*entities.Trade, error) {
+	if latest {
+		return uc.historicalDataService.FetchLatestTrades(ctx, symbol, uc.batchSize)
+	}
+	return uc.historicalDataService.FetchHistoricalTrades(ctx, symbol, fromID, uc.batchSize)
+}
+
+// previousFromID returns the ID to fetch the batch preceding firstID from.
+// It reports false when there is no full batch left before firstID.
+func (uc *FetchHistoricalTradesUseCase) previousFromID(firstID uint64) (uint64, bool) {
+	if firstID < uint64(uc.batchSize) {
+		return 0, false
+	}
+	return firstID - uint64(uc.batchSize), true
+}
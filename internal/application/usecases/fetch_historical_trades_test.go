@@ -9,6 +9,7 @@ import (
 
 	"alarket/internal/domain/entities"
 	"alarket/internal/domain/mocks"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -37,30 +38,30 @@ func TestFetchHistoricalTradesUseCase_ExecuteForward(t *testing.T) {
 		mockHistoricalService := new(mocks.MockHistoricalDataService)
 		
 		// Setup existing newest trade ID
-		newestID := int64(1000)
+		newestID := uint64(1000)
 		mockTradeRepo.On("GetNewestTradeID", ctx, "BTCUSDT").Return(&newestID, nil)
 		
 		// Create test trades
 		now := time.Now()
 		trades := []*entities.Trade{
 			{
-				ID:       "1001",
+				ID:       1001,
 				Symbol:   "BTCUSDT",
-				Price:    50000.0,
-				Quantity: 0.01,
+				Price:    decimal.RequireFromString("50000.0"),
+				Quantity: decimal.RequireFromString("0.01"),
 				Time:     now.Add(-1 * time.Hour),
 			},
 			{
-				ID:       "1002",
+				ID:       1002,
 				Symbol:   "BTCUSDT",
-				Price:    50100.0,
-				Quantity: 0.02,
+				Price:    decimal.RequireFromString("50100.0"),
+				Quantity: decimal.RequireFromString("0.02"),
 				Time:     now.Add(-30 * time.Minute),
 			},
 		}
 		
 		// Mock historical service to return trades
-		mockHistoricalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", int64(1001), 1000).Return(trades, nil)
+		mockHistoricalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(1001), 1000).Return(trades, nil)
 		
 		// Mock save batch
 		mockTradeRepo.On("SaveBatch", ctx, trades).Return(nil)
@@ -87,16 +88,16 @@ func TestFetchHistoricalTradesUseCase_ExecuteForward(t *testing.T) {
 		now := time.Now()
 		trades := []*entities.Trade{
 			{
-				ID:       "1",
+				ID:       1,
 				Symbol:   "BTCUSDT",
-				Price:    50000.0,
-				Quantity: 0.01,
+				Price:    decimal.RequireFromString("50000.0"),
+				Quantity: decimal.RequireFromString("0.01"),
 				Time:     now.Add(-2 * time.Hour),
 			},
 		}
 		
 		// Mock historical service
-		mockHistoricalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", int64(0), 1000).Return(trades, nil)
+		mockHistoricalService.On("FetchLatestTrades", ctx, "BTCUSDT", 1000).Return(trades, nil)
 		
 		// Mock save batch
 		mockTradeRepo.On("SaveBatch", ctx, trades).Return(nil)
@@ -142,23 +143,23 @@ func TestFetchHistoricalTradesUseCase_ExecuteBackward(t *testing.T) {
 		
 		// Setup existing oldest trade time (2 days ago)
 		oldestTime := time.Now().AddDate(0, 0, -2)
-		oldestID := int64(1000)
+		oldestID := uint64(1000)
 		mockTradeRepo.On("GetOldestTradeTime", ctx, "BTCUSDT").Return(&oldestTime, nil)
 		mockTradeRepo.On("GetOldestTradeID", ctx, "BTCUSDT").Return(&oldestID, nil)
 		
 		// Create test trades (older than existing)
 		trades := []*entities.Trade{
 			{
-				ID:       "900",
+				ID:       900,
 				Symbol:   "BTCUSDT",
-				Price:    49000.0,
-				Quantity: 0.01,
+				Price:    decimal.RequireFromString("49000.0"),
+				Quantity: decimal.RequireFromString("0.01"),
 				Time:     time.Now().AddDate(0, 0, -5), // 5 days ago
 			},
 		}
 		
 		// Mock historical service
-		mockHistoricalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", int64(0), 1000).Return(trades, nil)
+		mockHistoricalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(0), 1000).Return(trades, nil)
 		
 		// Mock save batch
 		mockTradeRepo.On("SaveBatch", ctx, trades).Return(nil)
@@ -185,16 +186,16 @@ func TestFetchHistoricalTradesUseCase_ExecuteBackward(t *testing.T) {
 		// Create test trades
 		trades := []*entities.Trade{
 			{
-				ID:       "1",
+				ID:       1,
 				Symbol:   "BTCUSDT",
-				Price:    50000.0,
-				Quantity: 0.01,
+				Price:    decimal.RequireFromString("50000.0"),
+				Quantity: decimal.RequireFromString("0.01"),
 				Time:     time.Now().AddDate(0, 0, -3),
 			},
 		}
 		
 		// Mock historical service
-		mockHistoricalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", int64(0), 1000).Return(trades, nil)
+		mockHistoricalService.On("FetchLatestTrades", ctx, "BTCUSDT", 1000).Return(trades, nil)
 		
 		// Mock save batch
 		mockTradeRepo.On("SaveBatch", ctx, trades).Return(nil)
@@ -238,7 +239,7 @@ func TestFetchHistoricalTradesUseCase_ErrorHandling(t *testing.T) {
 		mockTradeRepo.On("GetOldestTradeTime", ctx, "BTCUSDT").Return(nil, nil)
 		
 		expectedErr := errors.New("API error")
-		mockHistoricalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", int64(0), 1000).Return(nil, expectedErr)
+		mockHistoricalService.On("FetchLatestTrades", ctx, "BTCUSDT", 1000).Return(nil, expectedErr)
 		
 		uc := NewFetchHistoricalTradesUseCase(mockTradeRepo, mockHistoricalService, logger)
 		
@@ -258,15 +259,15 @@ func TestFetchHistoricalTradesUseCase_ErrorHandling(t *testing.T) {
 		
 		trades := []*entities.Trade{
 			{
-				ID:       "1",
+				ID:       1,
 				Symbol:   "BTCUSDT",
-				Price:    50000.0,
-				Quantity: 0.01,
+				Price:    decimal.RequireFromString("50000.0"),
+				Quantity: decimal.RequireFromString("0.01"),
 				Time:     time.Now(),
 			},
 		}
 		
-		mockHistoricalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", int64(0), 1000).Return(trades, nil)
+		mockHistoricalService.On("FetchLatestTrades", ctx, "BTCUSDT", 1000).Return(trades, nil)
 		
 		expectedErr := errors.New("save error")
 		mockTradeRepo.On("SaveBatch", ctx, trades).Return(expectedErr)
@@ -0,0 +1,109 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+)
+
+// ManageBackfillJobsUseCase adds backfill jobs to the queue and pauses,
+// resumes and cancels them. RunBackfillJobsUseCase runs them.
+type ManageBackfillJobsUseCase struct {
+	jobRepository repositories.BackfillJobRepository
+	logger        *slog.Logger
+	newID         func() string
+	now           func() time.Time
+}
+
+func NewManageBackfillJobsUseCase(jobRepository repositories.BackfillJobRepository, logger *slog.Logger) *ManageBackfillJobsUseCase {
+	return &ManageBackfillJobsUseCase{
+		jobRepository: jobRepository,
+		logger:        logger,
+		newID:         newJobID,
+		now:           time.Now,
+	}
+}
+
+// Add queues a job for every symbol. Nothing is queued when one of them is
+// invalid.
+func (uc *ManageBackfillJobsUseCase) Add(ctx context.Context, symbols []string, direction entities.BackfillDirection, from, to time.Time) ([]*entities.BackfillJob, error) {
+	now := uc.now()
+
+	jobs := make([]*entities.BackfillJob, 0, len(symbols))
+	for _, symbol := range symbols {
+		job := entities.NewBackfillJob(uc.newID(), strings.ToUpper(strings.TrimSpace(symbol)), direction, from, to, now)
+		if err := job.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid backfill job for %q: %w", symbol, err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	for _, job := range jobs {
+		if err := uc.jobRepository.Save(ctx, job); err != nil {
+			return nil, fmt.Errorf("failed to save backfill job: %w", err)
+		}
+		uc.logger.Info("Backfill job added",
+			"job", job.ID,
+			"symbol", job.Symbol,
+			"direction", job.Direction,
+			"from", job.From.Format(time.RFC3339),
+			"to", job.To.Format(time.RFC3339))
+	}
+
+	return jobs, nil
+}
+
+// List returns every job, oldest first.
+func (uc *ManageBackfillJobsUseCase) List(ctx context.Context) ([]*entities.BackfillJob, error) {
+	jobs, err := uc.jobRepository.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backfill jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// Pause stops a job. A running job stops after its current batch.
+func (uc *ManageBackfillJobsUseCase) Pause(ctx context.Context, id string) (*entities.BackfillJob, error) {
+	return uc.update(ctx, id, (*entities.BackfillJob).Pause)
+}
+
+// Resume queues a paused or failed job again.
+func (uc *ManageBackfillJobsUseCase) Resume(ctx context.Context, id string) (*entities.BackfillJob, error) {
+	return uc.update(ctx, id, (*entities.BackfillJob).Resume)
+}
+
+// Cancel stops a job for good. A running job stops after its current batch.
+func (uc *ManageBackfillJobsUseCase) Cancel(ctx context.Context, id string) (*entities.BackfillJob, error) {
+	return uc.update(ctx, id, (*entities.BackfillJob).Cancel)
+}
+
+func (uc *ManageBackfillJobsUseCase) update(ctx context.Context, id string, change func(*entities.BackfillJob, time.Time) error) (*entities.BackfillJob, error) {
+	job, err := uc.jobRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(job, uc.now()); err != nil {
+		return nil, fmt.Errorf("backfill job %s: %w", id, err)
+	}
+	// Only the status, the cursor belongs to the runner
+	if err := uc.jobRepository.SaveStatus(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to save backfill job: %w", err)
+	}
+
+	uc.logger.Info("Backfill job updated", "job", job.ID, "symbol", job.Symbol, "status", job.Status)
+	return job, nil
+}
+
+// newJobID returns a short random ID that is easy to type.
+func newJobID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
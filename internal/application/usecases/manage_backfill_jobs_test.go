@@ -0,0 +1,121 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/mocks"
+)
+
+func newTestManageBackfillJobsUseCase(jobRepo *mocks.MockBackfillJobRepository, now time.Time) *ManageBackfillJobsUseCase {
+	uc := NewManageBackfillJobsUseCase(jobRepo, slog.Default())
+	ids := 0
+	uc.newID = func() string {
+		ids++
+		return fmt.Sprintf("job%d", ids)
+	}
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+func TestManageBackfillJobsUseCase_Add(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	from := now.AddDate(0, 0, -7)
+
+	t.Run("queues a job per symbol", func(t *testing.T) {
+		jobRepo := new(mocks.MockBackfillJobRepository)
+		jobRepo.On("Save", ctx, mock.AnythingOfType("*entities.BackfillJob")).Return(nil).Twice()
+
+		uc := newTestManageBackfillJobsUseCase(jobRepo, now)
+		jobs, err := uc.Add(ctx, []string{"btcusdt", " ETHUSDT"}, entities.BackfillBackward, from, now)
+		require.NoError(t, err)
+
+		require.Len(t, jobs, 2)
+		assert.Equal(t, "job1", jobs[0].ID)
+		assert.Equal(t, "BTCUSDT", jobs[0].Symbol)
+		assert.Equal(t, "ETHUSDT", jobs[1].Symbol)
+		for _, job := range jobs {
+			assert.Equal(t, entities.BackfillJobPending, job.Status)
+			assert.Equal(t, entities.BackfillBackward, job.Direction)
+			assert.Equal(t, from, job.From)
+			assert.Equal(t, now, job.To)
+		}
+		jobRepo.AssertExpectations(t)
+	})
+
+	t.Run("queues nothing when a job is invalid", func(t *testing.T) {
+		jobRepo := new(mocks.MockBackfillJobRepository)
+
+		uc := newTestManageBackfillJobsUseCase(jobRepo, now)
+		_, err := uc.Add(ctx, []string{"BTCUSDT", ""}, entities.BackfillBackward, from, now)
+		assert.ErrorIs(t, err, entities.ErrInvalidSymbol)
+
+		_, err = uc.Add(ctx, []string{"BTCUSDT"}, entities.BackfillForward, now, from)
+		assert.ErrorIs(t, err, entities.ErrInvalidTimeRange)
+
+		jobRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+}
+
+func TestManageBackfillJobsUseCase_StatusChanges(t *testing.T) {
+	ctx := context.Background()
+	created := time.Now().Add(-time.Hour)
+	now := time.Now()
+
+	newJob := func(status entities.BackfillJobStatus) *entities.BackfillJob {
+		job := entities.NewBackfillJob("job1", "BTCUSDT", entities.BackfillBackward, created.AddDate(0, 0, -1), created, created)
+		job.Status = status
+		job.Cursor = 5000
+		return job
+	}
+
+	t.Run("pause", func(t *testing.T) {
+		jobRepo := new(mocks.MockBackfillJobRepository)
+		jobRepo.On("GetByID", ctx, "job1").Return(newJob(entities.BackfillJobRunning), nil)
+		jobRepo.On("SaveStatus", ctx, mock.MatchedBy(func(job *entities.BackfillJob) bool {
+			return job.Status == entities.BackfillJobPaused && job.UpdatedAt.Equal(now) && job.Cursor == 5000
+		})).Return(nil)
+
+		job, err := newTestManageBackfillJobsUseCase(jobRepo, now).Pause(ctx, "job1")
+		require.NoError(t, err)
+		assert.Equal(t, entities.BackfillJobPaused, job.Status)
+		jobRepo.AssertExpectations(t)
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		jobRepo := new(mocks.MockBackfillJobRepository)
+		jobRepo.On("GetByID", ctx, "job1").Return(newJob(entities.BackfillJobFailed), nil)
+		jobRepo.On("SaveStatus", ctx, mock.Anything).Return(nil)
+
+		job, err := newTestManageBackfillJobsUseCase(jobRepo, now).Resume(ctx, "job1")
+		require.NoError(t, err)
+		assert.Equal(t, entities.BackfillJobPending, job.Status)
+	})
+
+	t.Run("cancel a completed job", func(t *testing.T) {
+		jobRepo := new(mocks.MockBackfillJobRepository)
+		jobRepo.On("GetByID", ctx, "job1").Return(newJob(entities.BackfillJobCompleted), nil)
+
+		_, err := newTestManageBackfillJobsUseCase(jobRepo, now).Cancel(ctx, "job1")
+		assert.ErrorIs(t, err, entities.ErrInvalidJobStatus)
+		jobRepo.AssertNotCalled(t, "SaveStatus", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		jobRepo := new(mocks.MockBackfillJobRepository)
+		jobRepo.On("GetByID", ctx, "nope").Return(nil, errors.New("backfill job nope not found"))
+
+		_, err := newTestManageBackfillJobsUseCase(jobRepo, now).Cancel(ctx, "nope")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,28 @@
+package usecases
+
+import (
+	"context"
+	"log/slog"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/infrastructure/clickhouse"
+)
+
+type ProcessAggTradeEventUseCase struct {
+	batchProcessor *clickhouse.AggTradeBatchProcessor
+	logger         *slog.Logger
+}
+
+func NewProcessAggTradeEventUseCase(
+	batchProcessor *clickhouse.AggTradeBatchProcessor,
+	logger *slog.Logger,
+) *ProcessAggTradeEventUseCase {
+	return &ProcessAggTradeEventUseCase{
+		batchProcessor: batchProcessor,
+		logger:         logger,
+	}
+}
+
+func (uc *ProcessAggTradeEventUseCase) Execute(ctx context.Context, aggTrade *entities.AggTrade) error {
+	return uc.batchProcessor.AddAggTrade(aggTrade)
+}
@@ -22,6 +22,8 @@ func TestNewProcessBookTickerEventUseCase(t *testing.T) {
 		logger,
 		10,
 		100*time.Millisecond,
+		clickhouse.DefaultFlushPoolConfig(),
+		nil,
 	)
 	defer func() { _ = batchProcessor.Close() }()
 
@@ -47,6 +49,8 @@ func TestProcessBookTickerEventUseCase_Execute(t *testing.T) {
 			logger,
 			1, // batch size of 1 for immediate flush
 			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
 		)
 		defer func() { _ = batchProcessor.Close() }()
 
@@ -80,6 +84,8 @@ func TestProcessBookTickerEventUseCase_Execute(t *testing.T) {
 			logger,
 			10,
 			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
 		)
 		defer func() { _ = batchProcessor.Close() }()
 
@@ -112,6 +118,8 @@ func TestProcessBookTickerEventUseCase_Execute(t *testing.T) {
 			logger,
 			10,
 			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
 		)
 		defer func() { _ = batchProcessor.Close() }()
 
@@ -144,6 +152,8 @@ func TestProcessBookTickerEventUseCase_Execute(t *testing.T) {
 			logger,
 			10,
 			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
 		)
 		defer func() { _ = batchProcessor.Close() }()
 
@@ -181,6 +191,8 @@ func TestProcessBookTickerEventUseCase_Execute(t *testing.T) {
 			logger,
 			5, // batch size of 5
 			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
 		)
 		defer func() { _ = batchProcessor.Close() }()
 
@@ -0,0 +1,28 @@
+package usecases
+
+import (
+	"context"
+	"log/slog"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/infrastructure/clickhouse"
+)
+
+type ProcessKlineEventUseCase struct {
+	batchProcessor *clickhouse.KlineBatchProcessor
+	logger         *slog.Logger
+}
+
+func NewProcessKlineEventUseCase(
+	batchProcessor *clickhouse.KlineBatchProcessor,
+	logger *slog.Logger,
+) *ProcessKlineEventUseCase {
+	return &ProcessKlineEventUseCase{
+		batchProcessor: batchProcessor,
+		logger:         logger,
+	}
+}
+
+func (uc *ProcessKlineEventUseCase) Execute(ctx context.Context, kline *entities.Kline) error {
+	return uc.batchProcessor.AddKline(kline)
+}
@@ -0,0 +1,28 @@
+package usecases
+
+import (
+	"context"
+	"log/slog"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/infrastructure/clickhouse"
+)
+
+type ProcessMarkPriceEventUseCase struct {
+	batchProcessor *clickhouse.MarkPriceBatchProcessor
+	logger         *slog.Logger
+}
+
+func NewProcessMarkPriceEventUseCase(
+	batchProcessor *clickhouse.MarkPriceBatchProcessor,
+	logger *slog.Logger,
+) *ProcessMarkPriceEventUseCase {
+	return &ProcessMarkPriceEventUseCase{
+		batchProcessor: batchProcessor,
+		logger:         logger,
+	}
+}
+
+func (uc *ProcessMarkPriceEventUseCase) Execute(ctx context.Context, markPrice *entities.MarkPrice) error {
+	return uc.batchProcessor.AddMarkPrice(markPrice)
+}
@@ -9,6 +9,7 @@ import (
 	"alarket/internal/domain/entities"
 	"alarket/internal/domain/mocks"
 	"alarket/internal/infrastructure/clickhouse"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -22,6 +23,8 @@ func TestNewProcessTradeEventUseCase(t *testing.T) {
 		logger,
 		10,
 		100*time.Millisecond,
+		clickhouse.DefaultFlushPoolConfig(),
+		nil,
 	)
 	defer func() { _ = batchProcessor.Close() }()
 
@@ -47,16 +50,18 @@ func TestProcessTradeEventUseCase_Execute(t *testing.T) {
 			logger,
 			1, // batch size of 1 for immediate flush
 			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
 		)
 		defer func() { _ = batchProcessor.Close() }()
 
 		uc := NewProcessTradeEventUseCase(batchProcessor, logger)
 
 		trade := &entities.Trade{
-			ID:           "123456",
+			ID:           123456,
 			Symbol:       "BTCUSDT",
-			Price:        50000.0,
-			Quantity:     0.01,
+			Price:        decimal.RequireFromString("50000.0"),
+			Quantity:     decimal.RequireFromString("0.01"),
 			Time:         time.Now(),
 			IsBuyerMaker: true,
 			EventTime:    time.Now(),
@@ -79,16 +84,18 @@ func TestProcessTradeEventUseCase_Execute(t *testing.T) {
 			logger,
 			10,
 			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
 		)
 		defer func() { _ = batchProcessor.Close() }()
 
 		uc := NewProcessTradeEventUseCase(batchProcessor, logger)
 
 		trade := &entities.Trade{
-			ID:           "123456",
+			ID:           123456,
 			Symbol:       "", // Invalid: empty symbol
-			Price:        50000.0,
-			Quantity:     0.01,
+			Price:        decimal.RequireFromString("50000.0"),
+			Quantity:     decimal.RequireFromString("0.01"),
 			Time:         time.Now(),
 			IsBuyerMaker: true,
 			EventTime:    time.Now(),
@@ -110,16 +117,18 @@ func TestProcessTradeEventUseCase_Execute(t *testing.T) {
 			logger,
 			10,
 			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
 		)
 		defer func() { _ = batchProcessor.Close() }()
 
 		uc := NewProcessTradeEventUseCase(batchProcessor, logger)
 
 		trade := &entities.Trade{
-			ID:           "123456",
+			ID:           123456,
 			Symbol:       "BTCUSDT",
-			Price:        -100.0, // Invalid: negative price
-			Quantity:     0.01,
+			Price:        decimal.RequireFromString("-100.0"), // Invalid: negative price
+			Quantity:     decimal.RequireFromString("0.01"),
 			Time:         time.Now(),
 			IsBuyerMaker: true,
 			EventTime:    time.Now(),
@@ -146,6 +155,8 @@ func TestProcessTradeEventUseCase_Execute(t *testing.T) {
 			logger,
 			3, // batch size of 3
 			100*time.Millisecond,
+			clickhouse.DefaultFlushPoolConfig(),
+			nil,
 		)
 		defer func() { _ = batchProcessor.Close() }()
 
@@ -154,10 +165,10 @@ func TestProcessTradeEventUseCase_Execute(t *testing.T) {
 		// Add 3 trades to trigger batch
 		for i := 0; i < 3; i++ {
 			trade := &entities.Trade{
-				ID:           string(rune('1' + i)),
+				ID:           uint64(i + 1),
 				Symbol:       "BTCUSDT",
-				Price:        50000.0 + float64(i),
-				Quantity:     0.01,
+				Price:        decimal.NewFromInt(50000 + int64(i)),
+				Quantity:     decimal.RequireFromString("0.01"),
 				Time:         time.Now(),
 				IsBuyerMaker: i%2 == 0,
 				EventTime:    time.Now(),
@@ -0,0 +1,139 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/events"
+	"alarket/internal/domain/repositories"
+	"alarket/internal/domain/services"
+)
+
+// RefreshSymbolsUseCase re-fetches the symbols of an exchange market, follows
+// new listings, delistings and status changes with the subscriptions and
+// publishes them as domain events.
+type RefreshSymbolsUseCase struct {
+	connector  services.ExchangeConnector
+	symbolRepo repositories.SymbolRepository
+	subscriber *SubscribeToSymbolsUseCase
+	publisher  services.EventPublisher
+	streams    Streams
+	logger     *slog.Logger
+	now        func() time.Time
+	mu         sync.Mutex
+}
+
+func NewRefreshSymbolsUseCase(
+	connector services.ExchangeConnector,
+	symbolRepo repositories.SymbolRepository,
+	subscriber *SubscribeToSymbolsUseCase,
+	publisher services.EventPublisher,
+	streams Streams,
+	logger *slog.Logger,
+) *RefreshSymbolsUseCase {
+	return &RefreshSymbolsUseCase{
+		connector:  connector,
+		symbolRepo: symbolRepo,
+		subscriber: subscriber,
+		publisher:  publisher,
+		streams:    streams,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Execute fetches the symbols, stores them and brings the subscriptions in
+// line with the symbol selection: symbols that became active or rank into a
+// top-N are subscribed, those that no longer qualify are unsubscribed.
+// Symbols whose subscription fails are retried on the next refresh.
+func (uc *RefreshSymbolsUseCase) Execute(ctx context.Context) error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	fetched, err := uc.connector.FetchSymbols(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch symbols: %w", err)
+	}
+
+	previous, err := uc.symbolRepo.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get symbols: %w", err)
+	}
+	changes := uc.diff(previous, fetched)
+
+	if err := uc.symbolRepo.ReplaceAll(ctx, fetched); err != nil {
+		return fmt.Errorf("failed to store symbols: %w", err)
+	}
+
+	for _, change := range changes {
+		if err := uc.publisher.Publish(ctx, change); err != nil {
+			uc.logger.Error("Failed to publish symbol change", "type", change.Type(), "error", err)
+		}
+	}
+
+	added, removed, err := uc.subscriber.Sync(ctx, uc.streams)
+	if err != nil {
+		return err
+	}
+
+	uc.logger.Info("Symbols refreshed",
+		"symbols", len(fetched),
+		"changes", len(changes),
+		"subscribed", countSymbols(added),
+		"unsubscribed", countSymbols(removed),
+	)
+	return nil
+}
+
+// diff returns the listings, delistings and status changes between two
+// symbol lists, ordered by symbol name.
+func (uc *RefreshSymbolsUseCase) diff(previous, current []*entities.Symbol) []events.DomainEvent {
+	exchange, market, now := uc.connector.Exchange(), uc.connector.Market(), uc.now()
+
+	before := make(map[string]*entities.Symbol, len(previous))
+	for _, symbol := range previous {
+		before[symbol.Name] = symbol
+	}
+	after := make(map[string]*entities.Symbol, len(current))
+	for _, symbol := range current {
+		after[symbol.Name] = symbol
+	}
+
+	names := make([]string, 0, len(before)+len(after))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, known := before[name]; !known {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []events.DomainEvent
+	for _, name := range names {
+		old, existed := before[name]
+		symbol, exists := after[name]
+
+		switch {
+		case !existed:
+			changes = append(changes, events.SymbolListedEvent{
+				Exchange: exchange, Market: market, Symbol: symbol, Time: now,
+			})
+		case !exists:
+			changes = append(changes, events.SymbolDelistedEvent{
+				Exchange: exchange, Market: market, Symbol: old, Time: now,
+			})
+		case old.Status != symbol.Status:
+			changes = append(changes, events.SymbolStatusChangedEvent{
+				Exchange: exchange, Market: market, Symbol: symbol, PreviousStatus: old.Status, Time: now,
+			})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,189 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/events"
+	"alarket/internal/domain/mocks"
+	"alarket/internal/domain/services"
+)
+
+func usdtSymbols(statuses map[string]entities.SymbolStatus, names ...string) []*entities.Symbol {
+	result := make([]*entities.Symbol, 0, len(names))
+	for _, name := range names {
+		result = append(result, entities.NewSymbol(name, name[:len(name)-4], "USDT", statuses[name]))
+	}
+	return result
+}
+
+func newRefreshTest(selections Selections, tickers *mocks.MockTickerService) (*RefreshSymbolsUseCase, *mocks.MockExchangeConnector, *mocks.MockSymbolRepository, *mocks.MockExchangeClient, *mocks.MockEventPublisher) {
+	connector := new(mocks.MockExchangeConnector)
+	connector.On("Exchange").Return(entities.ExchangeBinance)
+	connector.On("Market").Return(entities.MarketSpot)
+	symbolRepo := new(mocks.MockSymbolRepository)
+	exchangeClient := new(mocks.MockExchangeClient)
+	publisher := new(mocks.MockEventPublisher)
+
+	logger := slog.Default()
+	var tickerService services.TickerService
+	if tickers != nil {
+		tickerService = tickers
+	}
+	uc := NewRefreshSymbolsUseCase(
+		connector,
+		symbolRepo,
+		NewSubscribeToSymbolsUseCase(symbolRepo, exchangeClient, selections, tickerService, logger),
+		publisher,
+		Streams{Trades: true, BookTickers: true},
+		logger,
+	)
+	return uc, connector, symbolRepo, exchangeClient, publisher
+}
+
+func TestRefreshSymbolsUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+	trading := entities.SymbolStatusTrading
+	halt := entities.SymbolStatusHalt
+
+	t.Run("follows listings, delistings and status changes", func(t *testing.T) {
+		uc, connector, symbolRepo, exchangeClient, publisher := newRefreshTest(nil, nil)
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		uc.now = func() time.Time { return now }
+
+		previous := usdtSymbols(map[string]entities.SymbolStatus{"BTCUSDT": trading, "ETHUSDT": trading, "XRPUSDT": halt},
+			"BTCUSDT", "ETHUSDT", "XRPUSDT")
+		fetched := usdtSymbols(map[string]entities.SymbolStatus{"BTCUSDT": trading, "ETHUSDT": halt, "SOLUSDT": trading},
+			"BTCUSDT", "ETHUSDT", "SOLUSDT")
+
+		// The startup subscription covered the symbols active before
+		subscribed := []string{"BTCUSDT", "ETHUSDT"}
+		exchangeClient.On("SubscribeToTrades", ctx, subscribed).Return(nil).Once()
+		exchangeClient.On("SubscribeToBookTickers", ctx, subscribed).Return(nil).Once()
+		require.NoError(t, uc.subscriber.Subscribe(ctx, StreamSymbols{StreamTrades: subscribed, StreamBookTickers: subscribed}, nil))
+
+		connector.On("FetchSymbols", ctx).Return(fetched, nil)
+		symbolRepo.On("GetAll", ctx).Return(previous, nil)
+		symbolRepo.On("ReplaceAll", ctx, fetched).Return(nil)
+		symbolRepo.On("GetActive", ctx).Return([]*entities.Symbol{fetched[0], fetched[2]}, nil).Once()
+		publisher.On("Publish", ctx, mock.Anything).Return(nil)
+		exchangeClient.On("UnsubscribeFromTrades", ctx, []string{"ETHUSDT"}).Return(nil)
+		exchangeClient.On("UnsubscribeFromBookTickers", ctx, []string{"ETHUSDT"}).Return(nil)
+		exchangeClient.On("SubscribeToTrades", ctx, []string{"SOLUSDT"}).Return(nil)
+		exchangeClient.On("SubscribeToBookTickers", ctx, []string{"SOLUSDT"}).Return(nil)
+
+		require.NoError(t, uc.Execute(ctx))
+		assert.Equal(t, StreamSymbols{
+			StreamTrades:      {"BTCUSDT", "SOLUSDT"},
+			StreamBookTickers: {"BTCUSDT", "SOLUSDT"},
+		}, uc.subscriber.Subscriptions())
+
+		symbolRepo.AssertExpectations(t)
+		exchangeClient.AssertExpectations(t)
+
+		require.Len(t, publisher.Calls, 3)
+		assert.Equal(t, events.SymbolStatusChangedEvent{
+			Exchange:       entities.ExchangeBinance,
+			Market:         entities.MarketSpot,
+			Symbol:         fetched[1],
+			PreviousStatus: trading,
+			Time:           now,
+		}, publisher.Calls[0].Arguments.Get(1))
+		assert.Equal(t, events.SymbolListedEvent{
+			Exchange: entities.ExchangeBinance,
+			Market:   entities.MarketSpot,
+			Symbol:   fetched[2],
+			Time:     now,
+		}, publisher.Calls[1].Arguments.Get(1))
+		assert.Equal(t, events.SymbolDelistedEvent{
+			Exchange: entities.ExchangeBinance,
+			Market:   entities.MarketSpot,
+			Symbol:   previous[2],
+			Time:     now,
+		}, publisher.Calls[2].Arguments.Get(1))
+	})
+
+	t.Run("retries failed subscriptions on the next refresh", func(t *testing.T) {
+		uc, connector, symbolRepo, exchangeClient, publisher := newRefreshTest(Selections{StreamBookTickers: {Symbols: []string{"BTCUSDT"}}}, nil)
+
+		previous := usdtSymbols(map[string]entities.SymbolStatus{"BTCUSDT": trading}, "BTCUSDT")
+		fetched := usdtSymbols(map[string]entities.SymbolStatus{"BTCUSDT": trading, "SOLUSDT": trading},
+			"BTCUSDT", "SOLUSDT")
+
+		exchangeClient.On("SubscribeToTrades", ctx, []string{"BTCUSDT"}).Return(nil).Once()
+		exchangeClient.On("SubscribeToBookTickers", ctx, []string{"BTCUSDT"}).Return(nil).Once()
+		require.NoError(t, uc.subscriber.Subscribe(ctx, StreamSymbols{StreamTrades: {"BTCUSDT"}, StreamBookTickers: {"BTCUSDT"}}, nil))
+
+		connector.On("FetchSymbols", ctx).Return(fetched, nil)
+		symbolRepo.On("GetAll", ctx).Return(previous, nil).Once()
+		symbolRepo.On("GetAll", ctx).Return(fetched, nil).Once()
+		symbolRepo.On("ReplaceAll", ctx, fetched).Return(nil)
+		symbolRepo.On("GetActive", ctx).Return(fetched, nil)
+		publisher.On("Publish", ctx, mock.Anything).Return(nil)
+		exchangeClient.On("SubscribeToTrades", ctx, []string{"SOLUSDT"}).Return(errors.New("rejected")).Once()
+		exchangeClient.On("SubscribeToTrades", ctx, []string{"SOLUSDT"}).Return(nil).Once()
+
+		assert.Error(t, uc.Execute(ctx))
+		require.NoError(t, uc.Execute(ctx))
+
+		exchangeClient.AssertExpectations(t)
+		// The listing is published once, the second refresh sees no change
+		publisher.AssertNumberOfCalls(t, "Publish", 1)
+	})
+
+	t.Run("keeps the stored symbols when the fetch fails", func(t *testing.T) {
+		uc, connector, symbolRepo, exchangeClient, publisher := newRefreshTest(nil, nil)
+
+		connector.On("FetchSymbols", ctx).Return(nil, errors.New("unavailable"))
+
+		err := uc.Execute(ctx)
+		assert.ErrorContains(t, err, "failed to fetch symbols")
+
+		symbolRepo.AssertNotCalled(t, "ReplaceAll", mock.Anything, mock.Anything)
+		exchangeClient.AssertNotCalled(t, "SubscribeToTrades", mock.Anything, mock.Anything)
+		publisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+
+	t.Run("follows the top symbols by volume", func(t *testing.T) {
+		tickers := new(mocks.MockTickerService)
+		uc, connector, symbolRepo, exchangeClient, publisher := newRefreshTest(
+			Selections{StreamBookTickers: {TopByVolume: 1}}, tickers,
+		)
+
+		active := usdtSymbols(map[string]entities.SymbolStatus{"BTCUSDT": trading, "ETHUSDT": trading},
+			"BTCUSDT", "ETHUSDT")
+
+		exchangeClient.On("SubscribeToTrades", ctx, []string{"BTCUSDT", "ETHUSDT"}).Return(nil).Once()
+		exchangeClient.On("SubscribeToBookTickers", ctx, []string{"BTCUSDT"}).Return(nil).Once()
+		require.NoError(t, uc.subscriber.Subscribe(ctx, StreamSymbols{
+			StreamTrades:      {"BTCUSDT", "ETHUSDT"},
+			StreamBookTickers: {"BTCUSDT"},
+		}, nil))
+
+		connector.On("FetchSymbols", ctx).Return(active, nil)
+		symbolRepo.On("GetAll", ctx).Return(active, nil)
+		symbolRepo.On("ReplaceAll", ctx, active).Return(nil)
+		symbolRepo.On("GetActive", ctx).Return(active, nil)
+		tickers.On("FetchQuoteVolumes", ctx).Return(map[string]decimal.Decimal{
+			"BTCUSDT": decimal.NewFromInt(100),
+			"ETHUSDT": decimal.NewFromInt(200),
+		}, nil)
+		exchangeClient.On("UnsubscribeFromBookTickers", ctx, []string{"BTCUSDT"}).Return(nil)
+		exchangeClient.On("SubscribeToBookTickers", ctx, []string{"ETHUSDT"}).Return(nil)
+
+		require.NoError(t, uc.Execute(ctx))
+
+		exchangeClient.AssertExpectations(t)
+		tickers.AssertExpectations(t)
+		publisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+}
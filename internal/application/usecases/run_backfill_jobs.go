@@ -0,0 +1,473 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+	"alarket/internal/domain/services"
+)
+
+var (
+	// errJobStopped reports that a job was paused or cancelled while it ran.
+	errJobStopped = errors.New("backfill job stopped")
+	// errLeaseLost reports that another runner took a job over.
+	errLeaseLost = errors.New("backfill job taken over by another runner")
+)
+
+// releaseTimeout bounds the write that releases the lease of an interrupted
+// job.
+const releaseTimeout = 5 * time.Second
+
+// RunBackfillJobsUseCase works through the backfill job queue. Jobs of
+// different symbols run concurrently, the jobs of one symbol one after
+// another, oldest first. The historical data service paces the requests of
+// all of them. Every batch is stored before the job's cursor moves past it,
+// so an interrupted job continues where it stopped.
+//
+// A runner leases the jobs it runs and renews the lease with every batch, so
+// runners in other processes leave them alone. A job whose runner crashed is
+// taken over once its lease expires.
+type RunBackfillJobsUseCase struct {
+	jobRepository         repositories.BackfillJobRepository
+	tradeRepository       repositories.TradeRepository
+	historicalDataService services.HistoricalDataService
+	logger                *slog.Logger
+	concurrency           int
+	batchSize             int
+	owner                 string
+	leaseDuration         time.Duration
+	now                   func() time.Time
+}
+
+func NewRunBackfillJobsUseCase(
+	jobRepository repositories.BackfillJobRepository,
+	tradeRepository repositories.TradeRepository,
+	historicalDataService services.HistoricalDataService,
+	concurrency int,
+	logger *slog.Logger,
+) *RunBackfillJobsUseCase {
+	return &RunBackfillJobsUseCase{
+		jobRepository:         jobRepository,
+		tradeRepository:       tradeRepository,
+		historicalDataService: historicalDataService,
+		logger:                logger,
+		concurrency:           max(concurrency, 1),
+		batchSize:             1000,
+		owner:                 newRunnerID(),
+		leaseDuration:         5 * time.Minute,
+		now:                   time.Now,
+	}
+}
+
+// Execute runs the pending jobs, including the ones an earlier run was
+// interrupted in, until none is left or ctx is done. Jobs added or resumed
+// meanwhile are picked up too, jobs leased by another runner are left to it.
+// A job that fails is marked as failed without stopping the others; Execute
+// only fails when the job state cannot be stored.
+func (uc *RunBackfillJobsUseCase) Execute(ctx context.Context) error {
+	for {
+		jobs, err := uc.jobRepository.GetAll(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get backfill jobs: %w", err)
+		}
+
+		now := uc.now()
+		runnable := make([]*entities.BackfillJob, 0, len(jobs))
+		leased := 0
+		for _, job := range jobs {
+			switch {
+			case !job.IsRunnable():
+			case uc.leasedByOther(job, now):
+				leased++
+			default:
+				runnable = append(runnable, job)
+			}
+		}
+		if len(runnable) == 0 {
+			if leased > 0 {
+				uc.logger.Info("Backfill jobs left are run by other runners", "jobs", leased)
+			} else {
+				uc.logger.Info("No backfill jobs left to run")
+			}
+			return nil
+		}
+
+		if err := uc.runAll(ctx, runnable); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// runAll runs jobs with up to concurrency symbols at a time.
+func (uc *RunBackfillJobsUseCase) runAll(ctx context.Context, jobs []*entities.BackfillJob) error {
+	var symbols []string
+	queues := make(map[string][]*entities.BackfillJob)
+	for _, job := range jobs {
+		if _, ok := queues[job.Symbol]; !ok {
+			symbols = append(symbols, job.Symbol)
+		}
+		queues[job.Symbol] = append(queues[job.Symbol], job)
+	}
+
+	next := make(chan []*entities.BackfillJob, len(symbols))
+	for _, symbol := range symbols {
+		next <- queues[symbol]
+	}
+	close(next)
+
+	uc.logger.Info("Running backfill jobs",
+		"jobs", len(jobs),
+		"symbols", len(symbols),
+		"concurrency", min(uc.concurrency, len(symbols)))
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for range min(uc.concurrency, len(symbols)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for queue := range next {
+				for _, job := range queue {
+					if ctx.Err() != nil {
+						return
+					}
+					if err := uc.runJob(ctx, job.ID); err != nil {
+						mu.Lock()
+						errs = append(errs, err)
+						mu.Unlock()
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// runJob runs a job until it is done, fails, is stopped, is taken over or
+// ctx is done. It only returns an error when the job state cannot be read or
+// stored.
+func (uc *RunBackfillJobsUseCase) runJob(ctx context.Context, id string) error {
+	// Read again, the job may have been paused, cancelled or leased while
+	// queued
+	job, err := uc.jobRepository.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get backfill job %s: %w", id, err)
+	}
+	if !job.IsRunnable() || uc.leasedByOther(job, uc.now()) {
+		return nil
+	}
+
+	logger := uc.logger.With("job", job.ID, "symbol", job.Symbol, "direction", job.Direction)
+
+	claimed, err := uc.claim(ctx, job)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		logger.Info("Backfill job claimed by another runner")
+		return nil
+	}
+	logger.Info("Backfill job started",
+		"from", job.From.Format(time.RFC3339),
+		"to", job.To.Format(time.RFC3339),
+		"cursor", job.Cursor,
+		"fetched", job.Fetched)
+
+	err = uc.backfill(ctx, job, logger)
+	switch {
+	case errors.Is(err, errLeaseLost):
+		logger.Info("Backfill job taken over by another runner", "cursor", job.Cursor)
+		return nil
+	case ctx.Err() != nil:
+		// Release the lease, the next run continues from the cursor right away
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+		defer cancel()
+		job.Release(uc.now())
+		if err := uc.jobRepository.SaveProgress(releaseCtx, job); err != nil {
+			logger.Warn("Failed to release backfill job", "error", err)
+		}
+		logger.Info("Backfill job interrupted", "cursor", job.Cursor, "fetched", job.Fetched)
+		return nil
+	case errors.Is(err, errJobStopped):
+		logger.Info("Backfill job stopped", "status", job.Status, "cursor", job.Cursor, "fetched", job.Fetched)
+		return nil
+	case err != nil:
+		job.Fail(err, uc.now())
+		logger.Error("Backfill job failed", "cursor", job.Cursor, "error", err)
+	default:
+		logger.Info("Backfill job completed", "cursor", job.Cursor, "fetched", job.Fetched)
+	}
+
+	job.Release(uc.now())
+	if err := uc.jobRepository.SaveProgress(ctx, job); err != nil {
+		return fmt.Errorf("failed to save backfill job %s: %w", job.ID, err)
+	}
+	if err := uc.jobRepository.SaveStatus(ctx, job); err != nil {
+		return fmt.Errorf("failed to save backfill job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// claim leases job to this runner. Runners that claim a job at the same time
+// all save their lease and the last one wins, so the lease is read back. A
+// runner that read it back before the winner saved finds out after its
+// first batch.
+func (uc *RunBackfillJobsUseCase) claim(ctx context.Context, job *entities.BackfillJob) (bool, error) {
+	now := uc.now()
+	job.Lease(uc.owner, now.Add(uc.leaseDuration), now)
+	if err := uc.jobRepository.SaveProgress(ctx, job); err != nil {
+		return false, fmt.Errorf("failed to claim backfill job %s: %w", job.ID, err)
+	}
+
+	stored, err := uc.jobRepository.GetByID(ctx, job.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get backfill job %s: %w", job.ID, err)
+	}
+	return stored.Owner == uc.owner, nil
+}
+
+// leasedByOther reports whether another runner holds the lease of job.
+func (uc *RunBackfillJobsUseCase) leasedByOther(job *entities.BackfillJob, now time.Time) bool {
+	return job.IsLeased(now) && job.Owner != uc.owner
+}
+
+// backfill fetches batches until the job reaches the end of its range. It
+// completes the job, or returns errJobStopped once it was paused or
+// cancelled and errLeaseLost once another runner took it over.
+func (uc *RunBackfillJobsUseCase) backfill(ctx context.Context, job *entities.BackfillJob, logger *slog.Logger) error {
+	if job.Cursor == 0 {
+		if err := uc.startFromStoredTrades(ctx, job, logger); err != nil {
+			return err
+		}
+		if job.Status == entities.BackfillJobCompleted {
+			return nil
+		}
+	}
+
+	for {
+		trades, err := uc.fetchBatch(ctx, job)
+		if err != nil {
+			return err
+		}
+		if len(trades) == 0 {
+			logger.Info("No more trades available")
+			job.Complete(uc.now())
+			return nil
+		}
+
+		// A short batch is the end of the history, a clipped one the end of
+		// the range
+		reached := len(trades) < uc.batchSize
+		trades, clipped := clipToRange(job, trades)
+		if len(trades) == 0 {
+			logger.Info("Reached the end of the range")
+			job.Complete(uc.now())
+			return nil
+		}
+		reached = reached || clipped
+
+		if err := uc.tradeRepository.SaveBatch(ctx, trades); err != nil {
+			return fmt.Errorf("failed to save trades batch: %w", err)
+		}
+
+		// Trades come oldest first
+		first, last := trades[0], trades[len(trades)-1]
+		if job.Direction == entities.BackfillBackward {
+			job.Advance(first.ID, len(trades), uc.now())
+			reached = reached || first.ID == 0
+		} else {
+			job.Advance(last.ID, len(trades), uc.now())
+		}
+
+		logger.Debug("Saved trades batch",
+			"trades_in_batch", len(trades),
+			"fetched", job.Fetched,
+			"cursor", job.Cursor,
+			"oldest_in_batch", first.Time.Format(time.RFC3339),
+			"newest_in_batch", last.Time.Format(time.RFC3339))
+
+		// Pause and cancel arrive through the stored status, a takeover
+		// through the stored lease
+		stored, err := uc.jobRepository.GetByID(ctx, job.ID)
+		if err != nil {
+			return fmt.Errorf("failed to reload backfill job: %w", err)
+		}
+		if stored.Owner != uc.owner {
+			return errLeaseLost
+		}
+		if !stored.IsRunnable() {
+			job.Status = stored.Status
+			job.Release(uc.now())
+			if err := uc.jobRepository.SaveProgress(ctx, job); err != nil {
+				return fmt.Errorf("failed to save backfill job progress: %w", err)
+			}
+			return errJobStopped
+		}
+
+		if reached {
+			job.Complete(uc.now())
+			return nil
+		}
+		now := uc.now()
+		job.Lease(uc.owner, now.Add(uc.leaseDuration), now)
+		if err := uc.jobRepository.SaveProgress(ctx, job); err != nil {
+			return fmt.Errorf("failed to save backfill job progress: %w", err)
+		}
+	}
+}
+
+// startFromStoredTrades moves the cursor of a new job to the trades already
+// stored when they lie inside its range: backward jobs continue before the
+// oldest, forward jobs after the newest. Otherwise the cursor stays 0 and
+// fetchBatch starts at the far end of the range.
+func (uc *RunBackfillJobsUseCase) startFromStoredTrades(ctx context.Context, job *entities.BackfillJob, logger *slog.Logger) error {
+	if job.Direction == entities.BackfillForward {
+		newestTime, err := uc.tradeRepository.GetNewestTradeTime(ctx, job.Symbol)
+		if err != nil {
+			return fmt.Errorf("failed to get newest trade time: %w", err)
+		}
+		if newestTime == nil || !job.Covers(*newestTime) {
+			return nil
+		}
+
+		newestID, err := uc.tradeRepository.GetNewestTradeID(ctx, job.Symbol)
+		if err != nil {
+			return fmt.Errorf("failed to get newest trade ID: %w", err)
+		}
+		if newestID != nil {
+			job.Cursor = *newestID
+			logger.Info("Starting after newest stored trade", "newest_id", *newestID)
+		}
+		return nil
+	}
+
+	oldestTime, err := uc.tradeRepository.GetOldestTradeTime(ctx, job.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to check existing trades: %w", err)
+	}
+	if oldestTime == nil || !job.Covers(*oldestTime) {
+		return nil
+	}
+
+	oldestID, err := uc.tradeRepository.GetOldestTradeID(ctx, job.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get oldest trade ID: %w", err)
+	}
+	if oldestID == nil {
+		return nil
+	}
+	if *oldestID == 0 {
+		logger.Info("Already at the beginning of trade history")
+		job.Complete(uc.now())
+		return nil
+	}
+	job.Cursor = *oldestID
+	logger.Info("Starting before oldest stored trade", "oldest_id", *oldestID)
+	return nil
+}
+
+// fetchBatch fetches the batch that follows the cursor. A job without a
+// cursor starts at the first trade at From walking forward and before the
+// first trade at To walking backward, or at the latest trades when there was
+// none since To.
+func (uc *RunBackfillJobsUseCase) fetchBatch(ctx context.Context, job *entities.BackfillJob) ([]*entities.Trade, error) {
+	var (
+		trades []*entities.Trade
+		err    error
+	)
+	switch {
+	case job.Direction == entities.BackfillForward && job.Cursor == 0:
+		startID, ok, findErr := uc.findTradeID(ctx, job.Symbol, job.From)
+		if findErr != nil {
+			return nil, findErr
+		}
+		if !ok {
+			return nil, nil
+		}
+		trades, err = uc.historicalDataService.FetchHistoricalTrades(ctx, job.Symbol, startID, uc.batchSize)
+	case job.Direction == entities.BackfillForward:
+		trades, err = uc.historicalDataService.FetchHistoricalTrades(ctx, job.Symbol, job.Cursor+1, uc.batchSize)
+	case job.Cursor == 0:
+		endID, ok, findErr := uc.findTradeID(ctx, job.Symbol, job.To)
+		switch {
+		case findErr != nil:
+			return nil, findErr
+		case !ok:
+			trades, err = uc.historicalDataService.FetchLatestTrades(ctx, job.Symbol, uc.batchSize)
+		case endID == 0:
+			// No trade before To
+			return nil, nil
+		default:
+			return uc.fetchBefore(ctx, job.Symbol, endID)
+		}
+	default:
+		return uc.fetchBefore(ctx, job.Symbol, job.Cursor)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch historical trades: %w", err)
+	}
+	return trades, nil
+}
+
+// fetchBefore fetches the batch of trades right before the trade with ID id.
+// Near the first trade of the symbol it fetches only the trades left.
+func (uc *RunBackfillJobsUseCase) fetchBefore(ctx context.Context, symbol string, id uint64) ([]*entities.Trade, error) {
+	fromID, limit := uint64(0), int(id)
+	if id >= uint64(uc.batchSize) {
+		fromID, limit = id-uint64(uc.batchSize), uc.batchSize
+	}
+	trades, err := uc.historicalDataService.FetchHistoricalTrades(ctx, symbol, fromID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch historical trades: %w", err)
+	}
+	return trades, nil
+}
+
+func (uc *RunBackfillJobsUseCase) findTradeID(ctx context.Context, symbol string, since time.Time) (uint64, bool, error) {
+	id, ok, err := uc.historicalDataService.FindTradeID(ctx, symbol, since)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to find the first trade since %s: %w", since.Format(time.RFC3339), err)
+	}
+	return id, ok, nil
+}
+
+// clipToRange drops the trades of a batch that lie past the far end of the
+// range of job and reports whether it dropped any.
+func clipToRange(job *entities.BackfillJob, trades []*entities.Trade) ([]*entities.Trade, bool) {
+	kept := trades
+	if job.Direction == entities.BackfillBackward {
+		for len(kept) > 0 && kept[0].Time.Before(job.From) {
+			kept = kept[1:]
+		}
+	} else {
+		for len(kept) > 0 && !kept[len(kept)-1].Time.Before(job.To) {
+			kept = kept[:len(kept)-1]
+		}
+	}
+	return kept, len(kept) < len(trades)
+}
+
+// newRunnerID names this runner in the leases it takes: host, process and a
+// random suffix, so two runs on one host never share it.
+func newRunnerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s/%d/%s", host, os.Getpid(), newJobID())
+}
@@ -0,0 +1,593 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/mocks"
+)
+
+// memoryJobRepository keeps jobs in memory and hands out copies, like a
+// store would.
+type memoryJobRepository struct {
+	mu    sync.Mutex
+	jobs  map[string]entities.BackfillJob
+	order []string
+	// onSave is called with every job whose progress is saved, before it is
+	// stored
+	onSave func(job *entities.BackfillJob)
+}
+
+func newMemoryJobRepository(jobs ...*entities.BackfillJob) *memoryJobRepository {
+	r := &memoryJobRepository{jobs: make(map[string]entities.BackfillJob)}
+	for _, job := range jobs {
+		r.jobs[job.ID] = *job
+		r.order = append(r.order, job.ID)
+	}
+	return r
+}
+
+func (r *memoryJobRepository) Save(ctx context.Context, job *entities.BackfillJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.jobs[job.ID]; !ok {
+		r.order = append(r.order, job.ID)
+	}
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *memoryJobRepository) SaveStatus(ctx context.Context, job *entities.BackfillJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.jobs[job.ID]
+	if !ok {
+		return fmt.Errorf("backfill job %s not found", job.ID)
+	}
+	stored.Status = job.Status
+	stored.Error = job.Error
+	stored.UpdatedAt = job.UpdatedAt
+	r.jobs[job.ID] = stored
+	return nil
+}
+
+func (r *memoryJobRepository) SaveProgress(ctx context.Context, job *entities.BackfillJob) error {
+	if r.onSave != nil {
+		r.onSave(job)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.jobs[job.ID]
+	if !ok {
+		return fmt.Errorf("backfill job %s not found", job.ID)
+	}
+	stored.Cursor = job.Cursor
+	stored.Fetched = job.Fetched
+	stored.Owner = job.Owner
+	stored.LeaseUntil = job.LeaseUntil
+	stored.UpdatedAt = job.UpdatedAt
+	r.jobs[job.ID] = stored
+	return nil
+}
+
+func (r *memoryJobRepository) GetByID(ctx context.Context, id string) (*entities.BackfillJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("backfill job %s not found", id)
+	}
+	return &job, nil
+}
+
+func (r *memoryJobRepository) GetAll(ctx context.Context) ([]*entities.BackfillJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	jobs := make([]*entities.BackfillJob, 0, len(r.order))
+	for _, id := range r.order {
+		job := r.jobs[id]
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+func (r *memoryJobRepository) setStatus(id string, status entities.BackfillJobStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job := r.jobs[id]
+	job.Status = status
+	r.jobs[id] = job
+}
+
+func (r *memoryJobRepository) lease(id, owner string, until time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job := r.jobs[id]
+	job.Owner = owner
+	job.LeaseUntil = until
+	r.jobs[id] = job
+}
+
+// tradeBatch returns trades with consecutive IDs from fromID, the first at
+// start and one a minute after each other.
+func tradeBatch(symbol string, fromID uint64, count int, start time.Time) []*entities.Trade {
+	trades := make([]*entities.Trade, count)
+	for i := range trades {
+		trades[i] = &entities.Trade{
+			ID:       fromID + uint64(i),
+			Symbol:   symbol,
+			Price:    decimal.RequireFromString("50000"),
+			Quantity: decimal.RequireFromString("0.01"),
+			Time:     start.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	return trades
+}
+
+func newTestRunBackfillJobsUseCase(jobs *memoryJobRepository, tradeRepo *mocks.MockTradeRepository, historicalService *mocks.MockHistoricalDataService, concurrency int) *RunBackfillJobsUseCase {
+	uc := NewRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, concurrency, slog.Default())
+	uc.batchSize = 2
+	return uc
+}
+
+func TestRunBackfillJobsUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	from := now.Add(-24 * time.Hour)
+
+	t.Run("backward job without stored trades walks back to the start of the range", func(t *testing.T) {
+		job := entities.NewBackfillJob("job1", "BTCUSDT", entities.BackfillBackward, from, now, now)
+		jobs := newMemoryJobRepository(job)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		tradeRepo.On("GetOldestTradeTime", ctx, "BTCUSDT").Return(nil, nil)
+		historicalService.On("FindTradeID", ctx, "BTCUSDT", now).Return(uint64(0), false, nil).Once()
+		latest := tradeBatch("BTCUSDT", 500, 2, now.Add(-time.Hour))
+		older := tradeBatch("BTCUSDT", 498, 2, from.Add(-time.Minute))
+		historicalService.On("FetchLatestTrades", ctx, "BTCUSDT", 2).Return(latest, nil).Once()
+		historicalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(498), 2).Return(older, nil).Once()
+		tradeRepo.On("SaveBatch", ctx, latest).Return(nil).Once()
+		tradeRepo.On("SaveBatch", ctx, older[1:]).Return(nil).Once()
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, err := jobs.GetByID(ctx, "job1")
+		require.NoError(t, err)
+		assert.Equal(t, entities.BackfillJobCompleted, stored.Status)
+		assert.Equal(t, uint64(499), stored.Cursor, "trades before the range are dropped")
+		assert.Equal(t, uint64(3), stored.Fetched)
+		tradeRepo.AssertExpectations(t)
+		historicalService.AssertExpectations(t)
+	})
+
+	t.Run("backward job starts before the oldest stored trade", func(t *testing.T) {
+		job := entities.NewBackfillJob("job1", "BTCUSDT", entities.BackfillBackward, from, now, now)
+		jobs := newMemoryJobRepository(job)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		oldestTime := now.Add(-time.Hour)
+		oldestID := uint64(1000)
+		tradeRepo.On("GetOldestTradeTime", ctx, "BTCUSDT").Return(&oldestTime, nil)
+		tradeRepo.On("GetOldestTradeID", ctx, "BTCUSDT").Return(&oldestID, nil)
+		batch := tradeBatch("BTCUSDT", 998, 2, from.Add(-time.Minute))
+		historicalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(998), 2).Return(batch, nil).Once()
+		tradeRepo.On("SaveBatch", ctx, batch[1:]).Return(nil)
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, entities.BackfillJobCompleted, stored.Status)
+		assert.Equal(t, uint64(999), stored.Cursor)
+		historicalService.AssertExpectations(t)
+	})
+
+	t.Run("backward job fetches the trades left before the first trade", func(t *testing.T) {
+		job := entities.NewBackfillJob("job1", "BTCUSDT", entities.BackfillBackward, from, now, now)
+		jobs := newMemoryJobRepository(job)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		oldestTime := now.Add(-time.Hour)
+		oldestID := uint64(1)
+		tradeRepo.On("GetOldestTradeTime", ctx, "BTCUSDT").Return(&oldestTime, nil)
+		tradeRepo.On("GetOldestTradeID", ctx, "BTCUSDT").Return(&oldestID, nil)
+		batch := tradeBatch("BTCUSDT", 0, 1, now.Add(-2*time.Hour))
+		historicalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(0), 1).Return(batch, nil).Once()
+		tradeRepo.On("SaveBatch", ctx, batch).Return(nil)
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, entities.BackfillJobCompleted, stored.Status)
+		assert.Equal(t, uint64(0), stored.Cursor)
+		assert.Equal(t, uint64(1), stored.Fetched)
+		historicalService.AssertExpectations(t)
+	})
+
+	t.Run("backward job for a past range ignores stored trades outside it", func(t *testing.T) {
+		to := now.Add(-time.Hour)
+		job := entities.NewBackfillJob("job1", "BTCUSDT", entities.BackfillBackward, from, to, now)
+		jobs := newMemoryJobRepository(job)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		oldestTime := from.Add(-time.Minute)
+		tradeRepo.On("GetOldestTradeTime", ctx, "BTCUSDT").Return(&oldestTime, nil)
+		historicalService.On("FindTradeID", ctx, "BTCUSDT", to).Return(uint64(1000), true, nil).Once()
+		batch := tradeBatch("BTCUSDT", 998, 2, from.Add(-time.Minute))
+		historicalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(998), 2).Return(batch, nil).Once()
+		tradeRepo.On("SaveBatch", ctx, batch[1:]).Return(nil)
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, entities.BackfillJobCompleted, stored.Status)
+		assert.Equal(t, uint64(999), stored.Cursor)
+		historicalService.AssertExpectations(t)
+		tradeRepo.AssertNotCalled(t, "GetOldestTradeID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("backward job without stored trades starts at the end of its range", func(t *testing.T) {
+		to := now.Add(-time.Hour)
+		job := entities.NewBackfillJob("job1", "BTCUSDT", entities.BackfillBackward, from, to, now)
+		jobs := newMemoryJobRepository(job)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		tradeRepo.On("GetOldestTradeTime", ctx, "BTCUSDT").Return(nil, nil)
+		historicalService.On("FindTradeID", ctx, "BTCUSDT", to).Return(uint64(500), true, nil).Once()
+		newer := tradeBatch("BTCUSDT", 498, 2, to.Add(-2*time.Minute))
+		older := tradeBatch("BTCUSDT", 496, 2, from.Add(-time.Minute))
+		historicalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(498), 2).Return(newer, nil).Once()
+		historicalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(496), 2).Return(older, nil).Once()
+		tradeRepo.On("SaveBatch", ctx, newer).Return(nil).Once()
+		tradeRepo.On("SaveBatch", ctx, older[1:]).Return(nil).Once()
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, entities.BackfillJobCompleted, stored.Status)
+		assert.Equal(t, uint64(497), stored.Cursor)
+		assert.Equal(t, uint64(3), stored.Fetched)
+		tradeRepo.AssertExpectations(t)
+		historicalService.AssertExpectations(t)
+		historicalService.AssertNotCalled(t, "FetchLatestTrades", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("interrupted job continues from its cursor", func(t *testing.T) {
+		job := entities.NewBackfillJob("job1", "ETHUSDT", entities.BackfillForward, from, now, now)
+		job.Status = entities.BackfillJobRunning
+		job.Cursor = 700
+		job.Fetched = 200
+		jobs := newMemoryJobRepository(job)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		// A short batch is the end of the available trades
+		batch := tradeBatch("ETHUSDT", 701, 1, now.Add(-time.Minute))
+		historicalService.On("FetchHistoricalTrades", ctx, "ETHUSDT", uint64(701), 2).Return(batch, nil).Once()
+		tradeRepo.On("SaveBatch", ctx, batch).Return(nil)
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, entities.BackfillJobCompleted, stored.Status)
+		assert.Equal(t, uint64(701), stored.Cursor)
+		assert.Equal(t, uint64(201), stored.Fetched)
+		tradeRepo.AssertNotCalled(t, "GetNewestTradeID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("forward job stops at the end of the range", func(t *testing.T) {
+		to := now.Add(-time.Hour)
+		job := entities.NewBackfillJob("job1", "ETHUSDT", entities.BackfillForward, from, to, now)
+		jobs := newMemoryJobRepository(job)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		newestTime := to.Add(-2 * time.Hour)
+		newestID := uint64(100)
+		tradeRepo.On("GetNewestTradeTime", ctx, "ETHUSDT").Return(&newestTime, nil)
+		tradeRepo.On("GetNewestTradeID", ctx, "ETHUSDT").Return(&newestID, nil)
+		first := tradeBatch("ETHUSDT", 101, 2, to.Add(-time.Hour))
+		second := tradeBatch("ETHUSDT", 103, 2, to.Add(-time.Minute))
+		historicalService.On("FetchHistoricalTrades", ctx, "ETHUSDT", uint64(101), 2).Return(first, nil).Once()
+		historicalService.On("FetchHistoricalTrades", ctx, "ETHUSDT", uint64(103), 2).Return(second, nil).Once()
+		tradeRepo.On("SaveBatch", ctx, first).Return(nil).Once()
+		tradeRepo.On("SaveBatch", ctx, second[:1]).Return(nil).Once()
+
+		var cursors []uint64
+		jobs.onSave = func(job *entities.BackfillJob) { cursors = append(cursors, job.Cursor) }
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, entities.BackfillJobCompleted, stored.Status)
+		assert.Equal(t, []uint64{0, 102, 103}, cursors, "the cursor is saved after every batch")
+		tradeRepo.AssertExpectations(t)
+		historicalService.AssertExpectations(t)
+	})
+
+	t.Run("forward job for a past range ignores stored trades after it", func(t *testing.T) {
+		to := now.Add(-time.Hour)
+		job := entities.NewBackfillJob("job1", "ETHUSDT", entities.BackfillForward, from, to, now)
+		jobs := newMemoryJobRepository(job)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		// The collector keeps storing live trades
+		newestTime := now
+		tradeRepo.On("GetNewestTradeTime", ctx, "ETHUSDT").Return(&newestTime, nil)
+		historicalService.On("FindTradeID", ctx, "ETHUSDT", from).Return(uint64(300), true, nil).Once()
+		batch := tradeBatch("ETHUSDT", 300, 2, to.Add(-time.Minute))
+		historicalService.On("FetchHistoricalTrades", ctx, "ETHUSDT", uint64(300), 2).Return(batch, nil).Once()
+		tradeRepo.On("SaveBatch", ctx, batch[:1]).Return(nil).Once()
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, entities.BackfillJobCompleted, stored.Status)
+		assert.Equal(t, uint64(300), stored.Cursor, "trades at or after the end of the range are dropped")
+		tradeRepo.AssertExpectations(t)
+		tradeRepo.AssertNotCalled(t, "GetNewestTradeID", mock.Anything, mock.Anything)
+		historicalService.AssertExpectations(t)
+	})
+
+	t.Run("forward job without stored trades starts at the first trade of the range", func(t *testing.T) {
+		job := entities.NewBackfillJob("job1", "ETHUSDT", entities.BackfillForward, from, now, now)
+		jobs := newMemoryJobRepository(job)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		tradeRepo.On("GetNewestTradeTime", ctx, "ETHUSDT").Return(nil, nil)
+		historicalService.On("FindTradeID", ctx, "ETHUSDT", from).Return(uint64(300), true, nil).Once()
+		batch := tradeBatch("ETHUSDT", 300, 1, from)
+		historicalService.On("FetchHistoricalTrades", ctx, "ETHUSDT", uint64(300), 2).Return(batch, nil).Once()
+		tradeRepo.On("SaveBatch", ctx, batch).Return(nil)
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, entities.BackfillJobCompleted, stored.Status)
+		assert.Equal(t, uint64(300), stored.Cursor)
+		historicalService.AssertExpectations(t)
+		historicalService.AssertNotCalled(t, "FetchLatestTrades", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("forward job without trades in the range completes", func(t *testing.T) {
+		job := entities.NewBackfillJob("job1", "ETHUSDT", entities.BackfillForward, from, now, now)
+		jobs := newMemoryJobRepository(job)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		tradeRepo.On("GetNewestTradeTime", ctx, "ETHUSDT").Return(nil, nil)
+		historicalService.On("FindTradeID", ctx, "ETHUSDT", from).Return(uint64(0), false, nil).Once()
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, entities.BackfillJobCompleted, stored.Status)
+		historicalService.AssertNotCalled(t, "FetchHistoricalTrades", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("pausing a running job keeps its progress", func(t *testing.T) {
+		job := entities.NewBackfillJob("job1", "BTCUSDT", entities.BackfillForward, from, now, now)
+		job.Cursor = 10
+		jobs := newMemoryJobRepository(job)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		batch := tradeBatch("BTCUSDT", 11, 2, from)
+		historicalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(11), 2).Return(batch, nil).Once().
+			Run(func(args mock.Arguments) { jobs.setStatus("job1", entities.BackfillJobPaused) })
+		tradeRepo.On("SaveBatch", ctx, batch).Return(nil)
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, entities.BackfillJobPaused, stored.Status)
+		assert.Equal(t, uint64(12), stored.Cursor)
+		assert.Equal(t, uint64(2), stored.Fetched)
+		historicalService.AssertExpectations(t)
+	})
+
+	t.Run("pausing a job during a batch keeps the cursor the runner saves", func(t *testing.T) {
+		job := entities.NewBackfillJob("job1", "BTCUSDT", entities.BackfillForward, from, now, now)
+		job.Cursor = 10
+		jobs := newMemoryJobRepository(job)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+		manager := NewManageBackfillJobsUseCase(jobs, slog.Default())
+
+		// The manager reads the job before the runner saves the batch and
+		// saves the pause after it
+		batch := tradeBatch("BTCUSDT", 11, 2, from)
+		historicalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(11), 2).Return(batch, nil).Once()
+		tradeRepo.On("SaveBatch", ctx, batch).Return(nil).Once().Run(func(args mock.Arguments) {
+			_, err := manager.Pause(ctx, "job1")
+			require.NoError(t, err)
+		})
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, entities.BackfillJobPaused, stored.Status)
+		assert.Equal(t, uint64(12), stored.Cursor)
+		assert.False(t, stored.IsLeased(time.Now()))
+	})
+
+	t.Run("failed job does not stop the others", func(t *testing.T) {
+		failing := entities.NewBackfillJob("job1", "BTCUSDT", entities.BackfillForward, from, now, now)
+		failing.Cursor = 10
+		other := entities.NewBackfillJob("job2", "ETHUSDT", entities.BackfillForward, from, now, now)
+		other.Cursor = 20
+		jobs := newMemoryJobRepository(failing, other)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		historicalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(11), 2).Return(nil, errors.New("rate limited"))
+		historicalService.On("FetchHistoricalTrades", ctx, "ETHUSDT", uint64(21), 2).Return([]*entities.Trade{}, nil)
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, entities.BackfillJobFailed, stored.Status)
+		assert.Contains(t, stored.Error, "rate limited")
+		stored, _ = jobs.GetByID(ctx, "job2")
+		assert.Equal(t, entities.BackfillJobCompleted, stored.Status)
+	})
+
+	t.Run("skips jobs that are not runnable", func(t *testing.T) {
+		paused := entities.NewBackfillJob("job1", "BTCUSDT", entities.BackfillForward, from, now, now)
+		paused.Status = entities.BackfillJobPaused
+		completed := entities.NewBackfillJob("job2", "ETHUSDT", entities.BackfillForward, from, now, now)
+		completed.Status = entities.BackfillJobCompleted
+		jobs := newMemoryJobRepository(paused, completed)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		uc := newTestRunBackfillJobsUseCase(jobs, new(mocks.MockTradeRepository), historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		historicalService.AssertNotCalled(t, "FetchHistoricalTrades", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("runs symbols concurrently and the jobs of a symbol in order", func(t *testing.T) {
+		jobs := newMemoryJobRepository(
+			&entities.BackfillJob{ID: "btc1", Symbol: "BTCUSDT", Direction: entities.BackfillForward, From: from, To: now, Cursor: 10, Status: entities.BackfillJobPending},
+			&entities.BackfillJob{ID: "eth1", Symbol: "ETHUSDT", Direction: entities.BackfillForward, From: from, To: now, Cursor: 20, Status: entities.BackfillJobPending},
+			&entities.BackfillJob{ID: "btc2", Symbol: "BTCUSDT", Direction: entities.BackfillForward, From: from, To: now, Cursor: 30, Status: entities.BackfillJobPending},
+		)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		// The first job of each symbol waits until the other symbol started
+		var (
+			mu      sync.Mutex
+			started []uint64
+		)
+		both := make(chan struct{})
+		var once sync.Once
+		record := func(args mock.Arguments) {
+			mu.Lock()
+			started = append(started, args.Get(2).(uint64))
+			if len(started) == 2 {
+				once.Do(func() { close(both) })
+			}
+			mu.Unlock()
+
+			select {
+			case <-both:
+			case <-time.After(5 * time.Second):
+			}
+		}
+		historicalService.On("FetchHistoricalTrades", ctx, mock.Anything, mock.Anything, 2).Return([]*entities.Trade{}, nil).Run(record)
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 2)
+		require.NoError(t, uc.Execute(ctx))
+
+		assert.ElementsMatch(t, []uint64{11, 21}, started[:2], "both symbols run at once")
+		assert.Equal(t, uint64(31), started[2], "the second BTCUSDT job runs after the first")
+	})
+
+	t.Run("skips jobs leased by another runner", func(t *testing.T) {
+		job := entities.NewBackfillJob("job1", "BTCUSDT", entities.BackfillForward, from, now, now)
+		job.Lease("other", time.Now().Add(time.Hour), now)
+		jobs := newMemoryJobRepository(job)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		uc := newTestRunBackfillJobsUseCase(jobs, new(mocks.MockTradeRepository), historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, "other", stored.Owner)
+		historicalService.AssertNotCalled(t, "FetchHistoricalTrades", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("takes over a job whose lease expired", func(t *testing.T) {
+		job := entities.NewBackfillJob("job1", "ETHUSDT", entities.BackfillForward, from, now, now)
+		job.Cursor = 700
+		job.Lease("crashed", time.Now().Add(-time.Minute), now)
+		jobs := newMemoryJobRepository(job)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		batch := tradeBatch("ETHUSDT", 701, 1, now.Add(-time.Minute))
+		historicalService.On("FetchHistoricalTrades", ctx, "ETHUSDT", uint64(701), 2).Return(batch, nil).Once()
+		tradeRepo.On("SaveBatch", ctx, batch).Return(nil)
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, entities.BackfillJobCompleted, stored.Status)
+		assert.Equal(t, uc.owner, stored.Owner)
+		assert.Equal(t, uint64(701), stored.Cursor)
+	})
+
+	t.Run("stops without saving once another runner took the job over", func(t *testing.T) {
+		job := entities.NewBackfillJob("job1", "BTCUSDT", entities.BackfillForward, from, now, now)
+		job.Cursor = 10
+		jobs := newMemoryJobRepository(job)
+		tradeRepo := new(mocks.MockTradeRepository)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		batch := tradeBatch("BTCUSDT", 11, 2, from)
+		historicalService.On("FetchHistoricalTrades", ctx, "BTCUSDT", uint64(11), 2).Return(batch, nil).Once().
+			Run(func(args mock.Arguments) { jobs.lease("job1", "other", time.Now().Add(time.Hour)) })
+		tradeRepo.On("SaveBatch", ctx, batch).Return(nil)
+
+		uc := newTestRunBackfillJobsUseCase(jobs, tradeRepo, historicalService, 1)
+		require.NoError(t, uc.Execute(ctx))
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, "other", stored.Owner)
+		assert.Equal(t, entities.BackfillJobPending, stored.Status)
+		assert.Equal(t, uint64(10), stored.Cursor, "the new owner saves the progress")
+		historicalService.AssertExpectations(t)
+	})
+
+	t.Run("cancelled context releases the job", func(t *testing.T) {
+		job := entities.NewBackfillJob("job1", "BTCUSDT", entities.BackfillForward, from, now, now)
+		job.Cursor = 10
+		jobs := newMemoryJobRepository(job)
+		historicalService := new(mocks.MockHistoricalDataService)
+
+		cancelled, cancel := context.WithCancel(ctx)
+		historicalService.On("FetchHistoricalTrades", cancelled, "BTCUSDT", uint64(11), 2).Return(nil, context.Canceled).
+			Run(func(args mock.Arguments) { cancel() })
+
+		uc := newTestRunBackfillJobsUseCase(jobs, new(mocks.MockTradeRepository), historicalService, 1)
+		assert.ErrorIs(t, uc.Execute(cancelled), context.Canceled)
+
+		stored, _ := jobs.GetByID(ctx, "job1")
+		assert.Equal(t, entities.BackfillJobPending, stored.Status)
+		assert.False(t, stored.IsLeased(time.Now()))
+		assert.Equal(t, uint64(10), stored.Cursor)
+	})
+}
@@ -2,57 +2,384 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"sort"
+	"sync"
 
+	"github.com/shopspring/decimal"
+
+	"alarket/internal/domain/entities"
 	"alarket/internal/domain/repositories"
 	"alarket/internal/domain/services"
 )
 
+// Stream names a stream type collected per symbol.
+type Stream string
+
+const (
+	StreamTrades      Stream = "trades"
+	StreamAggTrades   Stream = "aggTrades"
+	StreamKlines      Stream = "klines"
+	StreamBookTickers Stream = "bookTickers"
+	StreamOrderBooks  Stream = "orderBooks"
+	StreamMarkPrices  Stream = "markPrices"
+)
+
+// streamOrder is the order streams are subscribed in.
+var streamOrder = []Stream{
+	StreamTrades, StreamAggTrades, StreamKlines, StreamBookTickers, StreamOrderBooks, StreamMarkPrices,
+}
+
+// StreamSymbols maps streams to symbol names.
+type StreamSymbols map[Stream][]string
+
+// Selections holds the symbol selection of each stream. Streams without one
+// are collected for every active symbol.
+type Selections map[Stream]entities.SymbolSelection
+
+// OrderBooks keeps the local order books built from the depth stream.
+type OrderBooks interface {
+	// Remove drops the books of symbols
+	Remove(symbols []string)
+}
+
 type SubscribeToSymbolsUseCase struct {
 	symbolRepo     repositories.SymbolRepository
 	exchangeClient services.ExchangeClient
+	selections     Selections
+	tickers        services.TickerService // nil = no volume ranking
+	orderBooks     OrderBooks             // nil = no local order books
 	logger         *slog.Logger
+
+	mu         sync.Mutex
+	subscribed map[Stream]map[string]bool
+	syncMu     sync.Mutex // one Sync at a time
 }
 
 func NewSubscribeToSymbolsUseCase(
 	symbolRepo repositories.SymbolRepository,
 	exchangeClient services.ExchangeClient,
+	selections Selections,
+	tickers services.TickerService,
 	logger *slog.Logger,
 ) *SubscribeToSymbolsUseCase {
 	return &SubscribeToSymbolsUseCase{
 		symbolRepo:     symbolRepo,
 		exchangeClient: exchangeClient,
+		selections:     selections,
+		tickers:        tickers,
 		logger:         logger,
+		subscribed:     make(map[Stream]map[string]bool),
 	}
 }
 
-func (uc *SubscribeToSymbolsUseCase) Execute(ctx context.Context, subscribeTrades, subscribeBookTickers bool) error {
-	activeSymbols, err := uc.symbolRepo.GetActiveUsdt(ctx)
+// Streams selects the streams collected for every symbol.
+type Streams struct {
+	Trades         bool
+	AggTrades      bool
+	BookTickers    bool
+	OrderBooks     bool // diff depth updates
+	MarkPrices     bool
+	KlineIntervals []entities.KlineInterval // empty = no klines
+}
+
+// Enabled returns the selected streams in subscription order.
+func (s Streams) Enabled() []Stream {
+	on := map[Stream]bool{
+		StreamTrades:      s.Trades,
+		StreamAggTrades:   s.AggTrades,
+		StreamKlines:      len(s.KlineIntervals) > 0,
+		StreamBookTickers: s.BookTickers,
+		StreamOrderBooks:  s.OrderBooks,
+		StreamMarkPrices:  s.MarkPrices,
+	}
+
+	streams := make([]Stream, 0, len(streamOrder))
+	for _, stream := range streamOrder {
+		if on[stream] {
+			streams = append(streams, stream)
+		}
+	}
+	return streams
+}
+
+// Execute selects the symbols of every stream in streams and subscribes to
+// them.
+func (uc *SubscribeToSymbolsUseCase) Execute(ctx context.Context, streams Streams) error {
+	symbols, err := uc.Select(ctx, streams)
 	if err != nil {
-		uc.logger.Error("Failed to get active symbols", "error", err)
+		uc.logger.Error("Failed to select symbols", "error", err)
 		return err
 	}
 
-	symbolNames := make([]string, 0, len(activeSymbols))
-	for _, symbol := range activeSymbols {
-		symbolNames = append(symbolNames, symbol.Name)
+	return uc.Subscribe(ctx, symbols, streams.KlineIntervals)
+}
+
+// SetSelections replaces the symbol selections. They apply from the next
+// Select or Sync on.
+func (uc *SubscribeToSymbolsUseCase) SetSelections(selections Selections) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	uc.selections = selections
+}
+
+// SetOrderBooks makes Unsubscribe drop the local order books of the symbols
+// whose depth stream it unsubscribes.
+func (uc *SubscribeToSymbolsUseCase) SetOrderBooks(orderBooks OrderBooks) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	uc.orderBooks = orderBooks
+}
+
+// Select returns the active symbols each enabled stream should be collected
+// for. The 24h volumes are only fetched when a selection ranks by them.
+func (uc *SubscribeToSymbolsUseCase) Select(ctx context.Context, streams Streams) (StreamSymbols, error) {
+	activeSymbols, err := uc.symbolRepo.GetActive(ctx)
+	if err != nil {
+		return nil, err
 	}
+	return uc.SelectFrom(ctx, activeSymbols, streams)
+}
 
-	uc.logger.Info("Subscribing to symbols", "count", len(symbolNames))
+// SelectFrom is Select over the given active symbols instead of the stored
+// ones, e.g. freshly fetched symbols when nothing is stored.
+func (uc *SubscribeToSymbolsUseCase) SelectFrom(ctx context.Context, activeSymbols []*entities.Symbol, streams Streams) (StreamSymbols, error) {
+	uc.mu.Lock()
+	selections := uc.selections
+	uc.mu.Unlock()
 
-	if subscribeTrades {
-		if err := uc.exchangeClient.SubscribeToTrades(ctx, symbolNames); err != nil {
-			uc.logger.Error("Failed to subscribe to trades", "error", err)
-			return err
+	enabled := streams.Enabled()
+
+	var quoteVolumes map[string]decimal.Decimal
+	for _, stream := range enabled {
+		if selections[stream].TopByVolume > 0 && uc.tickers != nil {
+			var err error
+			if quoteVolumes, err = uc.tickers.FetchQuoteVolumes(ctx); err != nil {
+				return nil, fmt.Errorf("failed to fetch 24h volumes: %w", err)
+			}
+			break
+		}
+	}
+
+	selected := make(StreamSymbols, len(enabled))
+	for _, stream := range enabled {
+		symbols := selections[stream].Select(activeSymbols, quoteVolumes)
+
+		symbolNames := make([]string, 0, len(symbols))
+		for _, symbol := range symbols {
+			symbolNames = append(symbolNames, symbol.Name)
+		}
+		selected[stream] = symbolNames
+
+		uc.logger.Info("Selected symbols", "stream", stream, "count", len(symbolNames), "active", len(activeSymbols))
+	}
+	return selected, nil
+}
+
+// Sync selects the symbols again and brings the subscriptions in line: the
+// selected symbols that are not subscribed yet are subscribed, the subscribed
+// ones that are no longer selected are unsubscribed. It returns both sets.
+func (uc *SubscribeToSymbolsUseCase) Sync(ctx context.Context, streams Streams) (added, removed StreamSymbols, err error) {
+	uc.syncMu.Lock()
+	defer uc.syncMu.Unlock()
+
+	selected, err := uc.Select(ctx, streams)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to select symbols: %w", err)
+	}
+	added, removed = subscriptionDelta(uc.Subscriptions(), selected)
+
+	if len(removed) > 0 {
+		uc.logger.Info("Unsubscribing deselected symbols", "symbols", removed)
+		if err := uc.Unsubscribe(ctx, removed, streams.KlineIntervals); err != nil {
+			return nil, nil, fmt.Errorf("failed to unsubscribe symbols: %w", err)
+		}
+	}
+
+	if len(added) > 0 {
+		uc.logger.Info("Subscribing newly selected symbols", "symbols", added)
+		if err := uc.Subscribe(ctx, added, streams.KlineIntervals); err != nil {
+			return nil, nil, fmt.Errorf("failed to subscribe symbols: %w", err)
 		}
 	}
 
-	if subscribeBookTickers {
-		if err := uc.exchangeClient.SubscribeToBookTickers(ctx, symbolNames); err != nil {
-			uc.logger.Error("Failed to subscribe to book tickers", "error", err)
+	return added, removed, nil
+}
+
+// Subscribe subscribes every stream in symbols to its symbols. Streams the
+// exchange does not offer are skipped.
+func (uc *SubscribeToSymbolsUseCase) Subscribe(ctx context.Context, symbols StreamSymbols, klineIntervals []entities.KlineInterval) error {
+	for _, stream := range streamOrder {
+		symbolNames, ok := symbols[stream]
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch stream {
+		case StreamTrades:
+			err = uc.exchangeClient.SubscribeToTrades(ctx, symbolNames)
+		case StreamAggTrades:
+			err = uc.exchangeClient.SubscribeToAggTrades(ctx, symbolNames)
+		case StreamKlines:
+			err = uc.exchangeClient.SubscribeToKlines(ctx, symbolNames, klineIntervals)
+		case StreamBookTickers:
+			err = uc.exchangeClient.SubscribeToBookTickers(ctx, symbolNames)
+		case StreamOrderBooks:
+			err = uc.exchangeClient.SubscribeToDepth(ctx, symbolNames)
+		case StreamMarkPrices:
+			err = uc.exchangeClient.SubscribeToMarkPrices(ctx, symbolNames)
+		}
+		if err != nil {
+			if uc.unsupported(err) {
+				continue
+			}
+			uc.logger.Error("Failed to subscribe", "stream", stream, "error", err)
 			return err
 		}
+
+		uc.track(stream, symbolNames, true)
 	}
 
 	return nil
 }
+
+// Unsubscribe removes every stream in symbols from its symbols. Every stream
+// is attempted; the first failure is returned.
+func (uc *SubscribeToSymbolsUseCase) Unsubscribe(ctx context.Context, symbols StreamSymbols, klineIntervals []entities.KlineInterval) error {
+	var firstErr error
+	for _, stream := range streamOrder {
+		symbolNames, ok := symbols[stream]
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch stream {
+		case StreamTrades:
+			err = uc.exchangeClient.UnsubscribeFromTrades(ctx, symbolNames)
+		case StreamAggTrades:
+			err = uc.exchangeClient.UnsubscribeFromAggTrades(ctx, symbolNames)
+		case StreamKlines:
+			err = uc.exchangeClient.UnsubscribeFromKlines(ctx, symbolNames, klineIntervals)
+		case StreamBookTickers:
+			err = uc.exchangeClient.UnsubscribeFromBookTickers(ctx, symbolNames)
+		case StreamOrderBooks:
+			err = uc.exchangeClient.UnsubscribeFromDepth(ctx, symbolNames)
+		case StreamMarkPrices:
+			err = uc.exchangeClient.UnsubscribeFromMarkPrices(ctx, symbolNames)
+		}
+		if err != nil {
+			if uc.unsupported(err) {
+				continue
+			}
+			uc.logger.Error("Failed to unsubscribe", "stream", stream, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		uc.track(stream, symbolNames, false)
+		if stream == StreamOrderBooks {
+			uc.removeOrderBooks(symbolNames)
+		}
+	}
+
+	return firstErr
+}
+
+// Subscriptions returns the symbols each stream is subscribed to, sorted by
+// name.
+func (uc *SubscribeToSymbolsUseCase) Subscriptions() StreamSymbols {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	subscriptions := make(StreamSymbols, len(uc.subscribed))
+	for stream, names := range uc.subscribed {
+		symbolNames := make([]string, 0, len(names))
+		for name := range names {
+			symbolNames = append(symbolNames, name)
+		}
+		sort.Strings(symbolNames)
+		subscriptions[stream] = symbolNames
+	}
+	return subscriptions
+}
+
+func (uc *SubscribeToSymbolsUseCase) removeOrderBooks(symbolNames []string) {
+	uc.mu.Lock()
+	orderBooks := uc.orderBooks
+	uc.mu.Unlock()
+
+	if orderBooks != nil {
+		orderBooks.Remove(symbolNames)
+	}
+}
+
+func (uc *SubscribeToSymbolsUseCase) track(stream Stream, symbolNames []string, subscribed bool) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	names := uc.subscribed[stream]
+	if names == nil {
+		names = make(map[string]bool)
+		uc.subscribed[stream] = names
+	}
+	for _, name := range symbolNames {
+		if subscribed {
+			names[name] = true
+		} else {
+			delete(names, name)
+		}
+	}
+}
+
+// subscriptionDelta returns per stream the selected symbols that are not
+// subscribed yet and the subscribed ones that are no longer selected. Streams
+// without a difference are left out.
+func subscriptionDelta(subscribed, selected StreamSymbols) (added, removed StreamSymbols) {
+	added, removed = make(StreamSymbols), make(StreamSymbols)
+	for stream, names := range selected {
+		current := make(map[string]bool, len(subscribed[stream]))
+		for _, name := range subscribed[stream] {
+			current[name] = true
+		}
+		wanted := make(map[string]bool, len(names))
+		for _, name := range names {
+			wanted[name] = true
+			if !current[name] {
+				added[stream] = append(added[stream], name)
+			}
+		}
+		for _, name := range subscribed[stream] {
+			if !wanted[name] {
+				removed[stream] = append(removed[stream], name)
+			}
+		}
+		sort.Strings(added[stream])
+	}
+	return added, removed
+}
+
+func countSymbols(symbols StreamSymbols) int {
+	total := 0
+	for _, names := range symbols {
+		total += len(names)
+	}
+	return total
+}
+
+// unsupported reports whether err means the exchange does not offer a stream,
+// which is skipped rather than failing the other subscriptions.
+func (uc *SubscribeToSymbolsUseCase) unsupported(err error) bool {
+	if !errors.Is(err, services.ErrStreamNotSupported) {
+		return false
+	}
+	uc.logger.Warn("Stream not supported by exchange, skipping", "error", err)
+	return true
+}
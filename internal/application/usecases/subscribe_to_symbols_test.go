@@ -3,13 +3,17 @@ package usecases
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"testing"
 
 	"alarket/internal/domain/entities"
 	"alarket/internal/domain/mocks"
+	"alarket/internal/domain/services"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewSubscribeToSymbolsUseCase(t *testing.T) {
@@ -17,7 +21,7 @@ func TestNewSubscribeToSymbolsUseCase(t *testing.T) {
 	mockExchangeClient := new(mocks.MockExchangeClient)
 	logger := slog.Default()
 	
-	uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, logger)
+	uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, nil, nil, logger)
 	
 	assert.NotNil(t, uc)
 	assert.NotNil(t, uc.symbolRepo)
@@ -49,12 +53,12 @@ func TestSubscribeToSymbolsUseCase_Execute(t *testing.T) {
 			},
 		}
 		
-		mockSymbolRepo.On("GetActiveUsdt", ctx).Return(activeSymbols, nil)
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
 		mockExchangeClient.On("SubscribeToTrades", ctx, []string{"BTCUSDT", "ETHUSDT"}).Return(nil)
 		
-		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, logger)
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, nil, nil, logger)
 		
-		err := uc.Execute(ctx, true, false)
+		err := uc.Execute(ctx, Streams{Trades: true})
 		assert.NoError(t, err)
 		
 		mockSymbolRepo.AssertExpectations(t)
@@ -62,6 +66,110 @@ func TestSubscribeToSymbolsUseCase_Execute(t *testing.T) {
 		mockExchangeClient.AssertNotCalled(t, "SubscribeToBookTickers", mock.Anything, mock.Anything)
 	})
 	
+	t.Run("successful subscription to aggregate trades only", func(t *testing.T) {
+		mockSymbolRepo := new(mocks.MockSymbolRepository)
+		mockExchangeClient := new(mocks.MockExchangeClient)
+
+		activeSymbols := []*entities.Symbol{
+			{
+				Name:       "BTCUSDT",
+				BaseAsset:  "BTC",
+				QuoteAsset: "USDT",
+				Status:     entities.SymbolStatusTrading,
+			},
+		}
+
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
+		mockExchangeClient.On("SubscribeToAggTrades", ctx, []string{"BTCUSDT"}).Return(nil)
+
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, nil, nil, logger)
+
+		err := uc.Execute(ctx, Streams{AggTrades: true})
+		assert.NoError(t, err)
+
+		mockExchangeClient.AssertExpectations(t)
+		mockExchangeClient.AssertNotCalled(t, "SubscribeToTrades", mock.Anything, mock.Anything)
+		mockExchangeClient.AssertNotCalled(t, "SubscribeToBookTickers", mock.Anything, mock.Anything)
+	})
+
+	t.Run("successful subscription to klines only", func(t *testing.T) {
+		mockSymbolRepo := new(mocks.MockSymbolRepository)
+		mockExchangeClient := new(mocks.MockExchangeClient)
+
+		activeSymbols := []*entities.Symbol{
+			{
+				Name:       "BTCUSDT",
+				BaseAsset:  "BTC",
+				QuoteAsset: "USDT",
+				Status:     entities.SymbolStatusTrading,
+			},
+		}
+		intervals := []entities.KlineInterval{"1m", "1h"}
+
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
+		mockExchangeClient.On("SubscribeToKlines", ctx, []string{"BTCUSDT"}, intervals).Return(nil)
+
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, nil, nil, logger)
+
+		err := uc.Execute(ctx, Streams{KlineIntervals: intervals})
+		assert.NoError(t, err)
+
+		mockExchangeClient.AssertExpectations(t)
+		mockExchangeClient.AssertNotCalled(t, "SubscribeToTrades", mock.Anything, mock.Anything)
+	})
+
+	t.Run("successful subscription to order books only", func(t *testing.T) {
+		mockSymbolRepo := new(mocks.MockSymbolRepository)
+		mockExchangeClient := new(mocks.MockExchangeClient)
+
+		activeSymbols := []*entities.Symbol{
+			{
+				Name:       "BTCUSDT",
+				BaseAsset:  "BTC",
+				QuoteAsset: "USDT",
+				Status:     entities.SymbolStatusTrading,
+			},
+		}
+
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
+		mockExchangeClient.On("SubscribeToDepth", ctx, []string{"BTCUSDT"}).Return(nil)
+
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, nil, nil, logger)
+
+		err := uc.Execute(ctx, Streams{OrderBooks: true})
+		assert.NoError(t, err)
+
+		mockExchangeClient.AssertExpectations(t)
+		mockExchangeClient.AssertNotCalled(t, "SubscribeToBookTickers", mock.Anything, mock.Anything)
+	})
+
+	t.Run("successful subscription to mark prices only", func(t *testing.T) {
+		mockSymbolRepo := new(mocks.MockSymbolRepository)
+		mockExchangeClient := new(mocks.MockExchangeClient)
+
+		activeSymbols := []*entities.Symbol{
+			{
+				Name:         "BTCUSDT",
+				BaseAsset:    "BTC",
+				QuoteAsset:   "USDT",
+				Status:       entities.SymbolStatusTrading,
+				Market:       entities.MarketUSDM,
+				ContractType: "PERPETUAL",
+			},
+		}
+
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
+		mockExchangeClient.On("SubscribeToMarkPrices", ctx, []string{"BTCUSDT"}).Return(nil)
+
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, nil, nil, logger)
+
+		err := uc.Execute(ctx, Streams{MarkPrices: true})
+		assert.NoError(t, err)
+
+		mockExchangeClient.AssertExpectations(t)
+		mockExchangeClient.AssertNotCalled(t, "SubscribeToTrades", mock.Anything, mock.Anything)
+	})
+
 	t.Run("successful subscription to book tickers only", func(t *testing.T) {
 		mockSymbolRepo := new(mocks.MockSymbolRepository)
 		mockExchangeClient := new(mocks.MockExchangeClient)
@@ -75,12 +183,12 @@ func TestSubscribeToSymbolsUseCase_Execute(t *testing.T) {
 			},
 		}
 		
-		mockSymbolRepo.On("GetActiveUsdt", ctx).Return(activeSymbols, nil)
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
 		mockExchangeClient.On("SubscribeToBookTickers", ctx, []string{"BTCUSDT"}).Return(nil)
 		
-		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, logger)
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, nil, nil, logger)
 		
-		err := uc.Execute(ctx, false, true)
+		err := uc.Execute(ctx, Streams{BookTickers: true})
 		assert.NoError(t, err)
 		
 		mockSymbolRepo.AssertExpectations(t)
@@ -115,13 +223,13 @@ func TestSubscribeToSymbolsUseCase_Execute(t *testing.T) {
 		
 		expectedSymbols := []string{"BTCUSDT", "ETHUSDT", "BNBUSDT"}
 		
-		mockSymbolRepo.On("GetActiveUsdt", ctx).Return(activeSymbols, nil)
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
 		mockExchangeClient.On("SubscribeToTrades", ctx, expectedSymbols).Return(nil)
 		mockExchangeClient.On("SubscribeToBookTickers", ctx, expectedSymbols).Return(nil)
 		
-		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, logger)
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, nil, nil, logger)
 		
-		err := uc.Execute(ctx, true, true)
+		err := uc.Execute(ctx, Streams{Trades: true, BookTickers: true})
 		assert.NoError(t, err)
 		
 		mockSymbolRepo.AssertExpectations(t)
@@ -141,11 +249,11 @@ func TestSubscribeToSymbolsUseCase_Execute(t *testing.T) {
 			},
 		}
 		
-		mockSymbolRepo.On("GetActiveUsdt", ctx).Return(activeSymbols, nil)
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
 		
-		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, logger)
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, nil, nil, logger)
 		
-		err := uc.Execute(ctx, false, false)
+		err := uc.Execute(ctx, Streams{})
 		assert.NoError(t, err)
 		
 		mockSymbolRepo.AssertExpectations(t)
@@ -158,11 +266,11 @@ func TestSubscribeToSymbolsUseCase_Execute(t *testing.T) {
 		mockExchangeClient := new(mocks.MockExchangeClient)
 		
 		expectedErr := errors.New("database error")
-		mockSymbolRepo.On("GetActiveUsdt", ctx).Return(nil, expectedErr)
+		mockSymbolRepo.On("GetActive", ctx).Return(nil, expectedErr)
 		
-		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, logger)
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, nil, nil, logger)
 		
-		err := uc.Execute(ctx, true, true)
+		err := uc.Execute(ctx, Streams{Trades: true, BookTickers: true})
 		assert.Error(t, err)
 		assert.Equal(t, expectedErr, err)
 		
@@ -185,12 +293,12 @@ func TestSubscribeToSymbolsUseCase_Execute(t *testing.T) {
 		}
 		
 		expectedErr := errors.New("subscription error")
-		mockSymbolRepo.On("GetActiveUsdt", ctx).Return(activeSymbols, nil)
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
 		mockExchangeClient.On("SubscribeToTrades", ctx, []string{"BTCUSDT"}).Return(expectedErr)
 		
-		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, logger)
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, nil, nil, logger)
 		
-		err := uc.Execute(ctx, true, false)
+		err := uc.Execute(ctx, Streams{Trades: true})
 		assert.Error(t, err)
 		assert.Equal(t, expectedErr, err)
 		
@@ -212,12 +320,12 @@ func TestSubscribeToSymbolsUseCase_Execute(t *testing.T) {
 		}
 		
 		expectedErr := errors.New("book ticker subscription error")
-		mockSymbolRepo.On("GetActiveUsdt", ctx).Return(activeSymbols, nil)
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
 		mockExchangeClient.On("SubscribeToBookTickers", ctx, []string{"BTCUSDT"}).Return(expectedErr)
 		
-		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, logger)
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, nil, nil, logger)
 		
-		err := uc.Execute(ctx, false, true)
+		err := uc.Execute(ctx, Streams{BookTickers: true})
 		assert.Error(t, err)
 		assert.Equal(t, expectedErr, err)
 		
@@ -229,16 +337,204 @@ func TestSubscribeToSymbolsUseCase_Execute(t *testing.T) {
 		mockSymbolRepo := new(mocks.MockSymbolRepository)
 		mockExchangeClient := new(mocks.MockExchangeClient)
 		
-		mockSymbolRepo.On("GetActiveUsdt", ctx).Return([]*entities.Symbol{}, nil)
+		mockSymbolRepo.On("GetActive", ctx).Return([]*entities.Symbol{}, nil)
 		mockExchangeClient.On("SubscribeToTrades", ctx, []string{}).Return(nil)
 		mockExchangeClient.On("SubscribeToBookTickers", ctx, []string{}).Return(nil)
 		
-		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, logger)
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, nil, nil, logger)
 		
-		err := uc.Execute(ctx, true, true)
+		err := uc.Execute(ctx, Streams{Trades: true, BookTickers: true})
 		assert.NoError(t, err)
 		
 		mockSymbolRepo.AssertExpectations(t)
 		mockExchangeClient.AssertExpectations(t)
 	})
-}
\ No newline at end of file
+
+	t.Run("streams the exchange does not offer are skipped", func(t *testing.T) {
+		mockSymbolRepo := new(mocks.MockSymbolRepository)
+		mockExchangeClient := new(mocks.MockExchangeClient)
+
+		activeSymbols := []*entities.Symbol{
+			{
+				Name:       "BTCUSDT",
+				BaseAsset:  "BTC",
+				QuoteAsset: "USDT",
+				Status:     entities.SymbolStatusTrading,
+			},
+		}
+
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
+		mockExchangeClient.On("SubscribeToTrades", ctx, []string{"BTCUSDT"}).Return(nil)
+		mockExchangeClient.On("SubscribeToBookTickers", ctx, []string{"BTCUSDT"}).
+			Return(fmt.Errorf("bybit book tickers: %w", services.ErrStreamNotSupported))
+
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, nil, nil, logger)
+
+		err := uc.Execute(ctx, Streams{Trades: true, BookTickers: true})
+		assert.NoError(t, err)
+		assert.Equal(t, StreamSymbols{StreamTrades: {"BTCUSDT"}}, uc.Subscriptions(), "skipped streams are not tracked")
+
+		mockSymbolRepo.AssertExpectations(t)
+		mockExchangeClient.AssertExpectations(t)
+	})
+}
+
+func TestSubscribeToSymbolsUseCase_Selections(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+
+	btc := entities.NewSymbol("BTCUSDT", "BTC", "USDT", entities.SymbolStatusTrading)
+	eth := entities.NewSymbol("ETHUSDT", "ETH", "USDT", entities.SymbolStatusTrading)
+	sol := entities.NewSymbol("SOLUSDT", "SOL", "USDT", entities.SymbolStatusTrading)
+	ethBTC := entities.NewSymbol("ETHBTC", "ETH", "BTC", entities.SymbolStatusTrading)
+	activeSymbols := []*entities.Symbol{btc, eth, sol, ethBTC}
+
+	t.Run("each stream follows its own selection", func(t *testing.T) {
+		mockSymbolRepo := new(mocks.MockSymbolRepository)
+		mockExchangeClient := new(mocks.MockExchangeClient)
+		mockTickers := new(mocks.MockTickerService)
+
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
+		mockTickers.On("FetchQuoteVolumes", ctx).Return(map[string]decimal.Decimal{
+			"BTCUSDT": decimal.NewFromInt(900),
+			"ETHUSDT": decimal.NewFromInt(500),
+			"SOLUSDT": decimal.NewFromInt(700),
+		}, nil)
+		mockExchangeClient.On("SubscribeToTrades", ctx, []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}).Return(nil)
+		mockExchangeClient.On("SubscribeToBookTickers", ctx, []string{"BTCUSDT", "SOLUSDT"}).Return(nil)
+
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, Selections{
+			StreamTrades:      {QuoteAssets: []string{"USDT"}},
+			StreamBookTickers: {QuoteAssets: []string{"USDT"}, TopByVolume: 2},
+		}, mockTickers, logger)
+
+		err := uc.Execute(ctx, Streams{Trades: true, BookTickers: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, StreamSymbols{
+			StreamTrades:      {"BTCUSDT", "ETHUSDT", "SOLUSDT"},
+			StreamBookTickers: {"BTCUSDT", "SOLUSDT"},
+		}, uc.Subscriptions())
+		mockTickers.AssertNumberOfCalls(t, "FetchQuoteVolumes", 1)
+		mockExchangeClient.AssertExpectations(t)
+	})
+
+	t.Run("volumes are not fetched without a top-N", func(t *testing.T) {
+		mockSymbolRepo := new(mocks.MockSymbolRepository)
+		mockTickers := new(mocks.MockTickerService)
+
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
+
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, new(mocks.MockExchangeClient), Selections{
+			StreamTrades: {BaseAssets: []string{"ETH"}},
+		}, mockTickers, logger)
+
+		selected, err := uc.Select(ctx, Streams{Trades: true})
+		require.NoError(t, err)
+		assert.Equal(t, StreamSymbols{StreamTrades: {"ETHUSDT", "ETHBTC"}}, selected)
+		mockTickers.AssertNotCalled(t, "FetchQuoteVolumes", mock.Anything)
+	})
+
+	t.Run("select from given symbols without the repository", func(t *testing.T) {
+		mockSymbolRepo := new(mocks.MockSymbolRepository)
+
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, new(mocks.MockExchangeClient), Selections{
+			StreamTrades: {QuoteAssets: []string{"BTC"}},
+		}, nil, logger)
+
+		selected, err := uc.SelectFrom(ctx, activeSymbols, Streams{Trades: true, AggTrades: true})
+		require.NoError(t, err)
+		assert.Equal(t, StreamSymbols{
+			StreamTrades:    {"ETHBTC"},
+			StreamAggTrades: {"BTCUSDT", "ETHUSDT", "SOLUSDT", "ETHBTC"},
+		}, selected)
+		mockSymbolRepo.AssertNotCalled(t, "GetActive", mock.Anything)
+	})
+
+	t.Run("error fetching volumes", func(t *testing.T) {
+		mockSymbolRepo := new(mocks.MockSymbolRepository)
+		mockTickers := new(mocks.MockTickerService)
+
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
+		mockTickers.On("FetchQuoteVolumes", ctx).Return(nil, errors.New("rate limited"))
+
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, new(mocks.MockExchangeClient), Selections{
+			StreamTrades: {TopByVolume: 1},
+		}, mockTickers, logger)
+
+		_, err := uc.Select(ctx, Streams{Trades: true})
+		assert.ErrorContains(t, err, "failed to fetch 24h volumes")
+	})
+
+	t.Run("new selections apply on the next sync", func(t *testing.T) {
+		mockSymbolRepo := new(mocks.MockSymbolRepository)
+		mockExchangeClient := new(mocks.MockExchangeClient)
+
+		mockSymbolRepo.On("GetActive", ctx).Return(activeSymbols, nil)
+		mockExchangeClient.On("SubscribeToTrades", ctx, []string{"BTCUSDT", "ETHUSDT"}).Return(nil)
+		mockExchangeClient.On("UnsubscribeFromTrades", ctx, []string{"BTCUSDT"}).Return(nil)
+		mockExchangeClient.On("SubscribeToTrades", ctx, []string{"ETHBTC"}).Return(nil)
+
+		uc := NewSubscribeToSymbolsUseCase(mockSymbolRepo, mockExchangeClient, Selections{
+			StreamTrades: {Symbols: []string{"BTCUSDT", "ETHUSDT"}},
+		}, nil, logger)
+		streams := Streams{Trades: true}
+
+		added, removed, err := uc.Sync(ctx, streams)
+		require.NoError(t, err)
+		assert.Equal(t, StreamSymbols{StreamTrades: {"BTCUSDT", "ETHUSDT"}}, added)
+		assert.Empty(t, removed)
+
+		uc.SetSelections(Selections{StreamTrades: {BaseAssets: []string{"ETH"}}})
+		added, removed, err = uc.Sync(ctx, streams)
+		require.NoError(t, err)
+		assert.Equal(t, StreamSymbols{StreamTrades: {"ETHBTC"}}, added)
+		assert.Equal(t, StreamSymbols{StreamTrades: {"BTCUSDT"}}, removed)
+		assert.Equal(t, StreamSymbols{StreamTrades: {"ETHBTC", "ETHUSDT"}}, uc.Subscriptions())
+		mockExchangeClient.AssertExpectations(t)
+	})
+}
+
+// removedOrderBooks records the symbols whose order books were removed.
+type removedOrderBooks struct {
+	symbols []string
+}
+
+func (r *removedOrderBooks) Remove(symbols []string) {
+	r.symbols = append(r.symbols, symbols...)
+}
+
+func TestSubscribeToSymbolsUseCase_Unsubscribe(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+
+	t.Run("drops the order books of unsubscribed depth streams", func(t *testing.T) {
+		mockExchangeClient := new(mocks.MockExchangeClient)
+		mockExchangeClient.On("UnsubscribeFromTrades", ctx, []string{"BTCUSDT"}).Return(nil)
+		mockExchangeClient.On("UnsubscribeFromDepth", ctx, []string{"ETHUSDT"}).Return(nil)
+		orderBooks := &removedOrderBooks{}
+
+		uc := NewSubscribeToSymbolsUseCase(nil, mockExchangeClient, nil, nil, logger)
+		uc.SetOrderBooks(orderBooks)
+
+		err := uc.Unsubscribe(ctx, StreamSymbols{
+			StreamTrades:     {"BTCUSDT"},
+			StreamOrderBooks: {"ETHUSDT"},
+		}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ETHUSDT"}, orderBooks.symbols)
+	})
+
+	t.Run("keeps the order books when the unsubscribe fails", func(t *testing.T) {
+		mockExchangeClient := new(mocks.MockExchangeClient)
+		mockExchangeClient.On("UnsubscribeFromDepth", ctx, []string{"ETHUSDT"}).Return(errors.New("connection lost"))
+		orderBooks := &removedOrderBooks{}
+
+		uc := NewSubscribeToSymbolsUseCase(nil, mockExchangeClient, nil, nil, logger)
+		uc.SetOrderBooks(orderBooks)
+
+		err := uc.Unsubscribe(ctx, StreamSymbols{StreamOrderBooks: {"ETHUSDT"}}, nil)
+		assert.Error(t, err)
+		assert.Empty(t, orderBooks.symbols)
+	})
+}
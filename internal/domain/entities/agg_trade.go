@@ -0,0 +1,72 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// AggTrade is an aggregate trade: consecutive fills of one taker order at the
+// same price, covering the trade IDs FirstTradeID through LastTradeID.
+type AggTrade struct {
+	ID           uint64
+	Symbol       string
+	Price        decimal.Decimal
+	Quantity     decimal.Decimal
+	FirstTradeID uint64
+	LastTradeID  uint64
+	Time         time.Time
+	IsBuyerMaker bool
+	IsBestMatch  bool
+	EventTime    time.Time
+	Source       TradeSource
+	Exchange     Exchange
+	Market       Market
+}
+
+func NewAggTrade(
+	id uint64,
+	symbol string,
+	price decimal.Decimal,
+	quantity decimal.Decimal,
+	firstTradeID uint64,
+	lastTradeID uint64,
+	tradeTime time.Time,
+	isBuyerMaker bool,
+	eventTime time.Time,
+	source TradeSource,
+) *AggTrade {
+	return &AggTrade{
+		ID:           id,
+		Symbol:       symbol,
+		Price:        price,
+		Quantity:     quantity,
+		FirstTradeID: firstTradeID,
+		LastTradeID:  lastTradeID,
+		Time:         tradeTime,
+		IsBuyerMaker: isBuyerMaker,
+		EventTime:    eventTime,
+		Source:       source,
+	}
+}
+
+// TradeCount returns the number of trades the aggregate covers.
+func (a *AggTrade) TradeCount() uint64 {
+	return a.LastTradeID - a.FirstTradeID + 1
+}
+
+func (a *AggTrade) Validate() error {
+	if a.Symbol == "" {
+		return ErrInvalidSymbol
+	}
+	if !a.Price.IsPositive() {
+		return ErrInvalidPrice
+	}
+	if !a.Quantity.IsPositive() {
+		return ErrInvalidQuantity
+	}
+	if a.FirstTradeID > a.LastTradeID {
+		return ErrInvalidTradeRange
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAggTrade(t *testing.T) {
+	now := time.Now()
+	eventTime := now.Add(time.Millisecond * 100)
+
+	aggTrade := NewAggTrade(
+		26129,
+		"BTCUSDT",
+		decimal.RequireFromString("50000.0"),
+		decimal.RequireFromString("0.01"),
+		100,
+		105,
+		now,
+		true,
+		eventTime,
+		TradeSourceLive,
+	)
+
+	assert.NotNil(t, aggTrade)
+	assert.Equal(t, uint64(26129), aggTrade.ID)
+	assert.Equal(t, "BTCUSDT", aggTrade.Symbol)
+	assert.Equal(t, "50000", aggTrade.Price.String())
+	assert.Equal(t, "0.01", aggTrade.Quantity.String())
+	assert.Equal(t, uint64(100), aggTrade.FirstTradeID)
+	assert.Equal(t, uint64(105), aggTrade.LastTradeID)
+	assert.Equal(t, uint64(6), aggTrade.TradeCount())
+	assert.Equal(t, now, aggTrade.Time)
+	assert.True(t, aggTrade.IsBuyerMaker)
+	assert.Equal(t, eventTime, aggTrade.EventTime)
+	assert.Equal(t, TradeSourceLive, aggTrade.Source)
+}
+
+func TestAggTrade_Validate(t *testing.T) {
+	valid := func() *AggTrade {
+		return &AggTrade{
+			ID:           26129,
+			Symbol:       "BTCUSDT",
+			Price:        decimal.RequireFromString("50000.0"),
+			Quantity:     decimal.RequireFromString("0.01"),
+			FirstTradeID: 100,
+			LastTradeID:  100,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(a *AggTrade)
+		wantErr error
+	}{
+		{
+			name:    "valid aggregate trade",
+			modify:  func(a *AggTrade) {},
+			wantErr: nil,
+		},
+		{
+			name:    "empty symbol",
+			modify:  func(a *AggTrade) { a.Symbol = "" },
+			wantErr: ErrInvalidSymbol,
+		},
+		{
+			name:    "zero price",
+			modify:  func(a *AggTrade) { a.Price = decimal.Zero },
+			wantErr: ErrInvalidPrice,
+		},
+		{
+			name:    "negative quantity",
+			modify:  func(a *AggTrade) { a.Quantity = decimal.RequireFromString("-1") },
+			wantErr: ErrInvalidQuantity,
+		},
+		{
+			name:    "inverted trade range",
+			modify:  func(a *AggTrade) { a.FirstTradeID = 101 },
+			wantErr: ErrInvalidTradeRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggTrade := valid()
+			tt.modify(aggTrade)
+
+			err := aggTrade.Validate()
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
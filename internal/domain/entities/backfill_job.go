@@ -0,0 +1,181 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+)
+
+// BackfillDirection is the way a backfill job walks through trade IDs.
+type BackfillDirection string
+
+const (
+	// BackfillBackward walks from the oldest stored trade back to the start
+	// of the range.
+	BackfillBackward BackfillDirection = "backward"
+	// BackfillForward walks from the newest stored trade up to the end of the
+	// range.
+	BackfillForward BackfillDirection = "forward"
+)
+
+type BackfillJobStatus string
+
+const (
+	BackfillJobPending BackfillJobStatus = "pending"
+	// BackfillJobRunning is not stored: it is a pending job whose lease a
+	// runner holds. Older versions stored it for jobs they had started.
+	BackfillJobRunning   BackfillJobStatus = "running"
+	BackfillJobPaused    BackfillJobStatus = "paused"
+	BackfillJobCompleted BackfillJobStatus = "completed"
+	BackfillJobFailed    BackfillJobStatus = "failed"
+	BackfillJobCancelled BackfillJobStatus = "cancelled"
+)
+
+// BackfillJob is a queued backfill of the trades of one symbol. Cursor is
+// the trade ID the job has reached: the oldest trade fetched when walking
+// backward, the newest when walking forward, 0 before the first batch. A job
+// left behind by a crash continues from it.
+//
+// The status and the progress are stored apart: pause, resume and cancel
+// change the status, the runner holding the lease saves the cursor, the
+// fetched count and the lease. Neither overwrites what the other saved.
+type BackfillJob struct {
+	ID         string
+	Symbol     string
+	Direction  BackfillDirection
+	From       time.Time // backward jobs stop once they pass From
+	To         time.Time // forward jobs stop once they pass To
+	Cursor     uint64
+	Fetched    uint64 // trades fetched so far
+	Status     BackfillJobStatus
+	Error      string    // why the job failed
+	Owner      string    // runner that leased the job last
+	LeaseUntil time.Time // no other runner takes the job over before then
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func NewBackfillJob(id, symbol string, direction BackfillDirection, from, to, now time.Time) *BackfillJob {
+	return &BackfillJob{
+		ID:        id,
+		Symbol:    symbol,
+		Direction: direction,
+		From:      from,
+		To:        to,
+		Status:    BackfillJobPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func (j *BackfillJob) Validate() error {
+	if j.Symbol == "" {
+		return ErrInvalidSymbol
+	}
+	if j.Direction != BackfillBackward && j.Direction != BackfillForward {
+		return ErrInvalidDirection
+	}
+	if !j.From.Before(j.To) {
+		return ErrInvalidTimeRange
+	}
+	return nil
+}
+
+// Covers reports whether t lies in the range of the job, [From, To).
+func (j *BackfillJob) Covers(t time.Time) bool {
+	return !t.Before(j.From) && t.Before(j.To)
+}
+
+// IsRunnable reports whether a runner should pick the job up, unless another
+// runner holds its lease. Jobs an older version left running are runnable
+// too: they were interrupted and continue from their cursor.
+func (j *BackfillJob) IsRunnable() bool {
+	return j.Status == BackfillJobPending || j.Status == BackfillJobRunning
+}
+
+// IsLeased reports whether a runner holds the lease of the job at now.
+func (j *BackfillJob) IsLeased(now time.Time) bool {
+	return j.Owner != "" && now.Before(j.LeaseUntil)
+}
+
+// State returns the status to show at now: running while a runner holds the
+// lease of a runnable job.
+func (j *BackfillJob) State(now time.Time) BackfillJobStatus {
+	switch {
+	case j.IsRunnable() && j.IsLeased(now):
+		return BackfillJobRunning
+	case j.IsRunnable():
+		return BackfillJobPending
+	default:
+		return j.Status
+	}
+}
+
+// IsFinished reports whether the job will never run again.
+func (j *BackfillJob) IsFinished() bool {
+	return j.Status == BackfillJobCompleted || j.Status == BackfillJobCancelled
+}
+
+// Lease gives owner the job until until. Runners renew it with every batch.
+func (j *BackfillJob) Lease(owner string, until, now time.Time) {
+	j.Owner = owner
+	j.LeaseUntil = until
+	j.UpdatedAt = now
+}
+
+// Release ends the lease, another runner can take the job right away.
+func (j *BackfillJob) Release(now time.Time) {
+	j.LeaseUntil = time.Time{}
+	j.UpdatedAt = now
+}
+
+// Advance moves the cursor to the trade ID reached by fetching count more
+// trades.
+func (j *BackfillJob) Advance(cursor uint64, count int, now time.Time) {
+	j.Cursor = cursor
+	j.Fetched += uint64(count)
+	j.UpdatedAt = now
+}
+
+// Complete marks the job as done.
+func (j *BackfillJob) Complete(now time.Time) {
+	j.setStatus(BackfillJobCompleted, now)
+}
+
+// Fail marks the job as failed with err. It can be resumed.
+func (j *BackfillJob) Fail(err error, now time.Time) {
+	j.Error = err.Error()
+	j.setStatus(BackfillJobFailed, now)
+}
+
+// Pause stops a pending or running job until it is resumed.
+func (j *BackfillJob) Pause(now time.Time) error {
+	if !j.IsRunnable() {
+		return fmt.Errorf("%w: cannot pause a %s job", ErrInvalidJobStatus, j.Status)
+	}
+	j.setStatus(BackfillJobPaused, now)
+	return nil
+}
+
+// Resume queues a paused or failed job again. It continues from its cursor.
+func (j *BackfillJob) Resume(now time.Time) error {
+	if j.Status != BackfillJobPaused && j.Status != BackfillJobFailed {
+		return fmt.Errorf("%w: cannot resume a %s job", ErrInvalidJobStatus, j.Status)
+	}
+	j.Error = ""
+	j.setStatus(BackfillJobPending, now)
+	return nil
+}
+
+// Cancel stops a job for good. What it fetched is kept.
+func (j *BackfillJob) Cancel(now time.Time) error {
+	if j.IsFinished() {
+		return fmt.Errorf("%w: cannot cancel a %s job", ErrInvalidJobStatus, j.Status)
+	}
+	j.setStatus(BackfillJobCancelled, now)
+	return nil
+}
+
+func (j *BackfillJob) setStatus(status BackfillJobStatus, now time.Time) {
+	j.Status = status
+	j.UpdatedAt = now
+}
@@ -0,0 +1,161 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBackfillJob(t *testing.T) {
+	now := time.Now()
+	from := now.AddDate(0, 0, -7)
+
+	job := NewBackfillJob("job1", "BTCUSDT", BackfillBackward, from, now, now)
+
+	assert.Equal(t, "job1", job.ID)
+	assert.Equal(t, "BTCUSDT", job.Symbol)
+	assert.Equal(t, BackfillBackward, job.Direction)
+	assert.Equal(t, from, job.From)
+	assert.Equal(t, now, job.To)
+	assert.Equal(t, BackfillJobPending, job.Status)
+	assert.Zero(t, job.Cursor)
+	assert.Equal(t, now, job.CreatedAt)
+	assert.Equal(t, now, job.UpdatedAt)
+}
+
+func TestBackfillJob_Validate(t *testing.T) {
+	now := time.Now()
+	from := now.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name    string
+		job     *BackfillJob
+		wantErr error
+	}{
+		{
+			name: "valid backward job",
+			job:  NewBackfillJob("job1", "BTCUSDT", BackfillBackward, from, now, now),
+		},
+		{
+			name: "valid forward job",
+			job:  NewBackfillJob("job1", "BTCUSDT", BackfillForward, from, now, now),
+		},
+		{
+			name:    "empty symbol",
+			job:     NewBackfillJob("job1", "", BackfillBackward, from, now, now),
+			wantErr: ErrInvalidSymbol,
+		},
+		{
+			name:    "unknown direction",
+			job:     NewBackfillJob("job1", "BTCUSDT", "sideways", from, now, now),
+			wantErr: ErrInvalidDirection,
+		},
+		{
+			name:    "empty range",
+			job:     NewBackfillJob("job1", "BTCUSDT", BackfillBackward, now, now, now),
+			wantErr: ErrInvalidTimeRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.job.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBackfillJob_StatusChanges(t *testing.T) {
+	created := time.Now()
+	later := created.Add(time.Minute)
+
+	newJob := func(status BackfillJobStatus) *BackfillJob {
+		job := NewBackfillJob("job1", "BTCUSDT", BackfillBackward, created.AddDate(0, 0, -1), created, created)
+		job.Status = status
+		return job
+	}
+
+	t.Run("run a job", func(t *testing.T) {
+		job := newJob(BackfillJobPending)
+		job.Lease("runner1", later.Add(time.Minute), later)
+		assert.Equal(t, BackfillJobPending, job.Status, "the lease leaves the status as it is")
+		assert.Equal(t, BackfillJobRunning, job.State(later))
+
+		job.Advance(5000, 1000, later)
+		job.Advance(4000, 1000, later)
+		assert.Equal(t, uint64(4000), job.Cursor)
+		assert.Equal(t, uint64(2000), job.Fetched)
+
+		job.Fail(errors.New("boom"), later)
+		assert.Equal(t, BackfillJobFailed, job.Status)
+		assert.Equal(t, "boom", job.Error)
+		assert.False(t, job.IsRunnable())
+
+		job.Complete(later)
+		assert.True(t, job.IsFinished())
+	})
+
+	t.Run("lease", func(t *testing.T) {
+		job := newJob(BackfillJobPending)
+		assert.False(t, job.IsLeased(later))
+		assert.Equal(t, BackfillJobPending, job.State(later))
+
+		job.Lease("runner1", later.Add(time.Minute), later)
+		assert.True(t, job.IsLeased(later))
+		assert.False(t, job.IsLeased(later.Add(time.Minute)), "the lease expires")
+
+		job.Release(later)
+		assert.False(t, job.IsLeased(later))
+		assert.Equal(t, "runner1", job.Owner)
+
+		require.NoError(t, job.Pause(later))
+		job.Lease("runner1", later.Add(time.Minute), later)
+		assert.Equal(t, BackfillJobPaused, job.State(later))
+		assert.Equal(t, BackfillJobPending, newJob(BackfillJobRunning).State(later), "left running by an older version")
+	})
+
+	t.Run("pause a running job", func(t *testing.T) {
+		job := newJob(BackfillJobRunning)
+		require.NoError(t, job.Pause(later))
+		assert.Equal(t, BackfillJobPaused, job.Status)
+		assert.Equal(t, later, job.UpdatedAt)
+	})
+
+	t.Run("resume a failed job clears its error", func(t *testing.T) {
+		job := newJob(BackfillJobFailed)
+		job.Error = "boom"
+		job.Cursor = 42
+		require.NoError(t, job.Resume(later))
+		assert.Equal(t, BackfillJobPending, job.Status)
+		assert.Empty(t, job.Error)
+		assert.Equal(t, uint64(42), job.Cursor, "resuming keeps the cursor")
+	})
+
+	t.Run("cancel a paused job", func(t *testing.T) {
+		job := newJob(BackfillJobPaused)
+		require.NoError(t, job.Cancel(later))
+		assert.Equal(t, BackfillJobCancelled, job.Status)
+		assert.True(t, job.IsFinished())
+	})
+
+	t.Run("invalid changes", func(t *testing.T) {
+		assert.ErrorIs(t, newJob(BackfillJobCompleted).Pause(later), ErrInvalidJobStatus)
+		assert.ErrorIs(t, newJob(BackfillJobRunning).Resume(later), ErrInvalidJobStatus)
+		assert.ErrorIs(t, newJob(BackfillJobCancelled).Cancel(later), ErrInvalidJobStatus)
+		assert.ErrorIs(t, newJob(BackfillJobCompleted).Cancel(later), ErrInvalidJobStatus)
+	})
+
+	t.Run("runnable jobs", func(t *testing.T) {
+		assert.True(t, newJob(BackfillJobPending).IsRunnable())
+		assert.True(t, newJob(BackfillJobRunning).IsRunnable())
+		assert.False(t, newJob(BackfillJobPaused).IsRunnable())
+		assert.False(t, newJob(BackfillJobFailed).IsRunnable())
+	})
+}
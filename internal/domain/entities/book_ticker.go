@@ -13,6 +13,8 @@ type BookTicker struct {
 	BestAskQuantity float64
 	TransactionTime time.Time
 	EventTime       time.Time
+	Exchange        Exchange
+	Market          Market
 }
 
 func NewBookTicker(
@@ -3,9 +3,18 @@ package entities
 import "errors"
 
 var (
-	ErrInvalidSymbol   = errors.New("invalid symbol")
-	ErrInvalidPrice    = errors.New("invalid price")
-	ErrInvalidQuantity = errors.New("invalid quantity")
-	ErrInvalidSpread   = errors.New("invalid spread: bid price cannot be higher than ask price")
-	ErrInvalidAsset    = errors.New("invalid asset")
+	ErrInvalidSymbol     = errors.New("invalid symbol")
+	ErrInvalidPrice      = errors.New("invalid price")
+	ErrInvalidQuantity   = errors.New("invalid quantity")
+	ErrInvalidSpread     = errors.New("invalid spread: bid price cannot be higher than ask price")
+	ErrInvalidAsset      = errors.New("invalid asset")
+	ErrInvalidTradeRange = errors.New("invalid trade range: from ID cannot be greater than to ID")
+	ErrInvalidInterval   = errors.New("invalid kline interval")
+	ErrInvalidKline      = errors.New("invalid kline: high cannot be lower than low")
+	ErrOrderBookGap      = errors.New("order book sequence gap")
+	ErrInvalidMarket     = errors.New("invalid market")
+	ErrInvalidExchange   = errors.New("invalid exchange")
+	ErrInvalidTimeRange  = errors.New("invalid time range: from must be before to")
+	ErrInvalidDirection  = errors.New("invalid backfill direction")
+	ErrInvalidJobStatus  = errors.New("invalid backfill job status change")
 )
@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Exchange names the venue market data was collected from.
+type Exchange string
+
+const (
+	ExchangeBinance Exchange = "binance"
+	ExchangeBybit   Exchange = "bybit"
+)
+
+func ParseExchange(value string) (Exchange, error) {
+	switch exchange := Exchange(strings.ToLower(strings.TrimSpace(value))); exchange {
+	case ExchangeBinance, ExchangeBybit:
+		return exchange, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidExchange, value)
+}
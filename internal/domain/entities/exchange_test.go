@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExchange(t *testing.T) {
+	for value, want := range map[string]Exchange{"binance": ExchangeBinance, " Bybit ": ExchangeBybit} {
+		exchange, err := ParseExchange(value)
+		require.NoError(t, err)
+		assert.Equal(t, want, exchange)
+	}
+
+	for _, value := range []string{"", "binance-usdm", "kraken"} {
+		_, err := ParseExchange(value)
+		assert.True(t, errors.Is(err, ErrInvalidExchange), value)
+	}
+}
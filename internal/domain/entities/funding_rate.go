@@ -0,0 +1,45 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FundingRate is a settled perpetual futures funding payment, keyed by the
+// time it was applied. The rate may be negative.
+type FundingRate struct {
+	Symbol      string
+	FundingTime time.Time
+	Rate        decimal.Decimal
+	MarkPrice   decimal.Decimal // zero when the source does not provide it
+	Source      TradeSource
+	Exchange    Exchange
+	Market      Market
+}
+
+func NewFundingRate(
+	symbol string,
+	fundingTime time.Time,
+	rate decimal.Decimal,
+	markPrice decimal.Decimal,
+	source TradeSource,
+) *FundingRate {
+	return &FundingRate{
+		Symbol:      symbol,
+		FundingTime: fundingTime,
+		Rate:        rate,
+		MarkPrice:   markPrice,
+		Source:      source,
+	}
+}
+
+func (f *FundingRate) Validate() error {
+	if f.Symbol == "" {
+		return ErrInvalidSymbol
+	}
+	if f.MarkPrice.IsNegative() {
+		return ErrInvalidPrice
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFundingRate(t *testing.T) {
+	rate := NewFundingRate(
+		"BTCUSDT",
+		time.UnixMilli(1700006400000),
+		decimal.RequireFromString("-0.00012"),
+		decimal.RequireFromString("50010.5"),
+		TradeSourceREST,
+	)
+
+	assert.Equal(t, "BTCUSDT", rate.Symbol)
+	assert.Equal(t, time.UnixMilli(1700006400000), rate.FundingTime)
+	assert.Equal(t, "-0.00012", rate.Rate.String())
+	assert.Equal(t, "50010.5", rate.MarkPrice.String())
+	assert.Equal(t, TradeSourceREST, rate.Source)
+	require.NoError(t, rate.Validate())
+}
+
+func TestFundingRate_Validate(t *testing.T) {
+	rate := NewFundingRate("", time.UnixMilli(1700006400000), decimal.Zero, decimal.Zero, TradeSourceREST)
+	assert.Equal(t, ErrInvalidSymbol, rate.Validate())
+
+	rate.Symbol = "BTCUSDT"
+	rate.MarkPrice = decimal.RequireFromString("-1")
+	assert.Equal(t, ErrInvalidPrice, rate.Validate())
+}
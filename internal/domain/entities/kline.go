@@ -0,0 +1,94 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// KlineInterval is a Binance candlestick interval such as 1m or 4h.
+type KlineInterval string
+
+var klineIntervals = map[KlineInterval]bool{
+	"1s": true, "1m": true, "3m": true, "5m": true, "15m": true, "30m": true,
+	"1h": true, "2h": true, "4h": true, "6h": true, "8h": true, "12h": true,
+	"1d": true, "3d": true, "1w": true, "1M": true,
+}
+
+func ParseKlineInterval(value string) (KlineInterval, error) {
+	interval := KlineInterval(value)
+	if !klineIntervals[interval] {
+		return "", fmt.Errorf("%w: %q", ErrInvalidInterval, value)
+	}
+	return interval, nil
+}
+
+// Kline is an OHLCV candle for one symbol and interval, keyed by its open time.
+type Kline struct {
+	Symbol              string
+	Interval            KlineInterval
+	OpenTime            time.Time
+	CloseTime           time.Time
+	Open                decimal.Decimal
+	High                decimal.Decimal
+	Low                 decimal.Decimal
+	Close               decimal.Decimal
+	Volume              decimal.Decimal
+	QuoteVolume         decimal.Decimal
+	TakerBuyVolume      decimal.Decimal
+	TakerBuyQuoteVolume decimal.Decimal
+	TradeCount          uint64
+	IsClosed            bool
+	EventTime           time.Time
+	Source              TradeSource
+	Exchange            Exchange
+	Market              Market
+}
+
+func NewKline(
+	symbol string,
+	interval KlineInterval,
+	openTime time.Time,
+	closeTime time.Time,
+	open decimal.Decimal,
+	high decimal.Decimal,
+	low decimal.Decimal,
+	closePrice decimal.Decimal,
+	volume decimal.Decimal,
+	eventTime time.Time,
+	source TradeSource,
+) *Kline {
+	return &Kline{
+		Symbol:    symbol,
+		Interval:  interval,
+		OpenTime:  openTime,
+		CloseTime: closeTime,
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     closePrice,
+		Volume:    volume,
+		EventTime: eventTime,
+		Source:    source,
+	}
+}
+
+func (k *Kline) Validate() error {
+	if k.Symbol == "" {
+		return ErrInvalidSymbol
+	}
+	if !klineIntervals[k.Interval] {
+		return ErrInvalidInterval
+	}
+	if !k.Open.IsPositive() || !k.High.IsPositive() || !k.Low.IsPositive() || !k.Close.IsPositive() {
+		return ErrInvalidPrice
+	}
+	if k.Volume.IsNegative() {
+		return ErrInvalidQuantity
+	}
+	if k.High.LessThan(k.Low) {
+		return ErrInvalidKline
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKline() *Kline {
+	openTime := time.UnixMilli(1700000000000)
+	return NewKline(
+		"BTCUSDT",
+		"1m",
+		openTime,
+		openTime.Add(time.Minute-time.Millisecond),
+		decimal.RequireFromString("50000"),
+		decimal.RequireFromString("50100"),
+		decimal.RequireFromString("49900"),
+		decimal.RequireFromString("50050"),
+		decimal.RequireFromString("12.5"),
+		openTime.Add(time.Minute),
+		TradeSourceLive,
+	)
+}
+
+func TestNewKline(t *testing.T) {
+	kline := testKline()
+
+	assert.Equal(t, "BTCUSDT", kline.Symbol)
+	assert.Equal(t, KlineInterval("1m"), kline.Interval)
+	assert.Equal(t, time.UnixMilli(1700000000000), kline.OpenTime)
+	assert.Equal(t, time.UnixMilli(1700000059999), kline.CloseTime)
+	assert.Equal(t, "50000", kline.Open.String())
+	assert.Equal(t, "50100", kline.High.String())
+	assert.Equal(t, "49900", kline.Low.String())
+	assert.Equal(t, "50050", kline.Close.String())
+	assert.Equal(t, "12.5", kline.Volume.String())
+	assert.Equal(t, TradeSourceLive, kline.Source)
+	assert.False(t, kline.IsClosed)
+}
+
+func TestParseKlineInterval(t *testing.T) {
+	for _, value := range []string{"1s", "1m", "15m", "1h", "4h", "1d", "1w", "1M"} {
+		interval, err := ParseKlineInterval(value)
+		require.NoError(t, err)
+		assert.Equal(t, KlineInterval(value), interval)
+	}
+
+	for _, value := range []string{"", "2m", "1H", "1y"} {
+		_, err := ParseKlineInterval(value)
+		assert.True(t, errors.Is(err, ErrInvalidInterval), value)
+	}
+}
+
+func TestKline_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(k *Kline)
+		wantErr error
+	}{
+		{
+			name:    "valid kline",
+			modify:  func(k *Kline) {},
+			wantErr: nil,
+		},
+		{
+			name:    "empty symbol",
+			modify:  func(k *Kline) { k.Symbol = "" },
+			wantErr: ErrInvalidSymbol,
+		},
+		{
+			name:    "unknown interval",
+			modify:  func(k *Kline) { k.Interval = "7m" },
+			wantErr: ErrInvalidInterval,
+		},
+		{
+			name:    "zero low",
+			modify:  func(k *Kline) { k.Low = decimal.Zero },
+			wantErr: ErrInvalidPrice,
+		},
+		{
+			name:    "negative volume",
+			modify:  func(k *Kline) { k.Volume = decimal.RequireFromString("-1") },
+			wantErr: ErrInvalidQuantity,
+		},
+		{
+			name:    "zero volume",
+			modify:  func(k *Kline) { k.Volume = decimal.Zero },
+			wantErr: nil,
+		},
+		{
+			name:    "high below low",
+			modify:  func(k *Kline) { k.High = decimal.RequireFromString("49000") },
+			wantErr: ErrInvalidKline,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kline := testKline()
+			tt.modify(kline)
+
+			err := kline.Validate()
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MarkPrice is a futures mark price update together with the funding rate the
+// next settlement would apply.
+type MarkPrice struct {
+	Symbol               string
+	MarkPrice            decimal.Decimal
+	IndexPrice           decimal.Decimal
+	EstimatedSettlePrice decimal.Decimal
+	FundingRate          decimal.Decimal // zero for delivery contracts
+	NextFundingTime      time.Time       // zero for delivery contracts
+	EventTime            time.Time
+	Exchange             Exchange
+	Market               Market
+}
+
+func NewMarkPrice(
+	symbol string,
+	markPrice decimal.Decimal,
+	indexPrice decimal.Decimal,
+	estimatedSettlePrice decimal.Decimal,
+	fundingRate decimal.Decimal,
+	nextFundingTime time.Time,
+	eventTime time.Time,
+) *MarkPrice {
+	return &MarkPrice{
+		Symbol:               symbol,
+		MarkPrice:            markPrice,
+		IndexPrice:           indexPrice,
+		EstimatedSettlePrice: estimatedSettlePrice,
+		FundingRate:          fundingRate,
+		NextFundingTime:      nextFundingTime,
+		EventTime:            eventTime,
+	}
+}
+
+func (m *MarkPrice) Validate() error {
+	if m.Symbol == "" {
+		return ErrInvalidSymbol
+	}
+	if !m.MarkPrice.IsPositive() || m.IndexPrice.IsNegative() || m.EstimatedSettlePrice.IsNegative() {
+		return ErrInvalidPrice
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMarkPrice() *MarkPrice {
+	return NewMarkPrice(
+		"BTCUSDT",
+		decimal.RequireFromString("50010.5"),
+		decimal.RequireFromString("50000.1"),
+		decimal.RequireFromString("50005.3"),
+		decimal.RequireFromString("0.0001"),
+		time.UnixMilli(1700006400000),
+		time.UnixMilli(1700000000000),
+	)
+}
+
+func TestNewMarkPrice(t *testing.T) {
+	markPrice := testMarkPrice()
+
+	assert.Equal(t, "BTCUSDT", markPrice.Symbol)
+	assert.Equal(t, "50010.5", markPrice.MarkPrice.String())
+	assert.Equal(t, "50000.1", markPrice.IndexPrice.String())
+	assert.Equal(t, "50005.3", markPrice.EstimatedSettlePrice.String())
+	assert.Equal(t, "0.0001", markPrice.FundingRate.String())
+	assert.Equal(t, time.UnixMilli(1700006400000), markPrice.NextFundingTime)
+	assert.Equal(t, time.UnixMilli(1700000000000), markPrice.EventTime)
+}
+
+func TestMarkPrice_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(m *MarkPrice)
+		wantErr error
+	}{
+		{
+			name:    "valid mark price",
+			modify:  func(m *MarkPrice) {},
+			wantErr: nil,
+		},
+		{
+			name:    "negative funding rate",
+			modify:  func(m *MarkPrice) { m.FundingRate = decimal.RequireFromString("-0.0003") },
+			wantErr: nil,
+		},
+		{
+			name:    "empty symbol",
+			modify:  func(m *MarkPrice) { m.Symbol = "" },
+			wantErr: ErrInvalidSymbol,
+		},
+		{
+			name:    "zero mark price",
+			modify:  func(m *MarkPrice) { m.MarkPrice = decimal.Zero },
+			wantErr: ErrInvalidPrice,
+		},
+		{
+			name:    "negative index price",
+			modify:  func(m *MarkPrice) { m.IndexPrice = decimal.RequireFromString("-1") },
+			wantErr: ErrInvalidPrice,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markPrice := testMarkPrice()
+			tt.modify(markPrice)
+
+			err := markPrice.Validate()
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
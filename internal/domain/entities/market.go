@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Market names the product family a symbol trades in on its exchange.
+type Market string
+
+const (
+	MarketSpot  Market = "spot"
+	MarketUSDM  Market = "usdm"  // USDⓈ-margined perpetual and delivery futures
+	MarketCoinM Market = "coinm" // coin-margined perpetual and delivery futures
+)
+
+func ParseMarket(value string) (Market, error) {
+	switch market := Market(strings.ToLower(strings.TrimSpace(value))); market {
+	case MarketSpot, MarketUSDM, MarketCoinM:
+		return market, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidMarket, value)
+}
+
+// IsFutures reports whether the market trades derivatives contracts.
+func (m Market) IsFutures() bool {
+	return m == MarketUSDM || m == MarketCoinM
+}
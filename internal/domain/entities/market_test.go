@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMarket(t *testing.T) {
+	for value, want := range map[string]Market{"spot": MarketSpot, "USDM": MarketUSDM, " coinm ": MarketCoinM} {
+		market, err := ParseMarket(value)
+		require.NoError(t, err)
+		assert.Equal(t, want, market)
+	}
+
+	for _, value := range []string{"", "futures", "usd-m"} {
+		_, err := ParseMarket(value)
+		assert.True(t, errors.Is(err, ErrInvalidMarket), value)
+	}
+}
+
+func TestMarket_IsFutures(t *testing.T) {
+	assert.False(t, MarketSpot.IsFutures())
+	assert.True(t, MarketUSDM.IsFutures())
+	assert.True(t, MarketCoinM.IsFutures())
+}
@@ -0,0 +1,139 @@
+package entities
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PriceLevel is the total quantity resting at one price.
+type PriceLevel struct {
+	Price    decimal.Decimal
+	Quantity decimal.Decimal
+}
+
+// DepthUpdate is one diff from the depth stream. It carries every change with
+// update IDs FirstUpdateID through FinalUpdateID; a zero quantity removes the
+// level.
+type DepthUpdate struct {
+	Symbol        string
+	FirstUpdateID uint64
+	FinalUpdateID uint64
+	Bids          []PriceLevel
+	Asks          []PriceLevel
+	EventTime     time.Time
+}
+
+func NewDepthUpdate(symbol string, firstUpdateID, finalUpdateID uint64, bids, asks []PriceLevel, eventTime time.Time) *DepthUpdate {
+	return &DepthUpdate{
+		Symbol:        symbol,
+		FirstUpdateID: firstUpdateID,
+		FinalUpdateID: finalUpdateID,
+		Bids:          bids,
+		Asks:          asks,
+		EventTime:     eventTime,
+	}
+}
+
+// OrderBook is a local copy of the book for one symbol, seeded from a REST
+// snapshot and kept current by applying depth updates in sequence.
+type OrderBook struct {
+	Symbol       string
+	LastUpdateID uint64
+	UpdatedAt    time.Time
+
+	// Levels are keyed by the normalized price string
+	bids map[string]PriceLevel
+	asks map[string]PriceLevel
+}
+
+func NewOrderBook(symbol string, lastUpdateID uint64, bids, asks []PriceLevel, updatedAt time.Time) *OrderBook {
+	book := &OrderBook{
+		Symbol:       symbol,
+		LastUpdateID: lastUpdateID,
+		UpdatedAt:    updatedAt,
+		bids:         make(map[string]PriceLevel, len(bids)),
+		asks:         make(map[string]PriceLevel, len(asks)),
+	}
+	setLevels(book.bids, bids)
+	setLevels(book.asks, asks)
+	return book
+}
+
+// Apply applies a depth update following the Binance sequencing rules.
+// Updates the book already contains are ignored. An update that starts after
+// LastUpdateID+1 means diffs were missed; it returns ErrOrderBookGap and leaves
+// the book untouched, and the book has to be rebuilt from a new snapshot.
+func (b *OrderBook) Apply(update *DepthUpdate) error {
+	if update.FinalUpdateID <= b.LastUpdateID {
+		return nil
+	}
+	if update.FirstUpdateID > b.LastUpdateID+1 {
+		return fmt.Errorf("%w: %s expected update %d, got %d-%d",
+			ErrOrderBookGap, b.Symbol, b.LastUpdateID+1, update.FirstUpdateID, update.FinalUpdateID)
+	}
+
+	setLevels(b.bids, update.Bids)
+	setLevels(b.asks, update.Asks)
+	b.LastUpdateID = update.FinalUpdateID
+	b.UpdatedAt = update.EventTime
+	return nil
+}
+
+// Bids returns the best depth bids, highest price first. depth <= 0 returns
+// every level.
+func (b *OrderBook) Bids(depth int) []PriceLevel {
+	return topLevels(b.bids, depth, func(x, y decimal.Decimal) bool { return x.GreaterThan(y) })
+}
+
+// Asks returns the best depth asks, lowest price first. depth <= 0 returns
+// every level.
+func (b *OrderBook) Asks(depth int) []PriceLevel {
+	return topLevels(b.asks, depth, func(x, y decimal.Decimal) bool { return x.LessThan(y) })
+}
+
+// Snapshot captures the best depth levels on each side.
+func (b *OrderBook) Snapshot(depth int, snapshotTime time.Time) *OrderBookSnapshot {
+	return &OrderBookSnapshot{
+		Symbol:       b.Symbol,
+		LastUpdateID: b.LastUpdateID,
+		Bids:         b.Bids(depth),
+		Asks:         b.Asks(depth),
+		SnapshotTime: snapshotTime,
+	}
+}
+
+func setLevels(side map[string]PriceLevel, levels []PriceLevel) {
+	for _, level := range levels {
+		key := level.Price.String()
+		if level.Quantity.IsZero() {
+			delete(side, key)
+			continue
+		}
+		side[key] = level
+	}
+}
+
+func topLevels(side map[string]PriceLevel, depth int, better func(x, y decimal.Decimal) bool) []PriceLevel {
+	levels := make([]PriceLevel, 0, len(side))
+	for _, level := range side {
+		levels = append(levels, level)
+	}
+	sort.Slice(levels, func(i, j int) bool { return better(levels[i].Price, levels[j].Price) })
+
+	if depth > 0 && len(levels) > depth {
+		levels = levels[:depth]
+	}
+	return levels
+}
+
+// OrderBookSnapshot is the top of an order book at a point in time.
+type OrderBookSnapshot struct {
+	Symbol       string
+	LastUpdateID uint64
+	Bids         []PriceLevel
+	Asks         []PriceLevel
+	SnapshotTime time.Time
+}
@@ -0,0 +1,91 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func level(price, quantity string) PriceLevel {
+	return PriceLevel{Price: decimal.RequireFromString(price), Quantity: decimal.RequireFromString(quantity)}
+}
+
+func levelStrings(levels []PriceLevel) []string {
+	result := make([]string, 0, len(levels))
+	for _, l := range levels {
+		result = append(result, l.Price.String()+"@"+l.Quantity.String())
+	}
+	return result
+}
+
+func testOrderBook() *OrderBook {
+	return NewOrderBook("BTCUSDT", 100,
+		[]PriceLevel{level("99.5", "1"), level("100", "2"), level("99", "3")},
+		[]PriceLevel{level("101", "1"), level("100.5", "2"), level("102", "3")},
+		time.UnixMilli(1700000000000))
+}
+
+func TestOrderBook_Levels(t *testing.T) {
+	book := testOrderBook()
+
+	assert.Equal(t, []string{"100@2", "99.5@1", "99@3"}, levelStrings(book.Bids(0)))
+	assert.Equal(t, []string{"100.5@2", "101@1", "102@3"}, levelStrings(book.Asks(0)))
+	assert.Equal(t, []string{"100@2", "99.5@1"}, levelStrings(book.Bids(2)))
+	assert.Equal(t, []string{"100.5@2"}, levelStrings(book.Asks(1)))
+}
+
+func TestOrderBook_Apply(t *testing.T) {
+	eventTime := time.UnixMilli(1700000001000)
+
+	t.Run("updates, adds and removes levels", func(t *testing.T) {
+		book := testOrderBook()
+
+		// Trailing zeros name the same level
+		err := book.Apply(NewDepthUpdate("BTCUSDT", 95, 101,
+			[]PriceLevel{level("100.00", "5"), level("99", "0")},
+			[]PriceLevel{level("100.25", "4")},
+			eventTime))
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(101), book.LastUpdateID)
+		assert.Equal(t, eventTime, book.UpdatedAt)
+		assert.Equal(t, []string{"100@5", "99.5@1"}, levelStrings(book.Bids(0)))
+		assert.Equal(t, []string{"100.25@4", "100.5@2", "101@1", "102@3"}, levelStrings(book.Asks(0)))
+	})
+
+	t.Run("ignores updates already in the book", func(t *testing.T) {
+		book := testOrderBook()
+
+		err := book.Apply(NewDepthUpdate("BTCUSDT", 90, 100, []PriceLevel{level("100", "0")}, nil, eventTime))
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(100), book.LastUpdateID)
+		assert.Equal(t, []string{"100@2", "99.5@1", "99@3"}, levelStrings(book.Bids(0)))
+	})
+
+	t.Run("rejects a gap", func(t *testing.T) {
+		book := testOrderBook()
+
+		err := book.Apply(NewDepthUpdate("BTCUSDT", 102, 105, []PriceLevel{level("100", "0")}, nil, eventTime))
+		assert.ErrorIs(t, err, ErrOrderBookGap)
+
+		assert.Equal(t, uint64(100), book.LastUpdateID)
+		assert.Equal(t, []string{"100@2", "99.5@1", "99@3"}, levelStrings(book.Bids(0)))
+	})
+}
+
+func TestOrderBook_Snapshot(t *testing.T) {
+	book := testOrderBook()
+	snapshotTime := time.UnixMilli(1700000002000)
+
+	snapshot := book.Snapshot(2, snapshotTime)
+
+	assert.Equal(t, "BTCUSDT", snapshot.Symbol)
+	assert.Equal(t, uint64(100), snapshot.LastUpdateID)
+	assert.Equal(t, snapshotTime, snapshot.SnapshotTime)
+	assert.Equal(t, []string{"100@2", "99.5@1"}, levelStrings(snapshot.Bids))
+	assert.Equal(t, []string{"100.5@2", "101@1"}, levelStrings(snapshot.Asks))
+}
@@ -1,5 +1,11 @@
 package entities
 
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
 type SymbolStatus string
 
 const (
@@ -19,6 +25,12 @@ type Symbol struct {
 	Status          SymbolStatus
 	IsSpotTrading   bool
 	IsMarginTrading bool
+	Market          Market
+	ContractType    string          // futures only, e.g. PERPETUAL or CURRENT_QUARTER
+	DeliveryDate    time.Time       // futures only; zero when the contract never expires
+	TickSize        decimal.Decimal // price increment, zero when unknown
+	StepSize        decimal.Decimal // quantity increment, zero when unknown
+	MinNotional     decimal.Decimal // minimum order value in the quote asset, zero when unknown
 }
 
 func NewSymbol(name, baseAsset, quoteAsset string, status SymbolStatus) *Symbol {
@@ -43,3 +55,23 @@ func (s *Symbol) Validate() error {
 	}
 	return nil
 }
+
+// SameFilters reports whether both symbols have the same exchange filters.
+func (s *Symbol) SameFilters(other *Symbol) bool {
+	return s.TickSize.Equal(other.TickSize) &&
+		s.StepSize.Equal(other.StepSize) &&
+		s.MinNotional.Equal(other.MinNotional)
+}
+
+// SymbolChange is a recorded status or filter change of a symbol, including
+// its listing and delisting. It holds the state from Time on.
+type SymbolChange struct {
+	Symbol         string
+	Status         SymbolStatus
+	PreviousStatus SymbolStatus // empty for a listing
+	Listed         bool         // false from the delisting on
+	TickSize       decimal.Decimal
+	StepSize       decimal.Decimal
+	MinNotional    decimal.Decimal
+	Time           time.Time
+}
@@ -0,0 +1,123 @@
+package entities
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// SymbolPattern matches symbol names by a glob such as BTC* or *USDT, or by a
+// regular expression when wrapped in slashes, e.g. /^(BTC|ETH)USDT$/. Globs
+// ignore case.
+type SymbolPattern struct {
+	glob   string
+	regexp *regexp.Regexp
+}
+
+func ParseSymbolPattern(pattern string) (SymbolPattern, error) {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return SymbolPattern{}, fmt.Errorf("invalid symbol pattern %q: %w", pattern, err)
+		}
+		return SymbolPattern{regexp: re}, nil
+	}
+
+	glob := strings.ToUpper(pattern)
+	if _, err := path.Match(glob, ""); err != nil {
+		return SymbolPattern{}, fmt.Errorf("invalid symbol pattern %q: %w", pattern, err)
+	}
+	return SymbolPattern{glob: glob}, nil
+}
+
+func (p SymbolPattern) Match(name string) bool {
+	if p.regexp != nil {
+		return p.regexp.MatchString(name)
+	}
+	matched, _ := path.Match(p.glob, strings.ToUpper(name))
+	return matched
+}
+
+// SymbolSelection picks the symbols a stream is collected for. Empty lists
+// don't filter.
+type SymbolSelection struct {
+	Symbols       []string        // exact symbol names
+	QuoteAssets   []string        // e.g. USDT, FDUSD
+	BaseAssets    []string        // e.g. BTC, ETH
+	Include       []SymbolPattern // a symbol must match one of them
+	Exclude       []SymbolPattern // a symbol must match none of them
+	SpotTrading   bool            // only symbols tradable on spot
+	MarginTrading bool            // only symbols tradable on margin
+	TopByVolume   int             // keep the symbols with the highest 24h quote volume (0 = all)
+}
+
+// Matches applies every filter but the top-N by volume.
+func (s SymbolSelection) Matches(symbol *Symbol) bool {
+	if len(s.Symbols) > 0 && !slices.Contains(s.Symbols, symbol.Name) {
+		return false
+	}
+	if len(s.QuoteAssets) > 0 && !containsFold(s.QuoteAssets, symbol.QuoteAsset) {
+		return false
+	}
+	if len(s.BaseAssets) > 0 && !containsFold(s.BaseAssets, symbol.BaseAsset) {
+		return false
+	}
+	if s.SpotTrading && !symbol.IsSpotTrading {
+		return false
+	}
+	if s.MarginTrading && !symbol.IsMarginTrading {
+		return false
+	}
+	if len(s.Include) > 0 && !matchAny(s.Include, symbol.Name) {
+		return false
+	}
+	return !matchAny(s.Exclude, symbol.Name)
+}
+
+// Select returns the matching symbols. With TopByVolume set they are ranked
+// by quoteVolumes, highest first, and cut to that many; symbols without a
+// volume rank last.
+func (s SymbolSelection) Select(symbols []*Symbol, quoteVolumes map[string]decimal.Decimal) []*Symbol {
+	selected := make([]*Symbol, 0, len(symbols))
+	for _, symbol := range symbols {
+		if s.Matches(symbol) {
+			selected = append(selected, symbol)
+		}
+	}
+
+	if s.TopByVolume <= 0 || len(selected) <= s.TopByVolume {
+		return selected
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		a, b := quoteVolumes[selected[i].Name], quoteVolumes[selected[j].Name]
+		if !a.Equal(b) {
+			return a.GreaterThan(b)
+		}
+		return selected[i].Name < selected[j].Name
+	})
+	return selected[:s.TopByVolume]
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []SymbolPattern, name string) bool {
+	for _, pattern := range patterns {
+		if pattern.Match(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSymbolPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"BTC*", "BTCUSDT", true},
+		{"btc*", "BTCUSDT", true},
+		{"*USDT", "ETHBTC", false},
+		{"?TCUSDT", "BTCUSDT", true},
+		{"/^(BTC|ETH)USDT$/", "ETHUSDT", true},
+		{"/^(BTC|ETH)USDT$/", "SOLUSDT", false},
+		{"/UP|DOWN/", "BTCUPUSDT", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			pattern, err := ParseSymbolPattern(tt.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tt.match, pattern.Match(tt.name))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParseSymbolPattern("BTC[")
+		assert.Error(t, err)
+
+		_, err = ParseSymbolPattern("/(BTC/")
+		assert.Error(t, err)
+	})
+}
+
+func selectionSymbols() []*Symbol {
+	btc := NewSymbol("BTCUSDT", "BTC", "USDT", SymbolStatusTrading)
+	btc.IsSpotTrading, btc.IsMarginTrading = true, true
+	eth := NewSymbol("ETHUSDT", "ETH", "USDT", SymbolStatusTrading)
+	eth.IsSpotTrading, eth.IsMarginTrading = true, true
+	sol := NewSymbol("SOLUSDT", "SOL", "USDT", SymbolStatusTrading)
+	sol.IsSpotTrading = true
+	ethBTC := NewSymbol("ETHBTC", "ETH", "BTC", SymbolStatusTrading)
+	ethBTC.IsSpotTrading = true
+	btcUp := NewSymbol("BTCUPUSDT", "BTCUP", "USDT", SymbolStatusTrading)
+	return []*Symbol{btc, eth, sol, ethBTC, btcUp}
+}
+
+func symbolNames(symbols []*Symbol) []string {
+	result := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		result = append(result, symbol.Name)
+	}
+	return result
+}
+
+func mustPatterns(t *testing.T, patterns ...string) []SymbolPattern {
+	t.Helper()
+	result := make([]SymbolPattern, 0, len(patterns))
+	for _, p := range patterns {
+		pattern, err := ParseSymbolPattern(p)
+		require.NoError(t, err)
+		result = append(result, pattern)
+	}
+	return result
+}
+
+func TestSymbolSelection_Select(t *testing.T) {
+	symbols := selectionSymbols()
+
+	tests := []struct {
+		name      string
+		selection SymbolSelection
+		want      []string
+	}{
+		{
+			name: "empty selection keeps everything",
+			want: []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "ETHBTC", "BTCUPUSDT"},
+		},
+		{
+			name:      "exact symbols",
+			selection: SymbolSelection{Symbols: []string{"ETHBTC", "SOLUSDT"}},
+			want:      []string{"SOLUSDT", "ETHBTC"},
+		},
+		{
+			name:      "quote asset",
+			selection: SymbolSelection{QuoteAssets: []string{"btc"}},
+			want:      []string{"ETHBTC"},
+		},
+		{
+			name:      "base asset",
+			selection: SymbolSelection{BaseAssets: []string{"ETH"}},
+			want:      []string{"ETHUSDT", "ETHBTC"},
+		},
+		{
+			name:      "margin permission",
+			selection: SymbolSelection{MarginTrading: true},
+			want:      []string{"BTCUSDT", "ETHUSDT"},
+		},
+		{
+			name:      "spot permission",
+			selection: SymbolSelection{SpotTrading: true},
+			want:      []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "ETHBTC"},
+		},
+		{
+			name: "include and exclude patterns",
+			selection: SymbolSelection{
+				Include: mustPatterns(t, "*USDT"),
+				Exclude: mustPatterns(t, "/UP|DOWN/"),
+			},
+			want: []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, symbolNames(tt.selection.Select(symbols, nil)))
+		})
+	}
+}
+
+func TestSymbolSelection_TopByVolume(t *testing.T) {
+	symbols := selectionSymbols()
+	volumes := map[string]decimal.Decimal{
+		"BTCUSDT": decimal.RequireFromString("900000000"),
+		"ETHUSDT": decimal.RequireFromString("500000000"),
+		"SOLUSDT": decimal.RequireFromString("700000000"),
+		"ETHBTC":  decimal.RequireFromString("3000"),
+	}
+
+	selection := SymbolSelection{QuoteAssets: []string{"USDT"}, TopByVolume: 2}
+	assert.Equal(t, []string{"BTCUSDT", "SOLUSDT"}, symbolNames(selection.Select(symbols, volumes)))
+
+	// Symbols without a volume rank last
+	selection = SymbolSelection{TopByVolume: 4}
+	assert.Equal(t, []string{"BTCUSDT", "SOLUSDT", "ETHUSDT", "ETHBTC"}, symbolNames(selection.Select(symbols, volumes)))
+
+	// Fewer matches than N keeps the exchange order
+	selection = SymbolSelection{QuoteAssets: []string{"USDT"}, TopByVolume: 10}
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "BTCUPUSDT"}, symbolNames(selection.Select(symbols, volumes)))
+}
@@ -3,6 +3,7 @@ package entities
 import (
 	"testing"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -209,3 +210,17 @@ func TestSymbol_EdgeCases(t *testing.T) {
 		assert.NoError(t, symbol.Validate())
 	})
 }
+
+func TestSymbol_SameFilters(t *testing.T) {
+	symbol := NewSymbol("BTCUSDT", "BTC", "USDT", SymbolStatusTrading)
+	symbol.TickSize = decimal.RequireFromString("0.01")
+	symbol.StepSize = decimal.RequireFromString("0.00001")
+	symbol.MinNotional = decimal.RequireFromString("5")
+
+	other := *symbol
+	other.TickSize = decimal.RequireFromString("0.010")
+	assert.True(t, symbol.SameFilters(&other))
+
+	other.MinNotional = decimal.RequireFromString("10")
+	assert.False(t, symbol.SameFilters(&other))
+}
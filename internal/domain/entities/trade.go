@@ -2,35 +2,59 @@ package entities
 
 import (
 	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TradeSource records where a trade was collected from.
+type TradeSource string
+
+const (
+	TradeSourceUnknown TradeSource = "unknown" // rows stored before the source was tracked
+	TradeSourceLive    TradeSource = "live"
+	TradeSourceREST    TradeSource = "rest"
+	TradeSourceCSV     TradeSource = "csv"
 )
 
 type Trade struct {
-	ID           string
-	Symbol       string
-	Price        float64
-	Quantity     float64
-	Time         time.Time
-	IsBuyerMaker bool
-	EventTime    time.Time
+	ID            uint64
+	Symbol        string
+	Price         decimal.Decimal
+	Quantity      decimal.Decimal
+	QuoteQuantity decimal.Decimal
+	BuyerOrderID  uint64 // 0 when the source does not provide it
+	SellerOrderID uint64 // 0 when the source does not provide it
+	Time          time.Time
+	IsBuyerMaker  bool
+	IsBestMatch   bool
+	EventTime     time.Time
+	Source        TradeSource
+	Exchange      Exchange
+	Market        Market
 }
 
+// NewTrade creates a trade with the quote quantity derived from price and
+// quantity. Sources that report the quote quantity themselves overwrite it.
 func NewTrade(
-	id string,
+	id uint64,
 	symbol string,
-	price float64,
-	quantity float64,
+	price decimal.Decimal,
+	quantity decimal.Decimal,
 	tradeTime time.Time,
 	isBuyerMaker bool,
 	eventTime time.Time,
+	source TradeSource,
 ) *Trade {
 	return &Trade{
-		ID:           id,
-		Symbol:       symbol,
-		Price:        price,
-		Quantity:     quantity,
-		Time:         tradeTime,
-		IsBuyerMaker: isBuyerMaker,
-		EventTime:    eventTime,
+		ID:            id,
+		Symbol:        symbol,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price.Mul(quantity),
+		Time:          tradeTime,
+		IsBuyerMaker:  isBuyerMaker,
+		EventTime:     eventTime,
+		Source:        source,
 	}
 }
 
@@ -38,10 +62,10 @@ func (t *Trade) Validate() error {
 	if t.Symbol == "" {
 		return ErrInvalidSymbol
 	}
-	if t.Price <= 0 {
+	if !t.Price.IsPositive() {
 		return ErrInvalidPrice
 	}
-	if t.Quantity <= 0 {
+	if !t.Quantity.IsPositive() {
 		return ErrInvalidQuantity
 	}
 	return nil
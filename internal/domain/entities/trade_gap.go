@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"time"
+)
+
+// TradeGap is a range of trade IDs that never arrived on the live stream.
+// Both ends are inclusive.
+type TradeGap struct {
+	Symbol     string
+	FromID     uint64
+	ToID       uint64
+	DetectedAt time.Time
+}
+
+func NewTradeGap(symbol string, fromID, toID uint64, detectedAt time.Time) *TradeGap {
+	return &TradeGap{
+		Symbol:     symbol,
+		FromID:     fromID,
+		ToID:       toID,
+		DetectedAt: detectedAt,
+	}
+}
+
+// Size returns the number of missing trades.
+func (g *TradeGap) Size() uint64 {
+	return g.ToID - g.FromID + 1
+}
+
+func (g *TradeGap) Validate() error {
+	if g.Symbol == "" {
+		return ErrInvalidSymbol
+	}
+	if g.ToID < g.FromID {
+		return ErrInvalidTradeRange
+	}
+	return nil
+}
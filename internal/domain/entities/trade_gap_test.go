@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTradeGap(t *testing.T) {
+	now := time.Now()
+
+	gap := NewTradeGap("BTCUSDT", 101, 105, now)
+
+	assert.NotNil(t, gap)
+	assert.Equal(t, "BTCUSDT", gap.Symbol)
+	assert.Equal(t, uint64(101), gap.FromID)
+	assert.Equal(t, uint64(105), gap.ToID)
+	assert.Equal(t, now, gap.DetectedAt)
+	assert.Equal(t, uint64(5), gap.Size())
+}
+
+func TestTradeGap_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		gap     *TradeGap
+		wantErr error
+	}{
+		{
+			name:    "valid gap",
+			gap:     NewTradeGap("BTCUSDT", 101, 105, time.Now()),
+			wantErr: nil,
+		},
+		{
+			name:    "single missing trade",
+			gap:     NewTradeGap("BTCUSDT", 101, 101, time.Now()),
+			wantErr: nil,
+		},
+		{
+			name:    "empty symbol",
+			gap:     NewTradeGap("", 101, 105, time.Now()),
+			wantErr: ErrInvalidSymbol,
+		},
+		{
+			name:    "inverted range",
+			gap:     NewTradeGap("BTCUSDT", 105, 101, time.Now()),
+			wantErr: ErrInvalidTradeRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.gap.Validate()
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -13,23 +14,29 @@ func TestNewTrade(t *testing.T) {
 	eventTime := now.Add(time.Millisecond * 100)
 
 	trade := NewTrade(
-		"12345",
+		12345,
 		"BTCUSDT",
-		50000.0,
-		0.01,
+		decimal.RequireFromString("50000.0"),
+		decimal.RequireFromString("0.01"),
 		now,
 		true,
 		eventTime,
+		TradeSourceLive,
 	)
 
 	assert.NotNil(t, trade)
-	assert.Equal(t, "12345", trade.ID)
+	assert.Equal(t, uint64(12345), trade.ID)
 	assert.Equal(t, "BTCUSDT", trade.Symbol)
-	assert.Equal(t, 50000.0, trade.Price)
-	assert.Equal(t, 0.01, trade.Quantity)
+	assert.True(t, decimal.RequireFromString("50000").Equal(trade.Price))
+	assert.Equal(t, "0.01", trade.Quantity.String())
 	assert.Equal(t, now, trade.Time)
 	assert.True(t, trade.IsBuyerMaker)
 	assert.Equal(t, eventTime, trade.EventTime)
+	assert.Equal(t, "500", trade.QuoteQuantity.String())
+	assert.Equal(t, TradeSourceLive, trade.Source)
+	assert.Zero(t, trade.BuyerOrderID)
+	assert.Zero(t, trade.SellerOrderID)
+	assert.False(t, trade.IsBestMatch)
 }
 
 func TestTrade_Validate(t *testing.T) {
@@ -41,10 +48,10 @@ func TestTrade_Validate(t *testing.T) {
 		{
 			name: "valid trade",
 			trade: &Trade{
-				ID:           "12345",
+				ID:           12345,
 				Symbol:       "BTCUSDT",
-				Price:        50000.0,
-				Quantity:     0.01,
+				Price:        decimal.RequireFromString("50000.0"),
+				Quantity:     decimal.RequireFromString("0.01"),
 				Time:         time.Now(),
 				IsBuyerMaker: true,
 				EventTime:    time.Now(),
@@ -54,10 +61,10 @@ func TestTrade_Validate(t *testing.T) {
 		{
 			name: "empty symbol",
 			trade: &Trade{
-				ID:           "12345",
+				ID:           12345,
 				Symbol:       "",
-				Price:        50000.0,
-				Quantity:     0.01,
+				Price:        decimal.RequireFromString("50000.0"),
+				Quantity:     decimal.RequireFromString("0.01"),
 				Time:         time.Now(),
 				IsBuyerMaker: true,
 				EventTime:    time.Now(),
@@ -67,10 +74,10 @@ func TestTrade_Validate(t *testing.T) {
 		{
 			name: "zero price",
 			trade: &Trade{
-				ID:           "12345",
+				ID:           12345,
 				Symbol:       "BTCUSDT",
-				Price:        0,
-				Quantity:     0.01,
+				Price:        decimal.RequireFromString("0"),
+				Quantity:     decimal.RequireFromString("0.01"),
 				Time:         time.Now(),
 				IsBuyerMaker: true,
 				EventTime:    time.Now(),
@@ -80,10 +87,10 @@ func TestTrade_Validate(t *testing.T) {
 		{
 			name: "negative price",
 			trade: &Trade{
-				ID:           "12345",
+				ID:           12345,
 				Symbol:       "BTCUSDT",
-				Price:        -100.0,
-				Quantity:     0.01,
+				Price:        decimal.RequireFromString("-100.0"),
+				Quantity:     decimal.RequireFromString("0.01"),
 				Time:         time.Now(),
 				IsBuyerMaker: true,
 				EventTime:    time.Now(),
@@ -93,10 +100,10 @@ func TestTrade_Validate(t *testing.T) {
 		{
 			name: "zero quantity",
 			trade: &Trade{
-				ID:           "12345",
+				ID:           12345,
 				Symbol:       "BTCUSDT",
-				Price:        50000.0,
-				Quantity:     0,
+				Price:        decimal.RequireFromString("50000.0"),
+				Quantity:     decimal.RequireFromString("0"),
 				Time:         time.Now(),
 				IsBuyerMaker: true,
 				EventTime:    time.Now(),
@@ -106,10 +113,10 @@ func TestTrade_Validate(t *testing.T) {
 		{
 			name: "negative quantity",
 			trade: &Trade{
-				ID:           "12345",
+				ID:           12345,
 				Symbol:       "BTCUSDT",
-				Price:        50000.0,
-				Quantity:     -0.01,
+				Price:        decimal.RequireFromString("50000.0"),
+				Quantity:     decimal.RequireFromString("-0.01"),
 				Time:         time.Now(),
 				IsBuyerMaker: true,
 				EventTime:    time.Now(),
@@ -135,30 +142,42 @@ func TestTrade_EdgeCases(t *testing.T) {
 	t.Run("very small valid quantity", func(t *testing.T) {
 		trade := &Trade{
 			Symbol:   "BTCUSDT",
-			Price:    50000.0,
-			Quantity: 0.00000001, // 1 satoshi worth
+			Price:    decimal.RequireFromString("50000.0"),
+			Quantity: decimal.RequireFromString("0.00000001"), // 1 satoshi worth
 		}
 		assert.NoError(t, trade.Validate())
 	})
 
+	t.Run("small prices stay exact", func(t *testing.T) {
+		trade := &Trade{
+			Symbol:   "SHIBUSDT",
+			Price:    decimal.RequireFromString("0.00000123"),
+			Quantity: decimal.RequireFromString("1000000"),
+		}
+		assert.NoError(t, trade.Validate())
+		assert.Equal(t, "0.00000123", trade.Price.String())
+		assert.Equal(t, "1.23", trade.Price.Mul(trade.Quantity).String())
+	})
+
 	t.Run("very large valid price", func(t *testing.T) {
 		trade := &Trade{
 			Symbol:   "BTCUSDT",
-			Price:    1e9, // 1 billion
-			Quantity: 0.01,
+			Price:    decimal.RequireFromString("1e9"), // 1 billion
+			Quantity: decimal.RequireFromString("0.01"),
 		}
 		assert.NoError(t, trade.Validate())
 	})
 
 	t.Run("zero time values", func(t *testing.T) {
 		trade := NewTrade(
-			"12345",
+			12345,
 			"BTCUSDT",
-			50000.0,
-			0.01,
+			decimal.RequireFromString("50000.0"),
+			decimal.RequireFromString("0.01"),
 			time.Time{}, // zero time
 			true,
 			time.Time{}, // zero time
+			TradeSourceLive,
 		)
 		assert.NotNil(t, trade)
 		assert.True(t, trade.Time.IsZero())
@@ -1,12 +1,20 @@
 package events
 
-import "alarket/internal/domain/entities"
+import (
+	"time"
+
+	"alarket/internal/domain/entities"
+)
 
 type EventType string
 
 const (
 	TradeEventType      EventType = "trade"
 	BookTickerEventType EventType = "bookTicker"
+
+	SymbolListedEventType        EventType = "symbolListed"
+	SymbolDelistedEventType      EventType = "symbolDelisted"
+	SymbolStatusChangedEventType EventType = "symbolStatusChanged"
 )
 
 type DomainEvent interface {
@@ -27,4 +35,43 @@ type BookTickerEvent struct {
 
 func (e BookTickerEvent) Type() EventType {
 	return BookTickerEventType
-}
\ No newline at end of file
+}
+
+// SymbolListedEvent is a symbol that appeared in the exchange info of an
+// exchange market.
+type SymbolListedEvent struct {
+	Exchange entities.Exchange
+	Market   entities.Market
+	Symbol   *entities.Symbol
+	Time     time.Time
+}
+
+func (e SymbolListedEvent) Type() EventType {
+	return SymbolListedEventType
+}
+
+// SymbolDelistedEvent is a symbol that is no longer in the exchange info.
+type SymbolDelistedEvent struct {
+	Exchange entities.Exchange
+	Market   entities.Market
+	Symbol   *entities.Symbol // as last seen
+	Time     time.Time
+}
+
+func (e SymbolDelistedEvent) Type() EventType {
+	return SymbolDelistedEventType
+}
+
+// SymbolStatusChangedEvent is a listed symbol whose trading status changed,
+// e.g. from TRADING to HALT.
+type SymbolStatusChangedEvent struct {
+	Exchange       entities.Exchange
+	Market         entities.Market
+	Symbol         *entities.Symbol
+	PreviousStatus entities.SymbolStatus
+	Time           time.Time
+}
+
+func (e SymbolStatusChangedEvent) Type() EventType {
+	return SymbolStatusChangedEventType
+}
@@ -5,15 +5,16 @@ import (
 	"time"
 
 	"alarket/internal/domain/entities"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestTradeEvent_Type(t *testing.T) {
 	trade := &entities.Trade{
-		ID:           "12345",
+		ID:           12345,
 		Symbol:       "BTCUSDT",
-		Price:        50000.0,
-		Quantity:     0.01,
+		Price:        decimal.RequireFromString("50000.0"),
+		Quantity:     decimal.RequireFromString("0.01"),
 		Time:         time.Now(),
 		IsBuyerMaker: true,
 		EventTime:    time.Now(),
@@ -31,7 +31,7 @@ func (m *MockTradeRepository) GetBySymbol(ctx context.Context, symbol string, fr
 	return args.Get(0).([]*entities.Trade), args.Error(1)
 }
 
-func (m *MockTradeRepository) GetByID(ctx context.Context, id string) (*entities.Trade, error) {
+func (m *MockTradeRepository) GetByID(ctx context.Context, id uint64) (*entities.Trade, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -47,20 +47,28 @@ func (m *MockTradeRepository) GetOldestTradeTime(ctx context.Context, symbol str
 	return args.Get(0).(*time.Time), args.Error(1)
 }
 
-func (m *MockTradeRepository) GetOldestTradeID(ctx context.Context, symbol string) (*int64, error) {
+func (m *MockTradeRepository) GetOldestTradeID(ctx context.Context, symbol string) (*uint64, error) {
 	args := m.Called(ctx, symbol)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*int64), args.Error(1)
+	return args.Get(0).(*uint64), args.Error(1)
 }
 
-func (m *MockTradeRepository) GetNewestTradeID(ctx context.Context, symbol string) (*int64, error) {
+func (m *MockTradeRepository) GetNewestTradeID(ctx context.Context, symbol string) (*uint64, error) {
 	args := m.Called(ctx, symbol)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*int64), args.Error(1)
+	return args.Get(0).(*uint64), args.Error(1)
+}
+
+func (m *MockTradeRepository) GetNewestTradeTime(ctx context.Context, symbol string) (*time.Time, error) {
+	args := m.Called(ctx, symbol)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
 }
 
 // MockSymbolRepository is a mock implementation of SymbolRepository
@@ -76,7 +84,7 @@ func (m *MockSymbolRepository) GetAll(ctx context.Context) ([]*entities.Symbol,
 	return args.Get(0).([]*entities.Symbol), args.Error(1)
 }
 
-func (m *MockSymbolRepository) GetActiveUsdt(ctx context.Context) ([]*entities.Symbol, error) {
+func (m *MockSymbolRepository) GetActive(ctx context.Context) ([]*entities.Symbol, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -97,6 +105,27 @@ func (m *MockSymbolRepository) UpdateStatus(ctx context.Context, name string, st
 	return args.Error(0)
 }
 
+func (m *MockSymbolRepository) ReplaceAll(ctx context.Context, symbols []*entities.Symbol) error {
+	args := m.Called(ctx, symbols)
+	return args.Error(0)
+}
+
+func (m *MockSymbolRepository) GetHistory(ctx context.Context, name string, from, to time.Time) ([]*entities.SymbolChange, error) {
+	args := m.Called(ctx, name, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.SymbolChange), args.Error(1)
+}
+
+func (m *MockSymbolRepository) GetAsOf(ctx context.Context, name string, at time.Time) (*entities.SymbolChange, error) {
+	args := m.Called(ctx, name, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.SymbolChange), args.Error(1)
+}
+
 // MockBookTickerRepository is a mock implementation of BookTickerRepository
 type MockBookTickerRepository struct {
 	mock.Mock
@@ -126,4 +155,145 @@ func (m *MockBookTickerRepository) GetBySymbol(ctx context.Context, symbol strin
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*entities.BookTicker), args.Error(1)
-}
\ No newline at end of file
+}
+
+// MockTradeGapRepository is a mock implementation of TradeGapRepository
+type MockTradeGapRepository struct {
+	mock.Mock
+}
+
+func (m *MockTradeGapRepository) Save(ctx context.Context, gap *entities.TradeGap) error {
+	args := m.Called(ctx, gap)
+	return args.Error(0)
+}
+
+func (m *MockTradeGapRepository) GetBySymbol(ctx context.Context, symbol string, from, to time.Time) ([]*entities.TradeGap, error) {
+	args := m.Called(ctx, symbol, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.TradeGap), args.Error(1)
+}
+
+// MockAggTradeRepository is a mock implementation of AggTradeRepository
+type MockAggTradeRepository struct {
+	mock.Mock
+}
+
+func (m *MockAggTradeRepository) Save(ctx context.Context, aggTrade *entities.AggTrade) error {
+	args := m.Called(ctx, aggTrade)
+	return args.Error(0)
+}
+
+func (m *MockAggTradeRepository) SaveBatch(ctx context.Context, aggTrades []*entities.AggTrade) error {
+	args := m.Called(ctx, aggTrades)
+	return args.Error(0)
+}
+
+func (m *MockAggTradeRepository) GetBySymbol(ctx context.Context, symbol string, from, to time.Time) ([]*entities.AggTrade, error) {
+	args := m.Called(ctx, symbol, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.AggTrade), args.Error(1)
+}
+
+// MockKlineRepository is a mock implementation of KlineRepository
+type MockKlineRepository struct {
+	mock.Mock
+}
+
+func (m *MockKlineRepository) SaveBatch(ctx context.Context, klines []*entities.Kline) error {
+	args := m.Called(ctx, klines)
+	return args.Error(0)
+}
+
+func (m *MockKlineRepository) GetBySymbol(ctx context.Context, symbol string, interval entities.KlineInterval, from, to time.Time) ([]*entities.Kline, error) {
+	args := m.Called(ctx, symbol, interval, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Kline), args.Error(1)
+}
+
+func (m *MockKlineRepository) GetNewestOpenTime(ctx context.Context, symbol string, interval entities.KlineInterval) (*time.Time, error) {
+	args := m.Called(ctx, symbol, interval)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+// MockMarkPriceRepository is a mock implementation of MarkPriceRepository
+type MockMarkPriceRepository struct {
+	mock.Mock
+}
+
+func (m *MockMarkPriceRepository) SaveBatch(ctx context.Context, markPrices []*entities.MarkPrice) error {
+	args := m.Called(ctx, markPrices)
+	return args.Error(0)
+}
+
+// MockFundingRateRepository is a mock implementation of FundingRateRepository
+type MockFundingRateRepository struct {
+	mock.Mock
+}
+
+func (m *MockFundingRateRepository) SaveBatch(ctx context.Context, rates []*entities.FundingRate) error {
+	args := m.Called(ctx, rates)
+	return args.Error(0)
+}
+
+func (m *MockFundingRateRepository) GetNewestFundingTime(ctx context.Context, symbol string) (*time.Time, error) {
+	args := m.Called(ctx, symbol)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+// MockOrderBookSnapshotRepository is a mock implementation of OrderBookSnapshotRepository
+type MockOrderBookSnapshotRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderBookSnapshotRepository) SaveBatch(ctx context.Context, snapshots []*entities.OrderBookSnapshot) error {
+	args := m.Called(ctx, snapshots)
+	return args.Error(0)
+}
+
+// MockBackfillJobRepository is a mock implementation of BackfillJobRepository
+type MockBackfillJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockBackfillJobRepository) Save(ctx context.Context, job *entities.BackfillJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockBackfillJobRepository) SaveStatus(ctx context.Context, job *entities.BackfillJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockBackfillJobRepository) SaveProgress(ctx context.Context, job *entities.BackfillJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockBackfillJobRepository) GetByID(ctx context.Context, id string) (*entities.BackfillJob, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.BackfillJob), args.Error(1)
+}
+
+func (m *MockBackfillJobRepository) GetAll(ctx context.Context) ([]*entities.BackfillJob, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.BackfillJob), args.Error(1)
+}
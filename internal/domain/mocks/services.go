@@ -2,8 +2,11 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"alarket/internal/domain/entities"
+	"alarket/internal/domain/services"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -17,6 +20,21 @@ func (m *MockExchangeClient) SubscribeToTrades(ctx context.Context, symbols []st
 	return args.Error(0)
 }
 
+func (m *MockExchangeClient) SubscribeToAggTrades(ctx context.Context, symbols []string) error {
+	args := m.Called(ctx, symbols)
+	return args.Error(0)
+}
+
+func (m *MockExchangeClient) SubscribeToKlines(ctx context.Context, symbols []string, intervals []entities.KlineInterval) error {
+	args := m.Called(ctx, symbols, intervals)
+	return args.Error(0)
+}
+
+func (m *MockExchangeClient) SubscribeToDepth(ctx context.Context, symbols []string) error {
+	args := m.Called(ctx, symbols)
+	return args.Error(0)
+}
+
 func (m *MockExchangeClient) SubscribeToBookTickers(ctx context.Context, symbols []string) error {
 	args := m.Called(ctx, symbols)
 	return args.Error(0)
@@ -27,16 +45,113 @@ func (m *MockExchangeClient) UnsubscribeFromTrades(ctx context.Context, symbols
 	return args.Error(0)
 }
 
+func (m *MockExchangeClient) UnsubscribeFromAggTrades(ctx context.Context, symbols []string) error {
+	args := m.Called(ctx, symbols)
+	return args.Error(0)
+}
+
+func (m *MockExchangeClient) UnsubscribeFromKlines(ctx context.Context, symbols []string, intervals []entities.KlineInterval) error {
+	args := m.Called(ctx, symbols, intervals)
+	return args.Error(0)
+}
+
+func (m *MockExchangeClient) UnsubscribeFromDepth(ctx context.Context, symbols []string) error {
+	args := m.Called(ctx, symbols)
+	return args.Error(0)
+}
+
 func (m *MockExchangeClient) UnsubscribeFromBookTickers(ctx context.Context, symbols []string) error {
 	args := m.Called(ctx, symbols)
 	return args.Error(0)
 }
 
+func (m *MockExchangeClient) SubscribeToMarkPrices(ctx context.Context, symbols []string) error {
+	args := m.Called(ctx, symbols)
+	return args.Error(0)
+}
+
+func (m *MockExchangeClient) UnsubscribeFromMarkPrices(ctx context.Context, symbols []string) error {
+	args := m.Called(ctx, symbols)
+	return args.Error(0)
+}
+
+func (m *MockExchangeClient) PendingRequests() int {
+	args := m.Called()
+	return args.Int(0)
+}
+
 func (m *MockExchangeClient) Close() error {
 	args := m.Called()
 	return args.Error(0)
 }
 
+// MockExchangeConnector is a mock implementation of ExchangeConnector
+type MockExchangeConnector struct {
+	mock.Mock
+}
+
+func (m *MockExchangeConnector) Exchange() entities.Exchange {
+	args := m.Called()
+	return args.Get(0).(entities.Exchange)
+}
+
+func (m *MockExchangeConnector) Market() entities.Market {
+	args := m.Called()
+	return args.Get(0).(entities.Market)
+}
+
+func (m *MockExchangeConnector) FetchSymbols(ctx context.Context) ([]*entities.Symbol, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Symbol), args.Error(1)
+}
+
+func (m *MockExchangeConnector) NewClient(handler func(message []byte) error) services.ExchangeClient {
+	args := m.Called(handler)
+	return args.Get(0).(services.ExchangeClient)
+}
+
+func (m *MockExchangeConnector) NewDryRunClient() services.DryRunClient {
+	args := m.Called()
+	return args.Get(0).(services.DryRunClient)
+}
+
+func (m *MockExchangeConnector) Decoder() services.MessageDecoder {
+	args := m.Called()
+	return args.Get(0).(services.MessageDecoder)
+}
+
+func (m *MockExchangeConnector) HistoricalTrades() services.HistoricalDataService {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(services.HistoricalDataService)
+}
+
+func (m *MockExchangeConnector) Tickers() (services.TickerService, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(services.TickerService), args.Error(1)
+}
+
+// MockTickerService is a mock implementation of TickerService
+type MockTickerService struct {
+	mock.Mock
+}
+
+func (m *MockTickerService) FetchQuoteVolumes(ctx context.Context) (map[string]decimal.Decimal, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]decimal.Decimal), args.Error(1)
+}
+
 // MockEventPublisher is a mock implementation of EventPublisher
 type MockEventPublisher struct {
 	mock.Mock
@@ -62,10 +177,75 @@ type MockHistoricalDataService struct {
 	mock.Mock
 }
 
-func (m *MockHistoricalDataService) FetchHistoricalTrades(ctx context.Context, symbol string, fromID int64, limit int) ([]*entities.Trade, error) {
+func (m *MockHistoricalDataService) FetchHistoricalTrades(ctx context.Context, symbol string, fromID uint64, limit int) ([]*entities.Trade, error) {
 	args := m.Called(ctx, symbol, fromID, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*entities.Trade), args.Error(1)
-}
\ No newline at end of file
+}
+
+func (m *MockHistoricalDataService) FetchLatestTrades(ctx context.Context, symbol string, limit int) ([]*entities.Trade, error) {
+	args := m.Called(ctx, symbol, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Trade), args.Error(1)
+}
+
+func (m *MockHistoricalDataService) FindTradeID(ctx context.Context, symbol string, since time.Time) (uint64, bool, error) {
+	args := m.Called(ctx, symbol, since)
+	return args.Get(0).(uint64), args.Bool(1), args.Error(2)
+}
+
+// MockAggTradeDataService is a mock implementation of AggTradeDataService
+type MockAggTradeDataService struct {
+	mock.Mock
+}
+
+func (m *MockAggTradeDataService) FetchAggTrades(ctx context.Context, symbol string, startTime, endTime time.Time, limit int) ([]*entities.AggTrade, error) {
+	args := m.Called(ctx, symbol, startTime, endTime, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.AggTrade), args.Error(1)
+}
+
+// MockKlineDataService is a mock implementation of KlineDataService
+type MockKlineDataService struct {
+	mock.Mock
+}
+
+func (m *MockKlineDataService) FetchKlines(ctx context.Context, symbol string, interval entities.KlineInterval, startTime, endTime time.Time, limit int) ([]*entities.Kline, error) {
+	args := m.Called(ctx, symbol, interval, startTime, endTime, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Kline), args.Error(1)
+}
+
+// MockFundingRateDataService is a mock implementation of FundingRateDataService
+type MockFundingRateDataService struct {
+	mock.Mock
+}
+
+func (m *MockFundingRateDataService) FetchFundingRates(ctx context.Context, symbol string, startTime, endTime time.Time, limit int) ([]*entities.FundingRate, error) {
+	args := m.Called(ctx, symbol, startTime, endTime, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.FundingRate), args.Error(1)
+}
+
+// MockOrderBookDataService is a mock implementation of OrderBookDataService
+type MockOrderBookDataService struct {
+	mock.Mock
+}
+
+func (m *MockOrderBookDataService) FetchOrderBook(ctx context.Context, symbol string, limit int) (*entities.OrderBook, error) {
+	args := m.Called(ctx, symbol, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.OrderBook), args.Error(1)
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"alarket/internal/domain/entities"
+)
+
+type AggTradeRepository interface {
+	Save(ctx context.Context, aggTrade *entities.AggTrade) error
+	SaveBatch(ctx context.Context, aggTrades []*entities.AggTrade) error
+	GetBySymbol(ctx context.Context, symbol string, from, to time.Time) ([]*entities.AggTrade, error)
+}
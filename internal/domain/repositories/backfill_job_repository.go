@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"alarket/internal/domain/entities"
+)
+
+type BackfillJobRepository interface {
+	// Save stores a new job with its status and progress
+	Save(ctx context.Context, job *entities.BackfillJob) error
+	// SaveStatus stores the status and error of the job, its progress stays
+	// as stored
+	SaveStatus(ctx context.Context, job *entities.BackfillJob) error
+	// SaveProgress stores the cursor, fetched count and lease of the job, its
+	// status stays as stored
+	SaveProgress(ctx context.Context, job *entities.BackfillJob) error
+	GetByID(ctx context.Context, id string) (*entities.BackfillJob, error)
+	// GetAll returns every job, oldest first
+	GetAll(ctx context.Context) ([]*entities.BackfillJob, error)
+}
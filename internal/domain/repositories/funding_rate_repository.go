@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"alarket/internal/domain/entities"
+)
+
+type FundingRateRepository interface {
+	SaveBatch(ctx context.Context, rates []*entities.FundingRate) error
+	GetNewestFundingTime(ctx context.Context, symbol string) (*time.Time, error)
+}
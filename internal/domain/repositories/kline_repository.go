@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"alarket/internal/domain/entities"
+)
+
+type KlineRepository interface {
+	SaveBatch(ctx context.Context, klines []*entities.Kline) error
+	GetBySymbol(ctx context.Context, symbol string, interval entities.KlineInterval, from, to time.Time) ([]*entities.Kline, error)
+	GetNewestOpenTime(ctx context.Context, symbol string, interval entities.KlineInterval) (*time.Time, error)
+}
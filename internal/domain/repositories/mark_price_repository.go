@@ -0,0 +1,11 @@
+package repositories
+
+import (
+	"context"
+
+	"alarket/internal/domain/entities"
+)
+
+type MarkPriceRepository interface {
+	SaveBatch(ctx context.Context, markPrices []*entities.MarkPrice) error
+}
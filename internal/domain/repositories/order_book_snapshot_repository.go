@@ -0,0 +1,11 @@
+package repositories
+
+import (
+	"context"
+
+	"alarket/internal/domain/entities"
+)
+
+type OrderBookSnapshotRepository interface {
+	SaveBatch(ctx context.Context, snapshots []*entities.OrderBookSnapshot) error
+}
@@ -2,13 +2,23 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"alarket/internal/domain/entities"
 )
 
 type SymbolRepository interface {
 	GetAll(ctx context.Context) ([]*entities.Symbol, error)
-	GetActiveUsdt(ctx context.Context) ([]*entities.Symbol, error)
+	// GetActive returns the symbols that are trading
+	GetActive(ctx context.Context) ([]*entities.Symbol, error)
 	GetByName(ctx context.Context, name string) (*entities.Symbol, error)
 	UpdateStatus(ctx context.Context, name string, status entities.SymbolStatus) error
+	// ReplaceAll swaps the known symbols for a freshly fetched list
+	ReplaceAll(ctx context.Context, symbols []*entities.Symbol) error
+	// GetHistory returns the status and filter changes of a symbol in a time
+	// range, oldest first
+	GetHistory(ctx context.Context, name string, from, to time.Time) ([]*entities.SymbolChange, error)
+	// GetAsOf returns the last change of a symbol at or before at, nil when
+	// the symbol was not known yet
+	GetAsOf(ctx context.Context, name string, at time.Time) (*entities.SymbolChange, error)
 }
@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"alarket/internal/domain/entities"
+)
+
+type TradeGapRepository interface {
+	Save(ctx context.Context, gap *entities.TradeGap) error
+	GetBySymbol(ctx context.Context, symbol string, from, to time.Time) ([]*entities.TradeGap, error)
+}
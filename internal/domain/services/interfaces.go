@@ -3,16 +3,91 @@ package services
 import (
 	"alarket/internal/domain/entities"
 	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
+// ErrStreamNotSupported is returned by exchange clients for streams the
+// exchange does not offer.
+var ErrStreamNotSupported = errors.New("stream not supported by exchange")
+
 type ExchangeClient interface {
 	SubscribeToTrades(ctx context.Context, symbols []string) error
+	SubscribeToAggTrades(ctx context.Context, symbols []string) error
+	SubscribeToKlines(ctx context.Context, symbols []string, intervals []entities.KlineInterval) error
+	SubscribeToDepth(ctx context.Context, symbols []string) error
 	SubscribeToBookTickers(ctx context.Context, symbols []string) error
+	SubscribeToMarkPrices(ctx context.Context, symbols []string) error
 	UnsubscribeFromTrades(ctx context.Context, symbols []string) error
+	UnsubscribeFromAggTrades(ctx context.Context, symbols []string) error
+	UnsubscribeFromKlines(ctx context.Context, symbols []string, intervals []entities.KlineInterval) error
+	UnsubscribeFromDepth(ctx context.Context, symbols []string) error
 	UnsubscribeFromBookTickers(ctx context.Context, symbols []string) error
+	UnsubscribeFromMarkPrices(ctx context.Context, symbols []string) error
+	// PendingRequests returns the number of subscription requests the
+	// exchange has not answered yet
+	PendingRequests() int
 	Close() error
 }
 
+// MessageDecoder turns a raw websocket frame into domain events: *Trade,
+// *AggTrade, *Kline, *DepthUpdate, *BookTicker or *MarkPrice. Control frames such as
+// subscription acknowledgements decode to no events.
+type MessageDecoder interface {
+	Decode(message []byte) ([]any, error)
+}
+
+// StreamDecoder is a MessageDecoder that also decodes the payload of a named
+// stream, as delivered on combined stream connections. Knowing the stream
+// spares sniffing the payload for its event type.
+type StreamDecoder interface {
+	MessageDecoder
+	DecodeStream(stream string, data []byte) ([]any, error)
+}
+
+// ExchangeConnector bundles everything the collector needs from one exchange:
+// symbol discovery, a streaming client, a decoder for its frames and
+// historical fetch.
+type ExchangeConnector interface {
+	Exchange() entities.Exchange
+	Market() entities.Market
+	FetchSymbols(ctx context.Context) ([]*entities.Symbol, error)
+	// NewClient returns a client that passes every frame it receives to handler
+	NewClient(handler func(message []byte) error) ExchangeClient
+	// NewDryRunClient returns a client that connects nowhere
+	NewDryRunClient() DryRunClient
+	Decoder() MessageDecoder
+	// HistoricalTrades returns nil when the exchange cannot fetch trades by ID
+	HistoricalTrades() HistoricalDataService
+	// Tickers returns nil when the exchange has no 24h ticker statistics
+	Tickers() (TickerService, error)
+}
+
+// DryRunClient is an ExchangeClient that opens no connections. It only
+// records how the subscriptions would be spread over connections.
+type DryRunClient interface {
+	ExchangeClient
+	// Connections returns the connections the subscriptions would open, in
+	// the order they would be opened
+	Connections() []PlannedConnection
+}
+
+// PlannedConnection is a connection a DryRunClient would open.
+type PlannedConnection struct {
+	ID      string
+	URL     string
+	Streams []string // In subscription order
+}
+
+// TickerService reads 24h ticker statistics.
+type TickerService interface {
+	// FetchQuoteVolumes returns the 24h volume of every symbol in its quote
+	// asset
+	FetchQuoteVolumes(ctx context.Context) (map[string]decimal.Decimal, error)
+}
+
 type EventPublisher interface {
 	Publish(ctx context.Context, event interface{}) error
 }
@@ -21,6 +96,34 @@ type EventSubscriber interface {
 	Subscribe(ctx context.Context, handler func(event interface{}) error) error
 }
 
+// HistoricalDataService fetches past trades by trade ID, oldest first.
 type HistoricalDataService interface {
-	FetchHistoricalTrades(ctx context.Context, symbol string, fromID int64, limit int) ([]*entities.Trade, error)
+	// FetchHistoricalTrades returns up to limit trades starting at fromID
+	FetchHistoricalTrades(ctx context.Context, symbol string, fromID uint64, limit int) ([]*entities.Trade, error)
+	// FetchLatestTrades returns the limit most recent trades
+	FetchLatestTrades(ctx context.Context, symbol string, limit int) ([]*entities.Trade, error)
+	// FindTradeID returns the ID of the first trade at or after since. It
+	// reports false when there was no trade since then.
+	FindTradeID(ctx context.Context, symbol string, since time.Time) (uint64, bool, error)
+}
+
+// AggTradeDataService fetches aggregate trades executed within [startTime, endTime].
+type AggTradeDataService interface {
+	FetchAggTrades(ctx context.Context, symbol string, startTime, endTime time.Time, limit int) ([]*entities.AggTrade, error)
+}
+
+// KlineDataService fetches candles that open within [startTime, endTime].
+type KlineDataService interface {
+	FetchKlines(ctx context.Context, symbol string, interval entities.KlineInterval, startTime, endTime time.Time, limit int) ([]*entities.Kline, error)
+}
+
+// FundingRateDataService fetches funding payments settled within [startTime, endTime].
+type FundingRateDataService interface {
+	FetchFundingRates(ctx context.Context, symbol string, startTime, endTime time.Time, limit int) ([]*entities.FundingRate, error)
+}
+
+// OrderBookDataService fetches a snapshot of the best limit levels on each side
+// of the order book.
+type OrderBookDataService interface {
+	FetchOrderBook(ctx context.Context, symbol string, limit int) (*entities.OrderBook, error)
 }
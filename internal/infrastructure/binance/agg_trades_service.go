@@ -0,0 +1,76 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/shopspring/decimal"
+
+	"alarket/internal/domain/entities"
+)
+
+// AggTradesService reads aggregate trades from the public aggTrades endpoint,
+// which needs no API key.
+type AggTradesService struct {
+	client *binance.Client
+	logger *slog.Logger
+}
+
+func NewAggTradesService(useTestnet bool, logger *slog.Logger) *AggTradesService {
+	return &AggTradesService{
+		client: newSpotClient("", "", useTestnet),
+		logger: logger,
+	}
+}
+
+func (s *AggTradesService) FetchAggTrades(ctx context.Context, symbol string, startTime, endTime time.Time, limit int) ([]*entities.AggTrade, error) {
+	binanceAggTrades, err := s.client.NewAggTradesService().
+		Symbol(symbol).
+		StartTime(startTime.UnixMilli()).
+		EndTime(endTime.UnixMilli()).
+		Limit(limit).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch aggregate trades from Binance: %w", err)
+	}
+
+	aggTrades := make([]*entities.AggTrade, 0, len(binanceAggTrades))
+	for _, bt := range binanceAggTrades {
+		price, err := decimal.NewFromString(bt.Price)
+		if err != nil {
+			s.logger.Warn("Failed to parse price", "price", bt.Price, "error", err)
+			continue
+		}
+
+		quantity, err := decimal.NewFromString(bt.Quantity)
+		if err != nil {
+			s.logger.Warn("Failed to parse quantity", "quantity", bt.Quantity, "error", err)
+			continue
+		}
+
+		tradeTime := time.UnixMilli(bt.Timestamp)
+
+		aggTrade := entities.NewAggTrade(
+			uint64(bt.AggTradeID),
+			symbol,
+			price,
+			quantity,
+			uint64(bt.FirstTradeID),
+			uint64(bt.LastTradeID),
+			tradeTime,
+			bt.IsBuyerMaker,
+			tradeTime, // Using trade time as event time for historical data
+			entities.TradeSourceREST,
+		)
+		aggTrade.IsBestMatch = bt.IsBestPriceMatch
+		aggTrade.Exchange = entities.ExchangeBinance
+		aggTrade.Market = entities.MarketSpot
+
+		aggTrades = append(aggTrades, aggTrade)
+	}
+
+	return aggTrades, nil
+}
@@ -1,88 +1,323 @@
 package binance
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 
 	"alarket/internal/application/dto"
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/services"
+	"alarket/internal/infrastructure/metrics"
 	"alarket/internal/infrastructure/websocket"
 )
 
 const (
-	maxStreamsPerConnection = 1022
 	maxSubscriptionsPerRequest = 100
-	baseWSURL = "wss://stream.binance.com:443/ws"
-	testnetWSURL = "wss://testnet.binance.vision/ws"
+
+	// Binance drops every connection after 24 hours, rotate well before that
+	maxConnectionLifetime = 23 * time.Hour
+
+	// Binance disconnects clients sending more than 5 messages per second on a
+	// connection, pings and pongs included. Leave room for those
+	defaultMessagesPerSecond = 4
+	defaultMessageBurst      = 1
+
+	// Binance answers every SUBSCRIBE and UNSUBSCRIBE request. One that is not
+	// answered in time, or whose connection drops first, is sent again
+	requestTimeout     = 10 * time.Second
+	maxRequestAttempts = 3
+	requestRetryDelay  = 1 * time.Second
+
+	depthStream = "depth@100ms"
+	// Futures only, pushed every second with the current funding rate
+	markPriceStream = "markPrice@1s"
 )
 
+// streamEndpoint describes the websocket endpoint of one Binance market.
+type streamEndpoint struct {
+	url                     string
+	testnetURL              string
+	maxStreamsPerConnection int
+}
+
+var streamEndpoints = map[entities.Market]streamEndpoint{
+	entities.MarketSpot: {
+		url:                     "wss://stream.binance.com:443/ws",
+		testnetURL:              "wss://testnet.binance.vision/ws",
+		maxStreamsPerConnection: 1022,
+	},
+	entities.MarketUSDM: {
+		url:                     "wss://fstream.binance.com/ws",
+		testnetURL:              "wss://stream.binancefuture.com/ws",
+		maxStreamsPerConnection: 200,
+	},
+	entities.MarketCoinM: {
+		url:                     "wss://dstream.binance.com/ws",
+		testnetURL:              "wss://dstream.binancefuture.com/ws",
+		maxStreamsPerConnection: 200,
+	},
+}
+
+var errClientClosed = errors.New("client closed")
+
+// StreamMode selects how streams are attached to connections.
+type StreamMode string
+
+const (
+	// StreamModeSubscribe opens /ws connections and adds streams with
+	// SUBSCRIBE requests.
+	StreamModeSubscribe StreamMode = "subscribe"
+	// StreamModeCombined lists the streams in the /stream URL of each new
+	// connection, so no requests are sent and reconnects restore the streams
+	// on their own. Payloads arrive wrapped as {"stream":...,"data":...}.
+	StreamModeCombined StreamMode = "combined"
+)
+
+func ParseStreamMode(value string) (StreamMode, error) {
+	switch mode := StreamMode(value); mode {
+	case StreamModeSubscribe, StreamModeCombined:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown stream mode %q (expected %s or %s)",
+			value, StreamModeSubscribe, StreamModeCombined)
+	}
+}
+
+// RequestError is the error response to a SUBSCRIBE or UNSUBSCRIBE request,
+// e.g. for an invalid stream name.
+type RequestError struct {
+	Code int
+	Msg  string
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("binance rejected request: %s (code %d)", e.Msg, e.Code)
+}
+
 type Client struct {
 	wsManager      *websocket.Manager
 	logger         *slog.Logger
+	market         entities.Market
 	useTestnet     bool
+	wsURL          string
+	maxStreams     int
 	streamCount    atomic.Int32
 	connectionID   atomic.Int32
 	subscriptions  map[string]string // stream -> connectionID
 	mu             sync.RWMutex
 	messageHandler websocket.MessageHandler
+	requestID      atomic.Int32
+	requests       *websocket.Requests
+	requestTimeout time.Duration
+	retryDelay     time.Duration
+	mode           StreamMode
+	dryRun         bool
+	planned        []string // Streams in subscription order, dry runs only
 }
 
-func NewClient(logger *slog.Logger, useTestnet bool, messageHandler websocket.MessageHandler) *Client {
-	wsManager := websocket.NewManager(logger, messageHandler)
-	
-	return &Client{
-		wsManager:      wsManager,
+// NewClient streams from the given market. Diff depth is only collected on
+// spot: order_book_snapshots has no market column and futures depth follows
+// other sequencing rules than the order book manager implements. Mark prices
+// only exist on futures.
+func NewClient(logger *slog.Logger, market entities.Market, useTestnet bool, messageHandler websocket.MessageHandler) *Client {
+	endpoint, ok := streamEndpoints[market]
+	if !ok {
+		market, endpoint = entities.MarketSpot, streamEndpoints[entities.MarketSpot]
+	}
+	wsURL := endpoint.url
+	if useTestnet {
+		wsURL = endpoint.testnetURL
+	}
+
+	client := &Client{
 		logger:         logger,
+		market:         market,
 		useTestnet:     useTestnet,
+		wsURL:          wsURL,
+		maxStreams:     endpoint.maxStreamsPerConnection,
 		subscriptions:  make(map[string]string),
 		messageHandler: messageHandler,
+		requests:       websocket.NewRequests(),
+		requestTimeout: requestTimeout,
+		retryDelay:     requestRetryDelay,
+		mode:           StreamModeSubscribe,
 	}
+	client.wsManager = websocket.NewManager(logger, client.handleMessage, client.resubscribe, maxConnectionLifetime)
+	client.wsManager.SetMetricLabels(string(entities.ExchangeBinance), string(market))
+	client.wsManager.SetRateLimit(websocket.RateLimit{
+		PerSecond: defaultMessagesPerSecond,
+		Burst:     defaultMessageBurst,
+	})
+
+	return client
+}
+
+// NewDryRunClient returns a client for the given market that opens no
+// connections. Subscriptions are spread over planned connections the way a
+// client in the given mode would spread them.
+func NewDryRunClient(logger *slog.Logger, market entities.Market, useTestnet bool, mode StreamMode) *Client {
+	client := NewClient(logger, market, useTestnet, nil)
+	client.SetStreamMode(mode)
+	client.dryRun = true
+	return client
+}
+
+// SetRateLimit overrides how fast SUBSCRIBE and UNSUBSCRIBE requests are sent
+// on each connection. Call it before subscribing.
+func (c *Client) SetRateLimit(limit websocket.RateLimit) {
+	c.wsManager.SetRateLimit(limit)
+}
+
+// SetStreamMode switches between SUBSCRIBE requests and combined stream URLs.
+// Call it before subscribing.
+func (c *Client) SetStreamMode(mode StreamMode) {
+	c.mode = mode
 }
 
 func (c *Client) SubscribeToTrades(ctx context.Context, symbols []string) error {
-	streams := make([]string, len(symbols))
-	for i, symbol := range symbols {
-		streams[i] = fmt.Sprintf("%s@trade", strings.ToLower(symbol))
+	return c.subscribe(ctx, streamNames(symbols, "trade"))
+}
+
+func (c *Client) SubscribeToAggTrades(ctx context.Context, symbols []string) error {
+	return c.subscribe(ctx, streamNames(symbols, "aggTrade"))
+}
+
+func (c *Client) SubscribeToKlines(ctx context.Context, symbols []string, intervals []entities.KlineInterval) error {
+	return c.subscribe(ctx, klineStreamNames(symbols, intervals))
+}
+
+// SubscribeToDepth subscribes to diff depth updates pushed every 100ms.
+func (c *Client) SubscribeToDepth(ctx context.Context, symbols []string) error {
+	if err := c.spotOnly("diff depth"); err != nil {
+		return err
 	}
-	return c.subscribe(ctx, streams)
+	return c.subscribe(ctx, streamNames(symbols, depthStream))
 }
 
 func (c *Client) SubscribeToBookTickers(ctx context.Context, symbols []string) error {
-	streams := make([]string, len(symbols))
-	for i, symbol := range symbols {
-		streams[i] = fmt.Sprintf("%s@bookTicker", strings.ToLower(symbol))
+	return c.subscribe(ctx, streamNames(symbols, "bookTicker"))
+}
+
+func (c *Client) SubscribeToMarkPrices(ctx context.Context, symbols []string) error {
+	if err := c.futuresOnly("mark prices"); err != nil {
+		return err
 	}
-	return c.subscribe(ctx, streams)
+	return c.subscribe(ctx, streamNames(symbols, markPriceStream))
 }
 
 func (c *Client) UnsubscribeFromTrades(ctx context.Context, symbols []string) error {
-	streams := make([]string, len(symbols))
-	for i, symbol := range symbols {
-		streams[i] = fmt.Sprintf("%s@trade", strings.ToLower(symbol))
+	return c.unsubscribe(ctx, streamNames(symbols, "trade"))
+}
+
+func (c *Client) UnsubscribeFromAggTrades(ctx context.Context, symbols []string) error {
+	return c.unsubscribe(ctx, streamNames(symbols, "aggTrade"))
+}
+
+func (c *Client) UnsubscribeFromKlines(ctx context.Context, symbols []string, intervals []entities.KlineInterval) error {
+	return c.unsubscribe(ctx, klineStreamNames(symbols, intervals))
+}
+
+func (c *Client) UnsubscribeFromDepth(ctx context.Context, symbols []string) error {
+	if err := c.spotOnly("diff depth"); err != nil {
+		return err
 	}
-	return c.unsubscribe(ctx, streams)
+	return c.unsubscribe(ctx, streamNames(symbols, depthStream))
 }
 
 func (c *Client) UnsubscribeFromBookTickers(ctx context.Context, symbols []string) error {
-	streams := make([]string, len(symbols))
-	for i, symbol := range symbols {
-		streams[i] = fmt.Sprintf("%s@bookTicker", strings.ToLower(symbol))
+	return c.unsubscribe(ctx, streamNames(symbols, "bookTicker"))
+}
+
+func (c *Client) UnsubscribeFromMarkPrices(ctx context.Context, symbols []string) error {
+	if err := c.futuresOnly("mark prices"); err != nil {
+		return err
 	}
-	return c.unsubscribe(ctx, streams)
+	return c.unsubscribe(ctx, streamNames(symbols, markPriceStream))
 }
 
 func (c *Client) Close() error {
+	metrics.StreamsPerConnection.DeletePartialMatch(prometheus.Labels{
+		"exchange": string(entities.ExchangeBinance),
+		"market":   string(c.market),
+	})
+
+	c.requests.FailAll(errClientClosed)
 	return c.wsManager.CloseAll()
 }
 
+// PendingRequests returns the number of SUBSCRIBE and UNSUBSCRIBE requests
+// that have not been answered yet.
+func (c *Client) PendingRequests() int {
+	return c.requests.Pending()
+}
+
+// handleMessage completes the request a response answers and hands every
+// other frame on to the message handler.
+func (c *Client) handleMessage(message []byte) error {
+	if !isResponse(message) {
+		return c.messageHandler(message)
+	}
+
+	var resp dto.SubscriptionResponse
+	if err := json.Unmarshal(message, &resp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var err error
+	if resp.Error != nil {
+		err = &RequestError{Code: resp.Error.Code, Msg: resp.Error.Msg}
+	}
+	if !c.requests.Resolve(strconv.Itoa(resp.ID), err) {
+		c.logger.Warn("Response to unknown request", "id", resp.ID, "error", err)
+	}
+	return nil
+}
+
+// isResponse reports whether a frame answers a request rather than carrying
+// an event. Responses are {"result":...,"id":N} or {"error":...,"id":N}.
+func isResponse(message []byte) bool {
+	return bytes.HasPrefix(message, []byte(`{"result"`)) || bytes.HasPrefix(message, []byte(`{"error"`))
+}
+
+func (c *Client) spotOnly(stream string) error {
+	if c.market.IsFutures() {
+		return fmt.Errorf("binance %s %s: %w", c.market, stream, services.ErrStreamNotSupported)
+	}
+	return nil
+}
+
+func (c *Client) futuresOnly(stream string) error {
+	if !c.market.IsFutures() {
+		return fmt.Errorf("binance %s %s: %w", c.market, stream, services.ErrStreamNotSupported)
+	}
+	return nil
+}
+
 func (c *Client) subscribe(ctx context.Context, streams []string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.dryRun {
+		c.plan(streams)
+		return nil
+	}
+	if c.mode == StreamModeCombined {
+		return c.connectCombined(ctx, streams)
+	}
+
 	// Group streams by connection
 	connectionStreams := make(map[string][]string)
 	
@@ -98,25 +333,41 @@ func (c *Client) subscribe(ctx context.Context, streams []string) error {
 		c.subscriptions[stream] = connID
 	}
 
-	// Subscribe on each connection
-	for connID, connStreams := range connectionStreams {
-		if err := c.subscribeOnConnection(connID, connStreams); err != nil {
-			// Clean up subscriptions on error
-			for _, stream := range connStreams {
+	// Subscribe on every connection, even after one failed, so no stream
+	// stays assigned without its request being sent
+	connIDs := make([]string, 0, len(connectionStreams))
+	for connID := range connectionStreams {
+		connIDs = append(connIDs, connID)
+	}
+	sort.Strings(connIDs)
+
+	var errs []error
+	for _, connID := range connIDs {
+		connStreams := connectionStreams[connID]
+		subscribed, err := c.subscribeOnConnection(ctx, connID, connStreams)
+		if err != nil {
+			// Only acknowledged streams stay assigned
+			for _, stream := range connStreams[subscribed:] {
 				delete(c.subscriptions, stream)
 			}
-			return fmt.Errorf("failed to subscribe on connection %s: %w", connID, err)
+			errs = append(errs, fmt.Errorf("failed to subscribe on connection %s: %w", connID, err))
 		}
-		c.streamCount.Add(int32(len(connStreams)))
+		c.streamCount.Add(int32(subscribed))
 	}
+	c.reportStreams(connectionStreams)
 
-	return nil
+	return errors.Join(errs...)
 }
 
 func (c *Client) unsubscribe(ctx context.Context, streams []string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.dryRun {
+		c.unplan(streams)
+		return nil
+	}
+
 	// Group streams by connection
 	connectionStreams := make(map[string][]string)
 	
@@ -126,9 +377,14 @@ func (c *Client) unsubscribe(ctx context.Context, streams []string) error {
 		}
 	}
 
+	if c.mode == StreamModeCombined {
+		c.unsubscribeCombined(ctx, connectionStreams)
+		return nil
+	}
+
 	// Unsubscribe on each connection
 	for connID, connStreams := range connectionStreams {
-		if err := c.unsubscribeOnConnection(connID, connStreams); err != nil {
+		if err := c.unsubscribeOnConnection(ctx, connID, connStreams); err != nil {
 			c.logger.Error("Failed to unsubscribe", "connection", connID, "error", err)
 			continue
 		}
@@ -139,10 +395,58 @@ func (c *Client) unsubscribe(ctx context.Context, streams []string) error {
 		}
 		c.streamCount.Add(-int32(len(connStreams)))
 	}
+	c.reportStreams(connectionStreams)
 
 	return nil
 }
 
+// resubscribe replays SUBSCRIBE requests for every stream owned by a
+// connection after the websocket manager has re-dialed it.
+func (c *Client) resubscribe(connID string) error {
+	// Requests sent on the old connection will never be answered, let their
+	// callers retry. This has to happen before taking the lock they may hold
+	c.requests.FailConnection(connID, websocket.ErrConnectionReset)
+
+	if c.mode == StreamModeCombined {
+		// The streams are part of the connection URL
+		return nil
+	}
+
+	c.mu.RLock()
+	streams := make([]string, 0)
+	for stream, id := range c.subscriptions {
+		if id == connID {
+			streams = append(streams, stream)
+		}
+	}
+	c.mu.RUnlock()
+
+	if len(streams) == 0 {
+		return nil
+	}
+	sort.Strings(streams)
+
+	c.logger.Info("Resubscribing streams after reconnect", "connection", connID, "count", len(streams))
+	_, err := c.subscribeOnConnection(context.Background(), connID, streams)
+	return err
+}
+
+// reportStreams publishes the stream count of every connection touched by a
+// subscribe or unsubscribe. Must be called with c.mu held.
+func (c *Client) reportStreams(connectionStreams map[string][]string) {
+	for connID := range connectionStreams {
+		count := 0
+		for _, id := range c.subscriptions {
+			if id == connID {
+				count++
+			}
+		}
+		metrics.StreamsPerConnection.
+			WithLabelValues(string(entities.ExchangeBinance), string(c.market), connID).
+			Set(float64(count))
+	}
+}
+
 func (c *Client) findOrCreateConnection(ctx context.Context) string {
 	// Count streams per connection
 	streamCounts := make(map[string]int)
@@ -152,7 +456,7 @@ func (c *Client) findOrCreateConnection(ctx context.Context) string {
 
 	// Find connection with available capacity
 	for connID, count := range streamCounts {
-		if count < maxStreamsPerConnection {
+		if count < c.maxStreams {
 			return connID
 		}
 	}
@@ -172,7 +476,88 @@ func (c *Client) findOrCreateConnection(ctx context.Context) string {
 	return connID
 }
 
-func (c *Client) subscribeOnConnection(connID string, streams []string) error {
+// plan assigns streams to connections without opening any. Must be called
+// with c.mu held.
+func (c *Client) plan(streams []string) {
+	streamCounts := make(map[string]int)
+	for _, connID := range c.subscriptions {
+		streamCounts[connID]++
+	}
+
+	connID := ""
+	for _, stream := range streams {
+		if _, exists := c.subscriptions[stream]; exists {
+			continue
+		}
+		if connID == "" || streamCounts[connID] >= c.maxStreams {
+			connID = c.plannedConnection(streamCounts)
+		}
+		c.subscriptions[stream] = connID
+		c.planned = append(c.planned, stream)
+		streamCounts[connID]++
+	}
+}
+
+// plannedConnection picks the connection for the next planned stream: in
+// subscribe mode the first one with room, in combined mode always a new one
+// since its streams are part of the URL.
+func (c *Client) plannedConnection(streamCounts map[string]int) string {
+	if c.mode == StreamModeSubscribe {
+		for i := int32(1); i <= c.connectionID.Load(); i++ {
+			connID := fmt.Sprintf("conn-%d", i)
+			if count, ok := streamCounts[connID]; ok && count < c.maxStreams {
+				return connID
+			}
+		}
+	}
+	return fmt.Sprintf("conn-%d", c.connectionID.Add(1))
+}
+
+// unplan drops streams from their planned connections. Must be called with
+// c.mu held.
+func (c *Client) unplan(streams []string) {
+	for _, stream := range streams {
+		delete(c.subscriptions, stream)
+	}
+	planned := c.planned[:0]
+	for _, stream := range c.planned {
+		if _, exists := c.subscriptions[stream]; exists {
+			planned = append(planned, stream)
+		}
+	}
+	c.planned = planned
+}
+
+// Connections returns the connections a dry run client would have opened, in
+// the order it would have opened them.
+func (c *Client) Connections() []services.PlannedConnection {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var connections []services.PlannedConnection
+	index := make(map[string]int)
+	for _, stream := range c.planned {
+		connID := c.subscriptions[stream]
+		i, ok := index[connID]
+		if !ok {
+			i = len(connections)
+			index[connID] = i
+			connections = append(connections, services.PlannedConnection{ID: connID, URL: c.wsURL})
+		}
+		connections[i].Streams = append(connections[i].Streams, stream)
+	}
+
+	if c.mode == StreamModeCombined {
+		for i := range connections {
+			connections[i].URL = c.combinedStreamURL(connections[i].Streams)
+		}
+	}
+	return connections
+}
+
+// subscribeOnConnection subscribes streams in batches and returns how many of
+// them, from the first, were acknowledged.
+func (c *Client) subscribeOnConnection(ctx context.Context, connID string, streams []string) (int, error) {
 	// Split into batches if needed
 	for i := 0; i < len(streams); i += maxSubscriptionsPerRequest {
 		end := i + maxSubscriptionsPerRequest
@@ -181,50 +566,174 @@ func (c *Client) subscribeOnConnection(connID string, streams []string) error {
 		}
 
 		batch := streams[i:end]
-		req := dto.SubscriptionRequest{
-			Method: "SUBSCRIBE",
-			Params: batch,
-			ID:     1,
+		if err := c.request(ctx, connID, "SUBSCRIBE", batch); err != nil {
+			return i, err
 		}
 
-		data, err := json.Marshal(req)
-		if err != nil {
-			return fmt.Errorf("failed to marshal subscribe request: %w", err)
-		}
+		c.logger.Info("Subscribed to streams", "connection", connID, "count", len(batch))
+	}
 
-		if err := c.wsManager.Send(connID, data); err != nil {
-			return fmt.Errorf("failed to send subscribe request: %w", err)
-		}
+	return len(streams), nil
+}
 
-		c.logger.Info("Subscribed to streams", "connection", connID, "count", len(batch))
+func (c *Client) unsubscribeOnConnection(ctx context.Context, connID string, streams []string) error {
+	if err := c.request(ctx, connID, "UNSUBSCRIBE", streams); err != nil {
+		return err
 	}
 
+	c.logger.Info("Unsubscribed from streams", "connection", connID, "count", len(streams))
 	return nil
 }
 
-func (c *Client) unsubscribeOnConnection(connID string, streams []string) error {
+// request sends a SUBSCRIBE or UNSUBSCRIBE request and waits for Binance to
+// answer it. Requests that time out or lose their connection are sent again.
+func (c *Client) request(ctx context.Context, connID, method string, streams []string) error {
+	return websocket.Retry(ctx, maxRequestAttempts, c.retryDelay, func() error {
+		err := c.requestOnce(ctx, connID, method, streams)
+		if err != nil && websocket.IsTransient(err) {
+			c.logger.Warn("Request failed, retrying", "method", method, "connection", connID, "error", err)
+		}
+		return err
+	})
+}
+
+func (c *Client) requestOnce(ctx context.Context, connID, method string, streams []string) error {
 	req := dto.SubscriptionRequest{
-		Method: "UNSUBSCRIBE",
+		Method: method,
 		Params: streams,
-		ID:     1,
+		ID:     int(c.requestID.Add(1)),
 	}
 
 	data, err := json.Marshal(req)
 	if err != nil {
-		return fmt.Errorf("failed to marshal unsubscribe request: %w", err)
+		return fmt.Errorf("failed to marshal %s request: %w", strings.ToLower(method), err)
 	}
 
-	if err := c.wsManager.Send(connID, data); err != nil {
-		return fmt.Errorf("failed to send unsubscribe request: %w", err)
+	id := strconv.Itoa(req.ID)
+	result := c.requests.Add(id, connID)
+	if err := c.wsManager.Send(ctx, connID, data); err != nil {
+		c.requests.Remove(id)
+		return fmt.Errorf("%w %d on %s: %w", websocket.ErrSendFailed, req.ID, connID, err)
 	}
 
-	c.logger.Info("Unsubscribed from streams", "connection", connID, "count", len(streams))
+	if err := c.requests.Await(ctx, id, result, c.requestTimeout); err != nil {
+		return fmt.Errorf("%s request %d on %s: %w", method, req.ID, connID, err)
+	}
 	return nil
 }
 
+// streamNames builds <symbol>@<kind> stream names, e.g. btcusdt@aggTrade.
+func streamNames(symbols []string, kind string) []string {
+	streams := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		streams[i] = fmt.Sprintf("%s@%s", strings.ToLower(symbol), kind)
+	}
+	return streams
+}
+
+// klineStreamNames builds one <symbol>@kline_<interval> stream per symbol and
+// interval, e.g. btcusdt@kline_1m.
+func klineStreamNames(symbols []string, intervals []entities.KlineInterval) []string {
+	streams := make([]string, 0, len(symbols)*len(intervals))
+	for _, interval := range intervals {
+		streams = append(streams, streamNames(symbols, "kline_"+string(interval))...)
+	}
+	return streams
+}
+
 func (c *Client) getWebSocketURL() string {
-	if c.useTestnet {
-		return testnetWSURL
+	return c.wsURL
+}
+
+// combinedStreamURL lists streams in a combined stream URL, e.g.
+// wss://stream.binance.com:443/stream?streams=btcusdt@trade/ethusdt@trade.
+func (c *Client) combinedStreamURL(streams []string) string {
+	return strings.TrimSuffix(c.wsURL, "/ws") + "/stream?streams=" + strings.Join(streams, "/")
+}
+
+// connectCombined opens new connections for the streams that are not
+// collected yet, each listing up to maxStreams streams in its URL. Must be
+// called with c.mu held.
+func (c *Client) connectCombined(ctx context.Context, streams []string) error {
+	pending := make([]string, 0, len(streams))
+	for _, stream := range streams {
+		if _, exists := c.subscriptions[stream]; exists {
+			c.logger.Debug("Stream already subscribed", "stream", stream)
+			continue
+		}
+		pending = append(pending, stream)
+	}
+
+	connectionStreams := make(map[string][]string)
+	defer c.reportStreams(connectionStreams)
+
+	for i := 0; i < len(pending); i += c.maxStreams {
+		batch := pending[i:min(i+c.maxStreams, len(pending))]
+
+		connID := fmt.Sprintf("conn-%d", c.connectionID.Add(1))
+		if err := c.wsManager.Connect(ctx, c.combinedStreamURL(batch), connID); err != nil {
+			c.closeCombined(connectionStreams)
+			return fmt.Errorf("failed to open combined stream connection %s: %w", connID, err)
+		}
+
+		for _, stream := range batch {
+			c.subscriptions[stream] = connID
+		}
+		connectionStreams[connID] = batch
+		c.streamCount.Add(int32(len(batch)))
+		c.logger.Info("Opened combined stream connection", "connection", connID, "count", len(batch))
+	}
+
+	return nil
+}
+
+// closeCombined closes combined stream connections together with their
+// streams. Must be called with c.mu held.
+func (c *Client) closeCombined(connectionStreams map[string][]string) {
+	for connID, streams := range connectionStreams {
+		if err := c.wsManager.Close(connID); err != nil {
+			c.logger.Error("Failed to close combined stream connection", "connection", connID, "error", err)
+		}
+		for _, stream := range streams {
+			delete(c.subscriptions, stream)
+		}
+		c.streamCount.Add(-int32(len(streams)))
+		c.logger.Info("Closed combined stream connection", "connection", connID)
+	}
+}
+
+// unsubscribeCombined drops streams from combined stream connections. A
+// connection keeping other streams gets an UNSUBSCRIBE request, so they do
+// not miss any data, and is dialed with only those streams from then on. A
+// connection left without streams is closed. Must be called with c.mu held.
+func (c *Client) unsubscribeCombined(ctx context.Context, connectionStreams map[string][]string) {
+	empty := make(map[string][]string)
+	for connID, removed := range connectionStreams {
+		remaining := make([]string, 0)
+		for stream, id := range c.subscriptions {
+			if id == connID && !slices.Contains(removed, stream) {
+				remaining = append(remaining, stream)
+			}
+		}
+		if len(remaining) == 0 {
+			empty[connID] = removed
+			continue
+		}
+		sort.Strings(remaining)
+
+		if err := c.unsubscribeOnConnection(ctx, connID, removed); err != nil {
+			c.logger.Error("Failed to unsubscribe", "connection", connID, "error", err)
+			continue
+		}
+		for _, stream := range removed {
+			delete(c.subscriptions, stream)
+		}
+		c.streamCount.Add(-int32(len(removed)))
+
+		if err := c.wsManager.SetURL(connID, c.combinedStreamURL(remaining)); err != nil {
+			c.logger.Error("Failed to update combined stream URL", "connection", connID, "error", err)
+		}
 	}
-	return baseWSURL
+	c.closeCombined(empty)
+	c.reportStreams(connectionStreams)
 }
\ No newline at end of file
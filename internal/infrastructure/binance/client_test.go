@@ -0,0 +1,520 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/application/dto"
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/services"
+	"alarket/internal/infrastructure/metrics"
+	wsmanager "alarket/internal/infrastructure/websocket"
+)
+
+func TestClient_ResubscribesAfterConnectionDrop(t *testing.T) {
+	subscribed := make(chan []string, 10)
+	var connections atomic.Int32
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		n := connections.Add(1)
+
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var req dto.SubscriptionRequest
+			if err := json.Unmarshal(msg, &req); err != nil || req.Method != "SUBSCRIBE" {
+				continue
+			}
+			subscribed <- req.Params
+
+			resp := fmt.Sprintf(`{"result":null,"id":%d}`, req.ID)
+			trade := `{"e":"trade","s":"BTCUSDT","t":1,"p":"1","q":"1"}`
+			for _, frame := range []string{resp, trade} {
+				if err := conn.WriteMessage(websocket.TextMessage, []byte(frame)); err != nil {
+					return
+				}
+			}
+
+			if n == 1 {
+				// Simulate the exchange dropping the first connection
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	var messages atomic.Int32
+	client := NewClient(slog.Default(), entities.MarketSpot, false, func(message []byte) error {
+		messages.Add(1)
+		return nil
+	})
+	client.wsURL = "ws" + strings.TrimPrefix(server.URL, "http")
+	defer func() { _ = client.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, client.SubscribeToTrades(ctx, []string{"BTCUSDT", "ETHUSDT"}))
+
+	select {
+	case params := <-subscribed:
+		assert.ElementsMatch(t, []string{"btcusdt@trade", "ethusdt@trade"}, params)
+	case <-time.After(5 * time.Second):
+		t.Fatal("initial subscription not received")
+	}
+
+	select {
+	case params := <-subscribed:
+		assert.ElementsMatch(t, []string{"btcusdt@trade", "ethusdt@trade"}, params)
+	case <-time.After(10 * time.Second):
+		t.Fatal("streams were not resubscribed after reconnect")
+	}
+
+	assert.Eventually(t, func() bool {
+		return messages.Load() >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(2), connections.Load())
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.StreamsPerConnection.WithLabelValues("binance", "spot", "conn-1")))
+
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+	assert.Equal(t, "conn-1", client.subscriptions["btcusdt@trade"])
+	assert.Equal(t, "conn-1", client.subscriptions["ethusdt@trade"])
+}
+
+// newRequestServer starts a server that hands every request to answer and
+// writes back the response it returns, if any.
+func newRequestServer(t *testing.T, answer func(req dto.SubscriptionRequest) string) string {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var req dto.SubscriptionRequest
+			if err := json.Unmarshal(msg, &req); err != nil {
+				continue
+			}
+			if resp := answer(req); resp != "" {
+				if err := conn.WriteMessage(websocket.TextMessage, []byte(resp)); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestClient_CorrelatesResponses(t *testing.T) {
+	requests := make(chan dto.SubscriptionRequest, 10)
+	url := newRequestServer(t, func(req dto.SubscriptionRequest) string {
+		requests <- req
+		return fmt.Sprintf(`{"result":null,"id":%d}`, req.ID)
+	})
+
+	var forwarded atomic.Int32
+	client := NewClient(slog.Default(), entities.MarketSpot, false, func(message []byte) error {
+		forwarded.Add(1)
+		return nil
+	})
+	client.wsURL = url
+	defer func() { _ = client.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, client.SubscribeToTrades(ctx, []string{"BTCUSDT"}))
+	require.NoError(t, client.SubscribeToBookTickers(ctx, []string{"BTCUSDT"}))
+
+	first, second := <-requests, <-requests
+	assert.NotEqual(t, first.ID, second.ID, "every request gets its own ID")
+	assert.Equal(t, 0, client.PendingRequests())
+	assert.Equal(t, int32(0), forwarded.Load(), "responses are not handed to the message handler")
+}
+
+func TestClient_SurfacesRejectedSubscriptions(t *testing.T) {
+	url := newRequestServer(t, func(req dto.SubscriptionRequest) string {
+		return fmt.Sprintf(`{"error":{"code":2,"msg":"Invalid request: unknown stream"},"id":%d}`, req.ID)
+	})
+
+	client := NewClient(slog.Default(), entities.MarketSpot, false, func(message []byte) error { return nil })
+	client.wsURL = url
+	defer func() { _ = client.Close() }()
+
+	err := client.SubscribeToTrades(context.Background(), []string{"NOPE"})
+	require.Error(t, err)
+
+	var rejected *RequestError
+	require.ErrorAs(t, err, &rejected)
+	assert.Equal(t, 2, rejected.Code)
+	assert.Equal(t, "Invalid request: unknown stream", rejected.Msg)
+	assert.Empty(t, client.subscriptions, "rejected streams are not kept")
+	assert.Equal(t, 0, client.PendingRequests())
+}
+
+func TestClient_SubscribesOnEveryConnectionAfterAFailure(t *testing.T) {
+	var received atomic.Int32
+	url := newRequestServer(t, func(req dto.SubscriptionRequest) string {
+		received.Add(1)
+		if req.Params[0] == "ethusdt@trade" {
+			return fmt.Sprintf(`{"error":{"code":2,"msg":"Invalid request: unknown stream"},"id":%d}`, req.ID)
+		}
+		return fmt.Sprintf(`{"result":null,"id":%d}`, req.ID)
+	})
+
+	client := NewClient(slog.Default(), entities.MarketSpot, false, func(message []byte) error { return nil })
+	client.wsURL = url
+	client.maxStreams = 1
+	defer func() { _ = client.Close() }()
+
+	err := client.SubscribeToTrades(context.Background(), []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"})
+
+	var rejected *RequestError
+	require.ErrorAs(t, err, &rejected)
+	assert.Equal(t, int32(3), received.Load(), "every connection is attempted")
+	assert.ElementsMatch(t, []string{"btcusdt@trade", "solusdt@trade"}, streamsOf(client), "only acknowledged streams are kept")
+	assert.Equal(t, int32(2), client.streamCount.Load())
+}
+
+// streamsOf returns the streams the client believes are subscribed.
+func streamsOf(client *Client) []string {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+
+	streams := make([]string, 0, len(client.subscriptions))
+	for stream := range client.subscriptions {
+		streams = append(streams, stream)
+	}
+	return streams
+}
+
+func TestClient_RetriesUnansweredRequests(t *testing.T) {
+	requests := make(chan dto.SubscriptionRequest, 10)
+	var received atomic.Int32
+	url := newRequestServer(t, func(req dto.SubscriptionRequest) string {
+		requests <- req
+		if received.Add(1) == 1 {
+			return "" // lose the first request
+		}
+		return fmt.Sprintf(`{"result":null,"id":%d}`, req.ID)
+	})
+
+	client := NewClient(slog.Default(), entities.MarketSpot, false, func(message []byte) error { return nil })
+	client.wsURL = url
+	client.requestTimeout = 100 * time.Millisecond
+	client.retryDelay = 10 * time.Millisecond
+	defer func() { _ = client.Close() }()
+
+	require.NoError(t, client.SubscribeToTrades(context.Background(), []string{"BTCUSDT"}))
+
+	first, second := <-requests, <-requests
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, first.Params, second.Params)
+	assert.Equal(t, 0, client.PendingRequests())
+}
+
+func TestClient_GivesUpAfterRepeatedTimeouts(t *testing.T) {
+	var received atomic.Int32
+	url := newRequestServer(t, func(req dto.SubscriptionRequest) string {
+		received.Add(1)
+		return ""
+	})
+
+	client := NewClient(slog.Default(), entities.MarketSpot, false, func(message []byte) error { return nil })
+	client.wsURL = url
+	client.requestTimeout = 50 * time.Millisecond
+	client.retryDelay = 10 * time.Millisecond
+	defer func() { _ = client.Close() }()
+
+	err := client.SubscribeToTrades(context.Background(), []string{"BTCUSDT"})
+	require.ErrorIs(t, err, wsmanager.ErrRequestTimeout)
+	assert.Equal(t, int32(maxRequestAttempts), received.Load())
+	assert.Empty(t, client.subscriptions)
+}
+
+func TestClient_PacesRequests(t *testing.T) {
+	received := make(chan time.Time, 10)
+	url := newRequestServer(t, func(req dto.SubscriptionRequest) string {
+		received <- time.Now()
+		return fmt.Sprintf(`{"result":null,"id":%d}`, req.ID)
+	})
+
+	client := NewClient(slog.Default(), entities.MarketSpot, false, func(message []byte) error { return nil })
+	client.wsURL = url
+	client.SetRateLimit(wsmanager.RateLimit{PerSecond: 10, Burst: 1})
+	defer func() { _ = client.Close() }()
+
+	symbols := make([]string, 250)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYM%dUSDT", i)
+	}
+	require.NoError(t, client.SubscribeToTrades(context.Background(), symbols))
+
+	// 250 streams take three requests, 100 ms apart
+	first, second, third := <-received, <-received, <-received
+	assert.GreaterOrEqual(t, second.Sub(first), 90*time.Millisecond)
+	assert.GreaterOrEqual(t, third.Sub(second), 90*time.Millisecond)
+}
+
+func TestClient_CombinedStreams(t *testing.T) {
+	connected := make(chan []string, 10)
+	unsubscribed := make(chan []string, 10)
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stream" {
+			http.NotFound(w, r)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		streams := strings.Split(r.URL.Query().Get("streams"), "/")
+		connected <- streams
+
+		frame := fmt.Sprintf(`{"stream":%q,"data":{"e":"trade","s":"BTCUSDT","t":1,"p":"1","q":"1"}}`, streams[0])
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(frame)); err != nil {
+			return
+		}
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var req dto.SubscriptionRequest
+			if err := json.Unmarshal(msg, &req); err != nil {
+				continue
+			}
+			if req.Method != "UNSUBSCRIBE" {
+				t.Errorf("unexpected %s request", req.Method)
+				continue
+			}
+			unsubscribed <- req.Params
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"result":null,"id":%d}`, req.ID))); err != nil {
+				return
+			}
+			// Drop the connection to see which streams it is dialed with next
+			return
+		}
+	}))
+	defer server.Close()
+
+	forwarded := make(chan string, 10)
+	client := NewClient(slog.Default(), entities.MarketSpot, false, func(message []byte) error {
+		forwarded <- string(message)
+		return nil
+	})
+	client.wsURL = "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	client.maxStreams = 2
+	client.SetStreamMode(StreamModeCombined)
+	defer func() { _ = client.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, client.SubscribeToTrades(ctx, []string{"BTCUSDT", "ETHUSDT", "BNBUSDT"}))
+
+	// Streams are split over connections by maxStreams, no requests are sent
+	assert.Equal(t, []string{"btcusdt@trade", "ethusdt@trade"}, <-connected)
+	assert.Equal(t, []string{"bnbusdt@trade"}, <-connected)
+	assert.Equal(t, int32(3), client.streamCount.Load())
+
+	// Payloads are handed on still wrapped, the event handler unwraps them
+	assert.Contains(t, <-forwarded, `{"stream":"`)
+
+	// Removing a stream unsubscribes it on its connection, which keeps
+	// streaming the others; removing the last one closes the connection
+	require.NoError(t, client.UnsubscribeFromTrades(ctx, []string{"ETHUSDT", "BNBUSDT"}))
+	assert.Equal(t, []string{"ethusdt@trade"}, <-unsubscribed)
+	assert.Equal(t, int32(1), client.streamCount.Load())
+	client.mu.RLock()
+	assert.Equal(t, map[string]string{"btcusdt@trade": "conn-1"}, client.subscriptions)
+	client.mu.RUnlock()
+
+	// Once dropped, the connection is dialed with the remaining streams only
+	select {
+	case streams := <-connected:
+		assert.Equal(t, []string{"btcusdt@trade"}, streams)
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection was not re-established")
+	}
+	assert.Equal(t, 0, client.PendingRequests())
+}
+
+func TestClient_CombinedStreamsClosesConnectionsOnFailure(t *testing.T) {
+	closed := make(chan []string, 10)
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		streams := strings.Split(r.URL.Query().Get("streams"), "/")
+		if streams[0] == "bnbusdt@trade" {
+			http.Error(w, "too many connections", http.StatusTooManyRequests)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				closed <- streams
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(slog.Default(), entities.MarketSpot, false, func(message []byte) error { return nil })
+	client.wsURL = "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	client.maxStreams = 2
+	client.SetStreamMode(StreamModeCombined)
+	defer func() { _ = client.Close() }()
+
+	err := client.SubscribeToTrades(context.Background(), []string{"BTCUSDT", "ETHUSDT", "BNBUSDT"})
+	require.Error(t, err)
+
+	// The connection opened before the failure is closed again
+	select {
+	case streams := <-closed:
+		assert.Equal(t, []string{"btcusdt@trade", "ethusdt@trade"}, streams)
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection opened before the failure was left open")
+	}
+	assert.Equal(t, int32(0), client.streamCount.Load())
+	client.mu.RLock()
+	assert.Empty(t, client.subscriptions)
+	client.mu.RUnlock()
+}
+
+func TestParseStreamMode(t *testing.T) {
+	mode, err := ParseStreamMode("combined")
+	require.NoError(t, err)
+	assert.Equal(t, StreamModeCombined, mode)
+
+	_, err = ParseStreamMode("")
+	assert.Error(t, err)
+}
+
+func TestStreamNames(t *testing.T) {
+	assert.Equal(t, []string{"btcusdt@trade", "ethusdt@trade"}, streamNames([]string{"BTCUSDT", "ETHUSDT"}, "trade"))
+	assert.Equal(t, []string{"btcusdt@aggTrade"}, streamNames([]string{"BTCUSDT"}, "aggTrade"))
+	assert.Equal(t, []string{"btcusdt@bookTicker"}, streamNames([]string{"btcusdt"}, "bookTicker"))
+	assert.Equal(t, []string{"btcusdt@depth@100ms"}, streamNames([]string{"BTCUSDT"}, depthStream))
+	assert.Equal(t, []string{"btcusdt@markPrice@1s"}, streamNames([]string{"BTCUSDT"}, markPriceStream))
+}
+
+func TestNewClient_SelectsMarketEndpoint(t *testing.T) {
+	handler := func(message []byte) error { return nil }
+
+	spot := NewClient(slog.Default(), entities.MarketSpot, false, handler)
+	assert.Equal(t, "wss://stream.binance.com:443/ws", spot.wsURL)
+	assert.Equal(t, 1022, spot.maxStreams)
+
+	usdm := NewClient(slog.Default(), entities.MarketUSDM, false, handler)
+	assert.Equal(t, "wss://fstream.binance.com/ws", usdm.wsURL)
+	assert.Equal(t, 200, usdm.maxStreams)
+
+	coinm := NewClient(slog.Default(), entities.MarketCoinM, true, handler)
+	assert.Equal(t, "wss://dstream.binancefuture.com/ws", coinm.wsURL)
+	assert.Equal(t, 200, coinm.maxStreams)
+}
+
+func TestClient_MarketOnlyStreams(t *testing.T) {
+	ctx := context.Background()
+	handler := func(message []byte) error { return nil }
+	symbols := []string{"BTCUSDT"}
+
+	spot := NewClient(slog.Default(), entities.MarketSpot, false, handler)
+	assert.ErrorIs(t, spot.SubscribeToMarkPrices(ctx, symbols), services.ErrStreamNotSupported)
+	assert.ErrorIs(t, spot.UnsubscribeFromMarkPrices(ctx, symbols), services.ErrStreamNotSupported)
+
+	futures := NewClient(slog.Default(), entities.MarketUSDM, false, handler)
+	assert.ErrorIs(t, futures.SubscribeToDepth(ctx, symbols), services.ErrStreamNotSupported)
+	assert.Empty(t, futures.subscriptions)
+
+	// Aggregate trades and klines are stored with their market
+	planned := NewDryRunClient(slog.Default(), entities.MarketUSDM, false, StreamModeSubscribe)
+	require.NoError(t, planned.SubscribeToAggTrades(ctx, symbols))
+	require.NoError(t, planned.SubscribeToKlines(ctx, symbols, []entities.KlineInterval{"1m"}))
+	require.Len(t, planned.Connections(), 1)
+	assert.Equal(t, []string{"btcusdt@aggTrade", "btcusdt@kline_1m"}, planned.Connections()[0].Streams)
+}
+
+func TestKlineStreamNames(t *testing.T) {
+	streams := klineStreamNames([]string{"BTCUSDT", "ETHUSDT"}, []entities.KlineInterval{"1m", "1h"})
+	assert.Equal(t, []string{
+		"btcusdt@kline_1m", "ethusdt@kline_1m",
+		"btcusdt@kline_1h", "ethusdt@kline_1h",
+	}, streams)
+}
+
+func TestDryRunClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("subscribe mode fills connections", func(t *testing.T) {
+		client := NewDryRunClient(slog.Default(), entities.MarketSpot, false, StreamModeSubscribe)
+		client.maxStreams = 2
+
+		require.NoError(t, client.SubscribeToTrades(ctx, []string{"BTCUSDT", "ETHUSDT", "BNBUSDT"}))
+		require.NoError(t, client.UnsubscribeFromTrades(ctx, []string{"ETHUSDT"}))
+		require.NoError(t, client.SubscribeToBookTickers(ctx, []string{"BTCUSDT"}))
+
+		assert.Equal(t, []services.PlannedConnection{
+			{ID: "conn-1", URL: "wss://stream.binance.com:443/ws", Streams: []string{"btcusdt@trade", "btcusdt@bookTicker"}},
+			{ID: "conn-2", URL: "wss://stream.binance.com:443/ws", Streams: []string{"bnbusdt@trade"}},
+		}, client.Connections())
+	})
+
+	t.Run("combined mode opens connections per subscription", func(t *testing.T) {
+		client := NewDryRunClient(slog.Default(), entities.MarketSpot, false, StreamModeCombined)
+		client.maxStreams = 2
+
+		require.NoError(t, client.SubscribeToTrades(ctx, []string{"BTCUSDT"}))
+		require.NoError(t, client.SubscribeToBookTickers(ctx, []string{"BTCUSDT"}))
+
+		assert.Equal(t, []services.PlannedConnection{
+			{ID: "conn-1", URL: "wss://stream.binance.com:443/stream?streams=btcusdt@trade", Streams: []string{"btcusdt@trade"}},
+			{ID: "conn-2", URL: "wss://stream.binance.com:443/stream?streams=btcusdt@bookTicker", Streams: []string{"btcusdt@bookTicker"}},
+		}, client.Connections())
+	})
+
+	t.Run("market only streams are still refused", func(t *testing.T) {
+		client := NewDryRunClient(slog.Default(), entities.MarketUSDM, false, StreamModeSubscribe)
+
+		assert.ErrorIs(t, client.SubscribeToDepth(ctx, []string{"BTCUSDT"}), services.ErrStreamNotSupported)
+		assert.Empty(t, client.Connections())
+	})
+}
@@ -0,0 +1,84 @@
+package binance
+
+import (
+	"context"
+	"log/slog"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/services"
+	"alarket/internal/infrastructure/websocket"
+)
+
+// ClientConfig tunes the streaming clients a connector creates.
+type ClientConfig struct {
+	RateLimit websocket.RateLimit // Pace of SUBSCRIBE and UNSUBSCRIBE requests per connection
+	Mode      StreamMode
+}
+
+// Connector plugs one Binance market, spot or futures, into the collector.
+type Connector struct {
+	apiKey     string
+	secretKey  string
+	market     entities.Market
+	useTestnet bool
+	client     ClientConfig
+	logger     *slog.Logger
+}
+
+func NewConnector(
+	apiKey, secretKey string,
+	market entities.Market,
+	useTestnet bool,
+	client ClientConfig,
+	logger *slog.Logger,
+) *Connector {
+	return &Connector{
+		apiKey:     apiKey,
+		secretKey:  secretKey,
+		market:     market,
+		useTestnet: useTestnet,
+		client:     client,
+		logger:     logger.With("exchange", entities.ExchangeBinance, "market", market),
+	}
+}
+
+func (c *Connector) Exchange() entities.Exchange {
+	return entities.ExchangeBinance
+}
+
+func (c *Connector) Market() entities.Market {
+	return c.market
+}
+
+func (c *Connector) FetchSymbols(ctx context.Context) ([]*entities.Symbol, error) {
+	return NewSymbolFetcher(c.apiKey, c.secretKey, c.market, c.useTestnet, c.logger).FetchAllSymbols(ctx)
+}
+
+func (c *Connector) NewClient(handler func(message []byte) error) services.ExchangeClient {
+	client := NewClient(c.logger, c.market, c.useTestnet, handler)
+	client.SetRateLimit(c.client.RateLimit)
+	client.SetStreamMode(c.client.Mode)
+	return client
+}
+
+func (c *Connector) NewDryRunClient() services.DryRunClient {
+	return NewDryRunClient(c.logger, c.market, c.useTestnet, c.client.Mode)
+}
+
+func (c *Connector) Decoder() services.MessageDecoder {
+	return NewDecoder(c.market)
+}
+
+// HistoricalTrades returns nil for futures: trade gaps are only tracked for
+// spot, where /api/v3/historicalTrades serves trades by ID.
+func (c *Connector) HistoricalTrades() services.HistoricalDataService {
+	if c.market.IsFutures() {
+		return nil
+	}
+	return NewHistoricalTradesService(c.apiKey, c.secretKey, c.useTestnet, c.logger)
+}
+
+// Tickers returns the 24h ticker statistics of the market.
+func (c *Connector) Tickers() (services.TickerService, error) {
+	return NewTickerService(c.market, c.useTestnet, c.logger)
+}
@@ -0,0 +1,327 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"alarket/internal/application/dto"
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/services"
+)
+
+// Decoder turns raw stream frames into domain events tagged with the market
+// the stream belongs to.
+type Decoder struct {
+	market entities.Market
+}
+
+func NewDecoder(market entities.Market) services.MessageDecoder {
+	return &Decoder{market: market}
+}
+
+// Decode recognizes events by their "e" field. Spot book tickers carry no
+// event type and are told apart by their "u" update ID. Anything else, like
+// subscription responses, decodes to no events.
+func (d *Decoder) Decode(message []byte) ([]any, error) {
+	var baseEvent map[string]interface{}
+	if err := json.Unmarshal(message, &baseEvent); err != nil {
+		return nil, fmt.Errorf("failed to parse message: %w", err)
+	}
+
+	eventType, hasEventType := baseEvent["e"].(string)
+	if !hasEventType {
+		if _, isBookTicker := baseEvent["u"]; isBookTicker {
+			return decodeOne(message, "book ticker event", d.decodeBookTicker)
+		}
+		return nil, nil
+	}
+
+	switch eventType {
+	case "trade":
+		return decodeOne(message, "trade event", d.decodeTrade)
+	case "aggTrade":
+		return decodeOne(message, "aggregate trade event", d.decodeAggTrade)
+	case "kline":
+		return decodeOne(message, "kline event", d.decodeKline)
+	case "depthUpdate":
+		return decodeOne(message, "depth update event", decodeDepthUpdate)
+	case "bookTicker":
+		return decodeOne(message, "book ticker event", d.decodeBookTicker)
+	case "markPriceUpdate":
+		return decodeOne(message, "mark price event", d.decodeMarkPrice)
+	default:
+		return nil, nil
+	}
+}
+
+// DecodeStream decodes the payload of a combined stream frame by the stream
+// name, e.g. btcusdt@trade or btcusdt@kline_1m. Unknown streams fall back to
+// Decode.
+func (d *Decoder) DecodeStream(stream string, data []byte) ([]any, error) {
+	_, kind, _ := strings.Cut(stream, "@")
+	switch {
+	case kind == "trade":
+		return decodeOne(data, "trade event", d.decodeTrade)
+	case kind == "aggTrade":
+		return decodeOne(data, "aggregate trade event", d.decodeAggTrade)
+	case strings.HasPrefix(kind, "kline_"):
+		return decodeOne(data, "kline event", d.decodeKline)
+	case strings.HasPrefix(kind, "depth"):
+		return decodeOne(data, "depth update event", decodeDepthUpdate)
+	case kind == "bookTicker":
+		return decodeOne(data, "book ticker event", d.decodeBookTicker)
+	case strings.HasPrefix(kind, "markPrice"):
+		return decodeOne(data, "mark price event", d.decodeMarkPrice)
+	default:
+		return d.Decode(data)
+	}
+}
+
+func decodeOne[T any, E any](message []byte, kind string, decode func(T) (E, error)) ([]any, error) {
+	var event T
+	if err := json.Unmarshal(message, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", kind, err)
+	}
+
+	result, err := decode(event)
+	if err != nil {
+		return nil, err
+	}
+	return []any{result}, nil
+}
+
+func (d *Decoder) decodeTrade(event dto.TradeEventDTO) (*entities.Trade, error) {
+	price, err := decimal.NewFromString(event.Price)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price: %w", err)
+	}
+
+	quantity, err := decimal.NewFromString(event.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity: %w", err)
+	}
+
+	trade := entities.NewTrade(
+		event.TradeID,
+		event.Symbol,
+		price,
+		quantity,
+		time.UnixMilli(event.TradeTime),
+		event.IsBuyerMarketMaker,
+		time.UnixMilli(event.EventTime),
+		entities.TradeSourceLive,
+	)
+	trade.BuyerOrderID = event.BuyerOrderID
+	trade.SellerOrderID = event.SellerOrderID
+	trade.IsBestMatch = event.IsBestMatch
+	trade.Exchange = entities.ExchangeBinance
+	trade.Market = d.market
+
+	return trade, nil
+}
+
+func (d *Decoder) decodeAggTrade(event dto.AggTradeEventDTO) (*entities.AggTrade, error) {
+	price, err := decimal.NewFromString(event.Price)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price: %w", err)
+	}
+
+	quantity, err := decimal.NewFromString(event.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity: %w", err)
+	}
+
+	aggTrade := entities.NewAggTrade(
+		event.AggTradeID,
+		event.Symbol,
+		price,
+		quantity,
+		event.FirstTradeID,
+		event.LastTradeID,
+		time.UnixMilli(event.TradeTime),
+		event.IsBuyerMarketMaker,
+		time.UnixMilli(event.EventTime),
+		entities.TradeSourceLive,
+	)
+	aggTrade.IsBestMatch = event.IsBestMatch
+	aggTrade.Exchange = entities.ExchangeBinance
+	aggTrade.Market = d.market
+
+	return aggTrade, nil
+}
+
+func (d *Decoder) decodeKline(event dto.KlineEventDTO) (*entities.Kline, error) {
+	interval, err := entities.ParseKlineInterval(event.Kline.Interval)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]decimal.Decimal, 0, 8)
+	for _, field := range []struct{ name, value string }{
+		{"open", event.Kline.Open},
+		{"high", event.Kline.High},
+		{"low", event.Kline.Low},
+		{"close", event.Kline.Close},
+		{"volume", event.Kline.Volume},
+		{"quote volume", event.Kline.QuoteVolume},
+		{"taker buy volume", event.Kline.TakerBuyVolume},
+		{"taker buy quote volume", event.Kline.TakerBuyQuoteVolume},
+	} {
+		value, err := decimal.NewFromString(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", field.name, err)
+		}
+		values = append(values, value)
+	}
+
+	kline := entities.NewKline(
+		event.Symbol,
+		interval,
+		time.UnixMilli(event.Kline.OpenTime),
+		time.UnixMilli(event.Kline.CloseTime),
+		values[0],
+		values[1],
+		values[2],
+		values[3],
+		values[4],
+		time.UnixMilli(event.EventTime),
+		entities.TradeSourceLive,
+	)
+	kline.QuoteVolume = values[5]
+	kline.TakerBuyVolume = values[6]
+	kline.TakerBuyQuoteVolume = values[7]
+	kline.TradeCount = event.Kline.TradeCount
+	kline.IsClosed = event.Kline.IsClosed
+	kline.Exchange = entities.ExchangeBinance
+	kline.Market = d.market
+
+	return kline, nil
+}
+
+func decodeDepthUpdate(event dto.DepthUpdateEventDTO) (*entities.DepthUpdate, error) {
+	bids, err := parseDepthLevels(event.Bids)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bid: %w", err)
+	}
+
+	asks, err := parseDepthLevels(event.Asks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ask: %w", err)
+	}
+
+	return entities.NewDepthUpdate(
+		event.Symbol,
+		event.FirstUpdateID,
+		event.FinalUpdateID,
+		bids,
+		asks,
+		time.UnixMilli(event.EventTime),
+	), nil
+}
+
+// parseDepthLevels parses the [price, quantity] string pairs of a diff depth
+// event.
+func parseDepthLevels(levels [][2]string) ([]entities.PriceLevel, error) {
+	result := make([]entities.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		price, err := decimal.NewFromString(level[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid price: %w", err)
+		}
+
+		quantity, err := decimal.NewFromString(level[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity: %w", err)
+		}
+
+		result = append(result, entities.PriceLevel{Price: price, Quantity: quantity})
+	}
+	return result, nil
+}
+
+func (d *Decoder) decodeBookTicker(event dto.BookTickerEventDTO) (*entities.BookTicker, error) {
+	bidPrice, err := strconv.ParseFloat(event.BestBidPrice, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bid price: %w", err)
+	}
+
+	bidQuantity, err := strconv.ParseFloat(event.BestBidQuantity, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bid quantity: %w", err)
+	}
+
+	askPrice, err := strconv.ParseFloat(event.BestAskPrice, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ask price: %w", err)
+	}
+
+	askQuantity, err := strconv.ParseFloat(event.BestAskQuantity, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ask quantity: %w", err)
+	}
+
+	// Spot book ticker events don't have timestamps, so we use current time
+	transactionTime, eventTime := time.Now(), time.Now()
+	if event.EventTime > 0 {
+		transactionTime, eventTime = time.UnixMilli(event.TransactionTime), time.UnixMilli(event.EventTime)
+	}
+
+	bookTicker := entities.NewBookTicker(
+		event.UpdateID,
+		event.Symbol,
+		bidPrice,
+		bidQuantity,
+		askPrice,
+		askQuantity,
+		transactionTime,
+		eventTime,
+	)
+	bookTicker.Exchange = entities.ExchangeBinance
+	bookTicker.Market = d.market
+
+	return bookTicker, nil
+}
+
+func (d *Decoder) decodeMarkPrice(event dto.MarkPriceEventDTO) (*entities.MarkPrice, error) {
+	values := make([]decimal.Decimal, 0, 4)
+	for _, field := range []struct{ name, value string }{
+		{"mark price", event.MarkPrice},
+		{"index price", event.IndexPrice},
+		{"estimated settle price", event.EstimatedSettlePrice},
+		{"funding rate", event.FundingRate},
+	} {
+		if field.value == "" {
+			values = append(values, decimal.Zero)
+			continue
+		}
+		value, err := decimal.NewFromString(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", field.name, err)
+		}
+		values = append(values, value)
+	}
+
+	var nextFundingTime time.Time
+	if event.NextFundingTime > 0 {
+		nextFundingTime = time.UnixMilli(event.NextFundingTime)
+	}
+
+	markPrice := entities.NewMarkPrice(
+		event.Symbol,
+		values[0],
+		values[1],
+		values[2],
+		values[3],
+		nextFundingTime,
+		time.UnixMilli(event.EventTime),
+	)
+	markPrice.Exchange = entities.ExchangeBinance
+	markPrice.Market = d.market
+
+	return markPrice, nil
+}
@@ -0,0 +1,121 @@
+package binance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/services"
+)
+
+func TestDecoder_FuturesFrames(t *testing.T) {
+	decoder := NewDecoder(entities.MarketUSDM)
+
+	t.Run("trade", func(t *testing.T) {
+		events, err := decoder.Decode([]byte(`{"e":"trade","E":1700000000005,"T":1700000000001,"s":"BTCUSDT","t":42,"p":"37000.10","q":"0.002","X":"MARKET","m":true}`))
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+
+		trade := events[0].(*entities.Trade)
+		assert.Equal(t, uint64(42), trade.ID)
+		assert.Equal(t, time.UnixMilli(1700000000001), trade.Time)
+		assert.True(t, trade.IsBuyerMaker)
+		assert.Equal(t, entities.ExchangeBinance, trade.Exchange)
+		assert.Equal(t, entities.MarketUSDM, trade.Market)
+	})
+
+	t.Run("agg trade and kline carry the market", func(t *testing.T) {
+		events, err := decoder.Decode([]byte(`{"e":"aggTrade","E":1700000000005,"s":"BTCUSDT","a":7,"p":"37000.10","q":"0.002","f":1,"l":2,"T":1700000000001,"m":false}`))
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+
+		aggTrade := events[0].(*entities.AggTrade)
+		assert.Equal(t, entities.ExchangeBinance, aggTrade.Exchange)
+		assert.Equal(t, entities.MarketUSDM, aggTrade.Market)
+
+		events, err = decoder.Decode([]byte(`{"e":"kline","E":1700000000005,"s":"BTCUSDT","k":{"t":1700000000000,"T":1700000059999,"i":"1m","o":"1","h":"2","l":"0.5","c":"1.5","v":"10","q":"15","V":"5","Q":"7.5","n":3,"x":true}}`))
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+
+		kline := events[0].(*entities.Kline)
+		assert.Equal(t, entities.ExchangeBinance, kline.Exchange)
+		assert.Equal(t, entities.MarketUSDM, kline.Market)
+	})
+
+	t.Run("book ticker carries its timestamps", func(t *testing.T) {
+		events, err := decoder.Decode([]byte(`{"e":"bookTicker","u":400900217,"E":1700000000010,"T":1700000000008,"s":"BTCUSDT","b":"37000.1","B":"1.5","a":"37000.2","A":"2.25"}`))
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+
+		ticker := events[0].(*entities.BookTicker)
+		assert.Equal(t, int64(400900217), ticker.UpdateID)
+		assert.Equal(t, 37000.1, ticker.BestBidPrice)
+		assert.Equal(t, 2.25, ticker.BestAskQuantity)
+		assert.Equal(t, time.UnixMilli(1700000000008), ticker.TransactionTime)
+		assert.Equal(t, time.UnixMilli(1700000000010), ticker.EventTime)
+		assert.Equal(t, entities.MarketUSDM, ticker.Market)
+	})
+
+	t.Run("delivery contract mark price has no funding", func(t *testing.T) {
+		events, err := NewDecoder(entities.MarketCoinM).Decode([]byte(`{"e":"markPriceUpdate","E":1700000000000,"s":"BTCUSD_240329","p":"38100.5","i":"37001.1","P":"38050.0","r":"","T":0}`))
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+
+		markPrice := events[0].(*entities.MarkPrice)
+		assert.Equal(t, "BTCUSD_240329", markPrice.Symbol)
+		assert.True(t, markPrice.FundingRate.IsZero())
+		assert.True(t, markPrice.NextFundingTime.IsZero())
+		assert.Equal(t, entities.MarketCoinM, markPrice.Market)
+	})
+
+	t.Run("invalid mark price", func(t *testing.T) {
+		_, err := decoder.Decode([]byte(`{"e":"markPriceUpdate","s":"BTCUSDT","p":"abc","i":"1","P":"1","r":"0"}`))
+		assert.ErrorContains(t, err, "invalid mark price")
+	})
+}
+
+func TestDecoder_SpotBookTickerUsesReceiveTime(t *testing.T) {
+	before := time.Now()
+	events, err := NewDecoder(entities.MarketSpot).Decode([]byte(`{"u":400900217,"s":"BNBUSDT","b":"25.35","B":"31.21","a":"25.36","A":"40.66"}`))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	ticker := events[0].(*entities.BookTicker)
+	assert.False(t, ticker.EventTime.Before(before))
+	assert.Equal(t, entities.MarketSpot, ticker.Market)
+}
+
+func TestDecoder_DecodeStream(t *testing.T) {
+	decoder := NewDecoder(entities.MarketSpot).(services.StreamDecoder)
+
+	t.Run("routes by stream name", func(t *testing.T) {
+		for stream, data := range map[string]string{
+			"btcusdt@trade":       `{"e":"trade","E":1700000000005,"T":1700000000001,"s":"BTCUSDT","t":42,"p":"37000.10","q":"0.002","m":true}`,
+			"btcusdt@aggTrade":    `{"e":"aggTrade","E":1700000000005,"s":"BTCUSDT","a":7,"p":"37000.10","q":"0.002","f":1,"l":2,"T":1700000000001,"m":false}`,
+			"btcusdt@kline_1m":    `{"e":"kline","E":1700000000005,"s":"BTCUSDT","k":{"t":1700000000000,"T":1700000059999,"i":"1m","o":"1","h":"2","l":"0.5","c":"1.5","v":"10","q":"15","V":"5","Q":"7.5","n":3,"x":true}}`,
+			"btcusdt@depth@100ms": `{"e":"depthUpdate","E":1700000000005,"s":"BTCUSDT","U":1,"u":2,"b":[["37000.1","1"]],"a":[]}`,
+			"btcusdt@bookTicker":  `{"u":400900217,"s":"BTCUSDT","b":"37000.1","B":"1.5","a":"37000.2","A":"2.25"}`,
+		} {
+			events, err := decoder.DecodeStream(stream, []byte(data))
+			require.NoError(t, err, stream)
+			require.Len(t, events, 1, stream)
+		}
+	})
+
+	t.Run("book ticker payload without update ID", func(t *testing.T) {
+		// Sniffing needs the "u" key, the stream name does not
+		events, err := decoder.DecodeStream("btcusdt@bookTicker", []byte(`{"s":"BTCUSDT","b":"37000.1","B":"1.5","a":"37000.2","A":"2.25"}`))
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, "BTCUSDT", events[0].(*entities.BookTicker).Symbol)
+	})
+
+	t.Run("unknown stream falls back to the event type", func(t *testing.T) {
+		events, err := decoder.DecodeStream("btcusdt@ticker", []byte(`{"e":"24hrTicker","s":"BTCUSDT"}`))
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+}
@@ -0,0 +1,125 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"alarket/internal/domain/entities"
+)
+
+const fundingRateHTTPTimeout = 30 * time.Second
+
+// fundingRateEndpoints maps each futures market onto its REST host and the
+// path of its funding rate history endpoint.
+var fundingRateEndpoints = map[entities.Market]struct {
+	baseURL, testnetURL, path string
+}{
+	entities.MarketUSDM:  {"https://fapi.binance.com", futuresTestnetURL, "/fapi/v1/fundingRate"},
+	entities.MarketCoinM: {"https://dapi.binance.com", futuresTestnetURL, "/dapi/v1/fundingRate"},
+}
+
+type fundingRateDTO struct {
+	Symbol      string `json:"symbol"`
+	FundingRate string `json:"fundingRate"`
+	FundingTime int64  `json:"fundingTime"`
+	MarkPrice   string `json:"markPrice"`
+}
+
+// FundingRateService reads settled funding rates over plain HTTP, since
+// go-binance has no client for the COIN-M endpoint.
+type FundingRateService struct {
+	client  *http.Client
+	baseURL string
+	path    string
+	market  entities.Market
+	logger  *slog.Logger
+}
+
+func NewFundingRateService(market entities.Market, useTestnet bool, logger *slog.Logger) (*FundingRateService, error) {
+	endpoint, ok := fundingRateEndpoints[market]
+	if !ok {
+		return nil, fmt.Errorf("funding rates are not available for market %q", market)
+	}
+
+	baseURL := endpoint.baseURL
+	if useTestnet {
+		baseURL = endpoint.testnetURL
+	}
+
+	return &FundingRateService{
+		client:  &http.Client{Timeout: fundingRateHTTPTimeout},
+		baseURL: baseURL,
+		path:    endpoint.path,
+		market:  market,
+		logger:  logger,
+	}, nil
+}
+
+// FetchFundingRates returns funding payments settled within [startTime,
+// endTime], oldest first.
+func (s *FundingRateService) FetchFundingRates(ctx context.Context, symbol string, startTime, endTime time.Time, limit int) ([]*entities.FundingRate, error) {
+	query := url.Values{}
+	query.Set("symbol", symbol)
+	query.Set("startTime", fmt.Sprint(startTime.UnixMilli()))
+	query.Set("endTime", fmt.Sprint(endTime.UnixMilli()))
+	query.Set("limit", fmt.Sprint(limit))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+s.path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch funding rates from Binance: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch funding rates from Binance: unexpected status %s", resp.Status)
+	}
+
+	var response []fundingRateDTO
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode funding rates: %w", err)
+	}
+
+	rates := make([]*entities.FundingRate, 0, len(response))
+	for _, r := range response {
+		rate, err := decimal.NewFromString(r.FundingRate)
+		if err != nil {
+			s.logger.Warn("Failed to parse funding rate", "fundingRate", r.FundingRate, "error", err)
+			continue
+		}
+
+		// COIN-M omits the mark price and older USD-M rows send it empty
+		markPrice := decimal.Zero
+		if r.MarkPrice != "" {
+			if markPrice, err = decimal.NewFromString(r.MarkPrice); err != nil {
+				s.logger.Warn("Failed to parse mark price", "markPrice", r.MarkPrice, "error", err)
+				continue
+			}
+		}
+
+		fundingRate := entities.NewFundingRate(
+			r.Symbol,
+			time.UnixMilli(r.FundingTime),
+			rate,
+			markPrice,
+			entities.TradeSourceREST,
+		)
+		fundingRate.Exchange = entities.ExchangeBinance
+		fundingRate.Market = s.market
+
+		rates = append(rates, fundingRate)
+	}
+
+	return rates, nil
+}
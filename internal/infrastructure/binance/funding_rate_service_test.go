@@ -0,0 +1,67 @@
+package binance
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/domain/entities"
+)
+
+func TestFundingRateService_FetchFundingRates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/dapi/v1/fundingRate", r.URL.Path)
+		assert.Equal(t, "BTCUSD_PERP", r.URL.Query().Get("symbol"))
+		assert.Equal(t, "1700000000000", r.URL.Query().Get("startTime"))
+		assert.Equal(t, "1700086400000", r.URL.Query().Get("endTime"))
+		assert.Equal(t, "1000", r.URL.Query().Get("limit"))
+
+		_, _ = w.Write([]byte(`[
+			{"symbol":"BTCUSD_PERP","fundingTime":1700006400000,"fundingRate":"0.00010000"},
+			{"symbol":"BTCUSD_PERP","fundingTime":1700035200000,"fundingRate":"-0.00002500","markPrice":"37012.5"}
+		]`))
+	}))
+	defer server.Close()
+
+	service, err := NewFundingRateService(entities.MarketCoinM, false, slog.Default())
+	require.NoError(t, err)
+	service.baseURL = server.URL
+
+	rates, err := service.FetchFundingRates(context.Background(), "BTCUSD_PERP", time.UnixMilli(1700000000000), time.UnixMilli(1700086400000), 1000)
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+
+	assert.Equal(t, "BTCUSD_PERP", rates[0].Symbol)
+	assert.Equal(t, time.UnixMilli(1700006400000), rates[0].FundingTime)
+	assert.Equal(t, "0.0001", rates[0].Rate.String())
+	assert.True(t, rates[0].MarkPrice.IsZero())
+	assert.Equal(t, entities.ExchangeBinance, rates[0].Exchange)
+	assert.Equal(t, entities.MarketCoinM, rates[0].Market)
+	assert.Equal(t, entities.TradeSourceREST, rates[0].Source)
+
+	assert.Equal(t, "-0.000025", rates[1].Rate.String())
+	assert.Equal(t, "37012.5", rates[1].MarkPrice.String())
+}
+
+func TestFundingRateService_Errors(t *testing.T) {
+	_, err := NewFundingRateService(entities.MarketSpot, false, slog.Default())
+	assert.Error(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code":-1121,"msg":"Invalid symbol."}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	service, err := NewFundingRateService(entities.MarketUSDM, false, slog.Default())
+	require.NoError(t, err)
+	service.baseURL = server.URL
+
+	_, err = service.FetchFundingRates(context.Background(), "NOPE", time.Now().Add(-time.Hour), time.Now(), 1000)
+	assert.ErrorContains(t, err, "400")
+}
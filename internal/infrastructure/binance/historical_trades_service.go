@@ -4,40 +4,81 @@ import (
 	"context"
 	"fmt"
 	"github.com/adshao/go-binance/v2"
+	"github.com/shopspring/decimal"
 	"log/slog"
-	"strconv"
 	"time"
 
 	"alarket/internal/domain/entities"
+	"alarket/internal/infrastructure/ratelimit"
 )
 
+// historicalTradesWeight is the request weight of GET /api/v3/historicalTrades.
+const historicalTradesWeight = 25
+
+// aggTradesWeight is the request weight of GET /api/v3/aggTrades.
+const aggTradesWeight = 4
+
 type HistoricalTradesService struct {
 	client     *binance.Client
 	logger     *slog.Logger
 	useTestnet bool
+	limiter    *ratelimit.Limiter // nil when the caller paces its requests
 }
 
 func NewHistoricalTradesService(apiKey, secretKey string, useTestnet bool, logger *slog.Logger) *HistoricalTradesService {
-	client := binance.NewClient(apiKey, secretKey)
-
-	if useTestnet {
-		binance.UseTestnet = true
-	}
-
 	return &HistoricalTradesService{
-		client:     client,
+		client:     newSpotClient(apiKey, secretKey, useTestnet),
 		logger:     logger,
 		useTestnet: useTestnet,
 	}
 }
 
-func (s *HistoricalTradesService) FetchHistoricalTrades(ctx context.Context, symbol string, fromID int64, limit int) ([]*entities.Trade, error) {
-	service := s.client.NewHistoricalTradesService().
+// SetRateLimiter makes every request wait for its weight on limiter.
+func (s *HistoricalTradesService) SetRateLimiter(limiter *ratelimit.Limiter) {
+	s.limiter = limiter
+}
+
+func (s *HistoricalTradesService) FetchHistoricalTrades(ctx context.Context, symbol string, fromID uint64, limit int) ([]*entities.Trade, error) {
+	return s.fetch(ctx, symbol, s.client.NewHistoricalTradesService().
 		Symbol(symbol).
-		Limit(limit)
+		FromID(int64(fromID)).
+		Limit(limit))
+}
+
+func (s *HistoricalTradesService) FetchLatestTrades(ctx context.Context, symbol string, limit int) ([]*entities.Trade, error) {
+	return s.fetch(ctx, symbol, s.client.NewHistoricalTradesService().
+		Symbol(symbol).
+		Limit(limit))
+}
 
-	if fromID > 0 {
-		service = service.FromID(fromID)
+// FindTradeID looks the trade up through the aggregate trade at or after
+// since, which knows the ID of its first trade.
+func (s *HistoricalTradesService) FindTradeID(ctx context.Context, symbol string, since time.Time) (uint64, bool, error) {
+	if s.limiter != nil {
+		if err := s.limiter.Wait(ctx, aggTradesWeight); err != nil {
+			return 0, false, err
+		}
+	}
+
+	aggTrades, err := s.client.NewAggTradesService().
+		Symbol(symbol).
+		StartTime(since.UnixMilli()).
+		Limit(1).
+		Do(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to fetch aggregate trades from Binance: %w", err)
+	}
+	if len(aggTrades) == 0 {
+		return 0, false, nil
+	}
+	return uint64(aggTrades[0].FirstTradeID), true, nil
+}
+
+func (s *HistoricalTradesService) fetch(ctx context.Context, symbol string, service *binance.HistoricalTradesService) ([]*entities.Trade, error) {
+	if s.limiter != nil {
+		if err := s.limiter.Wait(ctx, historicalTradesWeight); err != nil {
+			return nil, err
+		}
 	}
 
 	binanceTrades, err := service.Do(ctx)
@@ -47,29 +88,40 @@ func (s *HistoricalTradesService) FetchHistoricalTrades(ctx context.Context, sym
 
 	trades := make([]*entities.Trade, 0, len(binanceTrades))
 	for _, bt := range binanceTrades {
-		price, err := strconv.ParseFloat(bt.Price, 64)
+		price, err := decimal.NewFromString(bt.Price)
 		if err != nil {
 			s.logger.Warn("Failed to parse price", "price", bt.Price, "error", err)
 			continue
 		}
 
-		quantity, err := strconv.ParseFloat(bt.Quantity, 64)
+		quantity, err := decimal.NewFromString(bt.Quantity)
 		if err != nil {
 			s.logger.Warn("Failed to parse quantity", "quantity", bt.Quantity, "error", err)
 			continue
 		}
 
+		quoteQuantity, err := decimal.NewFromString(bt.QuoteQuantity)
+		if err != nil {
+			s.logger.Warn("Failed to parse quote quantity", "quoteQuantity", bt.QuoteQuantity, "error", err)
+			continue
+		}
+
 		tradeTime := time.Unix(0, bt.Time*int64(time.Millisecond))
 
 		trade := entities.NewTrade(
-			fmt.Sprintf("%d", bt.ID),
+			uint64(bt.ID),
 			symbol,
 			price,
 			quantity,
 			tradeTime,
 			bt.IsBuyerMaker,
 			tradeTime, // Using trade time as event time for historical data
+			entities.TradeSourceREST,
 		)
+		trade.QuoteQuantity = quoteQuantity
+		trade.IsBestMatch = bt.IsBestMatch
+		trade.Exchange = entities.ExchangeBinance
+		trade.Market = entities.MarketSpot
 
 		trades = append(trades, trade)
 	}
@@ -0,0 +1,90 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/shopspring/decimal"
+
+	"alarket/internal/domain/entities"
+)
+
+// KlinesService reads candles from the public klines endpoint, which needs no
+// API key.
+type KlinesService struct {
+	client *binance.Client
+	logger *slog.Logger
+}
+
+func NewKlinesService(useTestnet bool, logger *slog.Logger) *KlinesService {
+	return &KlinesService{
+		client: newSpotClient("", "", useTestnet),
+		logger: logger,
+	}
+}
+
+func (s *KlinesService) FetchKlines(ctx context.Context, symbol string, interval entities.KlineInterval, startTime, endTime time.Time, limit int) ([]*entities.Kline, error) {
+	binanceKlines, err := s.client.NewKlinesService().
+		Symbol(symbol).
+		Interval(string(interval)).
+		StartTime(startTime.UnixMilli()).
+		EndTime(endTime.UnixMilli()).
+		Limit(limit).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch klines from Binance: %w", err)
+	}
+
+	now := time.Now()
+	klines := make([]*entities.Kline, 0, len(binanceKlines))
+	for _, bk := range binanceKlines {
+		values, err := parseDecimals(bk.Open, bk.High, bk.Low, bk.Close, bk.Volume,
+			bk.QuoteAssetVolume, bk.TakerBuyBaseAssetVolume, bk.TakerBuyQuoteAssetVolume)
+		if err != nil {
+			s.logger.Warn("Failed to parse kline", "openTime", bk.OpenTime, "error", err)
+			continue
+		}
+
+		closeTime := time.UnixMilli(bk.CloseTime)
+
+		kline := entities.NewKline(
+			symbol,
+			interval,
+			time.UnixMilli(bk.OpenTime),
+			closeTime,
+			values[0],
+			values[1],
+			values[2],
+			values[3],
+			values[4],
+			closeTime, // Using close time as event time for historical data
+			entities.TradeSourceREST,
+		)
+		kline.QuoteVolume = values[5]
+		kline.TakerBuyVolume = values[6]
+		kline.TakerBuyQuoteVolume = values[7]
+		kline.TradeCount = uint64(bk.TradeNum)
+		kline.IsClosed = closeTime.Before(now)
+		kline.Exchange = entities.ExchangeBinance
+		kline.Market = entities.MarketSpot
+
+		klines = append(klines, kline)
+	}
+
+	return klines, nil
+}
+
+func parseDecimals(values ...string) ([]decimal.Decimal, error) {
+	decimals := make([]decimal.Decimal, len(values))
+	for i, value := range values {
+		d, err := decimal.NewFromString(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q: %w", value, err)
+		}
+		decimals[i] = d
+	}
+	return decimals, nil
+}
@@ -0,0 +1,61 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/common"
+
+	"alarket/internal/domain/entities"
+)
+
+// OrderBookService reads depth snapshots from the public depth endpoint, which
+// needs no API key.
+type OrderBookService struct {
+	client *binance.Client
+	logger *slog.Logger
+}
+
+func NewOrderBookService(useTestnet bool, logger *slog.Logger) *OrderBookService {
+	return &OrderBookService{
+		client: newSpotClient("", "", useTestnet),
+		logger: logger,
+	}
+}
+
+func (s *OrderBookService) FetchOrderBook(ctx context.Context, symbol string, limit int) (*entities.OrderBook, error) {
+	depth, err := s.client.NewDepthService().
+		Symbol(symbol).
+		Limit(limit).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch order book from Binance: %w", err)
+	}
+
+	bids, err := parsePriceLevels(depth.Bids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bids: %w", err)
+	}
+
+	asks, err := parsePriceLevels(depth.Asks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse asks: %w", err)
+	}
+
+	return entities.NewOrderBook(symbol, uint64(depth.LastUpdateID), bids, asks, time.Now()), nil
+}
+
+func parsePriceLevels(levels []common.PriceLevel) ([]entities.PriceLevel, error) {
+	result := make([]entities.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		values, err := parseDecimals(level.Price, level.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entities.PriceLevel{Price: values[0], Quantity: values[1]})
+	}
+	return result, nil
+}
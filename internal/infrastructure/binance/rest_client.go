@@ -0,0 +1,44 @@
+package binance
+
+import (
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/delivery"
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// REST testnet hosts. go-binance only switches to them through its
+// package-global UseTestnet flags, which would move every other client in the
+// process to the testnet too, so each client gets its base URL instead.
+const (
+	spotTestnetURL    = "https://testnet.binance.vision"
+	futuresTestnetURL = "https://testnet.binancefuture.com"
+)
+
+// newSpotClient returns a spot REST client, on the testnet if useTestnet.
+func newSpotClient(apiKey, secretKey string, useTestnet bool) *binance.Client {
+	client := binance.NewClient(apiKey, secretKey)
+	if useTestnet {
+		client.BaseURL = spotTestnetURL
+	}
+	return client
+}
+
+// newFuturesClient returns a USD-M futures REST client, on the testnet if
+// useTestnet.
+func newFuturesClient(apiKey, secretKey string, useTestnet bool) *futures.Client {
+	client := futures.NewClient(apiKey, secretKey)
+	if useTestnet {
+		client.BaseURL = futuresTestnetURL
+	}
+	return client
+}
+
+// newDeliveryClient returns a COIN-M futures REST client, on the testnet if
+// useTestnet.
+func newDeliveryClient(apiKey, secretKey string, useTestnet bool) *delivery.Client {
+	client := delivery.NewClient(apiKey, secretKey)
+	if useTestnet {
+		client.BaseURL = futuresTestnetURL
+	}
+	return client
+}
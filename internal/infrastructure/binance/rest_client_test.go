@@ -0,0 +1,24 @@
+package binance
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/stretchr/testify/assert"
+
+	"alarket/internal/domain/entities"
+)
+
+func TestRESTClients_TestnetDoesNotLeak(t *testing.T) {
+	assert.Equal(t, spotTestnetURL, newSpotClient("", "", true).BaseURL)
+	assert.Equal(t, futuresTestnetURL, newFuturesClient("", "", true).BaseURL)
+	assert.Equal(t, futuresTestnetURL, newDeliveryClient("", "", true).BaseURL)
+
+	// Testnet services must not move clients created afterwards
+	NewKlinesService(true, slog.Default())
+	NewSymbolFetcher("", "", entities.MarketUSDM, true, slog.Default())
+	assert.NotEqual(t, spotTestnetURL, newSpotClient("", "", false).BaseURL)
+	assert.NotEqual(t, futuresTestnetURL, newFuturesClient("", "", false).BaseURL)
+	assert.False(t, binance.UseTestnet)
+}
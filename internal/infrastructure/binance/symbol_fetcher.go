@@ -4,32 +4,60 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/delivery"
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/shopspring/decimal"
+
 	"alarket/internal/domain/entities"
 )
 
+const perpetualContractType = "PERPETUAL"
+
 type SymbolFetcher struct {
-	client     *binance.Client
-	logger     *slog.Logger
-	useTestnet bool
+	client         *binance.Client
+	futuresClient  *futures.Client
+	deliveryClient *delivery.Client
+	market         entities.Market
+	logger         *slog.Logger
+	useTestnet     bool
 }
 
-func NewSymbolFetcher(apiKey, secretKey string, useTestnet bool, logger *slog.Logger) *SymbolFetcher {
-	client := binance.NewClient(apiKey, secretKey)
-	
-	if useTestnet {
-		binance.UseTestnet = true
-	}
-
-	return &SymbolFetcher{
-		client:     client,
+// NewSymbolFetcher reads symbols from the exchangeInfo endpoint of the given
+// market: /api/v3 for spot, /fapi/v1 for USD-M and /dapi/v1 for COIN-M futures.
+func NewSymbolFetcher(apiKey, secretKey string, market entities.Market, useTestnet bool, logger *slog.Logger) *SymbolFetcher {
+	fetcher := &SymbolFetcher{
+		market:     market,
 		logger:     logger,
 		useTestnet: useTestnet,
 	}
+
+	switch market {
+	case entities.MarketUSDM:
+		fetcher.futuresClient = newFuturesClient(apiKey, secretKey, useTestnet)
+	case entities.MarketCoinM:
+		fetcher.deliveryClient = newDeliveryClient(apiKey, secretKey, useTestnet)
+	default:
+		fetcher.client = newSpotClient(apiKey, secretKey, useTestnet)
+	}
+
+	return fetcher
 }
 
 func (f *SymbolFetcher) FetchAllSymbols(ctx context.Context) ([]*entities.Symbol, error) {
+	switch f.market {
+	case entities.MarketUSDM:
+		return f.fetchUSDMSymbols(ctx)
+	case entities.MarketCoinM:
+		return f.fetchCoinMSymbols(ctx)
+	default:
+		return f.fetchSpotSymbols(ctx)
+	}
+}
+
+func (f *SymbolFetcher) fetchSpotSymbols(ctx context.Context) ([]*entities.Symbol, error) {
 	exchangeInfo, err := f.client.NewExchangeInfoService().Do(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch exchange info: %w", err)
@@ -39,7 +67,7 @@ func (f *SymbolFetcher) FetchAllSymbols(ctx context.Context) ([]*entities.Symbol
 	for _, s := range exchangeInfo.Symbols {
 		status := entities.SymbolStatus(s.Status)
 		symbol := entities.NewSymbol(s.Symbol, s.BaseAsset, s.QuoteAsset, status)
-		
+
 		// Set trading flags based on permissions
 		for _, perm := range s.Permissions {
 			switch perm {
@@ -49,10 +77,93 @@ func (f *SymbolFetcher) FetchAllSymbols(ctx context.Context) ([]*entities.Symbol
 				symbol.IsMarginTrading = true
 			}
 		}
+		symbol.Market = entities.MarketSpot
+		setFilters(symbol, s.Filters)
 
 		symbols = append(symbols, symbol)
 	}
 
 	f.logger.Info("Fetched symbols from exchange", "count", len(symbols))
 	return symbols, nil
-}
\ No newline at end of file
+}
+
+func (f *SymbolFetcher) fetchUSDMSymbols(ctx context.Context) ([]*entities.Symbol, error) {
+	exchangeInfo, err := f.futuresClient.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch futures exchange info: %w", err)
+	}
+
+	symbols := make([]*entities.Symbol, 0, len(exchangeInfo.Symbols))
+	for _, s := range exchangeInfo.Symbols {
+		symbol := newFuturesSymbol(
+			entities.MarketUSDM, s.Symbol, s.BaseAsset, s.QuoteAsset, s.Status, string(s.ContractType), s.DeliveryDate,
+		)
+		setFilters(symbol, s.Filters)
+		symbols = append(symbols, symbol)
+	}
+
+	f.logger.Info("Fetched symbols from exchange", "market", entities.MarketUSDM, "count", len(symbols))
+	return symbols, nil
+}
+
+func (f *SymbolFetcher) fetchCoinMSymbols(ctx context.Context) ([]*entities.Symbol, error) {
+	exchangeInfo, err := f.deliveryClient.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch delivery exchange info: %w", err)
+	}
+
+	symbols := make([]*entities.Symbol, 0, len(exchangeInfo.Symbols))
+	for _, s := range exchangeInfo.Symbols {
+		symbol := newFuturesSymbol(
+			entities.MarketCoinM, s.Symbol, s.BaseAsset, s.QuoteAsset, s.ContractStatus, s.ContractType, s.DeliveryDate,
+		)
+		setFilters(symbol, s.Filters)
+		symbols = append(symbols, symbol)
+	}
+
+	f.logger.Info("Fetched symbols from exchange", "market", entities.MarketCoinM, "count", len(symbols))
+	return symbols, nil
+}
+
+// newFuturesSymbol maps a futures contract onto a symbol. Binance reports a
+// far-future delivery date for perpetuals, which is stored as zero instead.
+func newFuturesSymbol(market entities.Market, name, baseAsset, quoteAsset, status, contractType string, deliveryDate int64) *entities.Symbol {
+	symbol := entities.NewSymbol(name, baseAsset, quoteAsset, entities.SymbolStatus(status))
+	symbol.Market = market
+	symbol.ContractType = contractType
+	if contractType != perpetualContractType && deliveryDate > 0 {
+		symbol.DeliveryDate = time.UnixMilli(deliveryDate)
+	}
+	return symbol
+}
+
+// setFilters reads the tick size, step size and minimum notional from the
+// exchangeInfo filters. Spot reports the minimum notional as NOTIONAL or the
+// older MIN_NOTIONAL filter, USD-M futures as MIN_NOTIONAL with a "notional"
+// field; COIN-M contracts have none. Values that are missing or invalid stay
+// zero.
+func setFilters(symbol *entities.Symbol, filters []map[string]interface{}) {
+	for _, filter := range filters {
+		switch filter["filterType"] {
+		case "PRICE_FILTER":
+			symbol.TickSize = filterValue(filter, "tickSize")
+		case "LOT_SIZE":
+			symbol.StepSize = filterValue(filter, "stepSize")
+		case "NOTIONAL", "MIN_NOTIONAL":
+			if _, ok := filter["notional"]; ok {
+				symbol.MinNotional = filterValue(filter, "notional")
+			} else {
+				symbol.MinNotional = filterValue(filter, "minNotional")
+			}
+		}
+	}
+}
+
+func filterValue(filter map[string]interface{}, key string) decimal.Decimal {
+	value, _ := filter[key].(string)
+	parsed, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero
+	}
+	return parsed
+}
@@ -0,0 +1,50 @@
+package binance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"alarket/internal/domain/entities"
+)
+
+func TestSetFilters(t *testing.T) {
+	t.Run("spot", func(t *testing.T) {
+		symbol := entities.NewSymbol("BTCUSDT", "BTC", "USDT", entities.SymbolStatusTrading)
+		setFilters(symbol, []map[string]interface{}{
+			{"filterType": "PRICE_FILTER", "minPrice": "0.01000000", "maxPrice": "1000000.00000000", "tickSize": "0.01000000"},
+			{"filterType": "LOT_SIZE", "minQty": "0.00001000", "maxQty": "9000.00000000", "stepSize": "0.00001000"},
+			{"filterType": "ICEBERG_PARTS", "limit": float64(10)},
+			{"filterType": "NOTIONAL", "minNotional": "5.00000000", "applyMinToMarket": true, "maxNotional": "9000000.00000000"},
+		})
+
+		assert.Equal(t, "0.01", symbol.TickSize.String())
+		assert.Equal(t, "0.00001", symbol.StepSize.String())
+		assert.Equal(t, "5", symbol.MinNotional.String())
+	})
+
+	t.Run("usdm futures", func(t *testing.T) {
+		symbol := entities.NewSymbol("BTCUSDT", "BTC", "USDT", entities.SymbolStatusTrading)
+		setFilters(symbol, []map[string]interface{}{
+			{"filterType": "PRICE_FILTER", "minPrice": "261.10", "maxPrice": "809484", "tickSize": "0.10"},
+			{"filterType": "LOT_SIZE", "minQty": "0.001", "maxQty": "1000", "stepSize": "0.001"},
+			{"filterType": "MIN_NOTIONAL", "notional": "100"},
+		})
+
+		assert.Equal(t, "0.1", symbol.TickSize.String())
+		assert.Equal(t, "0.001", symbol.StepSize.String())
+		assert.Equal(t, "100", symbol.MinNotional.String())
+	})
+
+	t.Run("missing and invalid values stay zero", func(t *testing.T) {
+		symbol := entities.NewSymbol("BTCUSD_PERP", "BTC", "USD", entities.SymbolStatusTrading)
+		setFilters(symbol, []map[string]interface{}{
+			{"filterType": "PRICE_FILTER", "tickSize": "abc"},
+			{"filterType": "LOT_SIZE"},
+		})
+
+		assert.True(t, symbol.TickSize.IsZero())
+		assert.True(t, symbol.StepSize.IsZero())
+		assert.True(t, symbol.MinNotional.IsZero())
+	})
+}
@@ -0,0 +1,115 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"alarket/internal/domain/entities"
+)
+
+const tickerHTTPTimeout = 30 * time.Second
+
+// tickerEndpoints maps each market onto its REST host and the path of its 24h
+// ticker statistics endpoint.
+var tickerEndpoints = map[entities.Market]struct {
+	baseURL, testnetURL, path string
+}{
+	entities.MarketSpot:  {"https://api.binance.com", spotTestnetURL, "/api/v3/ticker/24hr"},
+	entities.MarketUSDM:  {"https://fapi.binance.com", futuresTestnetURL, "/fapi/v1/ticker/24hr"},
+	entities.MarketCoinM: {"https://dapi.binance.com", futuresTestnetURL, "/dapi/v1/ticker/24hr"},
+}
+
+type tickerDTO struct {
+	Symbol           string `json:"symbol"`
+	QuoteVolume      string `json:"quoteVolume"`
+	BaseVolume       string `json:"baseVolume"`
+	WeightedAvgPrice string `json:"weightedAvgPrice"`
+}
+
+// TickerService reads the 24h ticker statistics of every symbol of a market.
+type TickerService struct {
+	client  *http.Client
+	baseURL string
+	path    string
+	market  entities.Market
+	logger  *slog.Logger
+}
+
+func NewTickerService(market entities.Market, useTestnet bool, logger *slog.Logger) (*TickerService, error) {
+	endpoint, ok := tickerEndpoints[market]
+	if !ok {
+		return nil, fmt.Errorf("24h tickers are not available for market %q", market)
+	}
+
+	baseURL := endpoint.baseURL
+	if useTestnet {
+		baseURL = endpoint.testnetURL
+	}
+
+	return &TickerService{
+		client:  &http.Client{Timeout: tickerHTTPTimeout},
+		baseURL: baseURL,
+		path:    endpoint.path,
+		market:  market,
+		logger:  logger,
+	}, nil
+}
+
+// FetchQuoteVolumes returns the 24h quote volume of every symbol. COIN-M
+// reports volumes in contracts, so its quote volume is the base volume valued
+// at the weighted average price.
+func (s *TickerService) FetchQuoteVolumes(ctx context.Context) (map[string]decimal.Decimal, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+s.path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch 24h tickers from Binance: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch 24h tickers from Binance: unexpected status %s", resp.Status)
+	}
+
+	var response []tickerDTO
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode 24h tickers: %w", err)
+	}
+
+	volumes := make(map[string]decimal.Decimal, len(response))
+	for _, t := range response {
+		volume, err := s.quoteVolume(t)
+		if err != nil {
+			s.logger.Warn("Failed to parse 24h volume", "symbol", t.Symbol, "error", err)
+			continue
+		}
+		volumes[t.Symbol] = volume
+	}
+
+	return volumes, nil
+}
+
+func (s *TickerService) quoteVolume(t tickerDTO) (decimal.Decimal, error) {
+	if s.market != entities.MarketCoinM {
+		return decimal.NewFromString(t.QuoteVolume)
+	}
+
+	baseVolume, err := decimal.NewFromString(t.BaseVolume)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	price, err := decimal.NewFromString(t.WeightedAvgPrice)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return baseVolume.Mul(price), nil
+}
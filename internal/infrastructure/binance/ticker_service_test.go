@@ -0,0 +1,65 @@
+package binance
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/domain/entities"
+)
+
+func TestTickerService_FetchQuoteVolumes(t *testing.T) {
+	tests := []struct {
+		market entities.Market
+		path   string
+		body   string
+		want   map[string]string
+	}{
+		{
+			market: entities.MarketSpot,
+			path:   "/api/v3/ticker/24hr",
+			body: `[
+				{"symbol":"BTCUSDT","weightedAvgPrice":"43000.5","volume":"100.5","quoteVolume":"4321550.25"},
+				{"symbol":"ETHUSDT","weightedAvgPrice":"2300","volume":"10","quoteVolume":"23000"},
+				{"symbol":"BADUSDT","quoteVolume":""}
+			]`,
+			want: map[string]string{"BTCUSDT": "4321550.25", "ETHUSDT": "23000"},
+		},
+		{
+			market: entities.MarketCoinM,
+			path:   "/dapi/v1/ticker/24hr",
+			body: `[
+				{"symbol":"BTCUSD_PERP","weightedAvgPrice":"40000","volume":"120000","baseVolume":"30.5"}
+			]`,
+			want: map[string]string{"BTCUSD_PERP": "1220000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.market), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.path, r.URL.Path)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			service, err := NewTickerService(tt.market, false, slog.Default())
+			require.NoError(t, err)
+			service.baseURL = server.URL
+
+			volumes, err := service.FetchQuoteVolumes(context.Background())
+			require.NoError(t, err)
+
+			got := make(map[string]string, len(volumes))
+			for symbol, volume := range volumes {
+				got[symbol] = volume.String()
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,439 @@
+package bybit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/services"
+	"alarket/internal/infrastructure/websocket"
+)
+
+const (
+	// Bybit caps the total length of subscribed args per connection, stay well
+	// below it
+	maxTopicsPerConnection = 200
+	maxTopicsPerRequest    = 10
+	baseWSURL              = "wss://stream.bybit.com/v5/public/spot"
+	testnetWSURL           = "wss://stream-testnet.bybit.com/v5/public/spot"
+	publicTradeTopicPrefix = "publicTrade."
+
+	// Bybit recommends an application level ping every 20 seconds
+	pingInterval = 20 * time.Second
+
+	// Bybit answers every op. One that is not answered in time, or whose
+	// connection drops first, is sent again
+	requestTimeout     = 10 * time.Second
+	maxRequestAttempts = 3
+	requestRetryDelay  = 1 * time.Second
+)
+
+var errClientClosed = errors.New("client closed")
+
+// subscriptionRequest is an op request of the v5 public stream.
+type subscriptionRequest struct {
+	ReqID string   `json:"req_id"`
+	Op    string   `json:"op"`
+	Args  []string `json:"args"`
+}
+
+// opResponse answers an op request, echoing its req_id.
+type opResponse struct {
+	Success bool   `json:"success"`
+	RetMsg  string `json:"ret_msg"`
+	ReqID   string `json:"req_id"`
+	Op      string `json:"op"`
+}
+
+// OpError is a subscribe or unsubscribe op that Bybit answered with
+// success=false, e.g. for an unknown topic.
+type OpError struct {
+	Op  string
+	Msg string
+}
+
+func (e *OpError) Error() string {
+	return fmt.Sprintf("bybit rejected %s: %s", e.Op, e.Msg)
+}
+
+// Client streams Bybit spot public trades. Other streams are not offered and
+// fail with services.ErrStreamNotSupported.
+type Client struct {
+	wsManager      *websocket.Manager
+	logger         *slog.Logger
+	wsURL          string
+	topicCount     atomic.Int32
+	connectionID   atomic.Int32
+	subscriptions  map[string]string // topic -> connectionID
+	mu             sync.RWMutex
+	messageHandler websocket.MessageHandler
+	requestID      atomic.Int64
+	requests       *websocket.Requests
+	requestTimeout time.Duration
+	retryDelay     time.Duration
+	dryRun         bool
+	planned        []string // Topics in subscription order, dry runs only
+}
+
+func NewClient(logger *slog.Logger, useTestnet bool, messageHandler websocket.MessageHandler) *Client {
+	wsURL := baseWSURL
+	if useTestnet {
+		wsURL = testnetWSURL
+	}
+
+	client := &Client{
+		logger:         logger,
+		wsURL:          wsURL,
+		subscriptions:  make(map[string]string),
+		messageHandler: messageHandler,
+		requests:       websocket.NewRequests(),
+		requestTimeout: requestTimeout,
+		retryDelay:     requestRetryDelay,
+	}
+	client.wsManager = websocket.NewManager(logger, client.handleMessage, client.resubscribe, 0)
+	client.wsManager.SetPingMessage([]byte(`{"op":"ping"}`), pingInterval)
+	client.wsManager.SetMetricLabels(string(entities.ExchangeBybit), string(entities.MarketSpot))
+
+	return client
+}
+
+// NewDryRunClient returns a client that opens no connections. Subscriptions
+// are spread over planned connections the way NewClient would spread them.
+func NewDryRunClient(logger *slog.Logger, useTestnet bool) *Client {
+	client := NewClient(logger, useTestnet, nil)
+	client.dryRun = true
+	return client
+}
+
+func (c *Client) SubscribeToTrades(ctx context.Context, symbols []string) error {
+	return c.subscribe(ctx, tradeTopics(symbols))
+}
+
+func (c *Client) SubscribeToAggTrades(ctx context.Context, symbols []string) error {
+	return notSupported("aggregate trades")
+}
+
+func (c *Client) SubscribeToKlines(ctx context.Context, symbols []string, intervals []entities.KlineInterval) error {
+	return notSupported("klines")
+}
+
+func (c *Client) SubscribeToDepth(ctx context.Context, symbols []string) error {
+	return notSupported("diff depth")
+}
+
+func (c *Client) SubscribeToBookTickers(ctx context.Context, symbols []string) error {
+	return notSupported("book tickers")
+}
+
+func (c *Client) SubscribeToMarkPrices(ctx context.Context, symbols []string) error {
+	return notSupported("mark prices")
+}
+
+func (c *Client) UnsubscribeFromTrades(ctx context.Context, symbols []string) error {
+	return c.unsubscribe(ctx, tradeTopics(symbols))
+}
+
+func (c *Client) UnsubscribeFromAggTrades(ctx context.Context, symbols []string) error {
+	return notSupported("aggregate trades")
+}
+
+func (c *Client) UnsubscribeFromKlines(ctx context.Context, symbols []string, intervals []entities.KlineInterval) error {
+	return notSupported("klines")
+}
+
+func (c *Client) UnsubscribeFromDepth(ctx context.Context, symbols []string) error {
+	return notSupported("diff depth")
+}
+
+func (c *Client) UnsubscribeFromBookTickers(ctx context.Context, symbols []string) error {
+	return notSupported("book tickers")
+}
+
+func (c *Client) UnsubscribeFromMarkPrices(ctx context.Context, symbols []string) error {
+	return notSupported("mark prices")
+}
+
+func (c *Client) Close() error {
+	c.requests.FailAll(errClientClosed)
+	return c.wsManager.CloseAll()
+}
+
+// PendingRequests returns the number of subscribe and unsubscribe ops that
+// have not been answered yet.
+func (c *Client) PendingRequests() int {
+	return c.requests.Pending()
+}
+
+// handleMessage completes the op a response answers and hands every other
+// frame, including pongs, on. Op responses start with the success flag, topic
+// messages with the topic.
+func (c *Client) handleMessage(message []byte) error {
+	if !bytes.HasPrefix(message, []byte(`{"success"`)) {
+		return c.messageHandler(message)
+	}
+
+	var resp opResponse
+	if err := json.Unmarshal(message, &resp); err != nil {
+		return fmt.Errorf("failed to parse op response: %w", err)
+	}
+	if resp.ReqID == "" {
+		return c.messageHandler(message)
+	}
+
+	var err error
+	if !resp.Success {
+		err = &OpError{Op: resp.Op, Msg: resp.RetMsg}
+	}
+	if !c.requests.Resolve(resp.ReqID, err) {
+		c.logger.Warn("Response to unknown request", "reqId", resp.ReqID, "error", err)
+	}
+	return nil
+}
+
+func notSupported(stream string) error {
+	return fmt.Errorf("bybit %s: %w", stream, services.ErrStreamNotSupported)
+}
+
+func (c *Client) subscribe(ctx context.Context, topics []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dryRun {
+		c.plan(topics)
+		return nil
+	}
+
+	connectionTopics := make(map[string][]string)
+	for _, topic := range topics {
+		if _, exists := c.subscriptions[topic]; exists {
+			c.logger.Debug("Topic already subscribed", "topic", topic)
+			continue
+		}
+
+		connID, err := c.findOrCreateConnection(ctx)
+		if err != nil {
+			return err
+		}
+		connectionTopics[connID] = append(connectionTopics[connID], topic)
+		c.subscriptions[topic] = connID
+	}
+
+	for connID, connTopics := range connectionTopics {
+		if err := c.sendOp(ctx, connID, "subscribe", connTopics); err != nil {
+			for _, topic := range connTopics {
+				delete(c.subscriptions, topic)
+			}
+			return fmt.Errorf("failed to subscribe on connection %s: %w", connID, err)
+		}
+		c.topicCount.Add(int32(len(connTopics)))
+		c.logger.Info("Subscribed to topics", "connection", connID, "count", len(connTopics))
+	}
+
+	return nil
+}
+
+func (c *Client) unsubscribe(ctx context.Context, topics []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dryRun {
+		c.unplan(topics)
+		return nil
+	}
+
+	connectionTopics := make(map[string][]string)
+	for _, topic := range topics {
+		if connID, exists := c.subscriptions[topic]; exists {
+			connectionTopics[connID] = append(connectionTopics[connID], topic)
+		}
+	}
+
+	for connID, connTopics := range connectionTopics {
+		if err := c.sendOp(ctx, connID, "unsubscribe", connTopics); err != nil {
+			c.logger.Error("Failed to unsubscribe", "connection", connID, "error", err)
+			continue
+		}
+
+		for _, topic := range connTopics {
+			delete(c.subscriptions, topic)
+		}
+		c.topicCount.Add(-int32(len(connTopics)))
+		c.logger.Info("Unsubscribed from topics", "connection", connID, "count", len(connTopics))
+	}
+
+	return nil
+}
+
+// resubscribe replays subscribe ops for every topic owned by a connection after
+// the websocket manager has re-dialed it.
+func (c *Client) resubscribe(connID string) error {
+	// Ops sent on the old connection will never be answered, let their callers
+	// retry. This has to happen before taking the lock they may hold
+	c.requests.FailConnection(connID, websocket.ErrConnectionReset)
+
+	c.mu.RLock()
+	topics := make([]string, 0)
+	for topic, id := range c.subscriptions {
+		if id == connID {
+			topics = append(topics, topic)
+		}
+	}
+	c.mu.RUnlock()
+
+	if len(topics) == 0 {
+		return nil
+	}
+	sort.Strings(topics)
+
+	c.logger.Info("Resubscribing topics after reconnect", "connection", connID, "count", len(topics))
+	return c.sendOp(context.Background(), connID, "subscribe", topics)
+}
+
+func (c *Client) findOrCreateConnection(ctx context.Context) (string, error) {
+	topicCounts := make(map[string]int)
+	for _, connID := range c.subscriptions {
+		topicCounts[connID]++
+	}
+
+	for connID, count := range topicCounts {
+		if count < maxTopicsPerConnection {
+			return connID, nil
+		}
+	}
+
+	connID := fmt.Sprintf("conn-%d", c.connectionID.Add(1))
+	if err := c.wsManager.Connect(ctx, c.wsURL, connID); err != nil {
+		return "", fmt.Errorf("failed to create new connection: %w", err)
+	}
+	return connID, nil
+}
+
+// plan assigns topics to connections without opening any, filling the first
+// connection with room. Must be called with c.mu held.
+func (c *Client) plan(topics []string) {
+	topicCounts := make(map[string]int)
+	for _, connID := range c.subscriptions {
+		topicCounts[connID]++
+	}
+
+	for _, topic := range topics {
+		if _, exists := c.subscriptions[topic]; exists {
+			continue
+		}
+
+		connID := ""
+		for i := int32(1); i <= c.connectionID.Load(); i++ {
+			id := fmt.Sprintf("conn-%d", i)
+			if count, ok := topicCounts[id]; ok && count < maxTopicsPerConnection {
+				connID = id
+				break
+			}
+		}
+		if connID == "" {
+			connID = fmt.Sprintf("conn-%d", c.connectionID.Add(1))
+		}
+
+		c.subscriptions[topic] = connID
+		c.planned = append(c.planned, topic)
+		topicCounts[connID]++
+	}
+}
+
+// unplan drops topics from their planned connections. Must be called with
+// c.mu held.
+func (c *Client) unplan(topics []string) {
+	for _, topic := range topics {
+		delete(c.subscriptions, topic)
+	}
+	planned := c.planned[:0]
+	for _, topic := range c.planned {
+		if _, exists := c.subscriptions[topic]; exists {
+			planned = append(planned, topic)
+		}
+	}
+	c.planned = planned
+}
+
+// Connections returns the connections a dry run client would have opened, in
+// the order it would have opened them.
+func (c *Client) Connections() []services.PlannedConnection {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var connections []services.PlannedConnection
+	index := make(map[string]int)
+	for _, topic := range c.planned {
+		connID := c.subscriptions[topic]
+		i, ok := index[connID]
+		if !ok {
+			i = len(connections)
+			index[connID] = i
+			connections = append(connections, services.PlannedConnection{ID: connID, URL: c.wsURL})
+		}
+		connections[i].Streams = append(connections[i].Streams, topic)
+	}
+	return connections
+}
+
+// sendOp sends topics in requests of at most maxTopicsPerRequest args and
+// waits for each to be answered. Requests that time out or lose their
+// connection are sent again.
+func (c *Client) sendOp(ctx context.Context, connID, op string, topics []string) error {
+	for i := 0; i < len(topics); i += maxTopicsPerRequest {
+		args := topics[i:min(i+maxTopicsPerRequest, len(topics))]
+
+		err := websocket.Retry(ctx, maxRequestAttempts, c.retryDelay, func() error {
+			err := c.sendOpOnce(ctx, connID, op, args)
+			if err != nil && websocket.IsTransient(err) {
+				c.logger.Warn("Op failed, retrying", "op", op, "connection", connID, "error", err)
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) sendOpOnce(ctx context.Context, connID, op string, args []string) error {
+	req := subscriptionRequest{
+		ReqID: strconv.FormatInt(c.requestID.Add(1), 10),
+		Op:    op,
+		Args:  args,
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", op, err)
+	}
+
+	result := c.requests.Add(req.ReqID, connID)
+	if err := c.wsManager.Send(ctx, connID, data); err != nil {
+		c.requests.Remove(req.ReqID)
+		return fmt.Errorf("%w %s on %s: %w", websocket.ErrSendFailed, req.ReqID, connID, err)
+	}
+
+	if err := c.requests.Await(ctx, req.ReqID, result, c.requestTimeout); err != nil {
+		return fmt.Errorf("%s request %s on %s: %w", op, req.ReqID, connID, err)
+	}
+	return nil
+}
+
+// tradeTopics builds publicTrade.<SYMBOL> topics, e.g. publicTrade.BTCUSDT.
+func tradeTopics(symbols []string) []string {
+	topics := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		topics[i] = publicTradeTopicPrefix + symbol
+	}
+	return topics
+}
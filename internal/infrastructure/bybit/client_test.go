@@ -0,0 +1,170 @@
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/services"
+)
+
+func TestClient_SubscribeToTrades(t *testing.T) {
+	requests := make(chan subscriptionRequest, 10)
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var req subscriptionRequest
+			if err := json.Unmarshal(msg, &req); err != nil {
+				continue
+			}
+			requests <- req
+
+			resp := fmt.Sprintf(`{"success":true,"ret_msg":"","req_id":%q,"op":%q}`, req.ReqID, req.Op)
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(resp)); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(slog.Default(), false, func(message []byte) error { return nil })
+	client.wsURL = "ws" + strings.TrimPrefix(server.URL, "http")
+	defer func() { _ = client.Close() }()
+
+	symbols := make([]string, 12)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYM%dUSDT", i)
+	}
+	require.NoError(t, client.SubscribeToTrades(context.Background(), symbols))
+
+	// Requests carry at most 10 topics
+	var topics []string
+	for _, size := range []int{10, 2} {
+		select {
+		case req := <-requests:
+			assert.Equal(t, "subscribe", req.Op)
+			assert.Len(t, req.Args, size)
+			topics = append(topics, req.Args...)
+		case <-time.After(5 * time.Second):
+			t.Fatal("subscribe request was not sent")
+		}
+	}
+	assert.Equal(t, tradeTopics(symbols), topics)
+	assert.Equal(t, "publicTrade.SYM0USDT", topics[0])
+	assert.Equal(t, int32(12), client.topicCount.Load())
+	assert.Equal(t, 0, client.PendingRequests())
+}
+
+func TestClient_SurfacesRejectedOps(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var req subscriptionRequest
+			if err := json.Unmarshal(msg, &req); err != nil {
+				continue
+			}
+
+			resp := fmt.Sprintf(`{"success":false,"ret_msg":"error:handler not found,topic:%s","req_id":%q,"op":%q}`,
+				req.Args[0], req.ReqID, req.Op)
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(resp)); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	var forwarded atomic.Int32
+	client := NewClient(slog.Default(), false, func(message []byte) error {
+		forwarded.Add(1)
+		return nil
+	})
+	client.wsURL = "ws" + strings.TrimPrefix(server.URL, "http")
+	defer func() { _ = client.Close() }()
+
+	err := client.SubscribeToTrades(context.Background(), []string{"NOPE"})
+
+	var rejected *OpError
+	require.ErrorAs(t, err, &rejected)
+	assert.Equal(t, "subscribe", rejected.Op)
+	assert.Contains(t, rejected.Msg, "publicTrade.NOPE")
+	assert.Empty(t, client.subscriptions)
+	assert.Equal(t, int32(0), forwarded.Load(), "op responses are not handed to the message handler")
+}
+
+func TestClient_ForwardsPongs(t *testing.T) {
+	var forwarded atomic.Int32
+	client := NewClient(slog.Default(), false, func(message []byte) error {
+		forwarded.Add(1)
+		return nil
+	})
+
+	pong := `{"success":true,"ret_msg":"pong","conn_id":"abc","req_id":"","op":"ping"}`
+	require.NoError(t, client.handleMessage([]byte(pong)))
+	assert.Equal(t, int32(1), forwarded.Load())
+}
+
+func TestClient_UnsupportedStreams(t *testing.T) {
+	client := NewClient(slog.Default(), false, func(message []byte) error { return nil })
+	ctx := context.Background()
+	symbols := []string{"BTCUSDT"}
+
+	assert.ErrorIs(t, client.SubscribeToAggTrades(ctx, symbols), services.ErrStreamNotSupported)
+	assert.ErrorIs(t, client.SubscribeToKlines(ctx, symbols, []entities.KlineInterval{"1m"}), services.ErrStreamNotSupported)
+	assert.ErrorIs(t, client.SubscribeToDepth(ctx, symbols), services.ErrStreamNotSupported)
+	assert.ErrorIs(t, client.SubscribeToBookTickers(ctx, symbols), services.ErrStreamNotSupported)
+}
+
+func TestDryRunClient(t *testing.T) {
+	ctx := context.Background()
+	client := NewDryRunClient(slog.Default(), false)
+
+	symbols := make([]string, maxTopicsPerConnection+1)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYM%dUSDT", i)
+	}
+	require.NoError(t, client.SubscribeToTrades(ctx, symbols))
+	require.NoError(t, client.UnsubscribeFromTrades(ctx, symbols[:1]))
+	require.NoError(t, client.SubscribeToTrades(ctx, []string{"BTCUSDT"}))
+
+	connections := client.Connections()
+	require.Len(t, connections, 2)
+	assert.Equal(t, "conn-1", connections[0].ID)
+	assert.Equal(t, "wss://stream.bybit.com/v5/public/spot", connections[0].URL)
+	assert.Len(t, connections[0].Streams, maxTopicsPerConnection)
+	assert.Equal(t, "publicTrade.BTCUSDT", connections[0].Streams[maxTopicsPerConnection-1])
+	assert.Equal(t, []string{"publicTrade.SYM200USDT"}, connections[1].Streams)
+}
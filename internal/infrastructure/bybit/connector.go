@@ -0,0 +1,56 @@
+package bybit
+
+import (
+	"context"
+	"log/slog"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/services"
+)
+
+// Connector plugs Bybit spot public trades into the collector.
+type Connector struct {
+	useTestnet bool
+	logger     *slog.Logger
+}
+
+func NewConnector(useTestnet bool, logger *slog.Logger) *Connector {
+	return &Connector{
+		useTestnet: useTestnet,
+		logger:     logger.With("exchange", entities.ExchangeBybit),
+	}
+}
+
+func (c *Connector) Exchange() entities.Exchange {
+	return entities.ExchangeBybit
+}
+
+func (c *Connector) Market() entities.Market {
+	return entities.MarketSpot
+}
+
+func (c *Connector) FetchSymbols(ctx context.Context) ([]*entities.Symbol, error) {
+	return NewSymbolFetcher(c.useTestnet, c.logger).FetchAllSymbols(ctx)
+}
+
+func (c *Connector) NewClient(handler func(message []byte) error) services.ExchangeClient {
+	return NewClient(c.logger, c.useTestnet, handler)
+}
+
+func (c *Connector) NewDryRunClient() services.DryRunClient {
+	return NewDryRunClient(c.logger, c.useTestnet)
+}
+
+func (c *Connector) Decoder() services.MessageDecoder {
+	return NewDecoder()
+}
+
+// HistoricalTrades returns nil: the public API only serves the most recent
+// trades, so gaps cannot be backfilled by trade ID.
+func (c *Connector) HistoricalTrades() services.HistoricalDataService {
+	return nil
+}
+
+func (c *Connector) Tickers() (services.TickerService, error) {
+	return NewTickerService(c.useTestnet, c.logger), nil
+}
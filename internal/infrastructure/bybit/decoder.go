@@ -0,0 +1,121 @@
+package bybit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/services"
+)
+
+// frame is the envelope of every message on the v5 public stream. Topic
+// messages carry data, op responses (subscribe, ping) carry op and success.
+type frame struct {
+	Topic   string          `json:"topic"`
+	Type    string          `json:"type"`
+	Ts      int64           `json:"ts"`
+	Data    json.RawMessage `json:"data"`
+	Op      string          `json:"op"`
+	Success *bool           `json:"success"`
+	RetMsg  string          `json:"ret_msg"`
+}
+
+type publicTradeDTO struct {
+	TradeTime  int64  `json:"T"`
+	Symbol     string `json:"s"`
+	Side       string `json:"S"` // taker side, Buy or Sell
+	Quantity   string `json:"v"`
+	Price      string `json:"p"`
+	TradeID    string `json:"i"`
+	BlockTrade bool   `json:"BT"`
+}
+
+// Decoder turns raw v5 public stream frames into domain events.
+type Decoder struct{}
+
+func NewDecoder() services.MessageDecoder {
+	return &Decoder{}
+}
+
+// Decode returns one trade per entry of a publicTrade frame. Op responses
+// decode to no events, unless the op was rejected.
+func (d *Decoder) Decode(message []byte) ([]any, error) {
+	var f frame
+	if err := json.Unmarshal(message, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse message: %w", err)
+	}
+
+	if f.Op != "" {
+		if f.Success != nil && !*f.Success {
+			return nil, fmt.Errorf("bybit %s request failed: %s", f.Op, f.RetMsg)
+		}
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(f.Topic, publicTradeTopicPrefix) {
+		return nil, nil
+	}
+
+	var entries []publicTradeDTO
+	if err := json.Unmarshal(f.Data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse public trade event: %w", err)
+	}
+
+	events := make([]any, 0, len(entries))
+	for _, entry := range entries {
+		trade, err := decodeTrade(entry, time.UnixMilli(f.Ts))
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, trade)
+	}
+	return events, nil
+}
+
+func decodeTrade(entry publicTradeDTO, eventTime time.Time) (*entities.Trade, error) {
+	// Spot trade IDs are numeric strings
+	id, err := strconv.ParseUint(entry.TradeID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trade ID: %w", err)
+	}
+
+	price, err := decimal.NewFromString(entry.Price)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price: %w", err)
+	}
+
+	quantity, err := decimal.NewFromString(entry.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity: %w", err)
+	}
+
+	var isBuyerMaker bool
+	switch entry.Side {
+	case "Buy":
+	case "Sell":
+		// The taker sold into a resting bid
+		isBuyerMaker = true
+	default:
+		return nil, fmt.Errorf("invalid side %q", entry.Side)
+	}
+
+	trade := entities.NewTrade(
+		id,
+		entry.Symbol,
+		price,
+		quantity,
+		time.UnixMilli(entry.TradeTime),
+		isBuyerMaker,
+		eventTime,
+		entities.TradeSourceLive,
+	)
+	trade.Exchange = entities.ExchangeBybit
+	trade.Market = entities.MarketSpot
+
+	return trade, nil
+}
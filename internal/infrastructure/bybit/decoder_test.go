@@ -0,0 +1,95 @@
+package bybit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/domain/entities"
+)
+
+// Frames recorded from wss://stream.bybit.com/v5/public/spot.
+func loadFrame(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return data
+}
+
+func TestDecoder_PublicTrade(t *testing.T) {
+	events, err := NewDecoder().Decode(loadFrame(t, "public_trade_btcusdt.json"))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	buy, ok := events[0].(*entities.Trade)
+	require.True(t, ok)
+	assert.Equal(t, uint64(2290000000061666327), buy.ID)
+	assert.Equal(t, "BTCUSDT", buy.Symbol)
+	assert.Equal(t, "36512.45", buy.Price.String())
+	assert.Equal(t, "0.001531", buy.Quantity.String())
+	assert.Equal(t, "55.90056095", buy.QuoteQuantity.String())
+	assert.Equal(t, time.UnixMilli(1700000000120), buy.Time)
+	assert.Equal(t, time.UnixMilli(1700000000123), buy.EventTime)
+	assert.False(t, buy.IsBuyerMaker)
+	assert.Equal(t, entities.TradeSourceLive, buy.Source)
+	assert.Equal(t, entities.ExchangeBybit, buy.Exchange)
+	assert.NoError(t, buy.Validate())
+
+	sell, ok := events[1].(*entities.Trade)
+	require.True(t, ok)
+	assert.Equal(t, uint64(2290000000061666328), sell.ID)
+	assert.Equal(t, "36512.4", sell.Price.String())
+	assert.True(t, sell.IsBuyerMaker)
+}
+
+func TestDecoder_ControlFrames(t *testing.T) {
+	decoder := NewDecoder()
+
+	for _, name := range []string{"subscribe_ok.json", "pong.json"} {
+		t.Run(name, func(t *testing.T) {
+			events, err := decoder.Decode(loadFrame(t, name))
+			require.NoError(t, err)
+			assert.Empty(t, events)
+		})
+	}
+
+	t.Run("rejected subscription", func(t *testing.T) {
+		_, err := decoder.Decode(loadFrame(t, "subscribe_failed.json"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "publicTrade.FOOBAR")
+	})
+}
+
+func TestDecoder_InvalidFrames(t *testing.T) {
+	decoder := NewDecoder()
+
+	tests := []struct {
+		name    string
+		message string
+		err     string
+	}{
+		{"invalid JSON", `not json`, "failed to parse message"},
+		{"invalid price", `{"topic":"publicTrade.BTCUSDT","ts":1,"data":[{"i":"1","T":1,"p":"x","v":"1","S":"Buy","s":"BTCUSDT"}]}`, "invalid price"},
+		{"invalid quantity", `{"topic":"publicTrade.BTCUSDT","ts":1,"data":[{"i":"1","T":1,"p":"1","v":"x","S":"Buy","s":"BTCUSDT"}]}`, "invalid quantity"},
+		{"non numeric trade ID", `{"topic":"publicTrade.BTCUSDT","ts":1,"data":[{"i":"20f43950-d8dd","T":1,"p":"1","v":"1","S":"Buy","s":"BTCUSDT"}]}`, "invalid trade ID"},
+		{"unknown side", `{"topic":"publicTrade.BTCUSDT","ts":1,"data":[{"i":"1","T":1,"p":"1","v":"1","S":"None","s":"BTCUSDT"}]}`, "invalid side"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decoder.Decode([]byte(tt.message))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	t.Run("other topics are ignored", func(t *testing.T) {
+		events, err := decoder.Decode([]byte(`{"topic":"tickers.BTCUSDT","ts":1,"type":"snapshot","data":{}}`))
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+}
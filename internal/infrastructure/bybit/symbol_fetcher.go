@@ -0,0 +1,153 @@
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"alarket/internal/domain/entities"
+)
+
+const (
+	baseAPIURL        = "https://api.bybit.com"
+	testnetAPIURL     = "https://api-testnet.bybit.com"
+	instrumentsPath   = "/v5/market/instruments-info"
+	instrumentsLimit  = 1000
+	httpClientTimeout = 30 * time.Second
+)
+
+type instrumentsResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		List []struct {
+			Symbol        string `json:"symbol"`
+			BaseCoin      string `json:"baseCoin"`
+			QuoteCoin     string `json:"quoteCoin"`
+			Status        string `json:"status"`
+			MarginTrading string `json:"marginTrading"`
+			LotSizeFilter struct {
+				BasePrecision string `json:"basePrecision"`
+				MinOrderAmt   string `json:"minOrderAmt"`
+			} `json:"lotSizeFilter"`
+			PriceFilter struct {
+				TickSize string `json:"tickSize"`
+			} `json:"priceFilter"`
+		} `json:"list"`
+		NextPageCursor string `json:"nextPageCursor"`
+	} `json:"result"`
+}
+
+type SymbolFetcher struct {
+	client  *http.Client
+	baseURL string
+	logger  *slog.Logger
+}
+
+func NewSymbolFetcher(useTestnet bool, logger *slog.Logger) *SymbolFetcher {
+	baseURL := baseAPIURL
+	if useTestnet {
+		baseURL = testnetAPIURL
+	}
+
+	return &SymbolFetcher{
+		client:  &http.Client{Timeout: httpClientTimeout},
+		baseURL: baseURL,
+		logger:  logger,
+	}
+}
+
+// FetchAllSymbols lists every spot instrument, following the page cursor.
+func (f *SymbolFetcher) FetchAllSymbols(ctx context.Context) ([]*entities.Symbol, error) {
+	var symbols []*entities.Symbol
+	cursor := ""
+
+	for {
+		page, err := f.fetchPage(ctx, cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range page.Result.List {
+			symbol := entities.NewSymbol(s.Symbol, s.BaseCoin, s.QuoteCoin, symbolStatus(s.Status))
+			symbol.IsSpotTrading = true
+			symbol.Market = entities.MarketSpot
+			symbol.IsMarginTrading = s.MarginTrading != "" && s.MarginTrading != "none"
+			symbol.TickSize = filterValue(s.PriceFilter.TickSize)
+			symbol.StepSize = filterValue(s.LotSizeFilter.BasePrecision)
+			symbol.MinNotional = filterValue(s.LotSizeFilter.MinOrderAmt)
+			symbols = append(symbols, symbol)
+		}
+
+		cursor = page.Result.NextPageCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	f.logger.Info("Fetched symbols from exchange", "count", len(symbols))
+	return symbols, nil
+}
+
+func (f *SymbolFetcher) fetchPage(ctx context.Context, cursor string) (*instrumentsResponse, error) {
+	query := url.Values{}
+	query.Set("category", "spot")
+	query.Set("limit", fmt.Sprint(instrumentsLimit))
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.baseURL+instrumentsPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch instruments info: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch instruments info: unexpected status %s", resp.Status)
+	}
+
+	var page instrumentsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode instruments info: %w", err)
+	}
+	if page.RetCode != 0 {
+		return nil, fmt.Errorf("failed to fetch instruments info: %d %s", page.RetCode, page.RetMsg)
+	}
+
+	return &page, nil
+}
+
+// symbolStatus maps Bybit statuses onto the Binance style ones used across the
+// collector, so only "Trading" symbols count as active.
+func symbolStatus(status string) entities.SymbolStatus {
+	switch status {
+	case "Trading":
+		return entities.SymbolStatusTrading
+	case "PreLaunch":
+		return entities.SymbolStatusPreTrading
+	default:
+		return entities.SymbolStatus(strings.ToUpper(status))
+	}
+}
+
+// filterValue parses an instrument filter, missing or invalid values are zero.
+func filterValue(value string) decimal.Decimal {
+	parsed, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero
+	}
+	return parsed
+}
@@ -0,0 +1,86 @@
+package bybit
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/domain/entities"
+)
+
+func newTestSymbolFetcher(t *testing.T, handler http.HandlerFunc) *SymbolFetcher {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	fetcher := NewSymbolFetcher(false, slog.Default())
+	fetcher.baseURL = server.URL
+	return fetcher
+}
+
+func TestSymbolFetcher_FetchAllSymbols(t *testing.T) {
+	fetcher := newTestSymbolFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, instrumentsPath, r.URL.Path)
+		assert.Equal(t, "spot", r.URL.Query().Get("category"))
+
+		// Serve the recorded page twice, split by a cursor
+		if r.URL.Query().Get("cursor") == "" {
+			_, _ = w.Write([]byte(`{"retCode":0,"retMsg":"OK","result":{"category":"spot","list":[{"symbol":"SOLUSDT","baseCoin":"SOL","quoteCoin":"USDT","status":"Trading","marginTrading":"utaOnly"}],"nextPageCursor":"page2"}}`))
+			return
+		}
+		assert.Equal(t, "page2", r.URL.Query().Get("cursor"))
+		_, _ = w.Write(loadFrame(t, "instruments_info.json"))
+	})
+
+	symbols, err := fetcher.FetchAllSymbols(context.Background())
+	require.NoError(t, err)
+	require.Len(t, symbols, 4)
+
+	assert.Equal(t, "SOLUSDT", symbols[0].Name)
+	assert.True(t, symbols[0].IsMarginTrading)
+
+	btc := symbols[1]
+	assert.Equal(t, "BTCUSDT", btc.Name)
+	assert.Equal(t, "BTC", btc.BaseAsset)
+	assert.Equal(t, "USDT", btc.QuoteAsset)
+	assert.Equal(t, entities.SymbolStatusTrading, btc.Status)
+	assert.True(t, btc.IsActive())
+	assert.True(t, btc.IsSpotTrading)
+	assert.True(t, btc.IsMarginTrading)
+	assert.Equal(t, "0.01", btc.TickSize.String())
+	assert.Equal(t, "0.000001", btc.StepSize.String())
+	assert.Equal(t, "1", btc.MinNotional.String())
+
+	assert.False(t, symbols[2].IsMarginTrading)
+	assert.True(t, symbols[2].TickSize.IsZero())
+
+	assert.Equal(t, entities.SymbolStatusPreTrading, symbols[3].Status)
+	assert.False(t, symbols[3].IsActive())
+}
+
+func TestSymbolFetcher_Errors(t *testing.T) {
+	t.Run("error ret code", func(t *testing.T) {
+		fetcher := newTestSymbolFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"retCode":10001,"retMsg":"params error","result":{}}`))
+		})
+
+		_, err := fetcher.FetchAllSymbols(context.Background())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "params error")
+	})
+
+	t.Run("http error", func(t *testing.T) {
+		fetcher := newTestSymbolFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		})
+
+		_, err := fetcher.FetchAllSymbols(context.Background())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "403")
+	})
+}
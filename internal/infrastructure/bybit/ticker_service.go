@@ -0,0 +1,87 @@
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/shopspring/decimal"
+)
+
+const tickersPath = "/v5/market/tickers"
+
+type tickersResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		List []struct {
+			Symbol      string `json:"symbol"`
+			Turnover24h string `json:"turnover24h"`
+		} `json:"list"`
+	} `json:"result"`
+}
+
+// TickerService reads the 24h ticker statistics of every spot symbol.
+type TickerService struct {
+	client  *http.Client
+	baseURL string
+	logger  *slog.Logger
+}
+
+func NewTickerService(useTestnet bool, logger *slog.Logger) *TickerService {
+	baseURL := baseAPIURL
+	if useTestnet {
+		baseURL = testnetAPIURL
+	}
+
+	return &TickerService{
+		client:  &http.Client{Timeout: httpClientTimeout},
+		baseURL: baseURL,
+		logger:  logger,
+	}
+}
+
+// FetchQuoteVolumes returns the 24h turnover of every spot symbol, which Bybit
+// reports in the quote coin.
+func (s *TickerService) FetchQuoteVolumes(ctx context.Context) (map[string]decimal.Decimal, error) {
+	query := url.Values{}
+	query.Set("category", "spot")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+tickersPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tickers: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch tickers: unexpected status %s", resp.Status)
+	}
+
+	var response tickersResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode tickers: %w", err)
+	}
+	if response.RetCode != 0 {
+		return nil, fmt.Errorf("failed to fetch tickers: %d %s", response.RetCode, response.RetMsg)
+	}
+
+	volumes := make(map[string]decimal.Decimal, len(response.Result.List))
+	for _, t := range response.Result.List {
+		volume, err := decimal.NewFromString(t.Turnover24h)
+		if err != nil {
+			s.logger.Warn("Failed to parse 24h turnover", "symbol", t.Symbol, "turnover24h", t.Turnover24h, "error", err)
+			continue
+		}
+		volumes[t.Symbol] = volume
+	}
+
+	return volumes, nil
+}
@@ -0,0 +1,48 @@
+package bybit
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTickerService_FetchQuoteVolumes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, tickersPath, r.URL.Path)
+		assert.Equal(t, "spot", r.URL.Query().Get("category"))
+
+		_, _ = w.Write([]byte(`{"retCode":0,"retMsg":"OK","result":{"category":"spot","list":[
+			{"symbol":"BTCUSDT","lastPrice":"43000","volume24h":"1200.5","turnover24h":"51621500.5"},
+			{"symbol":"ETHUSDT","lastPrice":"2300","volume24h":"10","turnover24h":"23000"},
+			{"symbol":"NEWUSDT","lastPrice":"","volume24h":"","turnover24h":""}
+		]}}`))
+	}))
+	defer server.Close()
+
+	service := NewTickerService(false, slog.Default())
+	service.baseURL = server.URL
+
+	volumes, err := service.FetchQuoteVolumes(context.Background())
+	require.NoError(t, err)
+	require.Len(t, volumes, 2)
+	assert.Equal(t, "51621500.5", volumes["BTCUSDT"].String())
+	assert.Equal(t, "23000", volumes["ETHUSDT"].String())
+
+	t.Run("error code", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"retCode":10001,"retMsg":"params error","result":{}}`))
+		}))
+		defer server.Close()
+
+		service := NewTickerService(false, slog.Default())
+		service.baseURL = server.URL
+
+		_, err := service.FetchQuoteVolumes(context.Background())
+		assert.ErrorContains(t, err, "10001 params error")
+	})
+}
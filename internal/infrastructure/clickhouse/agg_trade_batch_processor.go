@@ -0,0 +1,30 @@
+package clickhouse
+
+import (
+	"log/slog"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+)
+
+type AggTradeBatchProcessor struct {
+	*batchProcessor[*entities.AggTrade]
+}
+
+func NewAggTradeBatchProcessor(
+	aggTradeRepo repositories.AggTradeRepository,
+	logger *slog.Logger,
+	batchSize int,
+	flushTimeout time.Duration,
+	poolConfig FlushPoolConfig,
+	spool *Spool[*entities.AggTrade],
+) *AggTradeBatchProcessor {
+	return &AggTradeBatchProcessor{
+		batchProcessor: newBatchProcessor("agg_trades", aggTradeRepo.SaveBatch, logger, batchSize, flushTimeout, poolConfig, spool),
+	}
+}
+
+func (p *AggTradeBatchProcessor) AddAggTrade(aggTrade *entities.AggTrade) error {
+	return p.add(aggTrade)
+}
@@ -0,0 +1,150 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+)
+
+// AggTradeRepository stores aggregate trades of every exchange and market.
+// Reads are scoped to the exchange and market it was created for.
+type AggTradeRepository struct {
+	db       *sql.DB
+	exchange entities.Exchange
+	market   entities.Market
+}
+
+func NewAggTradeRepository(db *sql.DB, exchange entities.Exchange, market entities.Market) repositories.AggTradeRepository {
+	return &AggTradeRepository{db: db, exchange: exchange, market: market}
+}
+
+func (r *AggTradeRepository) Save(ctx context.Context, aggTrade *entities.AggTrade) error {
+	query := `
+		INSERT INTO agg_trades (
+			exchange, market, id, symbol, price, quantity, first_trade_id, last_trade_id,
+			trade_time, is_buyer_market_maker, is_best_match, event_time, source
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		exchangeValue(aggTrade.Exchange),
+		marketValue(aggTrade.Market),
+		aggTrade.ID,
+		aggTrade.Symbol,
+		aggTrade.Price,
+		aggTrade.Quantity,
+		aggTrade.FirstTradeID,
+		aggTrade.LastTradeID,
+		aggTrade.Time,
+		aggTrade.IsBuyerMaker,
+		aggTrade.IsBestMatch,
+		aggTrade.EventTime,
+		sourceValue(aggTrade.Source),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save aggregate trade: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AggTradeRepository) SaveBatch(ctx context.Context, aggTrades []*entities.AggTrade) error {
+	if len(aggTrades) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	batch, err := tx.Prepare(`
+		INSERT INTO agg_trades (
+			exchange, market, id, symbol, price, quantity, first_trade_id, last_trade_id,
+			trade_time, is_buyer_market_maker, is_best_match, event_time, source
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer func() { _ = batch.Close() }()
+
+	for _, aggTrade := range aggTrades {
+		_, err := batch.Exec(
+			exchangeValue(aggTrade.Exchange),
+			marketValue(aggTrade.Market),
+			aggTrade.ID,
+			aggTrade.Symbol,
+			aggTrade.Price,
+			aggTrade.Quantity,
+			aggTrade.FirstTradeID,
+			aggTrade.LastTradeID,
+			aggTrade.Time,
+			aggTrade.IsBuyerMaker,
+			aggTrade.IsBestMatch,
+			aggTrade.EventTime,
+			sourceValue(aggTrade.Source),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add aggregate trade to batch %d: %w", aggTrade.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AggTradeRepository) GetBySymbol(ctx context.Context, symbol string, from, to time.Time) ([]*entities.AggTrade, error) {
+	query := `
+		SELECT exchange, market, id, symbol, price, quantity, first_trade_id, last_trade_id,
+		       trade_time, is_buyer_market_maker, is_best_match, event_time, source
+		FROM agg_trades FINAL
+		WHERE exchange = ? AND market = ? AND symbol = ? AND trade_time >= ? AND trade_time <= ?
+		ORDER BY trade_time, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, r.exchange, r.market, symbol, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query aggregate trades: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var aggTrades []*entities.AggTrade
+	for rows.Next() {
+		var aggTrade entities.AggTrade
+		var exchange, market, source string
+		err := rows.Scan(
+			&exchange,
+			&market,
+			&aggTrade.ID,
+			&aggTrade.Symbol,
+			&aggTrade.Price,
+			&aggTrade.Quantity,
+			&aggTrade.FirstTradeID,
+			&aggTrade.LastTradeID,
+			&aggTrade.Time,
+			&aggTrade.IsBuyerMaker,
+			&aggTrade.IsBestMatch,
+			&aggTrade.EventTime,
+			&source,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate trade: %w", err)
+		}
+		aggTrade.Source = entities.TradeSource(source)
+		aggTrade.Exchange = entities.Exchange(exchange)
+		aggTrade.Market = entities.Market(market)
+		aggTrades = append(aggTrades, &aggTrade)
+	}
+
+	return aggTrades, nil
+}
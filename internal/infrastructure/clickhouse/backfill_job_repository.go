@@ -0,0 +1,168 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+)
+
+const backfillJobColumns = `
+	id, symbol, direction, range_from, range_to, cursor, fetched, status, error, created_at, updated_at
+`
+
+const backfillJobProgressColumns = `
+	id, cursor, fetched, owner, lease_until, updated_at
+`
+
+// selectBackfillJobs reads the jobs with their progress. Jobs without a
+// progress row keep the cursor stored with their status.
+const selectBackfillJobs = `
+	SELECT
+		j.id, j.symbol, j.direction, j.range_from, j.range_to,
+		if(p.id = '', j.cursor, p.cursor),
+		if(p.id = '', j.fetched, p.fetched),
+		j.status, j.error, p.owner, p.lease_until, j.created_at,
+		greatest(j.updated_at, p.updated_at)
+	FROM backfill_jobs AS j FINAL
+	LEFT JOIN (
+		SELECT ` + backfillJobProgressColumns + ` FROM backfill_job_progress FINAL
+	) AS p ON p.id = j.id
+`
+
+// BackfillJobRepository keeps the backfill job queue in the backfill_jobs
+// table and the progress of the jobs in backfill_job_progress.
+type BackfillJobRepository struct {
+	db *sql.DB
+}
+
+func NewBackfillJobRepository(db *sql.DB) repositories.BackfillJobRepository {
+	return &BackfillJobRepository{db: db}
+}
+
+func (r *BackfillJobRepository) Save(ctx context.Context, job *entities.BackfillJob) error {
+	if err := r.SaveStatus(ctx, job); err != nil {
+		return err
+	}
+	return r.SaveProgress(ctx, job)
+}
+
+func (r *BackfillJobRepository) SaveStatus(ctx context.Context, job *entities.BackfillJob) error {
+	query := `INSERT INTO backfill_jobs (` + backfillJobColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		job.ID,
+		job.Symbol,
+		string(job.Direction),
+		job.From,
+		job.To,
+		job.Cursor,
+		job.Fetched,
+		string(job.Status),
+		job.Error,
+		job.CreatedAt,
+		job.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save backfill job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *BackfillJobRepository) SaveProgress(ctx context.Context, job *entities.BackfillJob) error {
+	query := `INSERT INTO backfill_job_progress (` + backfillJobProgressColumns + `) VALUES (?, ?, ?, ?, ?, ?)`
+
+	// DateTime64 starts at 1900, a job without a lease stores the epoch
+	leaseUntil := job.LeaseUntil
+	if leaseUntil.IsZero() {
+		leaseUntil = time.Unix(0, 0)
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		job.ID,
+		job.Cursor,
+		job.Fetched,
+		job.Owner,
+		leaseUntil,
+		job.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save backfill job progress: %w", err)
+	}
+
+	return nil
+}
+
+func (r *BackfillJobRepository) GetByID(ctx context.Context, id string) (*entities.BackfillJob, error) {
+	query := selectBackfillJobs + `
+		WHERE j.id = ?
+	`
+
+	job, err := scanBackfillJob(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("backfill job %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *BackfillJobRepository) GetAll(ctx context.Context) ([]*entities.BackfillJob, error) {
+	query := selectBackfillJobs + `
+		ORDER BY j.created_at, j.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query backfill jobs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var jobs []*entities.BackfillJob
+	for rows.Next() {
+		job, err := scanBackfillJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read backfill jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func scanBackfillJob(row rowScanner) (*entities.BackfillJob, error) {
+	var job entities.BackfillJob
+	var direction, status string
+	err := row.Scan(
+		&job.ID,
+		&job.Symbol,
+		&direction,
+		&job.From,
+		&job.To,
+		&job.Cursor,
+		&job.Fetched,
+		&status,
+		&job.Error,
+		&job.Owner,
+		&job.LeaseUntil,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan backfill job: %w", err)
+	}
+	job.Direction = entities.BackfillDirection(direction)
+	job.Status = entities.BackfillJobStatus(status)
+	return &job, nil
+}
@@ -0,0 +1,166 @@
+package clickhouse
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"alarket/internal/infrastructure/metrics"
+)
+
+// batchEntry is an entity that checks itself before it is buffered.
+type batchEntry interface {
+	Validate() error
+}
+
+// batchProcessor buffers the entries of one table and hands them to a flush
+// pool once batchSize entries are buffered or flushTimeout has passed since the
+// first one. The table specific processors embed it and supply the save func.
+type batchProcessor[T batchEntry] struct {
+	name         string
+	logger       *slog.Logger
+	batchSize    int
+	flushTimeout time.Duration
+	entries      []T
+	mu           sync.Mutex
+	flushTimer   *time.Timer
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	pool         *flushPool[T]
+	buffered     prometheus.Gauge
+}
+
+func newBatchProcessor[T batchEntry](
+	name string,
+	save SaveFunc[T],
+	logger *slog.Logger,
+	batchSize int,
+	flushTimeout time.Duration,
+	poolConfig FlushPoolConfig,
+	spool *Spool[T],
+) *batchProcessor[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	processor := &batchProcessor[T]{
+		name:         name,
+		logger:       logger,
+		batchSize:    batchSize,
+		flushTimeout: flushTimeout,
+		entries:      make([]T, 0, batchSize),
+		ctx:          ctx,
+		cancel:       cancel,
+		buffered:     metrics.BatchBuffered.WithLabelValues(name),
+		pool:         newFlushPool(name, save, spool, logger, poolConfig),
+	}
+
+	processor.flushTimer = time.NewTimer(flushTimeout)
+	processor.flushTimer.Stop() // Don't start timer until first entry
+
+	// Start background flush routine
+	processor.wg.Add(1)
+	go processor.flushRoutine()
+
+	return processor
+}
+
+func (p *batchProcessor[T]) add(entry T) error {
+	if err := entry.Validate(); err != nil {
+		p.logger.Error("Invalid batch entry", "batch", p.name, "error", err)
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Add entry to batch
+	p.entries = append(p.entries, entry)
+	p.buffered.Set(float64(len(p.entries)))
+
+	// Start timer if this is the first entry in batch
+	if len(p.entries) == 1 {
+		p.flushTimer.Reset(p.flushTimeout)
+	}
+
+	// Check if batch is full
+	if len(p.entries) >= p.batchSize {
+		p.flushBatch()
+	}
+
+	p.logger.Debug("Entry added to batch", "batch", p.name, "batchSize", len(p.entries))
+
+	return nil
+}
+
+// SetBatchSize changes the size and flush timeout of the batches that follow.
+// A batch that already holds batchSize entries is flushed right away.
+func (p *batchProcessor[T]) SetBatchSize(batchSize int, flushTimeout time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.batchSize = batchSize
+	p.flushTimeout = flushTimeout
+	if len(p.entries) >= p.batchSize {
+		p.flushBatch()
+	}
+}
+
+func (p *batchProcessor[T]) flushRoutine() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			// Flush remaining entries on shutdown
+			p.mu.Lock()
+			if len(p.entries) > 0 {
+				p.logger.Info("Graceful shutdown received, flushing remaining batch",
+					"batch", p.name, "batchSize", len(p.entries))
+				p.flushBatch()
+			}
+			p.mu.Unlock()
+			return
+
+		case <-p.flushTimer.C:
+			p.mu.Lock()
+			if len(p.entries) > 0 {
+				p.flushBatch()
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+func (p *batchProcessor[T]) flushBatch() {
+	if len(p.entries) == 0 {
+		return
+	}
+
+	// Create a copy of entries to flush
+	batch := make([]T, len(p.entries))
+	copy(batch, p.entries)
+
+	// Clear the current batch
+	p.entries = p.entries[:0]
+	p.buffered.Set(0)
+	p.flushTimer.Stop()
+
+	// Hand off to the flush workers, blocking here applies backpressure
+	p.pool.submit(batch)
+}
+
+func (p *batchProcessor[T]) Close() error {
+	p.cancel()
+	p.wg.Wait()
+
+	// Ensure timer is stopped
+	if p.flushTimer != nil {
+		p.flushTimer.Stop()
+	}
+
+	// Wait for queued and in-flight writes
+	return p.pool.close()
+}
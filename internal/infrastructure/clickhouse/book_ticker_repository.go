@@ -21,12 +21,14 @@ func NewBookTickerRepository(db *sql.DB) repositories.BookTickerRepository {
 func (r *BookTickerRepository) Save(ctx context.Context, ticker *entities.BookTicker) error {
 	query := `
 		INSERT INTO book_tickers (
-			update_id, symbol, best_bid_price, best_bid_quantity,
+			exchange, market, update_id, symbol, best_bid_price, best_bid_quantity,
 			best_ask_price, best_ask_quantity, transaction_time, event_time
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
+		exchangeValue(ticker.Exchange),
+		marketValue(ticker.Market),
 		ticker.UpdateID,
 		ticker.Symbol,
 		ticker.BestBidPrice,
@@ -57,7 +59,7 @@ func (r *BookTickerRepository) SaveBatch(ctx context.Context, tickers []*entitie
 
 	batch, err := tx.Prepare(`
 		INSERT INTO book_tickers (
-			update_id, symbol, best_bid_price, best_bid_quantity,
+			exchange, market, update_id, symbol, best_bid_price, best_bid_quantity,
 			best_ask_price, best_ask_quantity, transaction_time, event_time
 		)
 	`)
@@ -68,6 +70,8 @@ func (r *BookTickerRepository) SaveBatch(ctx context.Context, tickers []*entitie
 
 	for _, ticker := range tickers {
 		_, err := batch.Exec(
+			exchangeValue(ticker.Exchange),
+			marketValue(ticker.Market),
 			ticker.UpdateID,
 			ticker.Symbol,
 			ticker.BestBidPrice,
@@ -91,7 +95,7 @@ func (r *BookTickerRepository) SaveBatch(ctx context.Context, tickers []*entitie
 
 func (r *BookTickerRepository) GetLatestBySymbol(ctx context.Context, symbol string) (*entities.BookTicker, error) {
 	query := `
-		SELECT update_id, symbol, best_bid_price, best_bid_quantity,
+		SELECT exchange, market, update_id, symbol, best_bid_price, best_bid_quantity,
 			   best_ask_price, best_ask_quantity, transaction_time, event_time
 		FROM book_tickers
 		WHERE symbol = ?
@@ -100,7 +104,10 @@ func (r *BookTickerRepository) GetLatestBySymbol(ctx context.Context, symbol str
 	`
 
 	var ticker entities.BookTicker
+	var exchange, market string
 	err := r.db.QueryRowContext(ctx, query, symbol).Scan(
+		&exchange,
+		&market,
 		&ticker.UpdateID,
 		&ticker.Symbol,
 		&ticker.BestBidPrice,
@@ -117,13 +124,15 @@ func (r *BookTickerRepository) GetLatestBySymbol(ctx context.Context, symbol str
 	if err != nil {
 		return nil, fmt.Errorf("failed to get latest book ticker: %w", err)
 	}
+	ticker.Exchange = entities.Exchange(exchange)
+	ticker.Market = entities.Market(market)
 
 	return &ticker, nil
 }
 
 func (r *BookTickerRepository) GetBySymbol(ctx context.Context, symbol string, from, to time.Time) ([]*entities.BookTicker, error) {
 	query := `
-		SELECT update_id, symbol, best_bid_price, best_bid_quantity,
+		SELECT exchange, market, update_id, symbol, best_bid_price, best_bid_quantity,
 			   best_ask_price, best_ask_quantity, transaction_time, event_time
 		FROM book_tickers
 		WHERE symbol = ? AND event_time >= ? AND event_time <= ?
@@ -139,7 +148,10 @@ func (r *BookTickerRepository) GetBySymbol(ctx context.Context, symbol string, f
 	var tickers []*entities.BookTicker
 	for rows.Next() {
 		var ticker entities.BookTicker
+		var exchange, market string
 		err := rows.Scan(
+			&exchange,
+			&market,
 			&ticker.UpdateID,
 			&ticker.Symbol,
 			&ticker.BestBidPrice,
@@ -152,6 +164,8 @@ func (r *BookTickerRepository) GetBySymbol(ctx context.Context, symbol string, f
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan book ticker: %w", err)
 		}
+		ticker.Exchange = entities.Exchange(exchange)
+		ticker.Market = entities.Market(market)
 		tickers = append(tickers, &ticker)
 	}
 
@@ -0,0 +1,201 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"alarket/internal/infrastructure/metrics"
+)
+
+const flushWriteTimeout = 10 * time.Second
+
+// OverflowPolicy decides what happens to a batch when the flush queue is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits for queue space, which in turn blocks the websocket reader.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropOldest discards the oldest queued batch to make room.
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowSpill writes the batch straight to the spool.
+	OverflowSpill OverflowPolicy = "spill"
+)
+
+func ParseOverflowPolicy(value string) (OverflowPolicy, error) {
+	switch policy := OverflowPolicy(value); policy {
+	case OverflowBlock, OverflowDropOldest, OverflowSpill:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown overflow policy %q (expected %s, %s or %s)",
+			value, OverflowBlock, OverflowDropOldest, OverflowSpill)
+	}
+}
+
+type FlushPoolConfig struct {
+	Workers   int
+	QueueSize int
+	Overflow  OverflowPolicy
+}
+
+func DefaultFlushPoolConfig() FlushPoolConfig {
+	return FlushPoolConfig{
+		Workers:   4,
+		QueueSize: 16,
+		Overflow:  OverflowBlock,
+	}
+}
+
+// flushPool writes batches to ClickHouse with a fixed number of workers fed
+// from a bounded queue. Batches that fail to write go to the spool.
+type flushPool[T any] struct {
+	name   string
+	save   SaveFunc[T]
+	spool  *Spool[T] // nil = failed batches are dropped
+	logger *slog.Logger
+	policy OverflowPolicy
+	queue  chan []T
+	mu     sync.RWMutex // guards closed against concurrent submits
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func newFlushPool[T any](
+	name string,
+	save SaveFunc[T],
+	spool *Spool[T],
+	logger *slog.Logger,
+	config FlushPoolConfig,
+) *flushPool[T] {
+	workers := max(config.Workers, 1)
+	queueSize := max(config.QueueSize, 1)
+
+	pool := &flushPool[T]{
+		name:   name,
+		save:   save,
+		spool:  spool,
+		logger: logger.With("batch", name),
+		policy: config.Overflow,
+		queue:  make(chan []T, queueSize),
+	}
+
+	pool.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pool.worker()
+	}
+
+	return pool
+}
+
+// submit queues a batch for writing, applying the overflow policy when the
+// queue is full.
+func (p *flushPool[T]) submit(batch []T) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		p.logger.Error("Flush pool closed, spooling batch", "batchSize", len(batch))
+		p.spoolBatch(batch)
+		return
+	}
+
+	select {
+	case p.queue <- batch:
+		return
+	default:
+	}
+
+	switch p.policy {
+	case OverflowDropOldest:
+		for {
+			select {
+			case p.queue <- batch:
+				return
+			case oldest := <-p.queue:
+				p.logger.Warn("Flush queue full, dropped oldest batch", "batchSize", len(oldest))
+			}
+		}
+
+	case OverflowSpill:
+		p.logger.Warn("Flush queue full, spilling batch to disk", "batchSize", len(batch))
+		p.spoolBatch(batch)
+
+	default:
+		p.logger.Warn("Flush queue full, waiting for a free worker", "batchSize", len(batch))
+		p.queue <- batch
+	}
+}
+
+func (p *flushPool[T]) worker() {
+	defer p.wg.Done()
+
+	for batch := range p.queue {
+		p.write(batch)
+	}
+}
+
+func (p *flushPool[T]) write(batch []T) {
+	ctx, cancel := context.WithTimeout(context.Background(), flushWriteTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := p.save(ctx, batch)
+	duration := time.Since(start)
+	metrics.FlushDuration.WithLabelValues(p.name).Observe(duration.Seconds())
+	if err != nil {
+		metrics.FlushFailures.WithLabelValues(p.name).Inc()
+		p.logger.Error("Failed to flush batch",
+			"error", err,
+			"batchSize", len(batch),
+		)
+		p.spoolBatch(batch)
+		return
+	}
+
+	p.logger.Info("Batch flushed successfully",
+		"batchSize", len(batch),
+		"duration", duration,
+	)
+
+	if p.spool != nil && p.spool.Pending() > 0 {
+		// ClickHouse is accepting writes again, replay anything spooled
+		p.spool.Wake()
+	}
+}
+
+// spoolBatch hands a batch over to the spool so it is retried later instead
+// of being lost.
+func (p *flushPool[T]) spoolBatch(batch []T) {
+	if p.spool == nil {
+		p.logger.Error("No spool configured, batch is lost", "batchSize", len(batch))
+		return
+	}
+	if err := p.spool.Put(batch); err != nil {
+		p.logger.Error("Failed to spool batch, batch is lost",
+			"error", err,
+			"batchSize", len(batch),
+		)
+	}
+}
+
+// close stops accepting batches, waits until every queued and in-flight
+// write has finished and then closes the spool.
+func (p *flushPool[T]) close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.queue)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+
+	if p.spool != nil {
+		return p.spool.Close()
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package clickhouse
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedStore blocks every save until release is closed.
+type gatedStore struct {
+	release chan struct{}
+	started chan struct{}
+	mu      sync.Mutex
+	saved   []string
+}
+
+func newGatedStore() *gatedStore {
+	return &gatedStore{
+		release: make(chan struct{}),
+		started: make(chan struct{}, 100),
+	}
+}
+
+func (s *gatedStore) save(ctx context.Context, batch []string) error {
+	s.started <- struct{}{}
+	<-s.release
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved = append(s.saved, batch...)
+	return nil
+}
+
+func (s *gatedStore) savedItems() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.saved...)
+}
+
+func newTestPool(t *testing.T, store *gatedStore, policy OverflowPolicy, spool *Spool[string]) *flushPool[string] {
+	t.Helper()
+	pool := newFlushPool("test", store.save, spool, slog.Default(), FlushPoolConfig{
+		Workers:   1,
+		QueueSize: 1,
+		Overflow:  policy,
+	})
+
+	// Occupy the only worker so the queue fills up
+	pool.submit([]string{"a"})
+	<-store.started
+	pool.submit([]string{"b"})
+
+	return pool
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	for _, value := range []string{"block", "drop_oldest", "spill"} {
+		policy, err := ParseOverflowPolicy(value)
+		require.NoError(t, err)
+		assert.Equal(t, OverflowPolicy(value), policy)
+	}
+
+	_, err := ParseOverflowPolicy("discard")
+	assert.Error(t, err)
+}
+
+func TestFlushPool_BlockPolicyWaitsForQueueSpace(t *testing.T) {
+	store := newGatedStore()
+	pool := newTestPool(t, store, OverflowBlock, nil)
+
+	var submitted atomic.Bool
+	go func() {
+		pool.submit([]string{"c"})
+		submitted.Store(true)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, submitted.Load(), "submit should block while the queue is full")
+
+	close(store.release)
+	assert.Eventually(t, submitted.Load, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, pool.close())
+	assert.Equal(t, []string{"a", "b", "c"}, store.savedItems())
+}
+
+func TestFlushPool_DropOldestPolicy(t *testing.T) {
+	store := newGatedStore()
+	pool := newTestPool(t, store, OverflowDropOldest, nil)
+
+	pool.submit([]string{"c"})
+
+	close(store.release)
+	require.NoError(t, pool.close())
+	assert.Equal(t, []string{"a", "c"}, store.savedItems())
+}
+
+func TestFlushPool_SpillPolicy(t *testing.T) {
+	dir := t.TempDir()
+	store := newGatedStore()
+
+	spool, err := newSpool(dir, "test", func(ctx context.Context, batch []string) error {
+		return nil
+	}, slog.Default())
+	require.NoError(t, err)
+
+	pool := newTestPool(t, store, OverflowSpill, spool)
+	pool.submit([]string{"c"})
+
+	assert.Equal(t, 1, spool.Pending())
+
+	close(store.release)
+	require.NoError(t, pool.close())
+	assert.Equal(t, []string{"a", "b"}, store.savedItems())
+}
+
+func TestFlushPool_CloseWaitsForInFlightWrites(t *testing.T) {
+	var saved atomic.Int32
+	pool := newFlushPool("test", func(ctx context.Context, batch []string) error {
+		time.Sleep(50 * time.Millisecond)
+		saved.Add(int32(len(batch)))
+		return nil
+	}, nil, slog.Default(), FlushPoolConfig{Workers: 2, QueueSize: 4, Overflow: OverflowBlock})
+
+	for i := 0; i < 5; i++ {
+		pool.submit([]string{"x"})
+	}
+
+	require.NoError(t, pool.close())
+	assert.Equal(t, int32(5), saved.Load())
+}
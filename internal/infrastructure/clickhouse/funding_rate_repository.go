@@ -0,0 +1,86 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+)
+
+// FundingRateRepository stores funding rates of every exchange and market.
+// Reads are scoped to the exchange and market it was created for.
+type FundingRateRepository struct {
+	db       *sql.DB
+	exchange entities.Exchange
+	market   entities.Market
+}
+
+func NewFundingRateRepository(db *sql.DB, exchange entities.Exchange, market entities.Market) repositories.FundingRateRepository {
+	return &FundingRateRepository{db: db, exchange: exchange, market: market}
+}
+
+func (r *FundingRateRepository) SaveBatch(ctx context.Context, rates []*entities.FundingRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	batch, err := tx.Prepare(`
+		INSERT INTO funding_rates (
+			exchange, market, symbol, funding_time, funding_rate, mark_price, source
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer func() { _ = batch.Close() }()
+
+	for _, rate := range rates {
+		_, err := batch.Exec(
+			exchangeValue(rate.Exchange),
+			string(rate.Market),
+			rate.Symbol,
+			rate.FundingTime,
+			rate.Rate,
+			rate.MarkPrice,
+			sourceValue(rate.Source),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add funding rate to batch %s: %w", rate.FundingTime.Format(time.RFC3339), err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch: %w", err)
+	}
+
+	return nil
+}
+
+func (r *FundingRateRepository) GetNewestFundingTime(ctx context.Context, symbol string) (*time.Time, error) {
+	query := `
+		SELECT count(), max(funding_time)
+		FROM funding_rates
+		WHERE exchange = ? AND market = ? AND symbol = ?
+	`
+
+	var count uint64
+	var newestTime time.Time
+	if err := r.db.QueryRowContext(ctx, query, r.exchange, r.market, symbol).Scan(&count, &newestTime); err != nil {
+		return nil, fmt.Errorf("failed to get newest funding time: %w", err)
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	return &newestTime, nil
+}
@@ -0,0 +1,30 @@
+package clickhouse
+
+import (
+	"log/slog"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+)
+
+type KlineBatchProcessor struct {
+	*batchProcessor[*entities.Kline]
+}
+
+func NewKlineBatchProcessor(
+	klineRepo repositories.KlineRepository,
+	logger *slog.Logger,
+	batchSize int,
+	flushTimeout time.Duration,
+	poolConfig FlushPoolConfig,
+	spool *Spool[*entities.Kline],
+) *KlineBatchProcessor {
+	return &KlineBatchProcessor{
+		batchProcessor: newBatchProcessor("klines", klineRepo.SaveBatch, logger, batchSize, flushTimeout, poolConfig, spool),
+	}
+}
+
+func (p *KlineBatchProcessor) AddKline(kline *entities.Kline) error {
+	return p.add(kline)
+}
@@ -0,0 +1,151 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+)
+
+// KlineRepository stores klines of every exchange and market. Reads are
+// scoped to the exchange and market it was created for.
+type KlineRepository struct {
+	db       *sql.DB
+	exchange entities.Exchange
+	market   entities.Market
+}
+
+func NewKlineRepository(db *sql.DB, exchange entities.Exchange, market entities.Market) repositories.KlineRepository {
+	return &KlineRepository{db: db, exchange: exchange, market: market}
+}
+
+func (r *KlineRepository) SaveBatch(ctx context.Context, klines []*entities.Kline) error {
+	if len(klines) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	batch, err := tx.Prepare(`
+		INSERT INTO klines (
+			exchange, market, symbol, interval, open_time, close_time, open, high, low, close,
+			volume, quote_volume, taker_buy_volume, taker_buy_quote_volume,
+			trade_count, event_time, source
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer func() { _ = batch.Close() }()
+
+	for _, kline := range klines {
+		_, err := batch.Exec(
+			exchangeValue(kline.Exchange),
+			marketValue(kline.Market),
+			kline.Symbol,
+			string(kline.Interval),
+			kline.OpenTime,
+			kline.CloseTime,
+			kline.Open,
+			kline.High,
+			kline.Low,
+			kline.Close,
+			kline.Volume,
+			kline.QuoteVolume,
+			kline.TakerBuyVolume,
+			kline.TakerBuyQuoteVolume,
+			kline.TradeCount,
+			kline.EventTime,
+			sourceValue(kline.Source),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add kline to batch %s: %w", kline.OpenTime.Format(time.RFC3339), err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch: %w", err)
+	}
+
+	return nil
+}
+
+func (r *KlineRepository) GetBySymbol(ctx context.Context, symbol string, interval entities.KlineInterval, from, to time.Time) ([]*entities.Kline, error) {
+	query := `
+		SELECT exchange, market, symbol, interval, open_time, close_time, open, high, low, close,
+		       volume, quote_volume, taker_buy_volume, taker_buy_quote_volume,
+		       trade_count, event_time, source
+		FROM klines FINAL
+		WHERE exchange = ? AND market = ? AND symbol = ? AND interval = ? AND open_time >= ? AND open_time <= ?
+		ORDER BY open_time
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, r.exchange, r.market, symbol, string(interval), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query klines: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var klines []*entities.Kline
+	for rows.Next() {
+		var kline entities.Kline
+		var exchange, market, interval, source string
+		err := rows.Scan(
+			&exchange,
+			&market,
+			&kline.Symbol,
+			&interval,
+			&kline.OpenTime,
+			&kline.CloseTime,
+			&kline.Open,
+			&kline.High,
+			&kline.Low,
+			&kline.Close,
+			&kline.Volume,
+			&kline.QuoteVolume,
+			&kline.TakerBuyVolume,
+			&kline.TakerBuyQuoteVolume,
+			&kline.TradeCount,
+			&kline.EventTime,
+			&source,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan kline: %w", err)
+		}
+		kline.Interval = entities.KlineInterval(interval)
+		kline.Source = entities.TradeSource(source)
+		kline.Exchange = entities.Exchange(exchange)
+		kline.Market = entities.Market(market)
+		kline.IsClosed = true // Only closed candles are stored
+		klines = append(klines, &kline)
+	}
+
+	return klines, nil
+}
+
+func (r *KlineRepository) GetNewestOpenTime(ctx context.Context, symbol string, interval entities.KlineInterval) (*time.Time, error) {
+	query := `
+		SELECT count(), max(open_time)
+		FROM klines
+		WHERE exchange = ? AND market = ? AND symbol = ? AND interval = ?
+	`
+
+	var count uint64
+	var newestTime time.Time
+	if err := r.db.QueryRowContext(ctx, query, r.exchange, r.market, symbol, string(interval)).Scan(&count, &newestTime); err != nil {
+		return nil, fmt.Errorf("failed to get newest kline open time: %w", err)
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	return &newestTime, nil
+}
@@ -0,0 +1,30 @@
+package clickhouse
+
+import (
+	"log/slog"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+)
+
+type MarkPriceBatchProcessor struct {
+	*batchProcessor[*entities.MarkPrice]
+}
+
+func NewMarkPriceBatchProcessor(
+	markPriceRepo repositories.MarkPriceRepository,
+	logger *slog.Logger,
+	batchSize int,
+	flushTimeout time.Duration,
+	poolConfig FlushPoolConfig,
+	spool *Spool[*entities.MarkPrice],
+) *MarkPriceBatchProcessor {
+	return &MarkPriceBatchProcessor{
+		batchProcessor: newBatchProcessor("mark_prices", markPriceRepo.SaveBatch, logger, batchSize, flushTimeout, poolConfig, spool),
+	}
+}
+
+func (p *MarkPriceBatchProcessor) AddMarkPrice(markPrice *entities.MarkPrice) error {
+	return p.add(markPrice)
+}
@@ -0,0 +1,65 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+)
+
+type MarkPriceRepository struct {
+	db *sql.DB
+}
+
+func NewMarkPriceRepository(db *sql.DB) repositories.MarkPriceRepository {
+	return &MarkPriceRepository{db: db}
+}
+
+func (r *MarkPriceRepository) SaveBatch(ctx context.Context, markPrices []*entities.MarkPrice) error {
+	if len(markPrices) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	batch, err := tx.Prepare(`
+		INSERT INTO mark_prices (
+			exchange, market, symbol, mark_price, index_price, estimated_settle_price,
+			funding_rate, next_funding_time, event_time
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer func() { _ = batch.Close() }()
+
+	for _, markPrice := range markPrices {
+		_, err := batch.Exec(
+			exchangeValue(markPrice.Exchange),
+			string(markPrice.Market),
+			markPrice.Symbol,
+			markPrice.MarkPrice,
+			markPrice.IndexPrice,
+			markPrice.EstimatedSettlePrice,
+			markPrice.FundingRate,
+			markPrice.NextFundingTime,
+			markPrice.EventTime,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add mark price to batch for %s at %s: %w", markPrice.Symbol, markPrice.EventTime.Format(time.RFC3339), err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch: %w", err)
+	}
+
+	return nil
+}
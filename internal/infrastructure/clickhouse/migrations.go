@@ -2,76 +2,496 @@ package clickhouse
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"embed"
+	"encoding/hex"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const (
+	migrationsDir       = "migrations"
+	legacyTradesFile    = "legacy_trades.sql"
+	schemaMigrationsDDL = `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version UInt32,
+			name String,
+			checksum String,
+			applied UInt8,
+			applied_at DateTime64(3)
+		)
+		ENGINE = ReplacingMergeTree(applied_at)
+		ORDER BY version
+	`
+)
+
+// migrationFileName matches files like 0001_create_trades_table.up.sql.
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change. Up and Down hold the raw SQL of
+// the embedded files, Checksum identifies the Up script that was applied.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrationStatus describes a migration as seen by the database.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type appliedMigration struct {
+	version   int
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+type migrationStep struct {
+	migration Migration
+	up        bool
+}
+
+// Migrator applies the versioned migrations embedded in the binary and keeps
+// track of them in the schema_migrations table. ClickHouse has no
+// transactional DDL, so every statement must be safe to run again after a
+// partial failure.
 type Migrator struct {
-	db     *sql.DB
-	logger *slog.Logger
+	db         *sql.DB
+	logger     *slog.Logger
+	migrations []Migration
+	dryRun     bool
 }
 
-func NewMigrator(db *sql.DB, logger *slog.Logger) *Migrator {
-	return &Migrator{
-		db:     db,
-		logger: logger,
+func NewMigrator(db *sql.DB, logger *slog.Logger) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, migrationsDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid embedded migrations: %w", err)
 	}
+
+	return &Migrator{
+		db:         db,
+		logger:     logger,
+		migrations: migrations,
+	}, nil
 }
 
+// SetDryRun makes the migrator log the statements it would run instead of
+// executing them.
+func (m *Migrator) SetDryRun(dryRun bool) {
+	m.dryRun = dryRun
+}
+
+// Migrate applies all pending migrations.
 func (m *Migrator) Migrate(ctx context.Context) error {
-	migrations := []struct {
-		name  string
-		query string
-	}{
-		{
-			name: "create_trades_table",
-			query: `
-				CREATE TABLE IF NOT EXISTS trades (
-					id String,
-					symbol String,
-					price Float64,
-					quantity Float64,
-					trade_time DateTime64(3),
-					is_buyer_market_maker Bool,
-					event_time DateTime64(3),
-					created_at DateTime64(3) DEFAULT now64(3)
-				)
-				ENGINE = MergeTree()
-				PARTITION BY toYYYYMM(trade_time)
-				ORDER BY (symbol, trade_time, id)
-				SETTINGS index_granularity = 8192
-			`,
-		},
-		{
-			name: "create_book_tickers_table",
-			query: `
-				CREATE TABLE IF NOT EXISTS book_tickers (
-					update_id Int64,
-					symbol String,
-					best_bid_price Float64,
-					best_bid_quantity Float64,
-					best_ask_price Float64,
-					best_ask_quantity Float64,
-					transaction_time DateTime64(3),
-					event_time DateTime64(3),
-					created_at DateTime64(3) DEFAULT now64(3)
-				)
-				ENGINE = MergeTree()
-				PARTITION BY toYYYYMM(event_time)
-				ORDER BY (symbol, event_time, update_id)
-				SETTINGS index_granularity = 8192
-			`,
-		},
+	return m.Up(ctx)
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.latestVersion())
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	versions := appliedVersions(applied)
+	if len(versions) == 0 {
+		m.logger.Info("No migrations to roll back")
+		return nil
+	}
+
+	target := 0
+	if len(versions) > 1 {
+		target = versions[len(versions)-2]
+	}
+	return m.To(ctx, target)
+}
+
+// To migrates up or down until exactly the migrations up to and including
+// version are applied. Version 0 rolls back everything.
+func (m *Migrator) To(ctx context.Context, version int) error {
+	if version != 0 && !m.knownVersion(version) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyChecksums(m.migrations, applied); err != nil {
+		return err
+	}
+
+	if len(applied) == 0 && version > 0 {
+		if err := m.upgradeLegacyTrades(ctx); err != nil {
+			return fmt.Errorf("failed to upgrade legacy trades table: %w", err)
+		}
+	}
+
+	steps := planMigrations(m.migrations, applied, version)
+	if len(steps) == 0 {
+		m.logger.Info("Schema is up to date", "version", version)
+		return nil
+	}
+
+	for _, step := range steps {
+		if err := m.apply(ctx, step); err != nil {
+			return err
+		}
+	}
+
+	m.logger.Info("All migrations completed successfully", "version", version, "dryRun", m.dryRun)
+	return nil
+}
+
+// Status lists every known migration and whether it has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyChecksums(m.migrations, applied); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, step migrationStep) error {
+	direction, script := "down", step.migration.Down
+	if step.up {
+		direction, script = "up", step.migration.Up
+	}
+
+	m.logger.Info("Running migration",
+		"version", step.migration.Version,
+		"name", step.migration.Name,
+		"direction", direction,
+		"dryRun", m.dryRun)
+
+	if err := m.exec(ctx, splitStatements(script)); err != nil {
+		return fmt.Errorf("failed to run migration %04d_%s (%s): %w",
+			step.migration.Version, step.migration.Name, direction, err)
+	}
+
+	if m.dryRun {
+		return nil
+	}
+
+	var applied uint8
+	if step.up {
+		applied = 1
+	}
+
+	_, err := m.db.ExecContext(ctx, `
+		INSERT INTO schema_migrations (version, name, checksum, applied, applied_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, uint32(step.migration.Version), step.migration.Name, step.migration.Checksum, applied, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record migration %04d_%s: %w", step.migration.Version, step.migration.Name, err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) exec(ctx context.Context, statements []string) error {
+	for _, statement := range statements {
+		if m.dryRun {
+			m.logger.Info("Dry run, not executing statement", "statement", statement)
+			continue
+		}
+		if _, err := m.db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applied returns the migrations currently applied, keyed by version. The
+// schema_migrations table is created on first use.
+func (m *Migrator) applied(ctx context.Context) (map[int]appliedMigration, error) {
+	exists, err := m.tableExists(ctx, "schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		if m.dryRun {
+			return map[int]appliedMigration{}, nil
+		}
+		if _, err := m.db.ExecContext(ctx, schemaMigrationsDDL); err != nil {
+			return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+		}
+	}
+
+	// Every up and down is recorded as a new row, FINAL keeps the latest one
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT version, name, checksum, applied, applied_at
+		FROM schema_migrations FINAL
+		ORDER BY version
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var (
+			version uint32
+			record  appliedMigration
+			flag    uint8
+		)
+		if err := rows.Scan(&version, &record.name, &record.checksum, &flag, &record.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema migration: %w", err)
+		}
+		if flag == 0 {
+			continue
+		}
+		record.version = int(version)
+		applied[record.version] = record
+	}
+
+	return applied, rows.Err()
+}
+
+func (m *Migrator) tableExists(ctx context.Context, table string) (bool, error) {
+	var count uint64
+	err := m.db.QueryRowContext(ctx, `
+		SELECT count()
+		FROM system.tables
+		WHERE database = currentDatabase() AND name = ?
+	`, table).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check table %s: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// upgradeLegacyTrades converts a trades table created before versioned
+// migrations into the current schema. Only databases without any recorded
+// migration are checked.
+func (m *Migrator) upgradeLegacyTrades(ctx context.Context) error {
+	var engine string
+	err := m.db.QueryRowContext(ctx, `
+		SELECT engine
+		FROM system.tables
+		WHERE database = currentDatabase() AND name = 'trades'
+	`).Scan(&engine)
+	if err == sql.ErrNoRows {
+		return nil
 	}
+	if err != nil {
+		return fmt.Errorf("failed to inspect trades table: %w", err)
+	}
+
+	if engine != "MergeTree" {
+		return nil
+	}
+
+	m.logger.Warn("Found legacy trades table, copying it into the deduplicating schema", "dryRun", m.dryRun)
+
+	script, err := fs.ReadFile(migrationFiles, path.Join(migrationsDir, legacyTradesFile))
+	if err != nil {
+		return err
+	}
+	if err := m.exec(ctx, splitStatements(string(script))); err != nil {
+		return err
+	}
+
+	if m.dryRun {
+		m.logger.Info("Would replace legacy trades table, keeping old data in trades_legacy")
+		return nil
+	}
+	m.logger.Info("Legacy trades table replaced, old data kept in trades_legacy")
+	return nil
+}
+
+func (m *Migrator) latestVersion() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) knownVersion(version int) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// loadMigrations reads NNNN_name.up.sql / NNNN_name.down.sql pairs from dir
+// and returns them ordered by version.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
 
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d used by both %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(data)
+			migration.Checksum = checksum(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// planMigrations returns the steps that take the schema from the applied set
+// to target: pending migrations up to target in ascending order, or applied
+// migrations above target in descending order.
+func planMigrations(migrations []Migration, applied map[int]appliedMigration, target int) []migrationStep {
+	var steps []migrationStep
+
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; !ok && migration.Version <= target {
+			steps = append(steps, migrationStep{migration: migration, up: true})
+		}
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; ok && migration.Version > target {
+			steps = append(steps, migrationStep{migration: migration, up: false})
+		}
+	}
+
+	return steps
+}
+
+// verifyChecksums fails when an applied migration was edited after it ran or
+// is missing from this binary.
+func verifyChecksums(migrations []Migration, applied map[int]appliedMigration) error {
+	known := make(map[int]Migration, len(migrations))
 	for _, migration := range migrations {
-		m.logger.Info("Running migration", "name", migration.name)
-		if _, err := m.db.ExecContext(ctx, migration.query); err != nil {
-			return fmt.Errorf("failed to run migration %s: %w", migration.name, err)
+		known[migration.Version] = migration
+	}
+
+	for _, version := range appliedVersions(applied) {
+		record := applied[version]
+		migration, ok := known[version]
+		if !ok {
+			return fmt.Errorf("applied migration %04d_%s is unknown to this binary", version, record.name)
+		}
+		if migration.Checksum != record.checksum {
+			return fmt.Errorf("checksum mismatch for applied migration %04d_%s: the file was changed after it was applied",
+				version, migration.Name)
 		}
 	}
 
-	m.logger.Info("All migrations completed successfully")
 	return nil
 }
+
+func appliedVersions(applied map[int]appliedMigration) []int {
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// splitStatements splits a script into single statements, ClickHouse runs
+// one statement per query. Comment lines are dropped; semicolons inside
+// string literals are not supported.
+func splitStatements(script string) []string {
+	var lines []string
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	var statements []string
+	for _, statement := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,16 @@
+-- Trades are deduplicated on (symbol, id): the live stream, historical
+-- backfill and file import overlap and may write the same trade twice.
+CREATE TABLE IF NOT EXISTS trades (
+    id Int64,
+    symbol String,
+    price Float64,
+    quantity Float64,
+    trade_time DateTime64(3),
+    is_buyer_market_maker Bool,
+    event_time DateTime64(3),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(trade_time)
+ORDER BY (symbol, id)
+SETTINGS index_granularity = 8192;
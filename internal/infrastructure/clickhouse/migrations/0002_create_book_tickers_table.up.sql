@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS book_tickers (
+    update_id Int64,
+    symbol String,
+    best_bid_price Float64,
+    best_bid_quantity Float64,
+    best_ask_price Float64,
+    best_ask_quantity Float64,
+    transaction_time DateTime64(3),
+    event_time DateTime64(3),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = MergeTree()
+PARTITION BY toYYYYMM(event_time)
+ORDER BY (symbol, event_time, update_id)
+SETTINGS index_granularity = 8192;
@@ -0,0 +1,12 @@
+-- Trade ID ranges that were missing from the live stream
+CREATE TABLE IF NOT EXISTS trade_gaps (
+    symbol String,
+    from_id Int64,
+    to_id Int64,
+    detected_at DateTime64(3),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = MergeTree()
+PARTITION BY toYYYYMM(detected_at)
+ORDER BY (symbol, detected_at, from_id)
+SETTINGS index_granularity = 8192;
@@ -0,0 +1,3 @@
+ALTER TABLE trades
+    DROP COLUMN IF EXISTS buyer_order_id,
+    DROP COLUMN IF EXISTS seller_order_id;
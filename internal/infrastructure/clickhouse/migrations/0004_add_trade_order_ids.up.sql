@@ -0,0 +1,5 @@
+-- Brings the Go schema in line with scripts/clickhouse-init.sql, which has
+-- always declared these columns.
+ALTER TABLE trades
+    ADD COLUMN IF NOT EXISTS buyer_order_id Int64 DEFAULT 0 AFTER quantity,
+    ADD COLUMN IF NOT EXISTS seller_order_id Int64 DEFAULT 0 AFTER buyer_order_id;
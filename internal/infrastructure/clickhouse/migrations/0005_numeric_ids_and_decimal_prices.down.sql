@@ -0,0 +1,53 @@
+DROP TABLE IF EXISTS trades_float;
+
+CREATE TABLE trades_float (
+    id Int64,
+    symbol String,
+    price Float64,
+    quantity Float64,
+    buyer_order_id Int64 DEFAULT 0,
+    seller_order_id Int64 DEFAULT 0,
+    trade_time DateTime64(3),
+    is_buyer_market_maker Bool,
+    event_time DateTime64(3),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(trade_time)
+ORDER BY (symbol, id)
+SETTINGS index_granularity = 8192;
+
+INSERT INTO trades_float (
+    id, symbol, price, quantity, buyer_order_id, seller_order_id,
+    trade_time, is_buyer_market_maker, event_time, created_at
+)
+SELECT
+    toInt64(id), symbol, toFloat64(price), toFloat64(quantity),
+    buyer_order_id, seller_order_id, trade_time, is_buyer_market_maker, event_time, created_at
+FROM trades;
+
+EXCHANGE TABLES trades AND trades_float;
+
+DROP TABLE trades_float;
+
+DROP TABLE IF EXISTS trade_gaps_int64;
+
+CREATE TABLE trade_gaps_int64 (
+    symbol String,
+    from_id Int64,
+    to_id Int64,
+    detected_at DateTime64(3),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = MergeTree()
+PARTITION BY toYYYYMM(detected_at)
+ORDER BY (symbol, detected_at, from_id)
+SETTINGS index_granularity = 8192;
+
+INSERT INTO trade_gaps_int64 (symbol, from_id, to_id, detected_at, created_at)
+SELECT symbol, toInt64(from_id), toInt64(to_id), detected_at, created_at
+FROM trade_gaps;
+
+EXCHANGE TABLES trade_gaps AND trade_gaps_int64;
+
+DROP TABLE trade_gaps_int64;
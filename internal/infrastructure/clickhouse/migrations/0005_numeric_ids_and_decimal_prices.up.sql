@@ -0,0 +1,61 @@
+-- Trade IDs become UInt64 and prices/quantities exact Decimal(38, 18).
+-- Key columns cannot change type in place, so both tables are copied and
+-- swapped. Binance quotes at most 8 decimals, rounding to 8 strips the noise
+-- of the old Float64 values. The previous trades table is kept as
+-- trades_float64.
+
+DROP TABLE IF EXISTS trades_decimal;
+
+CREATE TABLE trades_decimal (
+    id UInt64,
+    symbol String,
+    price Decimal(38, 18),
+    quantity Decimal(38, 18),
+    buyer_order_id Int64 DEFAULT 0,
+    seller_order_id Int64 DEFAULT 0,
+    trade_time DateTime64(3),
+    is_buyer_market_maker Bool,
+    event_time DateTime64(3),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(trade_time)
+ORDER BY (symbol, id)
+SETTINGS index_granularity = 8192;
+
+INSERT INTO trades_decimal (
+    id, symbol, price, quantity, buyer_order_id, seller_order_id,
+    trade_time, is_buyer_market_maker, event_time, created_at
+)
+SELECT
+    toUInt64(id), symbol, round(toDecimal128(price, 18), 8), round(toDecimal128(quantity, 18), 8),
+    buyer_order_id, seller_order_id, trade_time, is_buyer_market_maker, event_time, created_at
+FROM trades;
+
+EXCHANGE TABLES trades AND trades_decimal;
+
+DROP TABLE IF EXISTS trades_float64;
+
+RENAME TABLE trades_decimal TO trades_float64;
+
+DROP TABLE IF EXISTS trade_gaps_uint64;
+
+CREATE TABLE trade_gaps_uint64 (
+    symbol String,
+    from_id UInt64,
+    to_id UInt64,
+    detected_at DateTime64(3),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = MergeTree()
+PARTITION BY toYYYYMM(detected_at)
+ORDER BY (symbol, detected_at, from_id)
+SETTINGS index_granularity = 8192;
+
+INSERT INTO trade_gaps_uint64 (symbol, from_id, to_id, detected_at, created_at)
+SELECT symbol, toUInt64(from_id), toUInt64(to_id), detected_at, created_at
+FROM trade_gaps;
+
+EXCHANGE TABLES trade_gaps AND trade_gaps_uint64;
+
+DROP TABLE trade_gaps_uint64;
@@ -0,0 +1,8 @@
+ALTER TABLE trades
+    MODIFY COLUMN buyer_order_id Int64 DEFAULT 0,
+    MODIFY COLUMN seller_order_id Int64 DEFAULT 0;
+
+ALTER TABLE trades
+    DROP COLUMN IF EXISTS quote_quantity,
+    DROP COLUMN IF EXISTS is_best_match,
+    DROP COLUMN IF EXISTS source;
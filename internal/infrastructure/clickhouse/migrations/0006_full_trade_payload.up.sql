@@ -0,0 +1,12 @@
+-- Carries the full trade payload: quote quantity, best-match flag and the
+-- source a row was collected from. Existing rows derive their quote quantity
+-- from price and quantity and are marked as 'unknown'. Order IDs become
+-- UInt64 like trade IDs.
+ALTER TABLE trades
+    ADD COLUMN IF NOT EXISTS quote_quantity Decimal(38, 18) DEFAULT multiplyDecimal(price, quantity, 18) AFTER quantity,
+    ADD COLUMN IF NOT EXISTS is_best_match Bool DEFAULT false AFTER is_buyer_market_maker,
+    ADD COLUMN IF NOT EXISTS source Enum8('unknown' = 0, 'live' = 1, 'rest' = 2, 'csv' = 3) DEFAULT 'unknown' AFTER event_time;
+
+ALTER TABLE trades
+    MODIFY COLUMN buyer_order_id UInt64 DEFAULT 0,
+    MODIFY COLUMN seller_order_id UInt64 DEFAULT 0;
@@ -0,0 +1,20 @@
+-- Aggregate trades from the aggTrade stream and the public aggTrades endpoint.
+-- Like trades, rows are deduplicated on (symbol, id); read them with FINAL.
+CREATE TABLE IF NOT EXISTS agg_trades (
+    id UInt64,
+    symbol String,
+    price Decimal(38, 18),
+    quantity Decimal(38, 18),
+    first_trade_id UInt64,
+    last_trade_id UInt64,
+    trade_time DateTime64(3),
+    is_buyer_market_maker Bool,
+    is_best_match Bool,
+    event_time DateTime64(3),
+    source Enum8('unknown' = 0, 'live' = 1, 'rest' = 2, 'csv' = 3),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(trade_time)
+ORDER BY (symbol, id)
+SETTINGS index_granularity = 8192;
@@ -0,0 +1,25 @@
+-- Closed candles only. Rows are keyed like trades, on symbol plus interval and
+-- open time, and the row with the latest event_time wins, so writing a candle
+-- again from the live stream or a backfill is an upsert. Read with FINAL.
+CREATE TABLE IF NOT EXISTS klines (
+    symbol String,
+    interval LowCardinality(String),
+    open_time DateTime64(3),
+    close_time DateTime64(3),
+    open Decimal(38, 18),
+    high Decimal(38, 18),
+    low Decimal(38, 18),
+    close Decimal(38, 18),
+    volume Decimal(38, 18),
+    quote_volume Decimal(38, 18),
+    taker_buy_volume Decimal(38, 18),
+    taker_buy_quote_volume Decimal(38, 18),
+    trade_count UInt64,
+    event_time DateTime64(3),
+    source Enum8('unknown' = 0, 'live' = 1, 'rest' = 2, 'csv' = 3),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree(event_time)
+PARTITION BY toYYYYMM(open_time)
+ORDER BY (symbol, interval, open_time)
+SETTINGS index_granularity = 8192;
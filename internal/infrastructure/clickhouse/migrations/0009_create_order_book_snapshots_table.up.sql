@@ -0,0 +1,16 @@
+-- Top N levels of each local order book, captured at a fixed cadence. Level i
+-- of a side is bid_prices[i] / bid_quantities[i], best price first.
+CREATE TABLE IF NOT EXISTS order_book_snapshots (
+    symbol String,
+    snapshot_time DateTime64(3),
+    last_update_id UInt64,
+    bid_prices Array(Decimal(38, 18)),
+    bid_quantities Array(Decimal(38, 18)),
+    ask_prices Array(Decimal(38, 18)),
+    ask_quantities Array(Decimal(38, 18)),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(snapshot_time)
+ORDER BY (symbol, snapshot_time)
+SETTINGS index_granularity = 8192;
@@ -0,0 +1,43 @@
+-- Tags trades and book tickers with the exchange they were collected from.
+-- Trade IDs are only unique per exchange, so the exchange becomes part of the
+-- trades sort key, which cannot change in place: the table is copied and
+-- swapped. Existing rows were all collected from Binance.
+
+DROP TABLE IF EXISTS trades_exchange;
+
+CREATE TABLE trades_exchange (
+    exchange LowCardinality(String) DEFAULT 'binance',
+    id UInt64,
+    symbol String,
+    price Decimal(38, 18),
+    quantity Decimal(38, 18),
+    quote_quantity Decimal(38, 18) DEFAULT multiplyDecimal(price, quantity, 18),
+    buyer_order_id UInt64 DEFAULT 0,
+    seller_order_id UInt64 DEFAULT 0,
+    trade_time DateTime64(3),
+    is_buyer_market_maker Bool,
+    is_best_match Bool DEFAULT false,
+    event_time DateTime64(3),
+    source Enum8('unknown' = 0, 'live' = 1, 'rest' = 2, 'csv' = 3) DEFAULT 'unknown',
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(trade_time)
+ORDER BY (exchange, symbol, id)
+SETTINGS index_granularity = 8192;
+
+INSERT INTO trades_exchange (
+    exchange, id, symbol, price, quantity, quote_quantity, buyer_order_id, seller_order_id,
+    trade_time, is_buyer_market_maker, is_best_match, event_time, source, created_at
+)
+SELECT
+    'binance', id, symbol, price, quantity, quote_quantity, buyer_order_id, seller_order_id,
+    trade_time, is_buyer_market_maker, is_best_match, event_time, source, created_at
+FROM trades;
+
+EXCHANGE TABLES trades AND trades_exchange;
+
+DROP TABLE trades_exchange;
+
+ALTER TABLE book_tickers
+    ADD COLUMN IF NOT EXISTS exchange LowCardinality(String) DEFAULT 'binance' FIRST;
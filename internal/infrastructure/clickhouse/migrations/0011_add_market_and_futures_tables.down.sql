@@ -0,0 +1,46 @@
+-- Only spot rows fit the previous schema, futures rows are dropped.
+
+DROP TABLE IF EXISTS funding_rates;
+
+DROP TABLE IF EXISTS mark_prices;
+
+DROP TABLE IF EXISTS trades_spot;
+
+CREATE TABLE trades_spot (
+    exchange LowCardinality(String) DEFAULT 'binance',
+    id UInt64,
+    symbol String,
+    price Decimal(38, 18),
+    quantity Decimal(38, 18),
+    quote_quantity Decimal(38, 18) DEFAULT multiplyDecimal(price, quantity, 18),
+    buyer_order_id UInt64 DEFAULT 0,
+    seller_order_id UInt64 DEFAULT 0,
+    trade_time DateTime64(3),
+    is_buyer_market_maker Bool,
+    is_best_match Bool DEFAULT false,
+    event_time DateTime64(3),
+    source Enum8('unknown' = 0, 'live' = 1, 'rest' = 2, 'csv' = 3) DEFAULT 'unknown',
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(trade_time)
+ORDER BY (exchange, symbol, id)
+SETTINGS index_granularity = 8192;
+
+INSERT INTO trades_spot (
+    exchange, id, symbol, price, quantity, quote_quantity, buyer_order_id, seller_order_id,
+    trade_time, is_buyer_market_maker, is_best_match, event_time, source, created_at
+)
+SELECT
+    exchange, id, symbol, price, quantity, quote_quantity, buyer_order_id, seller_order_id,
+    trade_time, is_buyer_market_maker, is_best_match, event_time, source, created_at
+FROM trades
+WHERE market = 'spot';
+
+EXCHANGE TABLES trades AND trades_spot;
+
+DROP TABLE trades_spot;
+
+ALTER TABLE book_tickers DELETE WHERE market != 'spot' SETTINGS mutations_sync = 2;
+
+ALTER TABLE book_tickers DROP COLUMN IF EXISTS market;
@@ -0,0 +1,81 @@
+-- Tags trades and book tickers with the market they were collected from and
+-- adds the futures-only mark price and funding rate tables. A spot symbol and
+-- a futures contract can share a name and a trade ID, so the market becomes
+-- part of the trades sort key; the table is copied and swapped as in 0010.
+-- Existing rows were all collected from spot markets.
+
+DROP TABLE IF EXISTS trades_market;
+
+CREATE TABLE trades_market (
+    exchange LowCardinality(String) DEFAULT 'binance',
+    market LowCardinality(String) DEFAULT 'spot',
+    id UInt64,
+    symbol String,
+    price Decimal(38, 18),
+    quantity Decimal(38, 18),
+    quote_quantity Decimal(38, 18) DEFAULT multiplyDecimal(price, quantity, 18),
+    buyer_order_id UInt64 DEFAULT 0,
+    seller_order_id UInt64 DEFAULT 0,
+    trade_time DateTime64(3),
+    is_buyer_market_maker Bool,
+    is_best_match Bool DEFAULT false,
+    event_time DateTime64(3),
+    source Enum8('unknown' = 0, 'live' = 1, 'rest' = 2, 'csv' = 3) DEFAULT 'unknown',
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(trade_time)
+ORDER BY (exchange, market, symbol, id)
+SETTINGS index_granularity = 8192;
+
+INSERT INTO trades_market (
+    exchange, market, id, symbol, price, quantity, quote_quantity, buyer_order_id, seller_order_id,
+    trade_time, is_buyer_market_maker, is_best_match, event_time, source, created_at
+)
+SELECT
+    exchange, 'spot', id, symbol, price, quantity, quote_quantity, buyer_order_id, seller_order_id,
+    trade_time, is_buyer_market_maker, is_best_match, event_time, source, created_at
+FROM trades;
+
+EXCHANGE TABLES trades AND trades_market;
+
+DROP TABLE trades_market;
+
+ALTER TABLE book_tickers
+    ADD COLUMN IF NOT EXISTS market LowCardinality(String) DEFAULT 'spot' AFTER exchange;
+
+-- Mark price updates arrive every second per contract; a replayed update
+-- replaces the stored one. Read with FINAL.
+CREATE TABLE IF NOT EXISTS mark_prices (
+    exchange LowCardinality(String),
+    market LowCardinality(String),
+    symbol String,
+    mark_price Decimal(38, 18),
+    index_price Decimal(38, 18),
+    estimated_settle_price Decimal(38, 18),
+    funding_rate Decimal(38, 18),
+    next_funding_time DateTime64(3),
+    event_time DateTime64(3),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(event_time)
+ORDER BY (exchange, market, symbol, event_time)
+SETTINGS index_granularity = 8192;
+
+-- Settled funding payments, one row per contract and funding time, so
+-- backfilling a range again is an upsert. Read with FINAL.
+CREATE TABLE IF NOT EXISTS funding_rates (
+    exchange LowCardinality(String),
+    market LowCardinality(String),
+    symbol String,
+    funding_time DateTime64(3),
+    funding_rate Decimal(38, 18),
+    mark_price Decimal(38, 18),
+    source Enum8('unknown' = 0, 'live' = 1, 'rest' = 2, 'csv' = 3),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree()
+PARTITION BY toYear(funding_time)
+ORDER BY (exchange, market, symbol, funding_time)
+SETTINGS index_granularity = 8192;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS symbol_status_history;
+
+DROP TABLE IF EXISTS symbols;
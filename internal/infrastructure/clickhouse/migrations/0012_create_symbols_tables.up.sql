@@ -0,0 +1,44 @@
+-- The latest known state of every symbol, one row per exchange, market and
+-- symbol. Each refresh writes the symbols that changed with a newer
+-- updated_at; delisted symbols keep their last state with listed = false.
+-- Read with FINAL.
+CREATE TABLE IF NOT EXISTS symbols (
+    exchange LowCardinality(String),
+    market LowCardinality(String),
+    symbol String,
+    base_asset String,
+    quote_asset String,
+    status LowCardinality(String),
+    is_spot_trading Bool,
+    is_margin_trading Bool,
+    contract_type LowCardinality(String),
+    delivery_date DateTime64(3),
+    tick_size Decimal(38, 18),
+    step_size Decimal(38, 18),
+    min_notional Decimal(38, 18),
+    listed Bool,
+    updated_at DateTime64(3)
+)
+ENGINE = ReplacingMergeTree(updated_at)
+ORDER BY (exchange, market, symbol)
+SETTINGS index_granularity = 8192;
+
+-- Every listing, delisting, status change and filter change of a symbol. A
+-- row holds the state from changed_at until the next row of the symbol, so
+-- the tick size on a date is the last row at or before it.
+CREATE TABLE IF NOT EXISTS symbol_status_history (
+    exchange LowCardinality(String),
+    market LowCardinality(String),
+    symbol String,
+    status LowCardinality(String),
+    previous_status LowCardinality(String),
+    listed Bool,
+    tick_size Decimal(38, 18),
+    step_size Decimal(38, 18),
+    min_notional Decimal(38, 18),
+    changed_at DateTime64(3)
+)
+ENGINE = MergeTree()
+PARTITION BY toYear(changed_at)
+ORDER BY (exchange, market, symbol, changed_at)
+SETTINGS index_granularity = 8192;
@@ -0,0 +1,18 @@
+-- The backfill job queue, one row per job. Every status change and every
+-- batch writes the job again with a newer updated_at. Read with FINAL.
+CREATE TABLE IF NOT EXISTS backfill_jobs (
+    id String,
+    symbol String,
+    direction LowCardinality(String),
+    range_from DateTime64(3),
+    range_to DateTime64(3),
+    cursor UInt64,
+    fetched UInt64,
+    status LowCardinality(String),
+    error String,
+    created_at DateTime64(3),
+    updated_at DateTime64(3)
+)
+ENGINE = ReplacingMergeTree(updated_at)
+ORDER BY id
+SETTINGS index_granularity = 8192;
@@ -0,0 +1,15 @@
+-- The progress of the backfill jobs, saved by the runner holding a job's
+-- lease with every batch. Status changes stay in backfill_jobs, so neither
+-- overwrites the other; the cursor and fetched columns there only serve jobs
+-- without a progress row yet. Read with FINAL.
+CREATE TABLE IF NOT EXISTS backfill_job_progress (
+    id String,
+    cursor UInt64,
+    fetched UInt64,
+    owner String,
+    lease_until DateTime64(3),
+    updated_at DateTime64(3)
+)
+ENGINE = ReplacingMergeTree(updated_at)
+ORDER BY id
+SETTINGS index_granularity = 8192;
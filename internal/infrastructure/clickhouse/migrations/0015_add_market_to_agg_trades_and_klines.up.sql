@@ -0,0 +1,82 @@
+-- Tags aggregate trades and klines with the exchange and market they were
+-- collected from, so futures streams no longer mix with spot data. Both
+-- become part of the sort keys; the tables are copied and swapped as in 0011.
+-- Existing rows were all collected from Binance spot.
+
+DROP TABLE IF EXISTS agg_trades_market;
+
+CREATE TABLE agg_trades_market (
+    exchange LowCardinality(String) DEFAULT 'binance',
+    market LowCardinality(String) DEFAULT 'spot',
+    id UInt64,
+    symbol String,
+    price Decimal(38, 18),
+    quantity Decimal(38, 18),
+    first_trade_id UInt64,
+    last_trade_id UInt64,
+    trade_time DateTime64(3),
+    is_buyer_market_maker Bool,
+    is_best_match Bool,
+    event_time DateTime64(3),
+    source Enum8('unknown' = 0, 'live' = 1, 'rest' = 2, 'csv' = 3),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(trade_time)
+ORDER BY (exchange, market, symbol, id)
+SETTINGS index_granularity = 8192;
+
+INSERT INTO agg_trades_market (
+    exchange, market, id, symbol, price, quantity, first_trade_id, last_trade_id,
+    trade_time, is_buyer_market_maker, is_best_match, event_time, source, created_at
+)
+SELECT
+    'binance', 'spot', id, symbol, price, quantity, first_trade_id, last_trade_id,
+    trade_time, is_buyer_market_maker, is_best_match, event_time, source, created_at
+FROM agg_trades;
+
+EXCHANGE TABLES agg_trades AND agg_trades_market;
+
+DROP TABLE agg_trades_market;
+
+DROP TABLE IF EXISTS klines_market;
+
+CREATE TABLE klines_market (
+    exchange LowCardinality(String) DEFAULT 'binance',
+    market LowCardinality(String) DEFAULT 'spot',
+    symbol String,
+    interval LowCardinality(String),
+    open_time DateTime64(3),
+    close_time DateTime64(3),
+    open Decimal(38, 18),
+    high Decimal(38, 18),
+    low Decimal(38, 18),
+    close Decimal(38, 18),
+    volume Decimal(38, 18),
+    quote_volume Decimal(38, 18),
+    taker_buy_volume Decimal(38, 18),
+    taker_buy_quote_volume Decimal(38, 18),
+    trade_count UInt64,
+    event_time DateTime64(3),
+    source Enum8('unknown' = 0, 'live' = 1, 'rest' = 2, 'csv' = 3),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree(event_time)
+PARTITION BY toYYYYMM(open_time)
+ORDER BY (exchange, market, symbol, interval, open_time)
+SETTINGS index_granularity = 8192;
+
+INSERT INTO klines_market (
+    exchange, market, symbol, interval, open_time, close_time, open, high, low, close,
+    volume, quote_volume, taker_buy_volume, taker_buy_quote_volume,
+    trade_count, event_time, source, created_at
+)
+SELECT
+    'binance', 'spot', symbol, interval, open_time, close_time, open, high, low, close,
+    volume, quote_volume, taker_buy_volume, taker_buy_quote_volume,
+    trade_count, event_time, source, created_at
+FROM klines;
+
+EXCHANGE TABLES klines AND klines_market;
+
+DROP TABLE klines_market;
@@ -0,0 +1,31 @@
+-- Moves a trades table created before versioned migrations (plain MergeTree
+-- keyed by a String id) to the schema of 0001_create_trades_table. The old
+-- table is kept as trades_legacy.
+
+-- Leftover from an interrupted copy
+DROP TABLE IF EXISTS trades_dedup;
+
+CREATE TABLE trades_dedup (
+    id Int64,
+    symbol String,
+    price Float64,
+    quantity Float64,
+    trade_time DateTime64(3),
+    is_buyer_market_maker Bool,
+    event_time DateTime64(3),
+    created_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree()
+PARTITION BY toYYYYMM(trade_time)
+ORDER BY (symbol, id)
+SETTINGS index_granularity = 8192;
+
+INSERT INTO trades_dedup (
+    id, symbol, price, quantity, trade_time, is_buyer_market_maker, event_time, created_at
+)
+SELECT
+    toInt64(id), symbol, price, quantity, trade_time, is_buyer_market_maker, event_time, created_at
+FROM trades
+WHERE isNotNull(toInt64OrNull(id));
+
+RENAME TABLE trades TO trades_legacy, trades_dedup TO trades;
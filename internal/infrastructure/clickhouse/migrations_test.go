@@ -3,9 +3,12 @@ package clickhouse
 import (
 	"database/sql"
 	"log/slog"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 
@@ -15,7 +18,8 @@ func TestNewMigrator(t *testing.T) {
 	var db *sql.DB // This would be initialized with sql.Open in real usage
 	logger := slog.Default()
 	
-	migrator := NewMigrator(db, logger)
+	migrator, err := NewMigrator(db, logger)
+	require.NoError(t, err)
 	
 	assert.NotNil(t, migrator)
 	assert.Equal(t, db, migrator.db)
@@ -25,7 +29,8 @@ func TestNewMigrator(t *testing.T) {
 func TestMigrator_Migrate_Success(t *testing.T) {
 	t.Run("migrator can be instantiated without error", func(t *testing.T) {
 		logger := slog.Default()
-		migrator := NewMigrator(nil, logger)
+		migrator, err := NewMigrator(nil, logger)
+		require.NoError(t, err)
 		
 		assert.NotNil(t, migrator)
 		assert.NotNil(t, migrator.logger)
@@ -41,7 +46,8 @@ func TestMigrator_ErrorHandling(t *testing.T) {
 		expectedTables := []string{"trades", "book_tickers"}
 		
 		// Create a migrator with nil DB (we won't actually execute)
-		migrator := NewMigrator(nil, logger)
+		migrator, err := NewMigrator(nil, logger)
+		require.NoError(t, err)
 		
 		// Verify the migrator has the expected structure
 		assert.NotNil(t, migrator)
@@ -105,4 +111,143 @@ func TestMigrator_MigrationStructure(t *testing.T) {
 			assert.NotEmpty(t, feature, "Table feature should be defined")
 		}
 	})
-}
\ No newline at end of file
+}
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, migrationsDir)
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, migration := range migrations {
+		assert.Equal(t, i+1, migration.Version, "versions must be contiguous")
+		assert.NotEmpty(t, splitStatements(migration.Up), "%s has no up statements", migration.Name)
+		assert.NotEmpty(t, splitStatements(migration.Down), "%s has no down statements", migration.Name)
+		assert.Len(t, migration.Checksum, 64)
+	}
+
+	// Every table the repositories write to must be created by a migration
+	var all strings.Builder
+	for _, migration := range migrations {
+		all.WriteString(migration.Up)
+	}
+	for _, table := range []string{"trades", "book_tickers", "trade_gaps", "mark_prices", "funding_rates", "symbols", "symbol_status_history"} {
+		assert.Contains(t, all.String(), "CREATE TABLE IF NOT EXISTS "+table+" (")
+	}
+	assert.Contains(t, all.String(), "buyer_order_id")
+	assert.Contains(t, all.String(), "seller_order_id")
+}
+
+func TestLoadMigrations_Invalid(t *testing.T) {
+	t.Run("missing down script", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"m/0001_create_things.up.sql": {Data: []byte("CREATE TABLE things (id Int64)")},
+		}
+		_, err := loadMigrations(fsys, "m")
+		assert.ErrorContains(t, err, "needs both an up and a down script")
+	})
+
+	t.Run("duplicate version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"m/0001_create_things.up.sql":   {Data: []byte("CREATE TABLE things (id Int64)")},
+			"m/0001_create_things.down.sql": {Data: []byte("DROP TABLE things")},
+			"m/0001_create_others.up.sql":   {Data: []byte("CREATE TABLE others (id Int64)")},
+		}
+		_, err := loadMigrations(fsys, "m")
+		assert.ErrorContains(t, err, "migration version 1 used by both")
+	})
+
+	t.Run("ignores other files", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"m/0002_b.up.sql":   {Data: []byte("B")},
+			"m/0002_b.down.sql": {Data: []byte("DROP B")},
+			"m/0001_a.up.sql":   {Data: []byte("A")},
+			"m/0001_a.down.sql": {Data: []byte("DROP A")},
+			"m/README.md":       {Data: []byte("notes")},
+		}
+		migrations, err := loadMigrations(fsys, "m")
+		require.NoError(t, err)
+		require.Len(t, migrations, 2)
+		assert.Equal(t, "a", migrations[0].Name)
+		assert.Equal(t, "b", migrations[1].Name)
+	})
+}
+
+func testMigrations() []Migration {
+	return []Migration{
+		{Version: 1, Name: "one", Checksum: "c1"},
+		{Version: 2, Name: "two", Checksum: "c2"},
+		{Version: 3, Name: "three", Checksum: "c3"},
+	}
+}
+
+func stepVersions(steps []migrationStep) ([]int, []bool) {
+	versions := make([]int, 0, len(steps))
+	ups := make([]bool, 0, len(steps))
+	for _, step := range steps {
+		versions = append(versions, step.migration.Version)
+		ups = append(ups, step.up)
+	}
+	return versions, ups
+}
+
+func TestPlanMigrations(t *testing.T) {
+	migrations := testMigrations()
+	applied := map[int]appliedMigration{
+		1: {version: 1, name: "one", checksum: "c1"},
+	}
+
+	versions, ups := stepVersions(planMigrations(migrations, applied, 3))
+	assert.Equal(t, []int{2, 3}, versions)
+	assert.Equal(t, []bool{true, true}, ups)
+
+	versions, _ = stepVersions(planMigrations(migrations, applied, 1))
+	assert.Empty(t, versions)
+
+	applied[2] = appliedMigration{version: 2, name: "two", checksum: "c2"}
+	applied[3] = appliedMigration{version: 3, name: "three", checksum: "c3"}
+
+	versions, ups = stepVersions(planMigrations(migrations, applied, 1))
+	assert.Equal(t, []int{3, 2}, versions)
+	assert.Equal(t, []bool{false, false}, ups)
+
+	versions, _ = stepVersions(planMigrations(migrations, applied, 0))
+	assert.Equal(t, []int{3, 2, 1}, versions)
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	migrations := testMigrations()
+
+	err := verifyChecksums(migrations, map[int]appliedMigration{
+		1: {version: 1, name: "one", checksum: "c1"},
+	})
+	assert.NoError(t, err)
+
+	err = verifyChecksums(migrations, map[int]appliedMigration{
+		2: {version: 2, name: "two", checksum: "edited"},
+	})
+	assert.ErrorContains(t, err, "checksum mismatch for applied migration 0002_two")
+
+	err = verifyChecksums(migrations, map[int]appliedMigration{
+		9: {version: 9, name: "future"},
+	})
+	assert.ErrorContains(t, err, "unknown to this binary")
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `
+-- leading comment
+CREATE TABLE a (id Int64);
+
+-- another comment
+ALTER TABLE a
+    ADD COLUMN b Int64;
+`
+	statements := splitStatements(script)
+	require.Len(t, statements, 2)
+	assert.Equal(t, "CREATE TABLE a (id Int64)", statements[0])
+	assert.Equal(t, "ALTER TABLE a\n    ADD COLUMN b Int64", statements[1])
+
+	legacy, err := migrationFiles.ReadFile(migrationsDir + "/" + legacyTradesFile)
+	require.NoError(t, err)
+	assert.Len(t, splitStatements(string(legacy)), 4)
+}
@@ -0,0 +1,79 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+)
+
+type OrderBookSnapshotRepository struct {
+	db *sql.DB
+}
+
+func NewOrderBookSnapshotRepository(db *sql.DB) repositories.OrderBookSnapshotRepository {
+	return &OrderBookSnapshotRepository{db: db}
+}
+
+func (r *OrderBookSnapshotRepository) SaveBatch(ctx context.Context, snapshots []*entities.OrderBookSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	batch, err := tx.Prepare(`
+		INSERT INTO order_book_snapshots (
+			symbol, snapshot_time, last_update_id,
+			bid_prices, bid_quantities, ask_prices, ask_quantities
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer func() { _ = batch.Close() }()
+
+	for _, snapshot := range snapshots {
+		bidPrices, bidQuantities := splitLevels(snapshot.Bids)
+		askPrices, askQuantities := splitLevels(snapshot.Asks)
+
+		_, err := batch.Exec(
+			snapshot.Symbol,
+			snapshot.SnapshotTime,
+			snapshot.LastUpdateID,
+			bidPrices,
+			bidQuantities,
+			askPrices,
+			askQuantities,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add order book snapshot to batch %s: %w", snapshot.Symbol, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch: %w", err)
+	}
+
+	return nil
+}
+
+// splitLevels turns levels into the parallel price and quantity arrays stored
+// in the table.
+func splitLevels(levels []entities.PriceLevel) ([]decimal.Decimal, []decimal.Decimal) {
+	prices := make([]decimal.Decimal, len(levels))
+	quantities := make([]decimal.Decimal, len(levels))
+	for i, level := range levels {
+		prices[i] = level.Price
+		quantities[i] = level.Quantity
+	}
+	return prices, quantities
+}
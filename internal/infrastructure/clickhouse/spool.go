@@ -0,0 +1,296 @@
+package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	segmentExt         = ".seg"
+	corruptExt         = ".corrupt"
+	spoolSaveTimeout   = 10 * time.Second
+	spoolRetryDelay    = 1 * time.Second
+	spoolMaxRetryDelay = 1 * time.Minute
+	spoolCloseTimeout  = 10 * time.Second
+)
+
+// SaveFunc writes a batch to the database.
+type SaveFunc[T any] func(ctx context.Context, batch []T) error
+
+// Spool is a write-ahead store for batches that could not be written to
+// ClickHouse. Every batch is kept in its own segment file under dir and is
+// replayed in order, with exponential backoff between failed attempts, until
+// the database accepts it. Segments survive restarts.
+type Spool[T any] struct {
+	dir     string
+	name    string
+	save    SaveFunc[T]
+	logger  *slog.Logger
+	seq     atomic.Uint64
+	pending atomic.Int64
+
+	retryDelay    time.Duration
+	maxRetryDelay time.Duration
+
+	replayMu sync.Mutex // serializes replay passes
+	wake     chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewSpool[T any](dir, name string, save SaveFunc[T], logger *slog.Logger) (*Spool[T], error) {
+	spool, err := newSpool(dir, name, save, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	spool.start()
+	return spool, nil
+}
+
+func newSpool[T any](dir, name string, save SaveFunc[T], logger *slog.Logger) (*Spool[T], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory %s: %w", dir, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	spool := &Spool[T]{
+		dir:           dir,
+		name:          name,
+		save:          save,
+		logger:        logger.With("spool", name),
+		retryDelay:    spoolRetryDelay,
+		maxRetryDelay: spoolMaxRetryDelay,
+		wake:          make(chan struct{}, 1),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+
+	segments, err := spool.segments()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	spool.pending.Store(int64(len(segments)))
+
+	if len(segments) > 0 {
+		spool.logger.Warn("Found spooled batches from a previous run", "segments", len(segments))
+	}
+
+	return spool, nil
+}
+
+// start launches the background replay routine and replays leftovers from a
+// previous run right away.
+func (s *Spool[T]) start() {
+	s.wg.Add(1)
+	go s.replayRoutine()
+	s.Wake()
+}
+
+// Put persists a batch as a new segment and schedules it for replay.
+func (s *Spool[T]) Put(batch []T) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode spool segment: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%020d-%06d%s", s.name, time.Now().UnixNano(), s.seq.Add(1), segmentExt)
+	if err := writeFileSync(filepath.Join(s.dir, name), data); err != nil {
+		return fmt.Errorf("failed to write spool segment: %w", err)
+	}
+
+	s.pending.Add(1)
+	s.logger.Warn("Batch spooled to disk", "segment", name, "batchSize", len(batch))
+
+	s.Wake()
+	return nil
+}
+
+// Wake triggers a replay attempt without waiting for the current backoff.
+func (s *Spool[T]) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Pending returns the number of segments waiting to be replayed.
+func (s *Spool[T]) Pending() int {
+	return int(s.pending.Load())
+}
+
+// Close stops the replay routine and makes a last attempt to drain the
+// spool. Segments that still cannot be written stay on disk and are replayed
+// on the next start.
+func (s *Spool[T]) Close() error {
+	s.cancel()
+	s.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), spoolCloseTimeout)
+	defer cancel()
+
+	if err := s.replay(ctx); err != nil {
+		s.logger.Warn("Spool not fully drained, remaining segments kept on disk",
+			"segments", s.Pending(),
+			"dir", s.dir,
+			"error", err,
+		)
+	}
+
+	return nil
+}
+
+func (s *Spool[T]) replayRoutine() {
+	defer s.wg.Done()
+
+	delay := s.retryDelay
+	var retry <-chan time.Time
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.wake:
+		case <-retry:
+		}
+
+		if err := s.replay(s.ctx); err != nil {
+			if s.ctx.Err() != nil {
+				return
+			}
+			s.logger.Error("Failed to replay spooled batch, will retry",
+				"error", err,
+				"segments", s.Pending(),
+				"retryIn", delay,
+			)
+			retry = time.After(delay)
+			delay = min(delay*2, s.maxRetryDelay)
+			continue
+		}
+
+		retry = nil
+		delay = s.retryDelay
+	}
+}
+
+// replay writes spooled segments oldest first and stops at the first failure.
+func (s *Spool[T]) replay(ctx context.Context) error {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	segments, err := s.segments()
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		path := filepath.Join(s.dir, segment)
+		batch, err := s.read(path)
+		if err != nil {
+			s.logger.Error("Discarding unreadable spool segment", "segment", segment, "error", err)
+			if err := os.Rename(path, path+corruptExt); err != nil {
+				return fmt.Errorf("failed to set aside segment %s: %w", segment, err)
+			}
+			s.pending.Add(-1)
+			continue
+		}
+
+		saveCtx, cancel := context.WithTimeout(ctx, spoolSaveTimeout)
+		err = s.save(saveCtx, batch)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to replay segment %s: %w", segment, err)
+		}
+
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove replayed segment %s: %w", segment, err)
+		}
+		s.pending.Add(-1)
+
+		s.logger.Info("Spooled batch replayed successfully", "segment", segment, "batchSize", len(batch))
+	}
+
+	return nil
+}
+
+func (s *Spool[T]) read(path string) ([]T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var batch []T
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// segments lists segment file names in the order they were written.
+func (s *Spool[T]) segments() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spool directory: %w", err)
+	}
+
+	prefix := s.name + "-"
+	segments := make([]string, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		segments = append(segments, name)
+	}
+	sort.Strings(segments)
+
+	return segments, nil
+}
+
+// writeFileSync writes data to a temporary file, syncs it and renames it into
+// place so a crash never leaves a partially written segment behind.
+func writeFileSync(path string, data []byte) error {
+	tmp := path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,201 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/mocks"
+)
+
+// flakyStore is a SaveFunc target that fails until healthy is set.
+type flakyStore struct {
+	mu      sync.Mutex
+	healthy bool
+	saved   [][]*entities.Trade
+	calls   int
+}
+
+func (s *flakyStore) SaveBatch(ctx context.Context, trades []*entities.Trade) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	if !s.healthy {
+		return errors.New("clickhouse unavailable")
+	}
+	s.saved = append(s.saved, trades)
+	return nil
+}
+
+func (s *flakyStore) setHealthy(healthy bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.healthy = healthy
+}
+
+func (s *flakyStore) savedBatches() [][]*entities.Trade {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]*entities.Trade(nil), s.saved...)
+}
+
+func testTrades(ids ...uint64) []*entities.Trade {
+	trades := make([]*entities.Trade, 0, len(ids))
+	for _, id := range ids {
+		trades = append(trades, &entities.Trade{
+			ID:       id,
+			Symbol:   "BTCUSDT",
+			Price:    decimal.RequireFromString("50000.0"),
+			Quantity: decimal.RequireFromString("0.01"),
+			Time:     time.UnixMilli(1700000000000).UTC(),
+		})
+	}
+	return trades
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "trades-*"+segmentExt))
+	require.NoError(t, err)
+	return files
+}
+
+func newTestSpool(t *testing.T, dir string, store *flakyStore) *Spool[*entities.Trade] {
+	t.Helper()
+	spool, err := newSpool(dir, "trades", store.SaveBatch, slog.Default())
+	require.NoError(t, err)
+	spool.retryDelay = 10 * time.Millisecond
+	spool.maxRetryDelay = 20 * time.Millisecond
+	spool.start()
+	return spool
+}
+
+func TestSpool_ReplaysOnceHealthy(t *testing.T) {
+	dir := t.TempDir()
+	store := &flakyStore{}
+	spool := newTestSpool(t, dir, store)
+
+	require.NoError(t, spool.Put(testTrades(1, 2)))
+	require.NoError(t, spool.Put(testTrades(3)))
+	assert.Len(t, segmentFiles(t, dir), 2)
+
+	// Replay keeps failing while ClickHouse is down
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 2, spool.Pending())
+	assert.Empty(t, store.savedBatches())
+
+	store.setHealthy(true)
+
+	assert.Eventually(t, func() bool {
+		return spool.Pending() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	batches := store.savedBatches()
+	require.Len(t, batches, 2)
+	assert.Equal(t, uint64(1), batches[0][0].ID)
+	assert.Equal(t, uint64(2), batches[0][1].ID)
+	assert.Equal(t, uint64(3), batches[1][0].ID)
+	assert.Equal(t, "50000", batches[0][0].Price.String())
+	assert.True(t, batches[0][0].Time.Equal(time.UnixMilli(1700000000000)))
+	assert.Empty(t, segmentFiles(t, dir))
+
+	require.NoError(t, spool.Close())
+}
+
+func TestSpool_CloseKeepsUnsavedSegments(t *testing.T) {
+	dir := t.TempDir()
+	store := &flakyStore{}
+	spool := newTestSpool(t, dir, store)
+
+	require.NoError(t, spool.Put(testTrades(1)))
+	require.NoError(t, spool.Close())
+
+	assert.Len(t, segmentFiles(t, dir), 1)
+
+	// The next run picks up where the previous one stopped
+	store.setHealthy(true)
+	reopened := newTestSpool(t, dir, store)
+
+	assert.Eventually(t, func() bool {
+		return reopened.Pending() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, store.savedBatches(), 1)
+	assert.Empty(t, segmentFiles(t, dir))
+
+	require.NoError(t, reopened.Close())
+}
+
+func TestSpool_CloseDrainsWhenHealthy(t *testing.T) {
+	dir := t.TempDir()
+	store := &flakyStore{}
+	spool, err := newSpool(dir, "trades", store.SaveBatch, slog.Default())
+	require.NoError(t, err)
+	spool.retryDelay = time.Hour
+	spool.maxRetryDelay = time.Hour
+	spool.start()
+
+	require.NoError(t, spool.Put(testTrades(1)))
+	time.Sleep(50 * time.Millisecond)
+
+	store.setHealthy(true)
+	require.NoError(t, spool.Close())
+
+	assert.Equal(t, 0, spool.Pending())
+	assert.Len(t, store.savedBatches(), 1)
+	assert.Empty(t, segmentFiles(t, dir))
+}
+
+func TestSpool_SetsAsideCorruptSegments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "trades-00000000000000000001-000001"+segmentExt)
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o644))
+
+	store := &flakyStore{healthy: true}
+	spool := newTestSpool(t, dir, store)
+
+	assert.Eventually(t, func() bool {
+		return spool.Pending() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, spool.Close())
+
+	_, err := os.Stat(path + corruptExt)
+	assert.NoError(t, err)
+	assert.Empty(t, store.savedBatches())
+}
+
+func TestTradeBatchProcessor_SpoolsFailedFlush(t *testing.T) {
+	dir := t.TempDir()
+	store := &flakyStore{}
+	spool := newTestSpool(t, dir, store)
+
+	mockTradeRepo := new(mocks.MockTradeRepository)
+	mockTradeRepo.On("SaveBatch", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+
+	processor := NewTradeBatchProcessor(mockTradeRepo, slog.Default(), 1, time.Second, DefaultFlushPoolConfig(), spool)
+	require.NoError(t, processor.AddTrade(testTrades(1)[0]))
+
+	assert.Eventually(t, func() bool {
+		return spool.Pending() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	store.setHealthy(true)
+	spool.Wake()
+
+	assert.Eventually(t, func() bool {
+		return spool.Pending() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, processor.Close())
+	assert.Len(t, store.savedBatches(), 1)
+}
@@ -4,54 +4,81 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync"
+	"time"
 
 	"alarket/internal/domain/entities"
 	"alarket/internal/domain/repositories"
 )
 
+const symbolColumns = `
+	symbol, base_asset, quote_asset, status, is_spot_trading, is_margin_trading,
+	contract_type, delivery_date, tick_size, step_size, min_notional
+`
+
+const symbolChangeColumns = `
+	symbol, status, previous_status, listed, tick_size, step_size, min_notional, changed_at
+`
+
+// SymbolRepository keeps the symbols of one exchange market in the symbols
+// table and records their status and filter changes in symbol_status_history.
 type SymbolRepository struct {
-	db             *sql.DB
-	symbols        []*entities.Symbol // In-memory cache for now
-	allowedSymbols map[string]bool    // Filter symbols (nil = all symbols)
-}
-
-func NewSymbolRepository(db *sql.DB, symbols []*entities.Symbol, allowedSymbols []string) repositories.SymbolRepository {
-	// Create a map for fast lookup if symbols are specified
-	var allowedMap map[string]bool
-	if len(allowedSymbols) > 0 {
-		allowedMap = make(map[string]bool, len(allowedSymbols))
-		for _, symbol := range allowedSymbols {
-			allowedMap[symbol] = true
-		}
-	}
+	db       *sql.DB
+	exchange entities.Exchange
+	market   entities.Market
+	now      func() time.Time
+	mu       sync.Mutex // serializes read-modify-write updates
+}
 
+func NewSymbolRepository(db *sql.DB, exchange entities.Exchange, market entities.Market) repositories.SymbolRepository {
 	return &SymbolRepository{
-		db:             db,
-		symbols:        symbols,
-		allowedSymbols: allowedMap,
+		db:       db,
+		exchange: exchange,
+		market:   market,
+		now:      time.Now,
 	}
 }
 
+// GetAll returns the listed symbols ordered by name.
 func (r *SymbolRepository) GetAll(ctx context.Context) ([]*entities.Symbol, error) {
-	// For now, return from memory
-	// In production, this would query from database
-	return r.symbols, nil
-}
+	query := `
+		SELECT ` + symbolColumns + `
+		FROM symbols FINAL
+		WHERE exchange = ? AND market = ? AND listed
+		ORDER BY symbol
+	`
 
-func (r *SymbolRepository) GetActiveUsdt(ctx context.Context) ([]*entities.Symbol, error) {
-	active := make([]*entities.Symbol, 0)
-	for _, symbol := range r.symbols {
-		if !symbol.IsActive() {
-			continue
+	rows, err := r.db.QueryContext(ctx, query, string(r.exchange), string(r.market))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query symbols: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var symbols []*entities.Symbol
+	for rows.Next() {
+		symbol, err := r.scanSymbol(rows)
+		if err != nil {
+			return nil, err
 		}
+		symbols = append(symbols, symbol)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read symbols: %w", err)
+	}
 
-		// If allowedSymbols is set, filter by the list
-		if r.allowedSymbols != nil {
-			if r.allowedSymbols[symbol.Name] {
-				active = append(active, symbol)
-			}
-		} else {
-			// If allowedSymbols is nil (SYMBOLS not configured), return all active symbols
+	return symbols, nil
+}
+
+// GetActive returns the listed symbols that are trading.
+func (r *SymbolRepository) GetActive(ctx context.Context) ([]*entities.Symbol, error) {
+	symbols, err := r.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make([]*entities.Symbol, 0, len(symbols))
+	for _, symbol := range symbols {
+		if symbol.IsActive() {
 			active = append(active, symbol)
 		}
 	}
@@ -59,20 +86,333 @@ func (r *SymbolRepository) GetActiveUsdt(ctx context.Context) ([]*entities.Symbo
 }
 
 func (r *SymbolRepository) GetByName(ctx context.Context, name string) (*entities.Symbol, error) {
-	for _, symbol := range r.symbols {
-		if symbol.Name == name {
-			return symbol, nil
-		}
+	query := `
+		SELECT ` + symbolColumns + `
+		FROM symbols FINAL
+		WHERE exchange = ? AND market = ? AND symbol = ? AND listed
+		LIMIT 1
+	`
+
+	symbol, err := r.scanSymbol(r.db.QueryRowContext(ctx, query, string(r.exchange), string(r.market), name))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("symbol %s not found", name)
+	}
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("symbol %s not found", name)
+	return symbol, nil
 }
 
 func (r *SymbolRepository) UpdateStatus(ctx context.Context, name string, status entities.SymbolStatus) error {
-	for _, symbol := range r.symbols {
-		if symbol.Name == name {
-			symbol.Status = status
-			return nil
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	symbol, err := r.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if symbol.Status == status {
+		return nil
+	}
+
+	updated := *symbol
+	updated.Status = status
+	return r.save(ctx, []*entities.Symbol{symbol}, []*entities.Symbol{&updated})
+}
+
+// ReplaceAll stores the fetched symbols. Only symbols that changed are
+// written, symbols missing from the list are marked as delisted.
+func (r *SymbolRepository) ReplaceAll(ctx context.Context, symbols []*entities.Symbol) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	return r.save(ctx, stored, symbols)
+}
+
+func (r *SymbolRepository) GetHistory(ctx context.Context, name string, from, to time.Time) ([]*entities.SymbolChange, error) {
+	query := `
+		SELECT ` + symbolChangeColumns + `
+		FROM symbol_status_history
+		WHERE exchange = ? AND market = ? AND symbol = ? AND changed_at >= ? AND changed_at <= ?
+		ORDER BY changed_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, string(r.exchange), string(r.market), name, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query symbol history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var changes []*entities.SymbolChange
+	for rows.Next() {
+		change, err := scanSymbolChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read symbol history: %w", err)
+	}
+
+	return changes, nil
+}
+
+func (r *SymbolRepository) GetAsOf(ctx context.Context, name string, at time.Time) (*entities.SymbolChange, error) {
+	query := `
+		SELECT ` + symbolChangeColumns + `
+		FROM symbol_status_history
+		WHERE exchange = ? AND market = ? AND symbol = ? AND changed_at <= ?
+		ORDER BY changed_at DESC
+		LIMIT 1
+	`
+
+	change, err := scanSymbolChange(r.db.QueryRowContext(ctx, query, string(r.exchange), string(r.market), name, at))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// save writes the symbols that differ from the stored ones, and a history row
+// for every listing, delisting, status or filter change. The history goes
+// first: the changes are found by comparing with the symbols table, so once
+// it is updated a change whose history row failed would never be recorded.
+// A failed symbols write only records the change again on the next save.
+func (r *SymbolRepository) save(ctx context.Context, stored, symbols []*entities.Symbol) error {
+	now := r.now()
+	updates, delisted, changes := diffSymbols(stored, symbols, now)
+	if len(updates) == 0 && len(delisted) == 0 {
+		return nil
+	}
+
+	if err := r.saveChanges(ctx, changes); err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	batch, err := tx.Prepare(`
+		INSERT INTO symbols (
+			exchange, market, ` + symbolColumns + `, listed, updated_at
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare symbols batch: %w", err)
+	}
+	defer func() { _ = batch.Close() }()
+
+	for _, rows := range []struct {
+		symbols []*entities.Symbol
+		listed  bool
+	}{{updates, true}, {delisted, false}} {
+		for _, symbol := range rows.symbols {
+			_, err := batch.Exec(
+				string(r.exchange),
+				string(r.market),
+				symbol.Name,
+				symbol.BaseAsset,
+				symbol.QuoteAsset,
+				string(symbol.Status),
+				symbol.IsSpotTrading,
+				symbol.IsMarginTrading,
+				symbol.ContractType,
+				deliveryDateValue(symbol.DeliveryDate),
+				symbol.TickSize,
+				symbol.StepSize,
+				symbol.MinNotional,
+				rows.listed,
+				now,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to add symbol %s to batch: %w", symbol.Name, err)
+			}
 		}
 	}
-	return fmt.Errorf("symbol %s not found", name)
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit symbols batch: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SymbolRepository) saveChanges(ctx context.Context, changes []*entities.SymbolChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	batch, err := tx.Prepare(`
+		INSERT INTO symbol_status_history (
+			exchange, market, ` + symbolChangeColumns + `
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare symbol history batch: %w", err)
+	}
+	defer func() { _ = batch.Close() }()
+
+	for _, change := range changes {
+		_, err := batch.Exec(
+			string(r.exchange),
+			string(r.market),
+			change.Symbol,
+			string(change.Status),
+			string(change.PreviousStatus),
+			change.Listed,
+			change.TickSize,
+			change.StepSize,
+			change.MinNotional,
+			change.Time,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add symbol change of %s to batch: %w", change.Symbol, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit symbol history batch: %w", err)
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func (r *SymbolRepository) scanSymbol(row rowScanner) (*entities.Symbol, error) {
+	var symbol entities.Symbol
+	var status string
+	err := row.Scan(
+		&symbol.Name,
+		&symbol.BaseAsset,
+		&symbol.QuoteAsset,
+		&status,
+		&symbol.IsSpotTrading,
+		&symbol.IsMarginTrading,
+		&symbol.ContractType,
+		&symbol.DeliveryDate,
+		&symbol.TickSize,
+		&symbol.StepSize,
+		&symbol.MinNotional,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan symbol: %w", err)
+	}
+	symbol.Status = entities.SymbolStatus(status)
+	symbol.Market = r.market
+	if symbol.DeliveryDate.Unix() <= 0 {
+		symbol.DeliveryDate = time.Time{}
+	}
+	return &symbol, nil
+}
+
+func scanSymbolChange(row rowScanner) (*entities.SymbolChange, error) {
+	var change entities.SymbolChange
+	var status, previousStatus string
+	err := row.Scan(
+		&change.Symbol,
+		&status,
+		&previousStatus,
+		&change.Listed,
+		&change.TickSize,
+		&change.StepSize,
+		&change.MinNotional,
+		&change.Time,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan symbol change: %w", err)
+	}
+	change.Status = entities.SymbolStatus(status)
+	change.PreviousStatus = entities.SymbolStatus(previousStatus)
+	return &change, nil
+}
+
+// diffSymbols compares fetched symbols with the stored ones. It returns the
+// symbols to write, the stored symbols that are gone and the history rows for
+// listings, delistings, status and filter changes.
+func diffSymbols(stored, symbols []*entities.Symbol, now time.Time) (updates, delisted []*entities.Symbol, changes []*entities.SymbolChange) {
+	before := make(map[string]*entities.Symbol, len(stored))
+	for _, symbol := range stored {
+		before[symbol.Name] = symbol
+	}
+
+	seen := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		seen[symbol.Name] = true
+
+		old, known := before[symbol.Name]
+		switch {
+		case !known:
+			changes = append(changes, newSymbolChange(symbol, "", true, now))
+		case old.Status != symbol.Status || !old.SameFilters(symbol):
+			changes = append(changes, newSymbolChange(symbol, old.Status, true, now))
+		case sameSymbol(old, symbol):
+			continue
+		}
+		updates = append(updates, symbol)
+	}
+
+	for _, symbol := range stored {
+		if !seen[symbol.Name] {
+			delisted = append(delisted, symbol)
+			changes = append(changes, newSymbolChange(symbol, symbol.Status, false, now))
+		}
+	}
+	return updates, delisted, changes
+}
+
+func newSymbolChange(symbol *entities.Symbol, previousStatus entities.SymbolStatus, listed bool, now time.Time) *entities.SymbolChange {
+	return &entities.SymbolChange{
+		Symbol:         symbol.Name,
+		Status:         symbol.Status,
+		PreviousStatus: previousStatus,
+		Listed:         listed,
+		TickSize:       symbol.TickSize,
+		StepSize:       symbol.StepSize,
+		MinNotional:    symbol.MinNotional,
+		Time:           now,
+	}
+}
+
+// sameSymbol compares the stored fields other than the status and filters.
+func sameSymbol(a, b *entities.Symbol) bool {
+	return a.BaseAsset == b.BaseAsset &&
+		a.QuoteAsset == b.QuoteAsset &&
+		a.IsSpotTrading == b.IsSpotTrading &&
+		a.IsMarginTrading == b.IsMarginTrading &&
+		a.ContractType == b.ContractType &&
+		a.DeliveryDate.Equal(b.DeliveryDate)
+}
+
+// deliveryDateValue stores a missing delivery date as the Unix epoch.
+func deliveryDateValue(deliveryDate time.Time) time.Time {
+	if deliveryDate.IsZero() {
+		return time.Unix(0, 0)
+	}
+	return deliveryDate
 }
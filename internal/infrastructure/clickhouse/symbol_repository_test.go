@@ -0,0 +1,253 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/domain/entities"
+)
+
+func testSymbol(name string, status entities.SymbolStatus, tickSize string) *entities.Symbol {
+	symbol := entities.NewSymbol(name, name[:3], "USDT", status)
+	symbol.IsSpotTrading = true
+	symbol.TickSize = decimal.RequireFromString(tickSize)
+	symbol.StepSize = decimal.RequireFromString("0.001")
+	symbol.MinNotional = decimal.RequireFromString("5")
+	return symbol
+}
+
+func TestDiffSymbols(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	stored := []*entities.Symbol{
+		testSymbol("BTCUSDT", entities.SymbolStatusTrading, "0.01"),
+		testSymbol("ETHUSDT", entities.SymbolStatusTrading, "0.01"),
+		testSymbol("SOLUSDT", entities.SymbolStatusTrading, "0.01"),
+		testSymbol("XRPUSDT", entities.SymbolStatusTrading, "0.0001"),
+		testSymbol("LTCUSDT", entities.SymbolStatusTrading, "0.01"),
+	}
+
+	margin := testSymbol("LTCUSDT", entities.SymbolStatusTrading, "0.01")
+	margin.IsMarginTrading = true
+	fetched := []*entities.Symbol{
+		testSymbol("BTCUSDT", entities.SymbolStatusTrading, "0.010"), // unchanged
+		testSymbol("ETHUSDT", entities.SymbolStatusHalt, "0.01"),     // halted
+		testSymbol("SOLUSDT", entities.SymbolStatusTrading, "0.001"), // new tick size
+		margin, // metadata only
+		testSymbol("ARBUSDT", entities.SymbolStatusTrading, "0.0001"), // listed
+	}
+
+	updates, delisted, changes := diffSymbols(stored, fetched, now)
+
+	names := func(symbols []*entities.Symbol) []string {
+		result := make([]string, 0, len(symbols))
+		for _, symbol := range symbols {
+			result = append(result, symbol.Name)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"ETHUSDT", "SOLUSDT", "LTCUSDT", "ARBUSDT"}, names(updates))
+	assert.Equal(t, []string{"XRPUSDT"}, names(delisted))
+
+	require.Len(t, changes, 4)
+
+	assert.Equal(t, "ETHUSDT", changes[0].Symbol)
+	assert.Equal(t, entities.SymbolStatusHalt, changes[0].Status)
+	assert.Equal(t, entities.SymbolStatusTrading, changes[0].PreviousStatus)
+	assert.True(t, changes[0].Listed)
+	assert.Equal(t, now, changes[0].Time)
+
+	assert.Equal(t, "SOLUSDT", changes[1].Symbol)
+	assert.Equal(t, entities.SymbolStatusTrading, changes[1].PreviousStatus)
+	assert.Equal(t, "0.001", changes[1].TickSize.String())
+
+	assert.Equal(t, "ARBUSDT", changes[2].Symbol)
+	assert.Empty(t, changes[2].PreviousStatus)
+	assert.True(t, changes[2].Listed)
+
+	assert.Equal(t, "XRPUSDT", changes[3].Symbol)
+	assert.False(t, changes[3].Listed)
+	assert.Equal(t, "0.0001", changes[3].TickSize.String())
+}
+
+func TestDiffSymbols_Unchanged(t *testing.T) {
+	stored := []*entities.Symbol{testSymbol("BTCUSDT", entities.SymbolStatusTrading, "0.01")}
+	fetched := []*entities.Symbol{testSymbol("BTCUSDT", entities.SymbolStatusTrading, "0.01")}
+
+	updates, delisted, changes := diffSymbols(stored, fetched, time.Now())
+	assert.Empty(t, updates)
+	assert.Empty(t, delisted)
+	assert.Empty(t, changes)
+}
+
+func TestSymbolRepository_HistoryFailureKeepsChange(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeStore()
+	repository := NewSymbolRepository(sql.OpenDB(store), entities.ExchangeBinance, entities.MarketSpot)
+
+	require.NoError(t, repository.ReplaceAll(ctx, []*entities.Symbol{
+		testSymbol("BTCUSDT", entities.SymbolStatusTrading, "0.01"),
+	}))
+	halted := []*entities.Symbol{testSymbol("BTCUSDT", entities.SymbolStatusHalt, "0.01")}
+
+	store.failCommits("symbol_status_history")
+	require.Error(t, repository.ReplaceAll(ctx, halted))
+	assert.Len(t, store.rows("symbols"), 1, "symbols must not move past an unrecorded change")
+
+	store.failCommits("")
+	require.NoError(t, repository.ReplaceAll(ctx, halted))
+
+	history := store.rows("symbol_status_history")
+	require.Len(t, history, 2)
+	assert.Equal(t, "BTCUSDT", history[1][2])
+	assert.Equal(t, string(entities.SymbolStatusHalt), history[1][3])
+	assert.Equal(t, string(entities.SymbolStatusTrading), history[1][4])
+
+	symbols, err := repository.GetAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, symbols, 1)
+	assert.Equal(t, entities.SymbolStatusHalt, symbols[0].Status)
+}
+
+// fakeStore is a database/sql driver keeping inserted rows per table, enough
+// to run the symbol repository without ClickHouse. Like a ClickHouse batch,
+// the rows of a transaction are sent on commit.
+type fakeStore struct {
+	mu      sync.Mutex
+	tables  map[string][][]driver.Value
+	failing string // commits inserting into this table fail
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{tables: make(map[string][][]driver.Value)}
+}
+
+func (s *fakeStore) failCommits(table string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing = table
+}
+
+func (s *fakeStore) rows(table string) [][]driver.Value {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables[table]
+}
+
+func (s *fakeStore) Connect(context.Context) (driver.Conn, error) { return &fakeConn{store: s}, nil }
+func (s *fakeStore) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct {
+	store *fakeStore
+	tx    *fakeTx
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.tx = &fakeTx{conn: c, rows: make(map[string][][]driver.Value)}
+	return c.tx, nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+	rows map[string][][]driver.Value
+}
+
+func (tx *fakeTx) Commit() error {
+	store := tx.conn.store
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	tx.conn.tx = nil
+
+	if _, ok := tx.rows[store.failing]; ok {
+		return errors.New("insert failed")
+	}
+	for table, rows := range tx.rows {
+		store.tables[table] = append(store.tables[table], rows...)
+	}
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.conn.tx = nil
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	fields := strings.Fields(s.query)
+	if len(fields) < 3 || fields[0] != "INSERT" || s.conn.tx == nil {
+		return nil, errors.New("only inserts in a transaction are supported")
+	}
+	table := fields[2]
+	s.conn.tx.rows[table] = append(s.conn.tx.rows[table], args)
+	return driver.RowsAffected(1), nil
+}
+
+// Query answers the symbols query of GetAll: the latest row of every listed
+// symbol, without the exchange, market, listed and updated_at columns.
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if !strings.Contains(s.query, "FROM symbols FINAL") {
+		return nil, errors.New("only the symbols query is supported")
+	}
+
+	store := s.conn.store
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	latest := make(map[string][]driver.Value)
+	var names []string
+	for _, row := range store.tables["symbols"] {
+		name := row[2].(string)
+		if _, ok := latest[name]; !ok {
+			names = append(names, name)
+		}
+		latest[name] = row
+	}
+
+	rows := &fakeRows{}
+	for _, name := range names {
+		if row := latest[name]; row[13].(bool) {
+			rows.values = append(rows.values, row[2:13])
+		}
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return strings.Split(strings.Join(strings.Fields(symbolColumns), ""), ",")
+}
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
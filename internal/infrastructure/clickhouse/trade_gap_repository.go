@@ -0,0 +1,72 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+)
+
+type TradeGapRepository struct {
+	db *sql.DB
+}
+
+func NewTradeGapRepository(db *sql.DB) repositories.TradeGapRepository {
+	return &TradeGapRepository{db: db}
+}
+
+func (r *TradeGapRepository) Save(ctx context.Context, gap *entities.TradeGap) error {
+	query := `
+		INSERT INTO trade_gaps (
+			symbol, from_id, to_id, detected_at
+		) VALUES (?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		gap.Symbol,
+		gap.FromID,
+		gap.ToID,
+		gap.DetectedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save trade gap: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TradeGapRepository) GetBySymbol(ctx context.Context, symbol string, from, to time.Time) ([]*entities.TradeGap, error) {
+	query := `
+		SELECT symbol, from_id, to_id, detected_at
+		FROM trade_gaps
+		WHERE symbol = ? AND detected_at >= ? AND detected_at <= ?
+		ORDER BY detected_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trade gaps: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var gaps []*entities.TradeGap
+	for rows.Next() {
+		var gap entities.TradeGap
+		err := rows.Scan(
+			&gap.Symbol,
+			&gap.FromID,
+			&gap.ToID,
+			&gap.DetectedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trade gap: %w", err)
+		}
+		gaps = append(gaps, &gap)
+	}
+
+	return gaps, nil
+}
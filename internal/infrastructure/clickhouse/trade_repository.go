@@ -4,36 +4,47 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"strconv"
 	"time"
 
 	"alarket/internal/domain/entities"
 	"alarket/internal/domain/repositories"
 )
 
+// TradeRepository stores trades of every exchange and market. Reads are scoped
+// to the exchange and market it was created for.
 type TradeRepository struct {
-	db *sql.DB
+	db       *sql.DB
+	exchange entities.Exchange
+	market   entities.Market
 }
 
-func NewTradeRepository(db *sql.DB) repositories.TradeRepository {
-	return &TradeRepository{db: db}
+func NewTradeRepository(db *sql.DB, exchange entities.Exchange, market entities.Market) repositories.TradeRepository {
+	return &TradeRepository{db: db, exchange: exchange, market: market}
 }
 
 func (r *TradeRepository) Save(ctx context.Context, trade *entities.Trade) error {
 	query := `
 		INSERT INTO trades (
-			id, symbol, price, quantity, trade_time, is_buyer_market_maker, event_time
-		) VALUES (?, ?, ?, ?, ?, ?, ?)
+			exchange, market, id, symbol, price, quantity, quote_quantity, buyer_order_id, seller_order_id,
+			trade_time, is_buyer_market_maker, is_best_match, event_time, source
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
+		exchangeValue(trade.Exchange),
+		marketValue(trade.Market),
 		trade.ID,
 		trade.Symbol,
 		trade.Price,
 		trade.Quantity,
+		trade.QuoteQuantity,
+		trade.BuyerOrderID,
+		trade.SellerOrderID,
 		trade.Time,
 		trade.IsBuyerMaker,
+		trade.IsBestMatch,
 		trade.EventTime,
+		sourceValue(trade.Source),
 	)
 
 	if err != nil {
@@ -56,7 +67,8 @@ func (r *TradeRepository) SaveBatch(ctx context.Context, trades []*entities.Trad
 
 	batch, err := tx.Prepare(`
 		INSERT INTO trades (
-			id, symbol, price, quantity, trade_time, is_buyer_market_maker, event_time
+			exchange, market, id, symbol, price, quantity, quote_quantity, buyer_order_id, seller_order_id,
+			trade_time, is_buyer_market_maker, is_best_match, event_time, source
 		)
 	`)
 	if err != nil {
@@ -66,16 +78,23 @@ func (r *TradeRepository) SaveBatch(ctx context.Context, trades []*entities.Trad
 
 	for _, trade := range trades {
 		_, err := batch.Exec(
+			exchangeValue(trade.Exchange),
+			marketValue(trade.Market),
 			trade.ID,
 			trade.Symbol,
 			trade.Price,
 			trade.Quantity,
+			trade.QuoteQuantity,
+			trade.BuyerOrderID,
+			trade.SellerOrderID,
 			trade.Time,
 			trade.IsBuyerMaker,
+			trade.IsBestMatch,
 			trade.EventTime,
+			sourceValue(trade.Source),
 		)
 		if err != nil {
-			return fmt.Errorf("failed to add trade to batch %s: %w", trade.ID, err)
+			return fmt.Errorf("failed to add trade to batch %d: %w", trade.ID, err)
 		}
 	}
 
@@ -88,13 +107,14 @@ func (r *TradeRepository) SaveBatch(ctx context.Context, trades []*entities.Trad
 
 func (r *TradeRepository) GetBySymbol(ctx context.Context, symbol string, from, to time.Time) ([]*entities.Trade, error) {
 	query := `
-		SELECT id, symbol, price, quantity, trade_time, is_buyer_market_maker, event_time
-		FROM trades
-		WHERE symbol = ? AND trade_time >= ? AND trade_time <= ?
-		ORDER BY trade_time
+		SELECT exchange, market, id, symbol, price, quantity, quote_quantity, buyer_order_id, seller_order_id,
+		       trade_time, is_buyer_market_maker, is_best_match, event_time, source
+		FROM trades FINAL
+		WHERE exchange = ? AND market = ? AND symbol = ? AND trade_time >= ? AND trade_time <= ?
+		ORDER BY trade_time, id
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, symbol, from, to)
+	rows, err := r.db.QueryContext(ctx, query, r.exchange, r.market, symbol, from, to)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query trades: %w", err)
 	}
@@ -103,41 +123,61 @@ func (r *TradeRepository) GetBySymbol(ctx context.Context, symbol string, from,
 	var trades []*entities.Trade
 	for rows.Next() {
 		var trade entities.Trade
+		var exchange, market, source string
 		err := rows.Scan(
+			&exchange,
+			&market,
 			&trade.ID,
 			&trade.Symbol,
 			&trade.Price,
 			&trade.Quantity,
+			&trade.QuoteQuantity,
+			&trade.BuyerOrderID,
+			&trade.SellerOrderID,
 			&trade.Time,
 			&trade.IsBuyerMaker,
+			&trade.IsBestMatch,
 			&trade.EventTime,
+			&source,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan trade: %w", err)
 		}
+		trade.Exchange = entities.Exchange(exchange)
+		trade.Market = entities.Market(market)
+		trade.Source = entities.TradeSource(source)
 		trades = append(trades, &trade)
 	}
 
 	return trades, nil
 }
 
-func (r *TradeRepository) GetByID(ctx context.Context, id string) (*entities.Trade, error) {
+func (r *TradeRepository) GetByID(ctx context.Context, id uint64) (*entities.Trade, error) {
 	query := `
-		SELECT id, symbol, price, quantity, trade_time, is_buyer_market_maker, event_time
-		FROM trades
-		WHERE id = ?
+		SELECT exchange, market, id, symbol, price, quantity, quote_quantity, buyer_order_id, seller_order_id,
+		       trade_time, is_buyer_market_maker, is_best_match, event_time, source
+		FROM trades FINAL
+		WHERE exchange = ? AND market = ? AND id = ?
 		LIMIT 1
 	`
 
 	var trade entities.Trade
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	var exchange, market, source string
+	err := r.db.QueryRowContext(ctx, query, r.exchange, r.market, id).Scan(
+		&exchange,
+		&market,
 		&trade.ID,
 		&trade.Symbol,
 		&trade.Price,
 		&trade.Quantity,
+		&trade.QuoteQuantity,
+		&trade.BuyerOrderID,
+		&trade.SellerOrderID,
 		&trade.Time,
 		&trade.IsBuyerMaker,
+		&trade.IsBestMatch,
 		&trade.EventTime,
+		&source,
 	)
 
 	if err == sql.ErrNoRows {
@@ -146,6 +186,9 @@ func (r *TradeRepository) GetByID(ctx context.Context, id string) (*entities.Tra
 	if err != nil {
 		return nil, fmt.Errorf("failed to get trade by id: %w", err)
 	}
+	trade.Exchange = entities.Exchange(exchange)
+	trade.Market = entities.Market(market)
+	trade.Source = entities.TradeSource(source)
 
 	return &trade, nil
 }
@@ -155,11 +198,11 @@ func (r *TradeRepository) GetOldestTradeTime(ctx context.Context, symbol string)
 	countQuery := `
 		SELECT COUNT(*) 
 		FROM trades 
-		WHERE symbol = ?
+		WHERE exchange = ? AND market = ? AND symbol = ?
 	`
 
 	var count int
-	err := r.db.QueryRowContext(ctx, countQuery, symbol).Scan(&count)
+	err := r.db.QueryRowContext(ctx, countQuery, r.exchange, r.market, symbol).Scan(&count)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count trades: %w", err)
 	}
@@ -172,11 +215,11 @@ func (r *TradeRepository) GetOldestTradeTime(ctx context.Context, symbol string)
 	query := `
 		SELECT MIN(trade_time) as oldest_time
 		FROM trades
-		WHERE symbol = ?
+		WHERE exchange = ? AND market = ? AND symbol = ?
 	`
 
 	var oldestTime time.Time
-	err = r.db.QueryRowContext(ctx, query, symbol).Scan(&oldestTime)
+	err = r.db.QueryRowContext(ctx, query, r.exchange, r.market, symbol).Scan(&oldestTime)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get oldest trade time: %w", err)
 	}
@@ -184,16 +227,16 @@ func (r *TradeRepository) GetOldestTradeTime(ctx context.Context, symbol string)
 	return &oldestTime, nil
 }
 
-func (r *TradeRepository) GetOldestTradeID(ctx context.Context, symbol string) (*int64, error) {
+func (r *TradeRepository) GetOldestTradeID(ctx context.Context, symbol string) (*uint64, error) {
 	// First check if there are any trades for this symbol
 	countQuery := `
 		SELECT COUNT(*) 
 		FROM trades 
-		WHERE symbol = ?
+		WHERE exchange = ? AND market = ? AND symbol = ?
 	`
 
 	var count int
-	err := r.db.QueryRowContext(ctx, countQuery, symbol).Scan(&count)
+	err := r.db.QueryRowContext(ctx, countQuery, r.exchange, r.market, symbol).Scan(&count)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count trades: %w", err)
 	}
@@ -202,40 +245,33 @@ func (r *TradeRepository) GetOldestTradeID(ctx context.Context, symbol string) (
 		return nil, nil
 	}
 
-	// If there are trades, get the oldest ID
+	// If there are trades, get the oldest ID. Trade IDs grow with time and
+	// duplicates do not change min(), so no FINAL is needed
 	query := `
-		SELECT id
+		SELECT min(id)
 		FROM trades
-		WHERE symbol = ?
-		ORDER BY trade_time ASC
-		LIMIT 1
+		WHERE exchange = ? AND market = ? AND symbol = ?
 	`
 
-	var oldestIDStr string
-	err = r.db.QueryRowContext(ctx, query, symbol).Scan(&oldestIDStr)
+	var oldestID uint64
+	err = r.db.QueryRowContext(ctx, query, r.exchange, r.market, symbol).Scan(&oldestID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get oldest trade ID: %w", err)
 	}
 
-	// Convert string ID to int64
-	oldestID, err := strconv.ParseInt(oldestIDStr, 10, 64)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse oldest trade ID: %w", err)
-	}
-
 	return &oldestID, nil
 }
 
-func (r *TradeRepository) GetNewestTradeID(ctx context.Context, symbol string) (*int64, error) {
+func (r *TradeRepository) GetNewestTradeID(ctx context.Context, symbol string) (*uint64, error) {
 	// First check if there are any trades for this symbol
 	countQuery := `
 		SELECT COUNT(*) 
 		FROM trades 
-		WHERE symbol = ?
+		WHERE exchange = ? AND market = ? AND symbol = ?
 	`
 
 	var count int
-	err := r.db.QueryRowContext(ctx, countQuery, symbol).Scan(&count)
+	err := r.db.QueryRowContext(ctx, countQuery, r.exchange, r.market, symbol).Scan(&count)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count trades: %w", err)
 	}
@@ -244,26 +280,79 @@ func (r *TradeRepository) GetNewestTradeID(ctx context.Context, symbol string) (
 		return nil, nil
 	}
 
-	// If there are trades, get the newest ID
+	// If there are trades, get the newest ID. Trade IDs grow with time and
+	// duplicates do not change max(), so no FINAL is needed
 	query := `
-		SELECT id
+		SELECT max(id)
 		FROM trades
-		WHERE symbol = ?
-		ORDER BY trade_time DESC
-		LIMIT 1
+		WHERE exchange = ? AND market = ? AND symbol = ?
 	`
 
-	var newestIDStr string
-	err = r.db.QueryRowContext(ctx, query, symbol).Scan(&newestIDStr)
+	var newestID uint64
+	err = r.db.QueryRowContext(ctx, query, r.exchange, r.market, symbol).Scan(&newestID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get newest trade ID: %w", err)
 	}
 
-	// Convert string ID to int64
-	newestID, err := strconv.ParseInt(newestIDStr, 10, 64)
+	return &newestID, nil
+}
+
+func (r *TradeRepository) GetNewestTradeTime(ctx context.Context, symbol string) (*time.Time, error) {
+	// First check if there are any trades for this symbol
+	countQuery := `
+		SELECT COUNT(*)
+		FROM trades
+		WHERE exchange = ? AND market = ? AND symbol = ?
+	`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, countQuery, r.exchange, r.market, symbol).Scan(&count)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse newest trade ID: %w", err)
+		return nil, fmt.Errorf("failed to count trades: %w", err)
 	}
 
-	return &newestID, nil
+	if count == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT max(trade_time)
+		FROM trades
+		WHERE exchange = ? AND market = ? AND symbol = ?
+	`
+
+	var newestTime time.Time
+	err = r.db.QueryRowContext(ctx, query, r.exchange, r.market, symbol).Scan(&newestTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get newest trade time: %w", err)
+	}
+
+	return &newestTime, nil
+}
+
+// sourceValue maps a missing source, e.g. from a batch spooled by an older
+// version, to the 'unknown' enum value.
+func sourceValue(source entities.TradeSource) string {
+	if source == "" {
+		return string(entities.TradeSourceUnknown)
+	}
+	return string(source)
+}
+
+// exchangeValue maps a missing exchange, e.g. from a batch spooled by an older
+// version, to Binance, the only exchange collected before it was tracked.
+func exchangeValue(exchange entities.Exchange) string {
+	if exchange == "" {
+		return string(entities.ExchangeBinance)
+	}
+	return string(exchange)
+}
+
+// marketValue maps a missing market, e.g. from a batch spooled by an older
+// version, to spot, the only market collected before it was tracked.
+func marketValue(market entities.Market) string {
+	if market == "" {
+		return string(entities.MarketSpot)
+	}
+	return string(market)
 }
@@ -1,94 +1,404 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"alarket/internal/domain/entities"
 )
 
 type Config struct {
-	Binance    BinanceConfig
-	ClickHouse ClickHouseConfig
-	App        AppConfig
+	Binance    BinanceConfig    `yaml:"binance"`
+	Bybit      BybitConfig      `yaml:"bybit"`
+	ClickHouse ClickHouseConfig `yaml:"clickhouse"`
+	App        AppConfig        `yaml:"app"`
 }
 
 type BinanceConfig struct {
-	APIKey     string
-	SecretKey  string
-	UseTestnet bool
+	APIKey            string  `yaml:"api_key"`
+	SecretKey         string  `yaml:"secret_key"`
+	UseTestnet        bool    `yaml:"use_testnet"`
+	MessagesPerSecond float64 `yaml:"ws_messages_per_second"` // SUBSCRIBE/UNSUBSCRIBE requests sent per second on each connection
+	MessageBurst      int     `yaml:"ws_message_burst"`       // Requests sent back to back before pacing kicks in
+	StreamMode        string  `yaml:"ws_mode"`                // subscribe (SUBSCRIBE requests) or combined (streams in the URL)
+	RequestWeight     int     `yaml:"request_weight_limit"`   // REST request weight per minute the backfill jobs spend together
+}
+
+type BybitConfig struct {
+	UseTestnet bool `yaml:"use_testnet"`
 }
 
 type ClickHouseConfig struct {
-	Host     string
-	Port     int
-	Database string
-	Username string
-	Password string
-	Debug    bool
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Database string `yaml:"database"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Debug    bool   `yaml:"debug"`
+}
+
+// DSN returns the clickhouse-go connection string, escaping the credentials.
+func (c ClickHouseConfig) DSN() string {
+	dsn := url.URL{
+		Scheme:   "clickhouse",
+		User:     url.UserPassword(c.Username, c.Password),
+		Host:     net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		Path:     "/" + c.Database,
+		RawQuery: "debug=" + strconv.FormatBool(c.Debug),
+	}
+	return dsn.String()
 }
 
 type AppConfig struct {
-	LogLevel             string
-	SubscribeTrades      bool
-	SubscribeBookTickers bool
-	BatchSize            int
-	BatchFlushTimeoutMs  int
-	Symbols              []string // Specific symbols to collect (empty = all USDT pairs)
+	LogLevel                  string                           `yaml:"log_level"`
+	Exchanges                 []string                         `yaml:"exchanges"` // Exchanges to collect from side by side, e.g. binance,binance-usdm,bybit
+	SubscribeTrades           bool                             `yaml:"subscribe_trades"`
+	SubscribeAggTrades        bool                             `yaml:"subscribe_agg_trades"`
+	SubscribeBookTickers      bool                             `yaml:"subscribe_book_tickers"`
+	SubscribeOrderBooks       bool                             `yaml:"subscribe_order_books"`           // Keep local order books from the diff depth stream
+	SubscribeMarkPrices       bool                             `yaml:"subscribe_mark_prices"`           // Futures mark prices and funding rates from the markPrice stream
+	OrderBookDepth            int                              `yaml:"order_book_depth"`                // Levels per side persisted in each order book snapshot
+	OrderBookSnapshotMs       int                              `yaml:"order_book_snapshot_interval_ms"` // Interval between persisted order book snapshots
+	KlineIntervals            []string                         `yaml:"kline_intervals"`                 // Kline intervals to collect, e.g. 1m,1h (empty = no klines)
+	BatchSize                 int                              `yaml:"batch_size"`
+	BatchFlushTimeoutMs       int                              `yaml:"batch_flush_timeout_ms"`
+	SpoolDir                  string                           `yaml:"spool_dir"` // Directory for batches that failed to flush
+	FlushWorkers              int                              `yaml:"flush_workers"`
+	FlushQueueSize            int                              `yaml:"flush_queue_size"`              // Batches waiting for a flush worker
+	FlushOverflowPolicy       string                           `yaml:"flush_overflow_policy"`         // block, drop_oldest or spill
+	GapBackfill               bool                             `yaml:"gap_backfill"`                  // Backfill trade ID gaps over REST, off: may need an API key
+	GapBackfillDelayMs        int                              `yaml:"gap_backfill_delay_ms"`         // Minimum delay between backfill requests
+	MetricsAddr               string                           `yaml:"metrics_addr"`                  // Listen address of /metrics and the health endpoints
+	LivenessMaxSilenceMs      int                              `yaml:"liveness_max_silence_ms"`       // Liveness fails when no message arrived for this long
+	LivenessMaxFlushErrorRate float64                          `yaml:"liveness_max_flush_error_rate"` // Liveness fails above this share of failed flushes
+	LivenessFlushWindowMs     int                              `yaml:"liveness_flush_window_ms"`      // Window the flush error rate is measured over
+	SymbolRefreshIntervalMs   int                              `yaml:"symbol_refresh_interval_ms"`    // Interval between symbol refreshes (0 = disabled)
+	BackfillJobsDir           string                           `yaml:"backfill_jobs_dir"`             // Keep the backfill job queue here instead of in ClickHouse
+	BackfillConcurrency       int                              `yaml:"backfill_concurrency"`          // Symbols backfilled at the same time
+	SymbolSelection           SymbolSelectionConfig            `yaml:"symbol_selection"`
+	StreamSymbolSelections    map[string]SymbolSelectionConfig `yaml:"stream_symbol_selections"` // Per stream, keyed by stream name
 }
 
-func Load() (*Config, error) {
+// SymbolSelectionConfig picks the active symbols a stream is collected for.
+// Empty lists don't filter.
+type SymbolSelectionConfig struct {
+	Symbols     []string `yaml:"symbols"`      // Exact symbol names
+	QuoteAssets []string `yaml:"quote_assets"` // e.g. USDT,FDUSD
+	BaseAssets  []string `yaml:"base_assets"`  // e.g. BTC,ETH
+	Include     []string `yaml:"include"`      // Globs such as *USDT or /regex/, a symbol must match one
+	Exclude     []string `yaml:"exclude"`      // Globs or /regex/, a symbol must match none
+	Permissions []string `yaml:"permissions"`  // spot and/or margin
+	TopN        int      `yaml:"top_n"`        // Keep the N symbols with the highest 24h quote volume (0 = all)
+}
+
+// withDefaults fills the fields left empty from defaults.
+func (s SymbolSelectionConfig) withDefaults(defaults SymbolSelectionConfig) SymbolSelectionConfig {
+	fill := func(value, fallback []string) []string {
+		if len(value) == 0 {
+			return fallback
+		}
+		return value
+	}
+
+	s.Symbols = fill(s.Symbols, defaults.Symbols)
+	s.QuoteAssets = fill(s.QuoteAssets, defaults.QuoteAssets)
+	s.BaseAssets = fill(s.BaseAssets, defaults.BaseAssets)
+	s.Include = fill(s.Include, defaults.Include)
+	s.Exclude = fill(s.Exclude, defaults.Exclude)
+	s.Permissions = fill(s.Permissions, defaults.Permissions)
+	if s.TopN == 0 {
+		s.TopN = defaults.TopN
+	}
+	return s
+}
+
+// streamEnvPrefixes maps stream names onto the prefix of their selection
+// overrides, e.g. BOOK_TICKERS_SYMBOL_TOP_N.
+var streamEnvPrefixes = map[string]string{
+	"trades":      "TRADES_",
+	"aggTrades":   "AGG_TRADES_",
+	"klines":      "KLINES_",
+	"bookTickers": "BOOK_TICKERS_",
+	"orderBooks":  "ORDER_BOOKS_",
+	"markPrices":  "MARK_PRICES_",
+}
+
+// Default returns the configuration used when neither a file nor the
+// environment sets a value.
+func Default() *Config {
 	cfg := &Config{}
 
 	// Binance configuration
-	cfg.Binance.APIKey = getEnv("BINANCE_API_KEY", "")
-	cfg.Binance.SecretKey = getEnv("BINANCE_SECRET_KEY", "")
-	cfg.Binance.UseTestnet = getEnvBool("BINANCE_USE_TESTNET", false)
+	cfg.Binance.MessagesPerSecond = 4
+	cfg.Binance.MessageBurst = 1
+	cfg.Binance.StreamMode = "subscribe"
+	cfg.Binance.RequestWeight = 3000
 
 	// ClickHouse configuration
-	cfg.ClickHouse.Host = getEnv("CLICKHOUSE_HOST", "localhost")
-	cfg.ClickHouse.Port = getEnvInt("CLICKHOUSE_PORT", 9000)
-	cfg.ClickHouse.Database = getEnv("CLICKHOUSE_DATABASE", "alarket")
-	cfg.ClickHouse.Username = getEnv("CLICKHOUSE_USERNAME", "default")
-	cfg.ClickHouse.Password = getEnv("CLICKHOUSE_PASSWORD", "")
-	cfg.ClickHouse.Debug = getEnvBool("CLICKHOUSE_DEBUG", false)
+	cfg.ClickHouse.Host = "localhost"
+	cfg.ClickHouse.Port = 9000
+	cfg.ClickHouse.Database = "alarket"
+	cfg.ClickHouse.Username = "default"
 
 	// App configuration
-	cfg.App.LogLevel = getEnv("LOG_LEVEL", "info")
-	cfg.App.SubscribeTrades = getEnvBool("SUBSCRIBE_TRADES", true)
-	cfg.App.SubscribeBookTickers = getEnvBool("SUBSCRIBE_BOOK_TICKERS", false)
-	cfg.App.BatchSize = getEnvInt("BATCH_SIZE", 10000)
-	cfg.App.BatchFlushTimeoutMs = getEnvInt("BATCH_FLUSH_TIMEOUT_MS", 1000)
-	cfg.App.Symbols = getEnvSlice("SYMBOLS", []string{})
+	cfg.App.LogLevel = "info"
+	cfg.App.Exchanges = []string{"binance"}
+	cfg.App.SubscribeTrades = true
+	cfg.App.OrderBookDepth = 20
+	cfg.App.OrderBookSnapshotMs = 1000
+	cfg.App.KlineIntervals = []string{}
+	cfg.App.BatchSize = 10000
+	cfg.App.BatchFlushTimeoutMs = 1000
+	cfg.App.SpoolDir = "./spool"
+	cfg.App.FlushWorkers = 4
+	cfg.App.FlushQueueSize = 16
+	cfg.App.FlushOverflowPolicy = "block"
+	cfg.App.GapBackfillDelayMs = 100
+	cfg.App.MetricsAddr = ":9090"
+	cfg.App.LivenessMaxSilenceMs = 60000
+	cfg.App.LivenessMaxFlushErrorRate = 0.5
+	cfg.App.LivenessFlushWindowMs = 300000
+	cfg.App.SymbolRefreshIntervalMs = 300000
+	cfg.App.BackfillConcurrency = 4
 
+	return cfg
+}
+
+// Load reads the YAML file named by CONFIG_FILE, if set, and the environment
+// on top of it.
+func Load() (*Config, error) {
+	return LoadFile(os.Getenv("CONFIG_FILE"))
+}
+
+// LoadFile layers the defaults, the YAML file at path (skipped when empty) and
+// the environment, in that order, and validates the result.
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		for stream := range cfg.App.StreamSymbolSelections {
+			if _, ok := streamEnvPrefixes[stream]; !ok {
+				return nil, fmt.Errorf("failed to parse config file %s: unknown stream %q in stream_symbol_selections", path, stream)
+			}
+		}
+	}
+
+	env := &envReader{}
+	cfg.readEnv(env)
+	if err := errors.Join(env.errs...); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
-func getEnv(key, defaultValue string) string {
+func (cfg *Config) readEnv(env *envReader) {
+	// Binance configuration
+	env.string("BINANCE_API_KEY", &cfg.Binance.APIKey)
+	env.string("BINANCE_SECRET_KEY", &cfg.Binance.SecretKey)
+	env.bool("BINANCE_USE_TESTNET", &cfg.Binance.UseTestnet)
+	env.float("BINANCE_WS_MESSAGES_PER_SECOND", &cfg.Binance.MessagesPerSecond)
+	env.int("BINANCE_WS_MESSAGE_BURST", &cfg.Binance.MessageBurst)
+	env.string("BINANCE_WS_MODE", &cfg.Binance.StreamMode)
+	env.int("BINANCE_REQUEST_WEIGHT_LIMIT", &cfg.Binance.RequestWeight)
+
+	// Bybit configuration
+	env.bool("BYBIT_USE_TESTNET", &cfg.Bybit.UseTestnet)
+
+	// ClickHouse configuration
+	env.string("CLICKHOUSE_HOST", &cfg.ClickHouse.Host)
+	env.int("CLICKHOUSE_PORT", &cfg.ClickHouse.Port)
+	env.string("CLICKHOUSE_DATABASE", &cfg.ClickHouse.Database)
+	env.string("CLICKHOUSE_USERNAME", &cfg.ClickHouse.Username)
+	env.string("CLICKHOUSE_PASSWORD", &cfg.ClickHouse.Password)
+	env.bool("CLICKHOUSE_DEBUG", &cfg.ClickHouse.Debug)
+
+	// App configuration
+	env.string("LOG_LEVEL", &cfg.App.LogLevel)
+	env.slice("EXCHANGES", &cfg.App.Exchanges)
+	env.bool("SUBSCRIBE_TRADES", &cfg.App.SubscribeTrades)
+	env.bool("SUBSCRIBE_AGG_TRADES", &cfg.App.SubscribeAggTrades)
+	env.bool("SUBSCRIBE_BOOK_TICKERS", &cfg.App.SubscribeBookTickers)
+	env.bool("SUBSCRIBE_ORDER_BOOKS", &cfg.App.SubscribeOrderBooks)
+	env.bool("SUBSCRIBE_MARK_PRICES", &cfg.App.SubscribeMarkPrices)
+	env.int("ORDER_BOOK_DEPTH", &cfg.App.OrderBookDepth)
+	env.int("ORDER_BOOK_SNAPSHOT_INTERVAL_MS", &cfg.App.OrderBookSnapshotMs)
+	env.slice("KLINE_INTERVALS", &cfg.App.KlineIntervals)
+	env.int("BATCH_SIZE", &cfg.App.BatchSize)
+	env.int("BATCH_FLUSH_TIMEOUT_MS", &cfg.App.BatchFlushTimeoutMs)
+	env.string("SPOOL_DIR", &cfg.App.SpoolDir)
+	env.int("FLUSH_WORKERS", &cfg.App.FlushWorkers)
+	env.int("FLUSH_QUEUE_SIZE", &cfg.App.FlushQueueSize)
+	env.string("FLUSH_OVERFLOW_POLICY", &cfg.App.FlushOverflowPolicy)
+	env.bool("GAP_BACKFILL", &cfg.App.GapBackfill)
+	env.int("GAP_BACKFILL_DELAY_MS", &cfg.App.GapBackfillDelayMs)
+	env.string("METRICS_ADDR", &cfg.App.MetricsAddr)
+	env.int("LIVENESS_MAX_SILENCE_MS", &cfg.App.LivenessMaxSilenceMs)
+	env.float("LIVENESS_MAX_FLUSH_ERROR_RATE", &cfg.App.LivenessMaxFlushErrorRate)
+	env.int("LIVENESS_FLUSH_WINDOW_MS", &cfg.App.LivenessFlushWindowMs)
+	env.int("SYMBOL_REFRESH_INTERVAL_MS", &cfg.App.SymbolRefreshIntervalMs)
+	env.string("BACKFILL_JOBS_DIR", &cfg.App.BackfillJobsDir)
+	env.int("BACKFILL_CONCURRENCY", &cfg.App.BackfillConcurrency)
+
+	// Each stream falls back to the default selection field by field, an
+	// environment override beats the file
+	env.symbolSelection("", &cfg.App.SymbolSelection)
+	fromFile := cfg.App.StreamSymbolSelections
+	cfg.App.StreamSymbolSelections = make(map[string]SymbolSelectionConfig, len(streamEnvPrefixes))
+	for stream, prefix := range streamEnvPrefixes {
+		selection := fromFile[stream].withDefaults(cfg.App.SymbolSelection)
+		env.symbolSelection(prefix, &selection)
+		cfg.App.StreamSymbolSelections[stream] = selection
+	}
+}
+
+// Validate reports every setting that is out of range or not understood.
+func (cfg *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(cfg.Binance.MessagesPerSecond > 0, "BINANCE_WS_MESSAGES_PER_SECOND must be greater than 0, got %v", cfg.Binance.MessagesPerSecond)
+	check(cfg.Binance.MessageBurst > 0, "BINANCE_WS_MESSAGE_BURST must be greater than 0, got %d", cfg.Binance.MessageBurst)
+	check(slices.Contains([]string{"subscribe", "combined"}, cfg.Binance.StreamMode),
+		"BINANCE_WS_MODE must be subscribe or combined, got %q", cfg.Binance.StreamMode)
+	check(cfg.Binance.RequestWeight > 0, "BINANCE_REQUEST_WEIGHT_LIMIT must be greater than 0, got %d", cfg.Binance.RequestWeight)
+
+	check(cfg.ClickHouse.Host != "", "CLICKHOUSE_HOST must not be empty")
+	check(cfg.ClickHouse.Port > 0 && cfg.ClickHouse.Port <= 65535, "CLICKHOUSE_PORT must be between 1 and 65535, got %d", cfg.ClickHouse.Port)
+	check(cfg.ClickHouse.Database != "", "CLICKHOUSE_DATABASE must not be empty")
+
+	check(slices.Contains([]string{"debug", "info", "warn", "error"}, cfg.App.LogLevel),
+		"LOG_LEVEL must be debug, info, warn or error, got %q", cfg.App.LogLevel)
+	check(len(cfg.App.Exchanges) > 0, "EXCHANGES must name at least one exchange")
+	check(cfg.App.OrderBookDepth > 0, "ORDER_BOOK_DEPTH must be greater than 0, got %d", cfg.App.OrderBookDepth)
+	check(cfg.App.OrderBookSnapshotMs > 0, "ORDER_BOOK_SNAPSHOT_INTERVAL_MS must be greater than 0, got %d", cfg.App.OrderBookSnapshotMs)
+	for _, value := range cfg.App.KlineIntervals {
+		_, err := entities.ParseKlineInterval(value)
+		check(err == nil, "KLINE_INTERVALS: %v", err)
+	}
+	check(cfg.App.BatchSize > 0, "BATCH_SIZE must be greater than 0, got %d", cfg.App.BatchSize)
+	check(cfg.App.BatchFlushTimeoutMs > 0, "BATCH_FLUSH_TIMEOUT_MS must be greater than 0, got %d", cfg.App.BatchFlushTimeoutMs)
+	check(cfg.App.FlushWorkers > 0, "FLUSH_WORKERS must be greater than 0, got %d", cfg.App.FlushWorkers)
+	check(cfg.App.FlushQueueSize > 0, "FLUSH_QUEUE_SIZE must be greater than 0, got %d", cfg.App.FlushQueueSize)
+	check(slices.Contains([]string{"block", "drop_oldest", "spill"}, cfg.App.FlushOverflowPolicy),
+		"FLUSH_OVERFLOW_POLICY must be block, drop_oldest or spill, got %q", cfg.App.FlushOverflowPolicy)
+	check(cfg.App.GapBackfillDelayMs >= 0, "GAP_BACKFILL_DELAY_MS must not be negative, got %d", cfg.App.GapBackfillDelayMs)
+	check(cfg.App.LivenessMaxSilenceMs > 0, "LIVENESS_MAX_SILENCE_MS must be greater than 0, got %d", cfg.App.LivenessMaxSilenceMs)
+	check(cfg.App.LivenessMaxFlushErrorRate >= 0 && cfg.App.LivenessMaxFlushErrorRate <= 1,
+		"LIVENESS_MAX_FLUSH_ERROR_RATE must be between 0 and 1, got %v", cfg.App.LivenessMaxFlushErrorRate)
+	check(cfg.App.LivenessFlushWindowMs > 0, "LIVENESS_FLUSH_WINDOW_MS must be greater than 0, got %d", cfg.App.LivenessFlushWindowMs)
+	check(cfg.App.SymbolRefreshIntervalMs >= 0, "SYMBOL_REFRESH_INTERVAL_MS must not be negative, got %d", cfg.App.SymbolRefreshIntervalMs)
+	check(cfg.App.BackfillConcurrency > 0, "BACKFILL_CONCURRENCY must be greater than 0, got %d", cfg.App.BackfillConcurrency)
+
+	errs = append(errs, cfg.App.SymbolSelection.validate("")...)
+	for stream, selection := range cfg.App.StreamSymbolSelections {
+		errs = append(errs, selection.validate(streamEnvPrefixes[stream])...)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s SymbolSelectionConfig) validate(prefix string) []error {
+	var errs []error
+	for _, pattern := range slices.Concat(s.Include, s.Exclude) {
+		if _, err := entities.ParseSymbolPattern(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("%sSYMBOL_INCLUDE/EXCLUDE: %w", prefix, err))
+		}
+	}
+	for _, permission := range s.Permissions {
+		if !slices.Contains([]string{"spot", "margin"}, strings.ToLower(permission)) {
+			errs = append(errs, fmt.Errorf("%sSYMBOL_PERMISSIONS must be spot or margin, got %q", prefix, permission))
+		}
+	}
+	if s.TopN < 0 {
+		errs = append(errs, fmt.Errorf("%sSYMBOL_TOP_N must not be negative, got %d", prefix, s.TopN))
+	}
+	return errs
+}
+
+// envReader overrides settings with the environment variables that are set
+// and collects the values that fail to parse.
+type envReader struct {
+	errs []error
+}
+
+func (r *envReader) symbolSelection(prefix string, selection *SymbolSelectionConfig) {
+	r.slice(prefix+"SYMBOLS", &selection.Symbols)
+	r.slice(prefix+"SYMBOL_QUOTE_ASSETS", &selection.QuoteAssets)
+	r.slice(prefix+"SYMBOL_BASE_ASSETS", &selection.BaseAssets)
+	r.slice(prefix+"SYMBOL_INCLUDE", &selection.Include)
+	r.slice(prefix+"SYMBOL_EXCLUDE", &selection.Exclude)
+	r.slice(prefix+"SYMBOL_PERMISSIONS", &selection.Permissions)
+	r.int(prefix+"SYMBOL_TOP_N", &selection.TopN)
+}
+
+func (r *envReader) string(key string, target *string) {
 	if value := os.Getenv(key); value != "" {
-		return value
+		*target = value
+	}
+}
+
+func (r *envReader) int(key string, target *int) {
+	if value := os.Getenv(key); value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("%s must be an integer, got %q", key, value))
+			return
+		}
+		*target = intValue
 	}
-	return defaultValue
 }
 
-func getEnvInt(key string, defaultValue int) int {
+func (r *envReader) float(key string, target *float64) {
 	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
+		floatValue, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("%s must be a number, got %q", key, value))
+			return
 		}
+		*target = floatValue
 	}
-	return defaultValue
 }
 
-func getEnvBool(key string, defaultValue bool) bool {
+func (r *envReader) bool(key string, target *bool) {
 	if value := os.Getenv(key); value != "" {
-		if boolValue, err := strconv.ParseBool(value); err == nil {
-			return boolValue
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("%s must be true or false, got %q", key, value))
+			return
 		}
+		*target = boolValue
 	}
-	return defaultValue
 }
 
-func getEnvSlice(key string, defaultValue []string) []string {
+func (r *envReader) slice(key string, target *[]string) {
 	if value := os.Getenv(key); value != "" {
 		// Split by comma and trim spaces
 		parts := strings.Split(value, ",")
@@ -99,8 +409,7 @@ func getEnvSlice(key string, defaultValue []string) []string {
 			}
 		}
 		if len(result) > 0 {
-			return result
+			*target = result
 		}
 	}
-	return defaultValue
 }
@@ -3,120 +3,162 @@ package container
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
-	"os"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/ClickHouse/clickhouse-go/v2"
 
 	appservices "alarket/internal/application/services"
 	"alarket/internal/application/usecases"
+	"alarket/internal/domain/entities"
 	"alarket/internal/domain/repositories"
 	domainservices "alarket/internal/domain/services"
 	"alarket/internal/infrastructure/binance"
 	"alarket/internal/infrastructure/clickhouse"
 	"alarket/internal/infrastructure/config"
+	"alarket/internal/infrastructure/eventlog"
+	"alarket/internal/infrastructure/exchanges"
+	"alarket/internal/infrastructure/health"
+	"alarket/internal/infrastructure/metrics"
 )
 
+// ExchangeCollector holds the components that collect from one exchange.
+// Batch processors and everything behind them are shared.
+type ExchangeCollector struct {
+	Name                      string // exchange/market, e.g. binance/usdm
+	Connector                 domainservices.ExchangeConnector
+	SymbolRepository          repositories.SymbolRepository
+	ExchangeClient            domainservices.ExchangeClient
+	EventHandler              *appservices.EventHandler
+	TradeGapDetector          *appservices.TradeGapDetector // nil = gap backfill disabled or not offered
+	SubscribeToSymbolsUseCase *usecases.SubscribeToSymbolsUseCase
+	SymbolRefresher           *appservices.SymbolRefresher // nil = symbol refresh disabled
+}
+
 type Container struct {
-	Config *config.Config
-	Logger *slog.Logger
+	Config         *config.Config
+	Logger         *slog.Logger
+	LogLevel       *slog.LevelVar           // Changed by Reload
+	KlineIntervals []entities.KlineInterval // Parsed from Config.App.KlineIntervals
+	Selections     usecases.Selections      // Parsed from Config.App.StreamSymbolSelections
 
 	// Repositories
-	TradeRepository      repositories.TradeRepository
-	BookTickerRepository repositories.BookTickerRepository
-	SymbolRepository     repositories.SymbolRepository
+	TradeRepository             repositories.TradeRepository
+	AggTradeRepository          repositories.AggTradeRepository
+	KlineRepository             repositories.KlineRepository
+	BookTickerRepository        repositories.BookTickerRepository
+	TradeGapRepository          repositories.TradeGapRepository
+	OrderBookSnapshotRepository repositories.OrderBookSnapshotRepository
+	MarkPriceRepository         repositories.MarkPriceRepository
 
 	// Batch Processors
 	TradeBatchProcessor      *clickhouse.TradeBatchProcessor
+	AggTradeBatchProcessor   *clickhouse.AggTradeBatchProcessor
+	KlineBatchProcessor      *clickhouse.KlineBatchProcessor
 	BookTickerBatchProcessor *clickhouse.BookTickerBatchProcessor
+	MarkPriceBatchProcessor  *clickhouse.MarkPriceBatchProcessor
 
 	// Use Cases
-	ProcessTradeUseCase       *usecases.ProcessTradeEventUseCase
-	ProcessBookTickerUseCase  *usecases.ProcessBookTickerEventUseCase
-	SubscribeToSymbolsUseCase *usecases.SubscribeToSymbolsUseCase
+	ProcessTradeUseCase      *usecases.ProcessTradeEventUseCase
+	ProcessAggTradeUseCase   *usecases.ProcessAggTradeEventUseCase
+	ProcessKlineUseCase      *usecases.ProcessKlineEventUseCase
+	ProcessBookTickerUseCase *usecases.ProcessBookTickerEventUseCase
+	ProcessMarkPriceUseCase  *usecases.ProcessMarkPriceEventUseCase
 
 	// Services
-	ExchangeClient domainservices.ExchangeClient
-	EventHandler   *appservices.EventHandler
+	OrderBookManager *appservices.OrderBookManager
+
+	// One collector per configured exchange
+	Exchanges []*ExchangeCollector
 
 	// Infrastructure
-	DB *sql.DB
+	DB            *sql.DB
+	MetricsServer *metrics.Server
+	Health        *health.Monitor
+
+	load Loader
+
+	// Held by SetupCollector, PlanSubscriptions and Reload, so a reload never
+	// changes Selections or Config.App while they read them
+	mu sync.Mutex
 }
 
-func New(ctx context.Context) (*Container, error) {
-	c := &Container{}
+// Loader returns a validated configuration, e.g. config.Load.
+type Loader func() (*config.Config, error)
+
+// New loads the configuration and sets up logging to logOutput. Nothing else
+// is wired yet: each command sets up only what it uses, e.g. OpenDatabase for
+// database work or SetupCollector for live collection. Reload calls load
+// again.
+func New(load Loader, logOutput io.Writer) (*Container, error) {
+	c := &Container{load: load}
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := load()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 	c.Config = cfg
 
-	// Setup logger
-	logLevel := slog.LevelInfo
-	switch cfg.App.LogLevel {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
+	if c.KlineIntervals, err = parseKlineIntervals(cfg.App.KlineIntervals); err != nil {
+		return nil, err
 	}
 
-	c.Logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
-
-	// Setup database
-	if err := c.setupDatabase(ctx); err != nil {
-		return nil, fmt.Errorf("failed to setup database: %w", err)
+	if c.Selections, err = parseSelections(cfg); err != nil {
+		return nil, err
 	}
 
-	// Setup repositories
-	if err := c.setupRepositories(ctx); err != nil {
-		return nil, fmt.Errorf("failed to setup repositories: %w", err)
-	}
+	// Setup logger, the level can change on reload
+	c.LogLevel = new(slog.LevelVar)
+	c.LogLevel.Set(parseLogLevel(cfg.App.LogLevel))
 
-	// Setup batch processors
-	c.setupBatchProcessors()
-
-	// Setup use cases
-	c.setupUseCases()
-
-	// Setup services
-	if err := c.setupServices(); err != nil {
-		return nil, fmt.Errorf("failed to setup services: %w", err)
-	}
+	c.Logger = slog.New(slog.NewJSONHandler(logOutput, &slog.HandlerOptions{
+		Level: c.LogLevel,
+	}))
 
 	return c, nil
 }
 
-func (c *Container) setupDatabase(ctx context.Context) error {
-	dsn := fmt.Sprintf("clickhouse://%s:%s@%s:%d/%s?debug=%t",
-		c.Config.ClickHouse.Username,
-		c.Config.ClickHouse.Password,
-		c.Config.ClickHouse.Host,
-		c.Config.ClickHouse.Port,
-		c.Config.ClickHouse.Database,
-		c.Config.ClickHouse.Debug,
-	)
+// OpenDatabase connects to ClickHouse without touching the schema. It does
+// nothing when the database is already open.
+func (c *Container) OpenDatabase(ctx context.Context) error {
+	if c.DB != nil {
+		return nil
+	}
 
-	db, err := sql.Open("clickhouse", dsn)
+	db, err := sql.Open("clickhouse", c.Config.ClickHouse.DSN())
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
 
 	if err := db.PingContext(ctx); err != nil {
+		if closeErr := db.Close(); closeErr != nil {
+			c.Logger.Error("Failed to close database after ping error", "error", closeErr)
+		}
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	c.DB = db
+	return nil
+}
 
-	// Run migrations
-	migrator := clickhouse.NewMigrator(db, c.Logger)
+// MigrateDatabase connects to ClickHouse and applies the pending schema
+// migrations.
+func (c *Container) MigrateDatabase(ctx context.Context) error {
+	if err := c.OpenDatabase(ctx); err != nil {
+		return err
+	}
+
+	migrator, err := clickhouse.NewMigrator(c.DB, c.Logger)
+	if err != nil {
+		return err
+	}
 	if err := migrator.Migrate(ctx); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
@@ -124,38 +166,112 @@ func (c *Container) setupDatabase(ctx context.Context) error {
 	return nil
 }
 
-func (c *Container) setupRepositories(ctx context.Context) error {
+// SetupCollector wires live collection: metrics and health endpoints, the
+// migrated database, batch processors and a collector for every exchange in
+// EXCHANGES, whose symbols are fetched and stored.
+func (c *Container) SetupCollector(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cfg := c.Config
+
+	// Serve Prometheus metrics and health probes, the collector reports ready
+	// once every startup step is done
+	c.Health = health.NewMonitor(health.Config{
+		MaxSilence:        time.Duration(cfg.App.LivenessMaxSilenceMs) * time.Millisecond,
+		MaxFlushErrorRate: cfg.App.LivenessMaxFlushErrorRate,
+		FlushWindow:       time.Duration(cfg.App.LivenessFlushWindowMs) * time.Millisecond,
+	})
+	c.Health.Expect(health.StepMigrations)
+
+	c.MetricsServer = metrics.NewServer(cfg.App.MetricsAddr, c.Logger)
+	c.MetricsServer.Handle("/healthz", http.HandlerFunc(c.Health.HandleHealthz))
+	c.MetricsServer.Handle("/readyz", http.HandlerFunc(c.Health.HandleReadyz))
+	c.MetricsServer.Handle("/status", http.HandlerFunc(c.Health.HandleStatus))
+	if err := c.MetricsServer.Start(); err != nil {
+		return fmt.Errorf("failed to start metrics server: %w", err)
+	}
+
+	// Setup database
+	if err := c.MigrateDatabase(ctx); err != nil {
+		return fmt.Errorf("failed to setup database: %w", err)
+	}
+	c.Health.Done(health.StepMigrations)
+
 	// Setup repositories
-	c.TradeRepository = clickhouse.NewTradeRepository(c.DB)
-	c.BookTickerRepository = clickhouse.NewBookTickerRepository(c.DB)
+	if err := c.setupRepositories(); err != nil {
+		return fmt.Errorf("failed to setup repositories: %w", err)
+	}
 
-	// Fetch symbols from Binance
-	symbolFetcher := binance.NewSymbolFetcher(
-		c.Config.Binance.APIKey,
-		c.Config.Binance.SecretKey,
-		c.Config.Binance.UseTestnet,
-		c.Logger,
-	)
+	// Setup batch processors
+	if err := c.setupBatchProcessors(); err != nil {
+		return fmt.Errorf("failed to setup batch processors: %w", err)
+	}
 
-	symbols, err := symbolFetcher.FetchAllSymbols(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to fetch symbols: %w", err)
+	// Setup use cases
+	c.setupUseCases()
+
+	// Setup services
+	if err := c.setupServices(); err != nil {
+		return fmt.Errorf("failed to setup services: %w", err)
 	}
 
-	c.SymbolRepository = clickhouse.NewSymbolRepository(c.DB, symbols, c.Config.App.Symbols)
+	// Setup exchanges
+	if err := c.setupExchanges(ctx); err != nil {
+		return fmt.Errorf("failed to setup exchanges: %w", err)
+	}
 
 	return nil
 }
 
-func (c *Container) setupBatchProcessors() {
+func (c *Container) setupRepositories() error {
+	// Setup repositories. Trades, aggregate trades and klines are written with
+	// the exchange and market they were collected from, reads through the
+	// shared repositories see Binance spot
+	c.TradeRepository = clickhouse.NewTradeRepository(c.DB, entities.ExchangeBinance, entities.MarketSpot)
+	c.AggTradeRepository = clickhouse.NewAggTradeRepository(c.DB, entities.ExchangeBinance, entities.MarketSpot)
+	c.KlineRepository = clickhouse.NewKlineRepository(c.DB, entities.ExchangeBinance, entities.MarketSpot)
+	c.BookTickerRepository = clickhouse.NewBookTickerRepository(c.DB)
+	c.TradeGapRepository = clickhouse.NewTradeGapRepository(c.DB)
+	c.OrderBookSnapshotRepository = clickhouse.NewOrderBookSnapshotRepository(c.DB)
+	c.MarkPriceRepository = clickhouse.NewMarkPriceRepository(c.DB)
+
+	return nil
+}
+
+func (c *Container) setupBatchProcessors() error {
 	// Create batch processors with configurable settings
 	flushTimeout := time.Duration(c.Config.App.BatchFlushTimeoutMs) * time.Millisecond
 
+	overflow, err := clickhouse.ParseOverflowPolicy(c.Config.App.FlushOverflowPolicy)
+	if err != nil {
+		return err
+	}
+	poolConfig := clickhouse.FlushPoolConfig{
+		Workers:   c.Config.App.FlushWorkers,
+		QueueSize: c.Config.App.FlushQueueSize,
+		Overflow:  overflow,
+	}
+
+	// Batches that fail to flush are spooled to disk and replayed later
+	tradeSpool, err := clickhouse.NewSpool(c.Config.App.SpoolDir, "trades", c.TradeRepository.SaveBatch, c.Logger)
+	if err != nil {
+		return fmt.Errorf("failed to create trade spool: %w", err)
+	}
+
+	bookTickerSpool, err := clickhouse.NewSpool(c.Config.App.SpoolDir, "book_tickers", c.BookTickerRepository.SaveBatch, c.Logger)
+	if err != nil {
+		_ = tradeSpool.Close()
+		return fmt.Errorf("failed to create book ticker spool: %w", err)
+	}
+
 	c.TradeBatchProcessor = clickhouse.NewTradeBatchProcessor(
 		c.TradeRepository,
 		c.Logger,
 		c.Config.App.BatchSize,
 		flushTimeout,
+		poolConfig,
+		tradeSpool,
 	)
 
 	c.BookTickerBatchProcessor = clickhouse.NewBookTickerBatchProcessor(
@@ -163,7 +279,101 @@ func (c *Container) setupBatchProcessors() {
 		c.Logger,
 		c.Config.App.BatchSize,
 		flushTimeout,
+		poolConfig,
+		bookTickerSpool,
 	)
+
+	if c.Config.App.SubscribeAggTrades {
+		aggTradeSpool, err := clickhouse.NewSpool(c.Config.App.SpoolDir, "agg_trades", c.AggTradeRepository.SaveBatch, c.Logger)
+		if err != nil {
+			c.closeBatchProcessors()
+			return fmt.Errorf("failed to create aggregate trade spool: %w", err)
+		}
+
+		c.AggTradeBatchProcessor = clickhouse.NewAggTradeBatchProcessor(
+			c.AggTradeRepository,
+			c.Logger,
+			c.Config.App.BatchSize,
+			flushTimeout,
+			poolConfig,
+			aggTradeSpool,
+		)
+	}
+
+	if len(c.KlineIntervals) > 0 {
+		klineSpool, err := clickhouse.NewSpool(c.Config.App.SpoolDir, "klines", c.KlineRepository.SaveBatch, c.Logger)
+		if err != nil {
+			c.closeBatchProcessors()
+			return fmt.Errorf("failed to create kline spool: %w", err)
+		}
+
+		c.KlineBatchProcessor = clickhouse.NewKlineBatchProcessor(
+			c.KlineRepository,
+			c.Logger,
+			c.Config.App.BatchSize,
+			flushTimeout,
+			poolConfig,
+			klineSpool,
+		)
+	}
+
+	if c.Config.App.SubscribeMarkPrices {
+		markPriceSpool, err := clickhouse.NewSpool(c.Config.App.SpoolDir, "mark_prices", c.MarkPriceRepository.SaveBatch, c.Logger)
+		if err != nil {
+			c.closeBatchProcessors()
+			return fmt.Errorf("failed to create mark price spool: %w", err)
+		}
+
+		c.MarkPriceBatchProcessor = clickhouse.NewMarkPriceBatchProcessor(
+			c.MarkPriceRepository,
+			c.Logger,
+			c.Config.App.BatchSize,
+			flushTimeout,
+			poolConfig,
+			markPriceSpool,
+		)
+	}
+
+	return nil
+}
+
+// closeBatchProcessors flushes and closes the batch processors that were
+// created, together with their spools.
+func (c *Container) closeBatchProcessors() {
+	if c.TradeBatchProcessor != nil {
+		if err := c.TradeBatchProcessor.Close(); err != nil {
+			c.Logger.Error("Failed to close trade batch processor", "error", err)
+		}
+		c.TradeBatchProcessor = nil
+	}
+
+	if c.AggTradeBatchProcessor != nil {
+		if err := c.AggTradeBatchProcessor.Close(); err != nil {
+			c.Logger.Error("Failed to close aggregate trade batch processor", "error", err)
+		}
+		c.AggTradeBatchProcessor = nil
+	}
+
+	if c.KlineBatchProcessor != nil {
+		if err := c.KlineBatchProcessor.Close(); err != nil {
+			c.Logger.Error("Failed to close kline batch processor", "error", err)
+		}
+		c.KlineBatchProcessor = nil
+	}
+
+	if c.BookTickerBatchProcessor != nil {
+		if err := c.BookTickerBatchProcessor.Close(); err != nil {
+			c.Logger.Error("Failed to close book ticker batch processor", "error", err)
+		}
+		c.BookTickerBatchProcessor = nil
+	}
+
+	if c.MarkPriceBatchProcessor != nil {
+		if err := c.MarkPriceBatchProcessor.Close(); err != nil {
+			c.Logger.Error("Failed to close mark price batch processor", "error", err)
+		}
+		c.MarkPriceBatchProcessor = nil
+	}
 }
 
 func (c *Container) setupUseCases() {
@@ -172,62 +382,443 @@ func (c *Container) setupUseCases() {
 		c.Logger,
 	)
 
+	if c.AggTradeBatchProcessor != nil {
+		c.ProcessAggTradeUseCase = usecases.NewProcessAggTradeEventUseCase(
+			c.AggTradeBatchProcessor,
+			c.Logger,
+		)
+	}
+
+	if c.KlineBatchProcessor != nil {
+		c.ProcessKlineUseCase = usecases.NewProcessKlineEventUseCase(
+			c.KlineBatchProcessor,
+			c.Logger,
+		)
+	}
+
 	c.ProcessBookTickerUseCase = usecases.NewProcessBookTickerEventUseCase(
 		c.BookTickerBatchProcessor,
 		c.Logger,
 	)
 
-	c.SubscribeToSymbolsUseCase = usecases.NewSubscribeToSymbolsUseCase(
-		c.SymbolRepository,
-		c.ExchangeClient,
-		c.Logger,
-	)
+	if c.MarkPriceBatchProcessor != nil {
+		c.ProcessMarkPriceUseCase = usecases.NewProcessMarkPriceEventUseCase(
+			c.MarkPriceBatchProcessor,
+			c.Logger,
+		)
+	}
 }
 
 func (c *Container) setupServices() error {
+	// Rebuild local order books from the diff depth stream, only Binance spot
+	// offers one
+	if c.Config.App.SubscribeOrderBooks {
+		c.OrderBookManager = appservices.NewOrderBookManager(
+			binance.NewOrderBookService(c.Config.Binance.UseTestnet, c.Logger),
+			c.OrderBookSnapshotRepository,
+			c.Logger,
+			c.Config.App.OrderBookDepth,
+			time.Duration(c.Config.App.OrderBookSnapshotMs)*time.Millisecond,
+		)
+	}
+
+	return nil
+}
+
+// Connectors returns a connector for every exchange market in EXCHANGES.
+func (c *Container) Connectors() ([]domainservices.ExchangeConnector, error) {
+	type exchangeMarket struct {
+		exchange entities.Exchange
+		market   entities.Market
+	}
+	seen := make(map[exchangeMarket]bool)
+
+	connectors := make([]domainservices.ExchangeConnector, 0, len(c.Config.App.Exchanges))
+	for _, name := range c.Config.App.Exchanges {
+		connector, err := exchanges.New(name, c.Config, c.Logger)
+		if err != nil {
+			return nil, err
+		}
+		key := exchangeMarket{connector.Exchange(), connector.Market()}
+		if seen[key] {
+			return nil, fmt.Errorf("exchange %s %s configured twice", key.exchange, key.market)
+		}
+		seen[key] = true
+		connectors = append(connectors, connector)
+	}
+
+	return connectors, nil
+}
+
+// setupExchanges builds a collector for every exchange market in EXCHANGES.
+// They run side by side and share the batch processors.
+func (c *Container) setupExchanges(ctx context.Context) error {
+	connectors, err := c.Connectors()
+	if err != nil {
+		return err
+	}
+
+	for _, connector := range connectors {
+		collector, err := c.newExchangeCollector(ctx, connector)
+		if err != nil {
+			return fmt.Errorf("failed to setup %s %s: %w", connector.Exchange(), connector.Market(), err)
+		}
+		c.Exchanges = append(c.Exchanges, collector)
+	}
+
+	return nil
+}
+
+func (c *Container) newExchangeCollector(ctx context.Context, connector domainservices.ExchangeConnector) (*ExchangeCollector, error) {
+	collector := &ExchangeCollector{
+		Name:      fmt.Sprintf("%s/%s", connector.Exchange(), connector.Market()),
+		Connector: connector,
+	}
+	c.Health.Expect(health.SymbolsStep(collector.Name))
+	c.Health.Expect(health.SubscriptionsStep(collector.Name))
+
+	symbols, err := connector.FetchSymbols(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch symbols: %w", err)
+	}
+	c.Health.Done(health.SymbolsStep(collector.Name))
+	collector.SymbolRepository = clickhouse.NewSymbolRepository(c.DB, connector.Exchange(), connector.Market())
+	if err := collector.SymbolRepository.ReplaceAll(ctx, symbols); err != nil {
+		return nil, fmt.Errorf("failed to store symbols: %w", err)
+	}
+
+	// Watch the live trade stream for missing trade IDs
+	if historicalService := connector.HistoricalTrades(); c.Config.App.GapBackfill && historicalService != nil {
+		backfillTradeGapUseCase := usecases.NewBackfillTradeGapUseCase(
+			clickhouse.NewTradeRepository(c.DB, connector.Exchange(), connector.Market()),
+			c.TradeGapRepository,
+			historicalService,
+			c.Logger,
+			time.Duration(c.Config.App.GapBackfillDelayMs)*time.Millisecond,
+		)
+		collector.TradeGapDetector = appservices.NewTradeGapDetector(backfillTradeGapUseCase, c.Logger)
+	}
+
 	// Create event handler first
-	c.EventHandler = appservices.NewEventHandler(
+	collector.EventHandler = appservices.NewEventHandler(
+		metrics.NewDecoder(connector.Decoder(), connector.Exchange(), connector.Market()),
 		c.ProcessTradeUseCase,
+		c.ProcessAggTradeUseCase,
+		c.ProcessKlineUseCase,
 		c.ProcessBookTickerUseCase,
+		c.ProcessMarkPriceUseCase,
+		collector.TradeGapDetector,
+		c.OrderBookManager,
 		c.Logger,
 	)
 
 	// Create exchange client with event handler
-	c.ExchangeClient = binance.NewClient(
+	collector.ExchangeClient = connector.NewClient(func(message []byte) error {
+		c.Health.MessageReceived()
+		return collector.EventHandler.HandleMessage(context.Background(), message)
+	})
+	c.Health.AddCheck("acknowledgements "+collector.Name, func() error {
+		if pending := collector.ExchangeClient.PendingRequests(); pending > 0 {
+			return fmt.Errorf("%d subscription requests not answered", pending)
+		}
+		return nil
+	})
+
+	tickers, err := connector.Tickers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ticker service: %w", err)
+	}
+	if err := requireTickers(c.Selections, tickers); err != nil {
+		return nil, err
+	}
+
+	collector.SubscribeToSymbolsUseCase = usecases.NewSubscribeToSymbolsUseCase(
+		collector.SymbolRepository,
+		collector.ExchangeClient,
+		c.Selections,
+		tickers,
 		c.Logger,
-		c.Config.Binance.UseTestnet,
-		func(message []byte) error {
-			return c.EventHandler.HandleMessage(context.Background(), message)
-		},
 	)
+	if c.OrderBookManager != nil {
+		collector.SubscribeToSymbolsUseCase.SetOrderBooks(c.OrderBookManager)
+	}
 
-	// Update use case with exchange client
-	c.SubscribeToSymbolsUseCase = usecases.NewSubscribeToSymbolsUseCase(
-		c.SymbolRepository,
-		c.ExchangeClient,
-		c.Logger,
+	// Follow listings, delistings and status changes while running
+	if interval := time.Duration(c.Config.App.SymbolRefreshIntervalMs) * time.Millisecond; interval > 0 {
+		refreshSymbolsUseCase := usecases.NewRefreshSymbolsUseCase(
+			connector,
+			collector.SymbolRepository,
+			collector.SubscribeToSymbolsUseCase,
+			eventlog.NewPublisher(c.Logger),
+			c.Streams(),
+			c.Logger,
+		)
+		collector.SymbolRefresher = appservices.NewSymbolRefresher(refreshSymbolsUseCase, interval, c.Logger)
+	}
+
+	return collector, nil
+}
+
+// Streams returns the streams selected in the configuration.
+func (c *Container) Streams() usecases.Streams {
+	return usecases.Streams{
+		Trades:         c.Config.App.SubscribeTrades,
+		AggTrades:      c.Config.App.SubscribeAggTrades,
+		BookTickers:    c.Config.App.SubscribeBookTickers,
+		OrderBooks:     c.Config.App.SubscribeOrderBooks,
+		MarkPrices:     c.Config.App.SubscribeMarkPrices,
+		KlineIntervals: c.KlineIntervals,
+	}
+}
+
+// SubscriptionPlan is what live collection would subscribe to on one
+// exchange.
+type SubscriptionPlan struct {
+	Name     string // exchange/market, e.g. binance/usdm
+	Active   int    // Active symbols before the selection
+	Selected usecases.StreamSymbols
+	Client   domainservices.DryRunClient // Subscribed to Selected
+}
+
+// PlanSubscriptions fetches the symbols of every exchange in EXCHANGES,
+// selects them per stream and subscribes a dry run client to them. Neither
+// the database nor a stream is touched.
+func (c *Container) PlanSubscriptions(ctx context.Context) ([]*SubscriptionPlan, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	connectors, err := c.Connectors()
+	if err != nil {
+		return nil, err
+	}
+
+	plans := make([]*SubscriptionPlan, 0, len(connectors))
+	for _, connector := range connectors {
+		plan := &SubscriptionPlan{
+			Name:   fmt.Sprintf("%s/%s", connector.Exchange(), connector.Market()),
+			Client: connector.NewDryRunClient(),
+		}
+
+		tickers, err := connector.Tickers()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s ticker service: %w", plan.Name, err)
+		}
+		if err := requireTickers(c.Selections, tickers); err != nil {
+			return nil, fmt.Errorf("%s: %w", plan.Name, err)
+		}
+
+		symbols, err := connector.FetchSymbols(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s symbols: %w", plan.Name, err)
+		}
+		active := make([]*entities.Symbol, 0, len(symbols))
+		for _, symbol := range symbols {
+			if symbol.IsActive() {
+				active = append(active, symbol)
+			}
+		}
+		plan.Active = len(active)
+
+		subscriber := usecases.NewSubscribeToSymbolsUseCase(nil, plan.Client, c.Selections, tickers, c.Logger)
+		if plan.Selected, err = subscriber.SelectFrom(ctx, active, c.Streams()); err != nil {
+			return nil, fmt.Errorf("failed to select %s symbols: %w", plan.Name, err)
+		}
+		if err := subscriber.Subscribe(ctx, plan.Selected, c.KlineIntervals); err != nil {
+			return nil, fmt.Errorf("failed to plan %s streams: %w", plan.Name, err)
+		}
+
+		plans = append(plans, plan)
+	}
+
+	return plans, nil
+}
+
+// Reload reads the configuration again and applies the settings that can
+// change while running: the log level, the batch sizes and the symbol
+// selections. Connections stay open, only the symbols that enter or leave a
+// selection are subscribed or unsubscribed. Other settings need a restart.
+func (c *Container) Reload(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cfg, err := c.load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	selections, err := parseSelections(cfg)
+	if err != nil {
+		return err
+	}
+	for _, collector := range c.Exchanges {
+		tickers, err := collector.Connector.Tickers()
+		if err != nil {
+			return fmt.Errorf("failed to create %s ticker service: %w", collector.Name, err)
+		}
+		if err := requireTickers(selections, tickers); err != nil {
+			return fmt.Errorf("%s: %w", collector.Name, err)
+		}
+	}
+
+	c.LogLevel.Set(parseLogLevel(cfg.App.LogLevel))
+	c.Config.App.LogLevel = cfg.App.LogLevel
+
+	batchSize := cfg.App.BatchSize
+	flushTimeout := time.Duration(cfg.App.BatchFlushTimeoutMs) * time.Millisecond
+	if c.TradeBatchProcessor != nil {
+		c.TradeBatchProcessor.SetBatchSize(batchSize, flushTimeout)
+	}
+	if c.AggTradeBatchProcessor != nil {
+		c.AggTradeBatchProcessor.SetBatchSize(batchSize, flushTimeout)
+	}
+	if c.KlineBatchProcessor != nil {
+		c.KlineBatchProcessor.SetBatchSize(batchSize, flushTimeout)
+	}
+	if c.BookTickerBatchProcessor != nil {
+		c.BookTickerBatchProcessor.SetBatchSize(batchSize, flushTimeout)
+	}
+	if c.MarkPriceBatchProcessor != nil {
+		c.MarkPriceBatchProcessor.SetBatchSize(batchSize, flushTimeout)
+	}
+	c.Config.App.BatchSize = cfg.App.BatchSize
+	c.Config.App.BatchFlushTimeoutMs = cfg.App.BatchFlushTimeoutMs
+
+	c.Selections = selections
+	c.Config.App.SymbolSelection = cfg.App.SymbolSelection
+	c.Config.App.StreamSymbolSelections = cfg.App.StreamSymbolSelections
+
+	c.Logger.Info("Configuration reloaded",
+		"logLevel", cfg.App.LogLevel,
+		"batchSize", batchSize,
+		"batchFlushTimeoutMs", cfg.App.BatchFlushTimeoutMs,
 	)
 
+	// Follow the new selections on every exchange
+	var errs []error
+	for _, collector := range c.Exchanges {
+		collector.SubscribeToSymbolsUseCase.SetSelections(selections)
+		if _, _, err := collector.SubscribeToSymbolsUseCase.Sync(ctx, c.Streams()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to resubscribe %s: %w", collector.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func parseLogLevel(value string) slog.Level {
+	switch value {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// parseKlineIntervals parses the configured kline intervals.
+func parseKlineIntervals(values []string) ([]entities.KlineInterval, error) {
+	intervals := make([]entities.KlineInterval, 0, len(values))
+	for _, value := range values {
+		interval, err := entities.ParseKlineInterval(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse KLINE_INTERVALS: %w", err)
+		}
+		intervals = append(intervals, interval)
+	}
+	return intervals, nil
+}
+
+// parseSelections returns the symbol selection of every stream.
+func parseSelections(cfg *config.Config) (usecases.Selections, error) {
+	selections := make(usecases.Selections, len(cfg.App.StreamSymbolSelections))
+	for stream, selectionConfig := range cfg.App.StreamSymbolSelections {
+		selection, err := parseSymbolSelection(selectionConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s symbol selection: %w", stream, err)
+		}
+		selections[usecases.Stream(stream)] = selection
+	}
+	return selections, nil
+}
+
+// requireTickers fails when a selection ranks by 24h volume on an exchange
+// without a ticker endpoint.
+func requireTickers(selections usecases.Selections, tickers domainservices.TickerService) error {
+	for stream, selection := range selections {
+		if selection.TopByVolume > 0 && tickers == nil {
+			return fmt.Errorf("the %s symbol selection ranks by 24h volume, which the exchange does not offer", stream)
+		}
+	}
 	return nil
 }
 
+// parseSymbolSelection turns a configured symbol selection into the one the
+// subscriptions apply.
+func parseSymbolSelection(cfg config.SymbolSelectionConfig) (entities.SymbolSelection, error) {
+	selection := entities.SymbolSelection{
+		Symbols:     cfg.Symbols,
+		QuoteAssets: cfg.QuoteAssets,
+		BaseAssets:  cfg.BaseAssets,
+		TopByVolume: cfg.TopN,
+	}
+
+	for _, value := range cfg.Include {
+		pattern, err := entities.ParseSymbolPattern(value)
+		if err != nil {
+			return entities.SymbolSelection{}, err
+		}
+		selection.Include = append(selection.Include, pattern)
+	}
+	for _, value := range cfg.Exclude {
+		pattern, err := entities.ParseSymbolPattern(value)
+		if err != nil {
+			return entities.SymbolSelection{}, err
+		}
+		selection.Exclude = append(selection.Exclude, pattern)
+	}
+
+	for _, permission := range cfg.Permissions {
+		switch strings.ToLower(permission) {
+		case "spot":
+			selection.SpotTrading = true
+		case "margin":
+			selection.MarginTrading = true
+		default:
+			return entities.SymbolSelection{}, fmt.Errorf("unknown symbol permission %q (expected spot or margin)", permission)
+		}
+	}
+
+	return selection, nil
+}
+
 func (c *Container) Close() error {
-	// Close batch processors first to flush remaining data
-	if c.TradeBatchProcessor != nil {
-		if err := c.TradeBatchProcessor.Close(); err != nil {
-			c.Logger.Error("Failed to close trade batch processor", "error", err)
+	// Stop refreshing symbols and backfilling gaps before the database goes away
+	for _, collector := range c.Exchanges {
+		if collector.SymbolRefresher != nil {
+			if err := collector.SymbolRefresher.Close(); err != nil {
+				c.Logger.Error("Failed to close symbol refresher", "exchange", collector.Connector.Exchange(), "market", collector.Connector.Market(), "error", err)
+			}
+		}
+		if collector.TradeGapDetector != nil {
+			if err := collector.TradeGapDetector.Close(); err != nil {
+				c.Logger.Error("Failed to close trade gap detector", "exchange", collector.Connector.Exchange(), "market", collector.Connector.Market(), "error", err)
+			}
 		}
 	}
 
-	if c.BookTickerBatchProcessor != nil {
-		if err := c.BookTickerBatchProcessor.Close(); err != nil {
-			c.Logger.Error("Failed to close book ticker batch processor", "error", err)
+	if c.OrderBookManager != nil {
+		if err := c.OrderBookManager.Close(); err != nil {
+			c.Logger.Error("Failed to close order book manager", "error", err)
 		}
 	}
 
-	if c.ExchangeClient != nil {
-		if err := c.ExchangeClient.Close(); err != nil {
-			c.Logger.Error("Failed to close exchange client", "error", err)
+	// Close batch processors first to flush remaining data
+	c.closeBatchProcessors()
+
+	for _, collector := range c.Exchanges {
+		if err := collector.ExchangeClient.Close(); err != nil {
+			c.Logger.Error("Failed to close exchange client", "exchange", collector.Connector.Exchange(), "market", collector.Connector.Market(), "error", err)
 		}
 	}
 
@@ -237,5 +828,11 @@ func (c *Container) Close() error {
 		}
 	}
 
+	if c.MetricsServer != nil {
+		if err := c.MetricsServer.Close(); err != nil {
+			c.Logger.Error("Failed to close metrics server", "error", err)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,48 @@
+// Package eventlog publishes domain events to the log and the metrics.
+package eventlog
+
+import (
+	"context"
+	"log/slog"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/events"
+	"alarket/internal/infrastructure/metrics"
+)
+
+// Publisher logs symbol listings, delistings and status changes and counts
+// them. Other domain events are logged at debug level.
+type Publisher struct {
+	logger *slog.Logger
+}
+
+func NewPublisher(logger *slog.Logger) *Publisher {
+	return &Publisher{logger: logger}
+}
+
+func (p *Publisher) Publish(ctx context.Context, event interface{}) error {
+	switch e := event.(type) {
+	case events.SymbolListedEvent:
+		count(e.Exchange, e.Market, e.Type())
+		p.logger.Info("Symbol listed",
+			"exchange", e.Exchange, "market", e.Market, "symbol", e.Symbol.Name, "status", e.Symbol.Status)
+	case events.SymbolDelistedEvent:
+		count(e.Exchange, e.Market, e.Type())
+		p.logger.Info("Symbol delisted",
+			"exchange", e.Exchange, "market", e.Market, "symbol", e.Symbol.Name)
+	case events.SymbolStatusChangedEvent:
+		count(e.Exchange, e.Market, e.Type())
+		p.logger.Info("Symbol status changed",
+			"exchange", e.Exchange, "market", e.Market, "symbol", e.Symbol.Name,
+			"from", e.PreviousStatus, "to", e.Symbol.Status)
+	case events.DomainEvent:
+		p.logger.Debug("Domain event", "type", e.Type())
+	default:
+		p.logger.Debug("Unknown event", "event", event)
+	}
+	return nil
+}
+
+func count(exchange entities.Exchange, market entities.Market, change events.EventType) {
+	metrics.SymbolChanges.WithLabelValues(string(exchange), string(market), string(change)).Inc()
+}
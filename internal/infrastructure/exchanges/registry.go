@@ -0,0 +1,65 @@
+// Package exchanges maps exchange names from the configuration to their
+// connectors. Futures markets are registered as <exchange>-<market>.
+package exchanges
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/services"
+	"alarket/internal/infrastructure/binance"
+	"alarket/internal/infrastructure/bybit"
+	"alarket/internal/infrastructure/config"
+	"alarket/internal/infrastructure/websocket"
+)
+
+// Factory builds the connector of one exchange from the configuration.
+type Factory func(cfg *config.Config, logger *slog.Logger) (services.ExchangeConnector, error)
+
+var factories = map[string]Factory{
+	"binance":       binanceFactory(entities.MarketSpot),
+	"binance-usdm":  binanceFactory(entities.MarketUSDM),
+	"binance-coinm": binanceFactory(entities.MarketCoinM),
+	"bybit": func(cfg *config.Config, logger *slog.Logger) (services.ExchangeConnector, error) {
+		return bybit.NewConnector(cfg.Bybit.UseTestnet, logger), nil
+	},
+}
+
+func binanceFactory(market entities.Market) Factory {
+	return func(cfg *config.Config, logger *slog.Logger) (services.ExchangeConnector, error) {
+		mode, err := binance.ParseStreamMode(cfg.Binance.StreamMode)
+		if err != nil {
+			return nil, err
+		}
+		client := binance.ClientConfig{
+			RateLimit: websocket.RateLimit{
+				PerSecond: cfg.Binance.MessagesPerSecond,
+				Burst:     cfg.Binance.MessageBurst,
+			},
+			Mode: mode,
+		}
+		return binance.NewConnector(cfg.Binance.APIKey, cfg.Binance.SecretKey, market, cfg.Binance.UseTestnet, client, logger), nil
+	}
+}
+
+// New returns the connector registered under name.
+func New(name string, cfg *config.Config, logger *slog.Logger) (services.ExchangeConnector, error) {
+	factory, ok := factories[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("unknown exchange %q, expected one of %s", name, strings.Join(Names(), ", "))
+	}
+	return factory(cfg, logger)
+}
+
+// Names lists the registered exchanges in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
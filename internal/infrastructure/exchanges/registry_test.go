@@ -0,0 +1,51 @@
+package exchanges
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/infrastructure/config"
+)
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, []string{"binance", "binance-coinm", "binance-usdm", "bybit"}, Names())
+}
+
+func TestNew(t *testing.T) {
+	cfg := &config.Config{Binance: config.BinanceConfig{StreamMode: "subscribe"}}
+
+	for _, name := range []string{"binance", "binance-usdm", "BINANCE-COINM", "bybit", " Bybit "} {
+		t.Run(name, func(t *testing.T) {
+			connector, err := New(name, cfg, slog.Default())
+			require.NoError(t, err)
+			assert.NotNil(t, connector.Decoder())
+		})
+	}
+
+	connector, err := New("bybit", cfg, slog.Default())
+	require.NoError(t, err)
+	assert.Equal(t, entities.ExchangeBybit, connector.Exchange())
+	assert.Equal(t, entities.MarketSpot, connector.Market())
+	assert.Nil(t, connector.HistoricalTrades())
+
+	connector, err = New("binance-usdm", cfg, slog.Default())
+	require.NoError(t, err)
+	assert.Equal(t, entities.ExchangeBinance, connector.Exchange())
+	assert.Equal(t, entities.MarketUSDM, connector.Market())
+	assert.Nil(t, connector.HistoricalTrades())
+
+	t.Run("unknown binance stream mode", func(t *testing.T) {
+		_, err := New("binance", &config.Config{Binance: config.BinanceConfig{StreamMode: "multiplex"}}, slog.Default())
+		assert.ErrorContains(t, err, `unknown stream mode "multiplex"`)
+	})
+
+	t.Run("unknown exchange", func(t *testing.T) {
+		_, err := New("kraken", cfg, slog.Default())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "binance, binance-coinm, binance-usdm, bybit")
+	})
+}
@@ -0,0 +1,250 @@
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/repositories"
+)
+
+const (
+	jobExt      = ".json"
+	progressExt = ".progress"
+)
+
+// BackfillJobRepository keeps the backfill job queue in a local directory
+// instead of ClickHouse: per job a JSON file with its status and one with its
+// progress. Every read goes to disk, so a runner sees the jobs another process
+// adds, pauses or cancels, and every save replaces only its own file.
+type BackfillJobRepository struct {
+	dir string
+}
+
+func NewBackfillJobRepository(dir string) (repositories.BackfillJobRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create backfill job directory %s: %w", dir, err)
+	}
+	return &BackfillJobRepository{dir: dir}, nil
+}
+
+// jobRecord is the file format of a job. Cursor and Fetched only serve jobs
+// without a progress file, which older versions did not write.
+type jobRecord struct {
+	ID        string    `json:"id"`
+	Symbol    string    `json:"symbol"`
+	Direction string    `json:"direction"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Cursor    uint64    `json:"cursor"`
+	Fetched   uint64    `json:"fetched"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// progressRecord is the file format of the progress of a job.
+type progressRecord struct {
+	Cursor     uint64    `json:"cursor"`
+	Fetched    uint64    `json:"fetched"`
+	Owner      string    `json:"owner,omitempty"`
+	LeaseUntil time.Time `json:"lease_until"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (r *BackfillJobRepository) Save(ctx context.Context, job *entities.BackfillJob) error {
+	if err := r.SaveStatus(ctx, job); err != nil {
+		return err
+	}
+	return r.SaveProgress(ctx, job)
+}
+
+func (r *BackfillJobRepository) SaveStatus(ctx context.Context, job *entities.BackfillJob) error {
+	if !validID(job.ID) {
+		return fmt.Errorf("failed to save backfill job: invalid ID %q", job.ID)
+	}
+
+	data, err := json.MarshalIndent(jobRecord{
+		ID:        job.ID,
+		Symbol:    job.Symbol,
+		Direction: string(job.Direction),
+		From:      job.From,
+		To:        job.To,
+		Cursor:    job.Cursor,
+		Fetched:   job.Fetched,
+		Status:    string(job.Status),
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backfill job: %w", err)
+	}
+
+	if err := r.write(job.ID+jobExt, data); err != nil {
+		return fmt.Errorf("failed to save backfill job: %w", err)
+	}
+	return nil
+}
+
+func (r *BackfillJobRepository) SaveProgress(ctx context.Context, job *entities.BackfillJob) error {
+	if !validID(job.ID) {
+		return fmt.Errorf("failed to save backfill job progress: invalid ID %q", job.ID)
+	}
+
+	data, err := json.MarshalIndent(progressRecord{
+		Cursor:     job.Cursor,
+		Fetched:    job.Fetched,
+		Owner:      job.Owner,
+		LeaseUntil: job.LeaseUntil,
+		UpdatedAt:  job.UpdatedAt,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backfill job progress: %w", err)
+	}
+
+	if err := r.write(job.ID+progressExt, data); err != nil {
+		return fmt.Errorf("failed to save backfill job progress: %w", err)
+	}
+	return nil
+}
+
+func (r *BackfillJobRepository) GetByID(ctx context.Context, id string) (*entities.BackfillJob, error) {
+	if !validID(id) {
+		return nil, fmt.Errorf("backfill job %s not found", id)
+	}
+
+	job, err := r.read(filepath.Join(r.dir, id+jobExt))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("backfill job %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *BackfillJobRepository) GetAll(ctx context.Context) ([]*entities.BackfillJob, error) {
+	paths, err := filepath.Glob(filepath.Join(r.dir, "*"+jobExt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backfill jobs: %w", err)
+	}
+
+	jobs := make([]*entities.BackfillJob, 0, len(paths))
+	for _, path := range paths {
+		job, err := r.read(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // removed meanwhile
+		}
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].CreatedAt.Equal(jobs[j].CreatedAt) {
+			return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+		}
+		return jobs[i].ID < jobs[j].ID
+	})
+	return jobs, nil
+}
+
+func (r *BackfillJobRepository) read(path string) (*entities.BackfillJob, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backfill job: %w", err)
+	}
+
+	var record jobRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode backfill job %s: %w", path, err)
+	}
+
+	job := &entities.BackfillJob{
+		ID:        record.ID,
+		Symbol:    record.Symbol,
+		Direction: entities.BackfillDirection(record.Direction),
+		From:      record.From,
+		To:        record.To,
+		Cursor:    record.Cursor,
+		Fetched:   record.Fetched,
+		Status:    entities.BackfillJobStatus(record.Status),
+		Error:     record.Error,
+		CreatedAt: record.CreatedAt,
+		UpdatedAt: record.UpdatedAt,
+	}
+
+	if err := r.readProgress(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// readProgress applies the progress file of job, if there is one.
+func (r *BackfillJobRepository) readProgress(job *entities.BackfillJob) error {
+	path := filepath.Join(r.dir, job.ID+progressExt)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read backfill job progress: %w", err)
+	}
+
+	var record progressRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return fmt.Errorf("failed to decode backfill job progress %s: %w", path, err)
+	}
+
+	job.Cursor = record.Cursor
+	job.Fetched = record.Fetched
+	job.Owner = record.Owner
+	job.LeaseUntil = record.LeaseUntil
+	if record.UpdatedAt.After(job.UpdatedAt) {
+		job.UpdatedAt = record.UpdatedAt
+	}
+	return nil
+}
+
+// write replaces name with data, going through a temporary file so readers
+// never see half a job.
+func (r *BackfillJobRepository) write(name string, data []byte) error {
+	file, err := os.CreateTemp(r.dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := file.Name()
+
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, filepath.Join(r.dir, name))
+}
+
+// validID reports whether id can name the files of a job.
+func validID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, `/\`)
+}
@@ -0,0 +1,108 @@
+package filestore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/domain/entities"
+)
+
+func TestBackfillJobRepository(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "jobs")
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	repository, err := NewBackfillJobRepository(dir)
+	require.NoError(t, err)
+
+	second := entities.NewBackfillJob("b", "ETHUSDT", entities.BackfillForward, created.AddDate(0, 0, -1), created, created.Add(time.Second))
+	first := entities.NewBackfillJob("a", "BTCUSDT", entities.BackfillBackward, created.AddDate(0, 0, -7), created, created)
+	require.NoError(t, repository.Save(ctx, second))
+	require.NoError(t, repository.Save(ctx, first))
+
+	t.Run("get all oldest first", func(t *testing.T) {
+		jobs, err := repository.GetAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+		assert.Equal(t, "a", jobs[0].ID)
+		assert.Equal(t, "b", jobs[1].ID)
+	})
+
+	t.Run("save replaces the job", func(t *testing.T) {
+		first.Lease("runner", created.Add(time.Hour), created.Add(time.Minute))
+		first.Advance(12345, 1000, created.Add(2*time.Minute))
+		require.NoError(t, repository.SaveProgress(ctx, first))
+		first.Fail(assert.AnError, created.Add(3*time.Minute))
+		require.NoError(t, repository.SaveStatus(ctx, first))
+
+		stored, err := repository.GetByID(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, first.Symbol, stored.Symbol)
+		assert.Equal(t, first.Direction, stored.Direction)
+		assert.True(t, first.From.Equal(stored.From))
+		assert.True(t, first.To.Equal(stored.To))
+		assert.Equal(t, uint64(12345), stored.Cursor)
+		assert.Equal(t, uint64(1000), stored.Fetched)
+		assert.Equal(t, entities.BackfillJobFailed, stored.Status)
+		assert.Equal(t, assert.AnError.Error(), stored.Error)
+		assert.Equal(t, "runner", stored.Owner)
+		assert.True(t, created.Add(time.Hour).Equal(stored.LeaseUntil))
+		assert.True(t, first.UpdatedAt.Equal(stored.UpdatedAt))
+
+		jobs, err := repository.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Len(t, jobs, 2)
+	})
+
+	t.Run("saving the status keeps the progress", func(t *testing.T) {
+		stale, err := repository.GetByID(ctx, "b")
+		require.NoError(t, err)
+
+		second.Lease("runner", created.Add(time.Hour), created.Add(time.Minute))
+		second.Advance(500, 10, created.Add(time.Minute))
+		require.NoError(t, repository.SaveProgress(ctx, second))
+
+		require.NoError(t, stale.Pause(created.Add(2*time.Minute)))
+		require.NoError(t, repository.SaveStatus(ctx, stale))
+
+		stored, err := repository.GetByID(ctx, "b")
+		require.NoError(t, err)
+		assert.Equal(t, entities.BackfillJobPaused, stored.Status)
+		assert.Equal(t, uint64(500), stored.Cursor)
+		assert.Equal(t, uint64(10), stored.Fetched)
+	})
+
+	t.Run("leaves no temporary files", func(t *testing.T) {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 4)
+	})
+
+	t.Run("survives a new repository on the same directory", func(t *testing.T) {
+		reopened, err := NewBackfillJobRepository(dir)
+		require.NoError(t, err)
+
+		stored, err := reopened.GetByID(ctx, "b")
+		require.NoError(t, err)
+		assert.Equal(t, "ETHUSDT", stored.Symbol)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		_, err := repository.GetByID(ctx, "nope")
+		assert.ErrorContains(t, err, "not found")
+
+		_, err = repository.GetByID(ctx, "../a")
+		assert.ErrorContains(t, err, "not found")
+	})
+
+	t.Run("rejects IDs that are paths", func(t *testing.T) {
+		job := entities.NewBackfillJob("../x", "BTCUSDT", entities.BackfillBackward, created.AddDate(0, 0, -1), created, created)
+		assert.Error(t, repository.Save(ctx, job))
+	})
+}
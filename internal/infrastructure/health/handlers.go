@@ -0,0 +1,42 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type probeResponse struct {
+	Status   string   `json:"status"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+// HandleHealthz answers the liveness probe: 503 once the collector stopped
+// receiving messages or fails to flush.
+func (m *Monitor) HandleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, m.Live())
+}
+
+// HandleReadyz answers the readiness probe: 503 until migrations have run,
+// symbols are fetched and every subscription has been acknowledged.
+func (m *Monitor) HandleReadyz(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, m.Ready())
+}
+
+// HandleStatus serves Status as JSON.
+func (m *Monitor) HandleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, m.Status())
+}
+
+func writeProbe(w http.ResponseWriter, problems []string) {
+	if len(problems) > 0 {
+		writeJSON(w, http.StatusServiceUnavailable, probeResponse{Status: "unavailable", Problems: problems})
+		return
+	}
+	writeJSON(w, http.StatusOK, probeResponse{Status: "ok"})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/infrastructure/metrics"
+)
+
+func TestMonitor_HandleProbes(t *testing.T) {
+	monitor, clock := newTestMonitor(Config{MaxSilence: time.Minute})
+	monitor.Expect(StepMigrations)
+
+	recorder := httptest.NewRecorder()
+	monitor.HandleReadyz(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.JSONEq(t, `{"status":"unavailable","problems":["migrations pending"]}`, recorder.Body.String())
+
+	monitor.Done(StepMigrations)
+	recorder = httptest.NewRecorder()
+	monitor.HandleReadyz(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	monitor.HandleHealthz(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	clock.Advance(2 * time.Minute)
+	recorder = httptest.NewRecorder()
+	monitor.HandleHealthz(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+}
+
+func TestMonitor_HandleStatus(t *testing.T) {
+	metrics.WebSocketConnections.WithLabelValues("test-status", "spot").Set(2)
+	metrics.StreamsPerConnection.WithLabelValues("test-status", "spot", "conn-1").Set(150)
+	metrics.LastEventTime.WithLabelValues("test-status", "spot", "BTCUSDT").Set(1704067200.5)
+	metrics.BatchBuffered.WithLabelValues("test-status").Set(42)
+
+	monitor, _ := newTestMonitor(Config{})
+	monitor.Expect(StepMigrations)
+	monitor.Done(StepMigrations)
+	monitor.MessageReceived()
+
+	recorder := httptest.NewRecorder()
+	monitor.HandleStatus(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var status Status
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	assert.True(t, status.Ready)
+	assert.True(t, status.Live)
+	require.NotNil(t, status.LastMessage)
+	assert.Contains(t, status.Connections, ConnectionStatus{Exchange: "test-status", Market: "spot", Open: 2})
+	assert.Contains(t, status.Streams, StreamStatus{Exchange: "test-status", Market: "spot", Connection: "conn-1", Streams: 150})
+	assert.Contains(t, status.Symbols, SymbolStatus{
+		Exchange:    "test-status",
+		Market:      "spot",
+		Symbol:      "BTCUSDT",
+		LastMessage: time.Date(2024, 1, 1, 0, 0, 0, 500_000_000, time.UTC),
+	})
+	assert.Contains(t, status.Buffers, BufferStatus{Batch: "test-status", Rows: 42})
+}
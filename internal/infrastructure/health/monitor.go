@@ -0,0 +1,171 @@
+package health
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StepMigrations is done once the schema migrations have run.
+const StepMigrations = "migrations"
+
+// SymbolsStep is done once the symbols of an exchange market are fetched.
+func SymbolsStep(exchange string) string {
+	return "symbols " + exchange
+}
+
+// SubscriptionsStep is done once every stream of an exchange market has been
+// requested.
+func SubscriptionsStep(exchange string) string {
+	return "subscriptions " + exchange
+}
+
+type Config struct {
+	MaxSilence        time.Duration // Liveness fails when no message arrived for this long
+	MaxFlushErrorRate float64       // Liveness fails above this share of failed flushes
+	FlushWindow       time.Duration // Window the flush error rate is measured over
+}
+
+// flushSample is a reading of the flush counters.
+type flushSample struct {
+	at       time.Time
+	flushes  float64
+	failures float64
+}
+
+// Monitor tracks the startup steps and runtime signals behind /readyz and
+// /healthz.
+type Monitor struct {
+	config      Config
+	now         func() time.Time
+	flushTotals func() (flushes, failures float64)
+	startedAt   time.Time
+	lastMessage atomic.Int64 // unix nanoseconds, 0 = nothing received yet
+
+	mu      sync.Mutex
+	steps   map[string]bool         // startup step -> done
+	checks  map[string]func() error // evaluated on every readiness probe
+	samples []flushSample
+}
+
+func NewMonitor(config Config) *Monitor {
+	return &Monitor{
+		config:      config,
+		now:         time.Now,
+		flushTotals: flushTotals,
+		startedAt:   time.Now(),
+		steps:       make(map[string]bool),
+		checks:      make(map[string]func() error),
+	}
+}
+
+// Expect registers a startup step that has to be done before the collector is
+// ready.
+func (m *Monitor) Expect(step string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.steps[step]; !exists {
+		m.steps[step] = false
+	}
+}
+
+// Done marks a startup step as done.
+func (m *Monitor) Done(step string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.steps[step] = true
+}
+
+// AddCheck registers a readiness condition that is evaluated on every probe.
+func (m *Monitor) AddCheck(name string, check func() error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checks[name] = check
+}
+
+// MessageReceived records that a frame arrived on any connection.
+func (m *Monitor) MessageReceived() {
+	m.lastMessage.Store(m.now().UnixNano())
+}
+
+// LastMessage returns when the latest frame arrived, zero if none has.
+func (m *Monitor) LastMessage() time.Time {
+	if nanos := m.lastMessage.Load(); nanos > 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+// Ready returns the reasons the collector is not ready, none once every
+// startup step is done and every check passes.
+func (m *Monitor) Ready() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var problems []string
+	if len(m.steps) == 0 {
+		problems = append(problems, "starting")
+	}
+	for step, done := range m.steps {
+		if !done {
+			problems = append(problems, step+" pending")
+		}
+	}
+	for name, check := range m.checks {
+		if err := check(); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	sort.Strings(problems)
+	return problems
+}
+
+// Live returns the reasons the collector is considered stuck: no message for
+// longer than MaxSilence, or too many failed flushes within FlushWindow.
+func (m *Monitor) Live() []string {
+	now := m.now()
+
+	var problems []string
+	if m.config.MaxSilence > 0 {
+		since := m.LastMessage()
+		if since.IsZero() {
+			since = m.startedAt
+		}
+		if silence := now.Sub(since); silence > m.config.MaxSilence {
+			problems = append(problems, fmt.Sprintf("no message received for %s", silence.Round(time.Second)))
+		}
+	}
+
+	if rate, ok := m.flushErrorRate(now); ok && m.config.MaxFlushErrorRate > 0 && rate > m.config.MaxFlushErrorRate {
+		problems = append(problems, fmt.Sprintf("flush error rate %.2f above %.2f", rate, m.config.MaxFlushErrorRate))
+	}
+
+	return problems
+}
+
+// flushErrorRate samples the flush counters and returns the share of failed
+// flushes since the oldest sample within the window. It reports false until
+// there is a baseline and at least one flush happened after it.
+func (m *Monitor) flushErrorRate(now time.Time) (float64, bool) {
+	flushes, failures := m.flushTotals()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.samples = append(m.samples, flushSample{at: now, flushes: flushes, failures: failures})
+
+	// Keep the newest sample at or before the window start as the baseline
+	cutoff := now.Add(-m.config.FlushWindow)
+	for len(m.samples) > 1 && !m.samples[1].at.After(cutoff) {
+		m.samples = m.samples[1:]
+	}
+
+	baseline := m.samples[0]
+	if flushes <= baseline.flushes {
+		return 0, false
+	}
+	return (failures - baseline.failures) / (flushes - baseline.flushes), true
+}
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestMonitor(config Config) (*Monitor, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	monitor := NewMonitor(config)
+	monitor.now = clock.Now
+	monitor.startedAt = clock.now
+	monitor.flushTotals = func() (float64, float64) { return 0, 0 }
+	return monitor, clock
+}
+
+func TestMonitor_Ready(t *testing.T) {
+	monitor, _ := newTestMonitor(Config{})
+	assert.Equal(t, []string{"starting"}, monitor.Ready())
+
+	monitor.Expect(StepMigrations)
+	monitor.Expect(SymbolsStep("binance/spot"))
+	monitor.Expect(SubscriptionsStep("binance/spot"))
+	assert.Len(t, monitor.Ready(), 3)
+
+	monitor.Done(StepMigrations)
+	monitor.Done(SymbolsStep("binance/spot"))
+	assert.Equal(t, []string{"subscriptions binance/spot pending"}, monitor.Ready())
+
+	pending := 2
+	monitor.AddCheck("acknowledgements binance/spot", func() error {
+		if pending > 0 {
+			return errors.New("requests not answered")
+		}
+		return nil
+	})
+	monitor.Done(SubscriptionsStep("binance/spot"))
+	assert.Equal(t, []string{"acknowledgements binance/spot: requests not answered"}, monitor.Ready())
+
+	pending = 0
+	assert.Empty(t, monitor.Ready())
+
+	// Expecting a step again does not undo it
+	monitor.Expect(StepMigrations)
+	assert.Empty(t, monitor.Ready())
+}
+
+func TestMonitor_LiveFailsAfterSilence(t *testing.T) {
+	monitor, clock := newTestMonitor(Config{MaxSilence: time.Minute})
+
+	clock.Advance(30 * time.Second)
+	assert.Empty(t, monitor.Live(), "silence is counted from start until the first message")
+
+	clock.Advance(31 * time.Second)
+	assert.Equal(t, []string{"no message received for 1m1s"}, monitor.Live())
+
+	monitor.MessageReceived()
+	assert.Empty(t, monitor.Live())
+
+	clock.Advance(90 * time.Second)
+	assert.Equal(t, []string{"no message received for 1m30s"}, monitor.Live())
+}
+
+func TestMonitor_LiveFailsOnFlushErrorRate(t *testing.T) {
+	monitor, clock := newTestMonitor(Config{MaxFlushErrorRate: 0.5, FlushWindow: time.Minute})
+
+	var flushes, failures float64
+	monitor.flushTotals = func() (float64, float64) { return flushes, failures }
+
+	// First probe only records the baseline
+	flushes, failures = 100, 10
+	assert.Empty(t, monitor.Live())
+
+	clock.Advance(10 * time.Second)
+	flushes, failures = 110, 14
+	assert.Empty(t, monitor.Live(), "4 of 10 flushes failed")
+
+	clock.Advance(10 * time.Second)
+	flushes, failures = 120, 24
+	assert.Equal(t, []string{"flush error rate 0.70 above 0.50"}, monitor.Live())
+
+	// Once the failures fall out of the window the rate recovers
+	clock.Advance(2 * time.Minute)
+	flushes, failures = 220, 24
+	assert.Empty(t, monitor.Live())
+}
+
+func TestMonitor_LiveIgnoresIdleFlushes(t *testing.T) {
+	monitor, clock := newTestMonitor(Config{MaxFlushErrorRate: 0.1, FlushWindow: time.Minute})
+	monitor.flushTotals = func() (float64, float64) { return 5, 5 }
+
+	assert.Empty(t, monitor.Live())
+	clock.Advance(30 * time.Second)
+	assert.Empty(t, monitor.Live(), "no flush happened within the window")
+}
@@ -0,0 +1,147 @@
+package health
+
+import (
+	"sort"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"alarket/internal/infrastructure/metrics"
+)
+
+// Status is the collector state served on /status.
+type Status struct {
+	Ready       bool               `json:"ready"`
+	Live        bool               `json:"live"`
+	Problems    []string           `json:"problems,omitempty"`
+	LastMessage *time.Time         `json:"lastMessage,omitempty"`
+	Connections []ConnectionStatus `json:"connections"`
+	Streams     []StreamStatus     `json:"streams"`
+	Symbols     []SymbolStatus     `json:"symbols"`
+	Buffers     []BufferStatus     `json:"buffers"`
+}
+
+type ConnectionStatus struct {
+	Exchange string `json:"exchange"`
+	Market   string `json:"market"`
+	Open     int    `json:"open"`
+}
+
+type StreamStatus struct {
+	Exchange   string `json:"exchange"`
+	Market     string `json:"market"`
+	Connection string `json:"connection"`
+	Streams    int    `json:"streams"`
+}
+
+type SymbolStatus struct {
+	Exchange    string    `json:"exchange"`
+	Market      string    `json:"market"`
+	Symbol      string    `json:"symbol"`
+	LastMessage time.Time `json:"lastMessage"`
+}
+
+type BufferStatus struct {
+	Batch string `json:"batch"`
+	Rows  int    `json:"rows"`
+}
+
+// Status combines readiness and liveness with the connection, stream, symbol
+// and buffer gauges of the metrics registry.
+func (m *Monitor) Status() Status {
+	notReady, notLive := m.Ready(), m.Live()
+	status := Status{
+		Ready:       len(notReady) == 0,
+		Live:        len(notLive) == 0,
+		Problems:    append(notReady, notLive...),
+		Connections: []ConnectionStatus{},
+		Streams:     []StreamStatus{},
+		Symbols:     []SymbolStatus{},
+		Buffers:     []BufferStatus{},
+	}
+	if lastMessage := m.LastMessage(); !lastMessage.IsZero() {
+		status.LastMessage = &lastMessage
+	}
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		status.Problems = append(status.Problems, "metrics: "+err.Error())
+	}
+
+	for _, metric := range series(families, "alarket_websocket_connections") {
+		status.Connections = append(status.Connections, ConnectionStatus{
+			Exchange: label(metric, "exchange"),
+			Market:   label(metric, "market"),
+			Open:     int(metric.GetGauge().GetValue()),
+		})
+	}
+	for _, metric := range series(families, "alarket_websocket_streams") {
+		status.Streams = append(status.Streams, StreamStatus{
+			Exchange:   label(metric, "exchange"),
+			Market:     label(metric, "market"),
+			Connection: label(metric, "connection"),
+			Streams:    int(metric.GetGauge().GetValue()),
+		})
+	}
+	for _, metric := range series(families, "alarket_last_event_timestamp_seconds") {
+		status.Symbols = append(status.Symbols, SymbolStatus{
+			Exchange:    label(metric, "exchange"),
+			Market:      label(metric, "market"),
+			Symbol:      label(metric, "symbol"),
+			LastMessage: time.UnixMilli(int64(metric.GetGauge().GetValue() * 1000)).UTC(),
+		})
+	}
+	for _, metric := range series(families, "alarket_batch_buffered_rows") {
+		status.Buffers = append(status.Buffers, BufferStatus{
+			Batch: label(metric, "batch"),
+			Rows:  int(metric.GetGauge().GetValue()),
+		})
+	}
+
+	sort.Slice(status.Symbols, func(i, j int) bool {
+		a, b := status.Symbols[i], status.Symbols[j]
+		if a.Exchange != b.Exchange {
+			return a.Exchange < b.Exchange
+		}
+		if a.Market != b.Market {
+			return a.Market < b.Market
+		}
+		return a.Symbol < b.Symbol
+	})
+
+	return status
+}
+
+// flushTotals sums flushes and failed flushes over every batch processor.
+func flushTotals() (flushes, failures float64) {
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		return 0, 0
+	}
+
+	for _, metric := range series(families, "alarket_flush_duration_seconds") {
+		flushes += float64(metric.GetHistogram().GetSampleCount())
+	}
+	for _, metric := range series(families, "alarket_flush_failures_total") {
+		failures += metric.GetCounter().GetValue()
+	}
+	return flushes, failures
+}
+
+func series(families []*dto.MetricFamily, name string) []*dto.Metric {
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()
+		}
+	}
+	return nil
+}
+
+func label(metric *dto.Metric, name string) string {
+	for _, pair := range metric.GetLabel() {
+		if pair.GetName() == name {
+			return pair.GetValue()
+		}
+	}
+	return ""
+}
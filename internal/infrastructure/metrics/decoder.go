@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"time"
+
+	"alarket/internal/domain/entities"
+	"alarket/internal/domain/services"
+)
+
+// Decoder wraps the decoder of one exchange market and records received
+// events, parse errors, event lag and the time of the latest event per symbol.
+type Decoder struct {
+	decoder  services.MessageDecoder
+	exchange string
+	market   string
+	now      func() time.Time
+}
+
+func NewDecoder(decoder services.MessageDecoder, exchange entities.Exchange, market entities.Market) *Decoder {
+	return &Decoder{
+		decoder:  decoder,
+		exchange: string(exchange),
+		market:   string(market),
+		now:      time.Now,
+	}
+}
+
+func (d *Decoder) Decode(message []byte) ([]any, error) {
+	return d.record(d.decoder.Decode(message))
+}
+
+// DecodeStream decodes the payload of a combined stream frame, by stream name
+// when the wrapped decoder supports it.
+func (d *Decoder) DecodeStream(stream string, data []byte) ([]any, error) {
+	if decoder, ok := d.decoder.(services.StreamDecoder); ok {
+		return d.record(decoder.DecodeStream(stream, data))
+	}
+	return d.record(d.decoder.Decode(data))
+}
+
+func (d *Decoder) record(events []any, err error) ([]any, error) {
+	if err != nil {
+		ParseErrors.WithLabelValues(d.exchange, d.market).Inc()
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		MessagesReceived.WithLabelValues(d.exchange, d.market, "control").Inc()
+		return events, nil
+	}
+
+	now := d.now()
+	for _, event := range events {
+		stream, symbol, eventTime := describe(event)
+		MessagesReceived.WithLabelValues(d.exchange, d.market, stream).Inc()
+		if symbol == "" {
+			continue
+		}
+		LastEventTime.WithLabelValues(d.exchange, d.market, symbol).Set(float64(now.UnixMilli()) / 1000)
+		if !eventTime.IsZero() {
+			EventLag.WithLabelValues(d.exchange, d.market, stream, symbol).Set(now.Sub(eventTime).Seconds())
+		}
+	}
+	return events, nil
+}
+
+// describe returns the stream type, symbol and exchange event time of a
+// decoded event.
+func describe(event any) (string, string, time.Time) {
+	switch e := event.(type) {
+	case *entities.Trade:
+		return "trade", e.Symbol, e.EventTime
+	case *entities.AggTrade:
+		return "agg_trade", e.Symbol, e.EventTime
+	case *entities.Kline:
+		return "kline", e.Symbol, e.EventTime
+	case *entities.DepthUpdate:
+		return "depth", e.Symbol, e.EventTime
+	case *entities.BookTicker:
+		return "book_ticker", e.Symbol, e.EventTime
+	case *entities.MarkPrice:
+		return "mark_price", e.Symbol, e.EventTime
+	default:
+		return "unknown", "", time.Time{}
+	}
+}
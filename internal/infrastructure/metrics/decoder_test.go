@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/domain/entities"
+)
+
+type stubDecoder struct {
+	events []any
+	err    error
+}
+
+func (d stubDecoder) Decode([]byte) ([]any, error) {
+	return d.events, d.err
+}
+
+// stubStreamDecoder records the stream names it was asked to decode.
+type stubStreamDecoder struct {
+	stubDecoder
+	streams *[]string
+}
+
+func (d stubStreamDecoder) DecodeStream(stream string, data []byte) ([]any, error) {
+	*d.streams = append(*d.streams, stream)
+	return d.events, d.err
+}
+
+func TestDecoder_CountsEventsAndRecordsLag(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	trade := entities.NewTrade(1, "BTCUSDT", decimal.NewFromInt(42000), decimal.NewFromInt(1),
+		now.Add(-300*time.Millisecond), false, now.Add(-250*time.Millisecond), entities.TradeSourceLive)
+	ticker := entities.NewBookTicker(7, "ETHUSDT", 2000, 1, 2001, 2,
+		now.Add(-2*time.Second), now.Add(-2*time.Second))
+
+	decoder := NewDecoder(stubDecoder{events: []any{trade, ticker}}, "test-lag", entities.MarketUSDM)
+	decoder.now = func() time.Time { return now }
+
+	trades := MessagesReceived.WithLabelValues("test-lag", "usdm", "trade")
+	tickers := MessagesReceived.WithLabelValues("test-lag", "usdm", "book_ticker")
+	tradesBefore, tickersBefore := testutil.ToFloat64(trades), testutil.ToFloat64(tickers)
+
+	events, err := decoder.Decode([]byte("{}"))
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	assert.Equal(t, tradesBefore+1, testutil.ToFloat64(trades))
+	assert.Equal(t, tickersBefore+1, testutil.ToFloat64(tickers))
+	assert.InDelta(t, 0.25, testutil.ToFloat64(EventLag.WithLabelValues("test-lag", "usdm", "trade", "BTCUSDT")), 1e-9)
+	assert.InDelta(t, 2.0, testutil.ToFloat64(EventLag.WithLabelValues("test-lag", "usdm", "book_ticker", "ETHUSDT")), 1e-9)
+	assert.Equal(t, float64(now.Unix()), testutil.ToFloat64(LastEventTime.WithLabelValues("test-lag", "usdm", "BTCUSDT")))
+}
+
+func TestDecoder_CountsControlFrames(t *testing.T) {
+	decoder := NewDecoder(stubDecoder{}, "test-control", entities.MarketSpot)
+	control := MessagesReceived.WithLabelValues("test-control", "spot", "control")
+	before := testutil.ToFloat64(control)
+
+	events, err := decoder.Decode([]byte(`{"result":null,"id":1}`))
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(control))
+}
+
+func TestDecoder_CountsParseErrors(t *testing.T) {
+	decoder := NewDecoder(stubDecoder{err: errors.New("bad frame")}, "test-errors", entities.MarketSpot)
+	parseErrors := ParseErrors.WithLabelValues("test-errors", "spot")
+	before := testutil.ToFloat64(parseErrors)
+
+	_, err := decoder.Decode([]byte("not json"))
+	require.Error(t, err)
+	_, err = decoder.Decode([]byte("not json"))
+	require.Error(t, err)
+
+	assert.Equal(t, before+2, testutil.ToFloat64(parseErrors))
+}
+
+func TestDecoder_DecodeStream(t *testing.T) {
+	trade := entities.NewTrade(1, "BTCUSDT", decimal.NewFromInt(42000), decimal.NewFromInt(1),
+		time.Now(), false, time.Now(), entities.TradeSourceLive)
+	trades := MessagesReceived.WithLabelValues("test-stream", "spot", "trade")
+	before := testutil.ToFloat64(trades)
+
+	var streams []string
+	decoder := NewDecoder(stubStreamDecoder{stubDecoder{events: []any{trade}}, &streams}, "test-stream", entities.MarketSpot)
+	events, err := decoder.DecodeStream("btcusdt@trade", []byte("{}"))
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, []string{"btcusdt@trade"}, streams)
+
+	// Decoders without stream support decode the payload
+	plain := NewDecoder(stubDecoder{events: []any{trade}}, "test-stream", entities.MarketSpot)
+	events, err = plain.DecodeStream("btcusdt@trade", []byte("{}"))
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	assert.Equal(t, before+2, testutil.ToFloat64(trades))
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "alarket"
+
+// Registry holds every collector metric plus the Go runtime and process
+// metrics. It is served on /metrics by Server.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// MessagesReceived counts decoded events per stream type. Frames that carry
+	// no event, such as subscription acknowledgements, count as "control".
+	MessagesReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_received_total",
+		Help:      "Websocket events received, by stream type.",
+	}, []string{"exchange", "market", "stream"})
+
+	// ParseErrors counts frames the decoder rejected.
+	ParseErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "parse_errors_total",
+		Help:      "Websocket frames that failed to decode.",
+	}, []string{"exchange", "market"})
+
+	// EventLag is the time between the exchange event time and the moment the
+	// event was decoded, for the latest event of each symbol and stream.
+	EventLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "event_lag_seconds",
+		Help:      "Now minus the exchange event time of the latest event, by symbol.",
+	}, []string{"exchange", "market", "stream", "symbol"})
+
+	// LastEventTime is when the latest event of each symbol was received.
+	LastEventTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_event_timestamp_seconds",
+		Help:      "Unix time the latest event of a symbol was received.",
+	}, []string{"exchange", "market", "symbol"})
+
+	// BatchBuffered is the number of rows waiting in a batch processor for the
+	// next flush.
+	BatchBuffered = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "batch_buffered_rows",
+		Help:      "Rows buffered in a batch processor waiting to be flushed.",
+	}, []string{"batch"})
+
+	// FlushDuration observes how long successful and failed ClickHouse
+	// writes took.
+	FlushDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "flush_duration_seconds",
+		Help:      "Latency of batch writes to ClickHouse.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"batch"})
+
+	// FlushFailures counts batches that could not be written and went to the
+	// spool.
+	FlushFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "flush_failures_total",
+		Help:      "Batch writes to ClickHouse that failed.",
+	}, []string{"batch"})
+
+	// WebSocketConnections is the number of open websocket connections.
+	WebSocketConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "websocket_connections",
+		Help:      "Open websocket connections.",
+	}, []string{"exchange", "market"})
+
+	// WebSocketReconnects counts connections that were re-dialed, either
+	// because they dropped or because they reached their maximum lifetime.
+	WebSocketReconnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "websocket_reconnects_total",
+		Help:      "Websocket connections re-established after a drop or rotation.",
+	}, []string{"exchange", "market", "reason"})
+
+	// StreamsPerConnection is the number of streams subscribed on each
+	// websocket connection.
+	StreamsPerConnection = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "websocket_streams",
+		Help:      "Streams subscribed on a websocket connection.",
+	}, []string{"exchange", "market", "connection"})
+
+	// SymbolChanges counts listings, delistings and status changes found by
+	// the symbol refresh.
+	SymbolChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "symbol_changes_total",
+		Help:      "Symbol listings, delistings and status changes, by change.",
+	}, []string{"exchange", "market", "change"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		MessagesReceived,
+		ParseErrors,
+		EventLag,
+		LastEventTime,
+		BatchBuffered,
+		FlushDuration,
+		FlushFailures,
+		WebSocketConnections,
+		WebSocketReconnects,
+		StreamsPerConnection,
+		SymbolChanges,
+	)
+}
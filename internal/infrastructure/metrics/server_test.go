@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ServesMetrics(t *testing.T) {
+	FlushFailures.WithLabelValues("test-server").Add(0)
+
+	server := NewServer("127.0.0.1:0", slog.Default())
+	recorder := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	body, err := io.ReadAll(recorder.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `alarket_flush_failures_total{batch="test-server"}`)
+	assert.Contains(t, string(body), "go_goroutines")
+}
+
+func TestServer_StartFailsOnBusyAddress(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	server := NewServer(listener.Addr().String(), slog.Default())
+	assert.Error(t, server.Start())
+}
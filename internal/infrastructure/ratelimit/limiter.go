@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter caps the request weight spent against an API within any window,
+// the way Binance counts REQUEST_WEIGHT per minute and IP. Share one limiter
+// between everything that calls the same API so they stay within the limit
+// together.
+type Limiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+	mu     sync.Mutex
+	spent  []spend // oldest first, all within the last window
+	used   int
+}
+
+type spend struct {
+	at     time.Time
+	weight int
+}
+
+// NewLimiter allows limit weight per window. A limit of 0 or less means no
+// limit.
+func NewLimiter(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:  limit,
+		window: window,
+		now:    time.Now,
+		sleep:  sleep,
+	}
+}
+
+// Wait blocks until a request of the given weight may be sent or ctx is
+// done. A weight above the limit is capped to it.
+func (l *Limiter) Wait(ctx context.Context, weight int) error {
+	if l.limit <= 0 || l.window <= 0 {
+		return nil
+	}
+	weight = min(max(weight, 1), l.limit)
+
+	for {
+		delay := l.reserve(weight)
+		if delay == 0 {
+			return nil
+		}
+		if err := l.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve spends weight and returns 0, or returns how long until enough of
+// the weight spent leaves the window.
+func (l *Limiter) reserve(weight int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for len(l.spent) > 0 && !now.Before(l.spent[0].at.Add(l.window)) {
+		l.used -= l.spent[0].weight
+		l.spent = l.spent[1:]
+	}
+
+	if l.used+weight <= l.limit {
+		l.spent = append(l.spent, spend{at: now, weight: weight})
+		l.used += weight
+		return 0
+	}
+
+	freed := 0
+	for _, s := range l.spent {
+		freed += s.weight
+		if l.used-freed+weight <= l.limit {
+			return s.at.Add(l.window).Sub(now)
+		}
+	}
+	return l.window // unreachable, weight never exceeds the limit
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock only moves when the limiter sleeps.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+func newFakeLimiter(limit int, window time.Duration) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	limiter := NewLimiter(limit, window)
+	limiter.now = clock.Now
+	limiter.sleep = clock.Sleep
+	return limiter, clock
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("spends up to the limit without waiting", func(t *testing.T) {
+		limiter, clock := newFakeLimiter(100, time.Minute)
+
+		for range 4 {
+			require.NoError(t, limiter.Wait(ctx, 25))
+		}
+		assert.Empty(t, clock.sleeps)
+	})
+
+	t.Run("waits until the oldest weight leaves the window", func(t *testing.T) {
+		limiter, clock := newFakeLimiter(100, time.Minute)
+		start := clock.Now()
+
+		require.NoError(t, limiter.Wait(ctx, 50))
+		clock.now = start.Add(10 * time.Second)
+		require.NoError(t, limiter.Wait(ctx, 50))
+
+		require.NoError(t, limiter.Wait(ctx, 30))
+		assert.Equal(t, start.Add(time.Minute), clock.Now())
+
+		require.NoError(t, limiter.Wait(ctx, 30))
+		assert.Equal(t, start.Add(70*time.Second), clock.Now())
+	})
+
+	t.Run("never spends more than the limit in a window", func(t *testing.T) {
+		limiter, clock := newFakeLimiter(100, time.Minute)
+
+		type sent struct {
+			at     time.Time
+			weight int
+		}
+		var requests []sent
+		for i := range 40 {
+			weight := 5 + i%4*10
+			require.NoError(t, limiter.Wait(ctx, weight))
+			requests = append(requests, sent{at: clock.Now(), weight: weight})
+		}
+
+		for i, first := range requests {
+			total := 0
+			for _, r := range requests[i:] {
+				if r.at.Sub(first.at) < time.Minute {
+					total += r.weight
+				}
+			}
+			assert.LessOrEqual(t, total, 100)
+		}
+	})
+
+	t.Run("caps a weight above the limit", func(t *testing.T) {
+		limiter, clock := newFakeLimiter(10, time.Minute)
+
+		require.NoError(t, limiter.Wait(ctx, 50))
+		require.NoError(t, limiter.Wait(ctx, 1))
+		assert.Equal(t, []time.Duration{time.Minute}, clock.sleeps)
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		limiter, clock := newFakeLimiter(0, time.Minute)
+
+		for range 100 {
+			require.NoError(t, limiter.Wait(ctx, 1000))
+		}
+		assert.Empty(t, clock.sleeps)
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		limiter, _ := newFakeLimiter(10, time.Minute)
+		require.NoError(t, limiter.Wait(ctx, 10))
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.ErrorIs(t, limiter.Wait(cancelled, 1), context.Canceled)
+	})
+
+	t.Run("shared between goroutines", func(t *testing.T) {
+		limiter := NewLimiter(1000, time.Minute)
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 10 {
+					assert.NoError(t, limiter.Wait(ctx, 10))
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1000, limiter.used)
+	})
+}
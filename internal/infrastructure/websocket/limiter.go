@@ -0,0 +1,86 @@
+package websocket
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimit caps the messages sent on one connection. Zero PerSecond means
+// no limit.
+type RateLimit struct {
+	PerSecond float64
+	Burst     int
+}
+
+// Limiter is a token bucket: it holds up to Burst tokens, refilled at
+// PerSecond, and every message takes one.
+type Limiter struct {
+	limit  RateLimit
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func NewLimiter(limit RateLimit) *Limiter {
+	limit.Burst = max(limit.Burst, 1)
+	return &Limiter{
+		limit:  limit,
+		now:    time.Now,
+		sleep:  sleep,
+		tokens: float64(limit.Burst),
+	}
+}
+
+// Wait blocks until a message may be sent or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l.limit.PerSecond <= 0 {
+		return nil
+	}
+
+	for {
+		delay := l.reserve()
+		if delay == 0 {
+			return nil
+		}
+		if err := l.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token and returns 0, or returns how long until the next
+// token is available.
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		elapsed := now.Sub(l.last).Seconds()
+		l.tokens = min(l.tokens+elapsed*l.limit.PerSecond, float64(l.limit.Burst))
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	missing := 1 - l.tokens
+	return time.Duration(math.Ceil(missing / l.limit.PerSecond * float64(time.Second)))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
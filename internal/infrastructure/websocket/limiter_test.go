@@ -0,0 +1,123 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock only moves when the limiter sleeps.
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+func newFakeLimiter(limit RateLimit) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	limiter := NewLimiter(limit)
+	limiter.now = clock.Now
+	limiter.sleep = clock.Sleep
+	return limiter, clock
+}
+
+// sendTimes waits for n messages and returns when each was let through.
+func sendTimes(t *testing.T, limiter *Limiter, clock *fakeClock, n int) []time.Time {
+	t.Helper()
+
+	times := make([]time.Time, n)
+	for i := range times {
+		require.NoError(t, limiter.Wait(context.Background()))
+		times[i] = clock.Now()
+	}
+	return times
+}
+
+// maxInWindow returns the most messages sent within any window.
+func maxInWindow(times []time.Time, window time.Duration) int {
+	most := 0
+	for i, start := range times {
+		count := 0
+		for _, at := range times[i:] {
+			if at.Sub(start) < window {
+				count++
+			}
+		}
+		most = max(most, count)
+	}
+	return most
+}
+
+func TestLimiter_PacesMessages(t *testing.T) {
+	limiter, clock := newFakeLimiter(RateLimit{PerSecond: 4, Burst: 1})
+	start := clock.Now()
+
+	times := sendTimes(t, limiter, clock, 10)
+
+	assert.Equal(t, start, times[0], "the first message is not delayed")
+	for i := 1; i < len(times); i++ {
+		assert.Equal(t, 250*time.Millisecond, times[i].Sub(times[i-1]))
+	}
+	assert.Equal(t, 4, maxInWindow(times, time.Second))
+}
+
+func TestLimiter_AllowsBurst(t *testing.T) {
+	limiter, clock := newFakeLimiter(RateLimit{PerSecond: 5, Burst: 3})
+	start := clock.Now()
+
+	times := sendTimes(t, limiter, clock, 8)
+
+	for _, at := range times[:3] {
+		assert.Equal(t, start, at, "a full bucket lets a burst through")
+	}
+	for i := 3; i < len(times); i++ {
+		assert.Equal(t, 200*time.Millisecond, times[i].Sub(times[i-1]))
+	}
+	// Burst plus the tokens refilled within the window
+	assert.LessOrEqual(t, maxInWindow(times, time.Second), 3+5)
+}
+
+func TestLimiter_RefillsWhileIdle(t *testing.T) {
+	limiter, clock := newFakeLimiter(RateLimit{PerSecond: 2, Burst: 2})
+
+	sendTimes(t, limiter, clock, 2)
+	assert.Empty(t, clock.sleeps)
+
+	// Idle for longer than it takes to refill the bucket: tokens are capped
+	// at the burst
+	clock.now = clock.now.Add(10 * time.Second)
+	sendTimes(t, limiter, clock, 2)
+	assert.Empty(t, clock.sleeps)
+
+	sendTimes(t, limiter, clock, 1)
+	assert.Equal(t, []time.Duration{500 * time.Millisecond}, clock.sleeps)
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	limiter, clock := newFakeLimiter(RateLimit{})
+
+	sendTimes(t, limiter, clock, 100)
+	assert.Empty(t, clock.sleeps)
+}
+
+func TestLimiter_WaitIsCancelled(t *testing.T) {
+	limiter, clock := newFakeLimiter(RateLimit{PerSecond: 1, Burst: 1})
+	sendTimes(t, limiter, clock, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx), context.Canceled)
+}
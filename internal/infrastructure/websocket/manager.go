@@ -4,40 +4,107 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"alarket/internal/infrastructure/metrics"
+)
+
+const (
+	handshakeTimeout      = 45 * time.Second
+	defaultPingInterval   = 30 * time.Second
+	defaultReconnectDelay = 1 * time.Second
+	maxReconnectDelay     = 60 * time.Second
+	rotationRetryDelay    = 1 * time.Minute
 )
 
 type MessageHandler func(message []byte) error
 
+// ReconnectHandler is called after a connection has been re-dialed, either
+// because the previous one dropped or because it was rotated. It receives the
+// connection ID so the caller can restore any per-connection state such as
+// stream subscriptions. When it fails the new connection is closed and dialed
+// again with backoff.
+type ReconnectHandler func(id string) error
+
 type Connection struct {
 	conn           *websocket.Conn
-	url            string
+	url            string // guarded by mu, see SetURL
 	id             string
 	messageHandler MessageHandler
 	logger         *slog.Logger
 	mu             sync.Mutex
 	closed         bool
 	pingTicker     *time.Ticker
+	pingMessage    []byte           // nil = websocket ping control frames
+	open           prometheus.Gauge // nil = not reported
+	limiter        *Limiter         // paces Send, pings are not limited
+	retryDelay     time.Duration    // backoff of the next reconnect, 0 = default
 }
 
 type Manager struct {
-	connections    map[string]*Connection
-	mu             sync.RWMutex
-	logger         *slog.Logger
-	messageHandler MessageHandler
+	connections      map[string]*Connection
+	mu               sync.RWMutex
+	logger           *slog.Logger
+	messageHandler   MessageHandler
+	reconnectHandler ReconnectHandler
+	maxLifetime      time.Duration // 0 = never rotate
+	pingInterval     time.Duration
+	pingMessage      []byte
+	reconnectDelay   time.Duration
+	maxDelay         time.Duration
+	openConnections  prometheus.Gauge       // nil = metrics not reported
+	reconnects       *prometheus.CounterVec // reconnects by reason
+	rateLimit        RateLimit
 }
 
-func NewManager(logger *slog.Logger, messageHandler MessageHandler) *Manager {
+func NewManager(
+	logger *slog.Logger,
+	messageHandler MessageHandler,
+	reconnectHandler ReconnectHandler,
+	maxLifetime time.Duration,
+) *Manager {
 	return &Manager{
-		connections:    make(map[string]*Connection),
-		logger:         logger,
-		messageHandler: messageHandler,
+		connections:      make(map[string]*Connection),
+		logger:           logger,
+		messageHandler:   messageHandler,
+		reconnectHandler: reconnectHandler,
+		maxLifetime:      maxLifetime,
+		pingInterval:     defaultPingInterval,
+		reconnectDelay:   defaultReconnectDelay,
+		maxDelay:         maxReconnectDelay,
 	}
 }
 
+// SetPingMessage makes connections send message as a text frame every interval
+// instead of a websocket ping, for exchanges that expect an application level
+// heartbeat. Call it before the first Connect.
+func (m *Manager) SetPingMessage(message []byte, interval time.Duration) {
+	m.pingMessage = message
+	m.pingInterval = interval
+}
+
+// SetRateLimit paces the messages sent with Send on each connection, for
+// exchanges that disconnect clients sending too many control messages. Call
+// it before the first Connect.
+func (m *Manager) SetRateLimit(limit RateLimit) {
+	m.rateLimit = limit
+}
+
+// SetMetricLabels reports open connections and reconnects under the given
+// exchange and market. Call it before the first Connect.
+func (m *Manager) SetMetricLabels(exchange, market string) {
+	m.openConnections = metrics.WebSocketConnections.WithLabelValues(exchange, market)
+	m.reconnects = metrics.WebSocketReconnects.MustCurryWith(prometheus.Labels{
+		"exchange": exchange,
+		"market":   market,
+	})
+}
+
 func (m *Manager) Connect(ctx context.Context, url, id string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -46,34 +113,21 @@ func (m *Manager) Connect(ctx context.Context, url, id string) error {
 		return fmt.Errorf("connection with id %s already exists", id)
 	}
 
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 45 * time.Second,
-	}
-
-	conn, _, err := dialer.DialContext(ctx, url, nil)
+	connection, err := m.dial(ctx, url, id)
 	if err != nil {
-		return fmt.Errorf("failed to connect to %s: %w", url, err)
-	}
-
-	connection := &Connection{
-		conn:           conn,
-		url:            url,
-		id:             id,
-		messageHandler: m.messageHandler,
-		logger:         m.logger,
-		pingTicker:     time.NewTicker(30 * time.Second),
+		return err
 	}
 
 	m.connections[id] = connection
-
-	go connection.readLoop(ctx)
-	go connection.pingLoop(ctx)
+	m.start(ctx, connection)
 
 	m.logger.Info("WebSocket connection established", "id", id, "url", url)
 	return nil
 }
 
-func (m *Manager) Send(id string, message []byte) error {
+// Send writes a text message to a connection, waiting for the connection's
+// rate limit first.
+func (m *Manager) Send(ctx context.Context, id string, message []byte) error {
 	m.mu.RLock()
 	conn, exists := m.connections[id]
 	m.mu.RUnlock()
@@ -82,9 +136,29 @@ func (m *Manager) Send(id string, message []byte) error {
 		return fmt.Errorf("connection %s not found", id)
 	}
 
+	if err := conn.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("failed to wait for rate limit: %w", err)
+	}
 	return conn.send(message)
 }
 
+// SetURL changes the URL a connection is dialed with when it reconnects or
+// rotates, for connections whose URL lists their subscriptions.
+func (m *Manager) SetURL(id, url string) error {
+	m.mu.RLock()
+	conn, exists := m.connections[id]
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("connection %s not found", id)
+	}
+
+	conn.mu.Lock()
+	conn.url = url
+	conn.mu.Unlock()
+	return nil
+}
+
 func (m *Manager) Close(id string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -94,28 +168,221 @@ func (m *Manager) Close(id string) error {
 		return fmt.Errorf("connection %s not found", id)
 	}
 
-	if err := conn.close(); err != nil {
-		return err
-	}
-
+	// Remove before closing so the supervisor does not treat this as a drop
 	delete(m.connections, id)
-	return nil
+	return conn.close()
 }
 
 func (m *Manager) CloseAll() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	for id, conn := range m.connections {
+	connections := m.connections
+	m.connections = make(map[string]*Connection)
+
+	for id, conn := range connections {
 		if err := conn.close(); err != nil {
 			m.logger.Error("Failed to close connection", "id", id, "error", err)
 		}
 	}
 
-	m.connections = make(map[string]*Connection)
 	return nil
 }
 
+func (m *Manager) dial(ctx context.Context, url, id string) (*Connection, error) {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: handshakeTimeout,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", url, err)
+	}
+
+	if m.openConnections != nil {
+		m.openConnections.Inc()
+	}
+
+	return &Connection{
+		conn:           conn,
+		url:            url,
+		id:             id,
+		messageHandler: m.messageHandler,
+		logger:         m.logger,
+		pingTicker:     time.NewTicker(m.pingInterval),
+		pingMessage:    m.pingMessage,
+		open:           m.openConnections,
+		limiter:        NewLimiter(m.rateLimit),
+	}, nil
+}
+
+func (m *Manager) start(ctx context.Context, conn *Connection) {
+	go conn.pingLoop(ctx)
+	go m.supervise(ctx, conn)
+}
+
+// supervise runs the read loop of a connection and replaces the connection
+// when it drops unexpectedly or reaches its maximum lifetime.
+func (m *Manager) supervise(ctx context.Context, conn *Connection) {
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		conn.readLoop(ctx)
+	}()
+
+	var rotate <-chan time.Time
+	if m.maxLifetime > 0 {
+		rotateTimer := time.NewTimer(m.maxLifetime)
+		defer rotateTimer.Stop()
+		rotate = rotateTimer.C
+	}
+
+	for {
+		select {
+		case <-readDone:
+			if ctx.Err() != nil || !m.isCurrent(conn) {
+				return
+			}
+			m.reconnect(ctx, conn)
+			return
+
+		case <-rotate:
+			if m.rotate(ctx, conn) {
+				return
+			}
+			rotate = time.After(rotationRetryDelay)
+		}
+	}
+}
+
+// reconnect re-dials a dropped connection with jittered exponential backoff
+// until it succeeds, the context is cancelled, or the connection is closed.
+func (m *Manager) reconnect(ctx context.Context, old *Connection) {
+	delay := m.reconnectDelay
+	old.mu.Lock()
+	if old.retryDelay > 0 {
+		delay = old.retryDelay
+	}
+	old.mu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		wait := jitter(delay)
+		m.logger.Warn("WebSocket connection lost, reconnecting",
+			"id", old.id,
+			"attempt", attempt,
+			"delay", wait,
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		if !m.isCurrent(old) {
+			return
+		}
+
+		conn, err := m.dial(ctx, old.dialURL(), old.id)
+		if err != nil {
+			m.logger.Error("Reconnect attempt failed", "id", old.id, "attempt", attempt, "error", err)
+			delay = min(delay*2, m.maxDelay)
+			continue
+		}
+
+		if !m.replace(old, conn) {
+			_ = conn.close()
+			return
+		}
+		m.start(ctx, conn)
+
+		m.logger.Info("WebSocket connection re-established", "id", old.id, "attempts", attempt)
+		m.countReconnect("drop")
+		m.restore(conn, min(delay*2, m.maxDelay))
+		return
+	}
+}
+
+// rotate swaps a connection for a freshly dialed one before the server closes
+// it. The new connection is restored before the old one is closed. Returns
+// false if the new connection could not be established.
+func (m *Manager) rotate(ctx context.Context, old *Connection) bool {
+	m.logger.Info("Rotating WebSocket connection", "id", old.id, "lifetime", m.maxLifetime)
+
+	conn, err := m.dial(ctx, old.dialURL(), old.id)
+	if err != nil {
+		m.logger.Error("Failed to rotate connection", "id", old.id, "error", err)
+		return false
+	}
+
+	if !m.replace(old, conn) {
+		_ = conn.close()
+		return true
+	}
+	m.start(ctx, conn)
+	m.countReconnect("rotation")
+	m.restore(conn, m.reconnectDelay)
+
+	if err := old.close(); err != nil {
+		m.logger.Debug("Failed to close rotated connection", "id", old.id, "error", err)
+	}
+	return true
+}
+
+// restore runs the reconnect handler for a new connection. A connection
+// that could not be restored carries no subscriptions, so it is dropped and
+// its supervisor dials it again after retryDelay.
+func (m *Manager) restore(conn *Connection, retryDelay time.Duration) {
+	if m.reconnectHandler == nil {
+		return
+	}
+	if err := m.reconnectHandler(conn.id); err != nil {
+		m.logger.Error("Reconnect handler failed, dropping connection", "id", conn.id, "retry_in", retryDelay, "error", err)
+		conn.mu.Lock()
+		conn.retryDelay = retryDelay
+		conn.mu.Unlock()
+		_ = conn.close()
+	}
+}
+
+func (m *Manager) countReconnect(reason string) {
+	if m.reconnects != nil {
+		m.reconnects.WithLabelValues(reason).Inc()
+	}
+}
+
+func (m *Manager) isCurrent(conn *Connection) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.connections[conn.id] == conn
+}
+
+func (m *Manager) replace(old, conn *Connection) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.connections[old.id] != old {
+		return false
+	}
+	m.connections[old.id] = conn
+	return true
+}
+
+// jitter returns a random duration in [d/2, d).
+func jitter(d time.Duration) time.Duration {
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + rand.N(half)
+}
+
+func (c *Connection) dialURL() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.url
+}
+
 func (c *Connection) readLoop(ctx context.Context) {
 	defer func() {
 		_ = c.close()
@@ -155,7 +422,7 @@ func (c *Connection) pingLoop(ctx context.Context) {
 				c.mu.Unlock()
 				return
 			}
-			err := c.conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(10*time.Second))
+			err := c.ping()
 			c.mu.Unlock()
 
 			if err != nil {
@@ -167,6 +434,19 @@ func (c *Connection) pingLoop(ctx context.Context) {
 	}
 }
 
+// ping must be called with c.mu held.
+func (c *Connection) ping() error {
+	deadline := time.Now().Add(10 * time.Second)
+	if c.pingMessage == nil {
+		return c.conn.WriteControl(websocket.PingMessage, []byte{}, deadline)
+	}
+
+	if err := c.conn.SetWriteDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set write deadline: %w", err)
+	}
+	return c.conn.WriteMessage(websocket.TextMessage, c.pingMessage)
+}
+
 func (c *Connection) send(message []byte) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -191,6 +471,9 @@ func (c *Connection) close() error {
 
 	c.closed = true
 	c.pingTicker.Stop()
+	if c.open != nil {
+		c.open.Dec()
+	}
 
 	if err := c.conn.WriteControl(
 		websocket.CloseMessage,
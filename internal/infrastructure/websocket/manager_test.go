@@ -0,0 +1,346 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"alarket/internal/infrastructure/metrics"
+)
+
+// testServer is a websocket server that hands every accepted connection to
+// onConnect together with its sequence number (starting at 1).
+type testServer struct {
+	*httptest.Server
+	connections atomic.Int32
+}
+
+func newTestServer(t *testing.T, onConnect func(n int, conn *websocket.Conn)) *testServer {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	s := &testServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		onConnect(int(s.connections.Add(1)), conn)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *testServer) wsURL() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func newTestManager(messageHandler MessageHandler, reconnectHandler ReconnectHandler, maxLifetime time.Duration) *Manager {
+	manager := NewManager(slog.Default(), messageHandler, reconnectHandler, maxLifetime)
+	manager.reconnectDelay = 10 * time.Millisecond
+	manager.maxDelay = 50 * time.Millisecond
+	return manager
+}
+
+func TestManager_ReconnectsAfterDrop(t *testing.T) {
+	received := make(chan string, 10)
+
+	server := newTestServer(t, func(n int, conn *websocket.Conn) {
+		if n == 1 {
+			// Drop the first connection straight away
+			return
+		}
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			received <- string(msg)
+		}
+	})
+
+	reconnected := make(chan string, 1)
+	manager := newTestManager(
+		func(message []byte) error { return nil },
+		func(id string) error {
+			reconnected <- id
+			return nil
+		},
+		0,
+	)
+	defer func() { _ = manager.CloseAll() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, manager.Connect(ctx, server.wsURL(), "conn-1"))
+
+	select {
+	case id := <-reconnected:
+		assert.Equal(t, "conn-1", id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection was not re-established")
+	}
+
+	require.NoError(t, manager.Send(ctx, "conn-1", []byte("hello")))
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, "hello", msg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was not delivered on the new connection")
+	}
+	assert.Equal(t, int32(2), server.connections.Load())
+}
+
+func TestManager_RedialsWhenRestoreFails(t *testing.T) {
+	server := newTestServer(t, func(n int, conn *websocket.Conn) {
+		if n == 1 {
+			return
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+
+	var calls atomic.Int32
+	restored := make(chan struct{})
+	manager := newTestManager(
+		func(message []byte) error { return nil },
+		func(id string) error {
+			if calls.Add(1) == 1 {
+				return assert.AnError
+			}
+			close(restored)
+			return nil
+		},
+		0,
+	)
+	defer func() { _ = manager.CloseAll() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, manager.Connect(ctx, server.wsURL(), "conn-1"))
+
+	select {
+	case <-restored:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection was not dialed again after the failed restore")
+	}
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, int32(3), server.connections.Load())
+}
+
+func TestManager_ReportsConnectionsAndReconnects(t *testing.T) {
+	server := newTestServer(t, func(n int, conn *websocket.Conn) {
+		if n == 1 {
+			// Drop the first connection straight away
+			return
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+
+	reconnected := make(chan string, 1)
+	manager := newTestManager(
+		func(message []byte) error { return nil },
+		func(id string) error {
+			reconnected <- id
+			return nil
+		},
+		0,
+	)
+	manager.SetMetricLabels("test", "spot")
+
+	// Metrics are process wide, compare against the values before the test
+	open := metrics.WebSocketConnections.WithLabelValues("test", "spot")
+	drops := metrics.WebSocketReconnects.WithLabelValues("test", "spot", "drop")
+	openBefore, dropsBefore := testutil.ToFloat64(open), testutil.ToFloat64(drops)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, manager.Connect(ctx, server.wsURL(), "conn-1"))
+
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection was not re-established")
+	}
+
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(open) == openBefore+1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, dropsBefore+1, testutil.ToFloat64(drops))
+
+	require.NoError(t, manager.CloseAll())
+	assert.Equal(t, openBefore, testutil.ToFloat64(open))
+}
+
+func TestManager_RotatesConnectionAfterMaxLifetime(t *testing.T) {
+	var mu sync.Mutex
+	closedByClient := make(map[int]bool)
+
+	server := newTestServer(t, func(n int, conn *websocket.Conn) {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				mu.Lock()
+				closedByClient[n] = websocket.IsCloseError(err, websocket.CloseNormalClosure)
+				mu.Unlock()
+				return
+			}
+		}
+	})
+
+	var rotations atomic.Int32
+	manager := newTestManager(
+		func(message []byte) error { return nil },
+		func(id string) error {
+			rotations.Add(1)
+			return nil
+		},
+		100*time.Millisecond,
+	)
+	defer func() { _ = manager.CloseAll() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, manager.Connect(ctx, server.wsURL(), "conn-1"))
+
+	assert.Eventually(t, func() bool {
+		return rotations.Load() >= 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return closedByClient[1]
+	}, 5*time.Second, 10*time.Millisecond, "rotated connection should be closed gracefully")
+}
+
+func TestManager_CloseDoesNotReconnect(t *testing.T) {
+	server := newTestServer(t, func(n int, conn *websocket.Conn) {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+
+	var reconnects atomic.Int32
+	manager := newTestManager(
+		func(message []byte) error { return nil },
+		func(id string) error {
+			reconnects.Add(1)
+			return nil
+		},
+		0,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, manager.Connect(ctx, server.wsURL(), "conn-1"))
+	require.NoError(t, manager.Close("conn-1"))
+
+	time.Sleep(200 * time.Millisecond)
+
+	assert.Equal(t, int32(0), reconnects.Load())
+	assert.Equal(t, int32(1), server.connections.Load())
+	assert.Error(t, manager.Send(ctx, "conn-1", []byte("hello")))
+}
+
+func TestManager_SendRespectsRateLimit(t *testing.T) {
+	received := make(chan string, 10)
+	server := newTestServer(t, func(n int, conn *websocket.Conn) {
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			received <- string(msg)
+		}
+	})
+
+	manager := newTestManager(func(message []byte) error { return nil }, nil, 0)
+	manager.SetRateLimit(RateLimit{PerSecond: 1, Burst: 1})
+	defer func() { _ = manager.CloseAll() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, manager.Connect(ctx, server.wsURL(), "conn-1"))
+	require.NoError(t, manager.Send(ctx, "conn-1", []byte("first")))
+
+	// The bucket is empty, the next message has to wait about a second
+	sendCtx, sendCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer sendCancel()
+	assert.ErrorIs(t, manager.Send(sendCtx, "conn-1", []byte("second")), context.DeadlineExceeded)
+
+	assert.Equal(t, "first", <-received)
+	select {
+	case msg := <-received:
+		t.Fatalf("rate limited message %q was sent", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := jitter(time.Second)
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.Less(t, d, time.Second)
+	}
+}
+
+func TestManager_SendsPingMessage(t *testing.T) {
+	received := make(chan string, 10)
+
+	server := newTestServer(t, func(n int, conn *websocket.Conn) {
+		for {
+			messageType, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if messageType == websocket.TextMessage {
+				received <- string(msg)
+			}
+		}
+	})
+
+	manager := newTestManager(func(message []byte) error { return nil }, nil, 0)
+	manager.SetPingMessage([]byte(`{"op":"ping"}`), 20*time.Millisecond)
+	defer func() { _ = manager.CloseAll() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, manager.Connect(ctx, server.wsURL(), "conn-1"))
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, `{"op":"ping"}`, msg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ping message was not sent")
+	}
+}
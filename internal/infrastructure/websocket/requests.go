@@ -0,0 +1,145 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRequestTimeout means no response arrived in time.
+	ErrRequestTimeout = errors.New("request timed out")
+	// ErrConnectionReset means the connection was replaced before the
+	// response arrived.
+	ErrConnectionReset = errors.New("connection reset before response")
+	// ErrSendFailed means the request could not be written to the connection.
+	ErrSendFailed = errors.New("failed to send request")
+)
+
+// IsTransient reports whether a failed request is worth sending again.
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrRequestTimeout) ||
+		errors.Is(err, ErrConnectionReset) ||
+		errors.Is(err, ErrSendFailed)
+}
+
+type pendingRequest struct {
+	connID string
+	result chan error
+}
+
+// Requests correlates requests sent over websocket connections with their
+// responses by request ID.
+type Requests struct {
+	mu      sync.Mutex
+	pending map[string]*pendingRequest
+}
+
+func NewRequests() *Requests {
+	return &Requests{pending: make(map[string]*pendingRequest)}
+}
+
+// Add registers a request sent on a connection. The returned channel receives
+// the outcome once Resolve or one of the Fail methods is called.
+func (r *Requests) Add(id, connID string) <-chan error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	request := &pendingRequest{connID: connID, result: make(chan error, 1)}
+	r.pending[id] = request
+	return request.result
+}
+
+// Resolve completes a request with the error from its response, nil on
+// success. It returns false for unknown IDs.
+func (r *Requests) Resolve(id string, err error) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	request, ok := r.pending[id]
+	if !ok {
+		return false
+	}
+	delete(r.pending, id)
+	request.result <- err
+	return true
+}
+
+// Remove forgets a request without completing it.
+func (r *Requests) Remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, id)
+}
+
+// FailConnection completes every request sent on a connection with err.
+func (r *Requests) FailConnection(connID string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, request := range r.pending {
+		if request.connID == connID {
+			delete(r.pending, id)
+			request.result <- err
+		}
+	}
+}
+
+// FailAll completes every pending request with err.
+func (r *Requests) FailAll(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, request := range r.pending {
+		delete(r.pending, id)
+		request.result <- err
+	}
+}
+
+// Pending returns the number of requests still waiting for a response.
+func (r *Requests) Pending() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
+
+// Await waits for the outcome of a request. It gives up with
+// ErrRequestTimeout after timeout.
+func (r *Requests) Await(ctx context.Context, id string, result <-chan error, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+		r.Remove(id)
+		return fmt.Errorf("request %s: %w", id, ErrRequestTimeout)
+	case <-ctx.Done():
+		r.Remove(id)
+		return ctx.Err()
+	}
+}
+
+// Retry runs send until it succeeds, fails with an error that is not
+// transient, or attempts are used up. The delay grows linearly per attempt.
+func Retry(ctx context.Context, attempts int, delay time.Duration, send func() error) error {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = send(); err == nil || !IsTransient(err) {
+			return err
+		}
+		if attempt == attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * delay):
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+}
@@ -0,0 +1,88 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequests_ResolveCompletesRequest(t *testing.T) {
+	requests := NewRequests()
+	result := requests.Add("1", "conn-1")
+	assert.Equal(t, 1, requests.Pending())
+
+	rejected := errors.New("rejected")
+	assert.True(t, requests.Resolve("1", rejected))
+	assert.False(t, requests.Resolve("1", nil), "a request is completed once")
+	assert.False(t, requests.Resolve("unknown", nil))
+
+	assert.ErrorIs(t, requests.Await(context.Background(), "1", result, time.Second), rejected)
+	assert.Equal(t, 0, requests.Pending())
+}
+
+func TestRequests_AwaitTimesOut(t *testing.T) {
+	requests := NewRequests()
+	result := requests.Add("1", "conn-1")
+
+	err := requests.Await(context.Background(), "1", result, 10*time.Millisecond)
+	assert.ErrorIs(t, err, ErrRequestTimeout)
+	assert.True(t, IsTransient(err))
+	assert.Equal(t, 0, requests.Pending(), "timed out requests are forgotten")
+}
+
+func TestRequests_FailConnection(t *testing.T) {
+	requests := NewRequests()
+	first := requests.Add("1", "conn-1")
+	second := requests.Add("2", "conn-2")
+
+	requests.FailConnection("conn-1", ErrConnectionReset)
+	assert.ErrorIs(t, <-first, ErrConnectionReset)
+	assert.Equal(t, 1, requests.Pending())
+
+	requests.FailAll(errors.New("closed"))
+	assert.EqualError(t, <-second, "closed")
+	assert.Equal(t, 0, requests.Pending())
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		calls := 0
+		err := Retry(ctx, 3, time.Millisecond, func() error {
+			calls++
+			if calls < 3 {
+				return ErrRequestTimeout
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("stops on other errors", func(t *testing.T) {
+		calls := 0
+		rejected := errors.New("rejected")
+		err := Retry(ctx, 3, time.Millisecond, func() error {
+			calls++
+			return rejected
+		})
+		assert.ErrorIs(t, err, rejected)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("gives up after attempts", func(t *testing.T) {
+		calls := 0
+		err := Retry(ctx, 2, time.Millisecond, func() error {
+			calls++
+			return ErrConnectionReset
+		})
+		assert.ErrorIs(t, err, ErrConnectionReset)
+		assert.Contains(t, err.Error(), "giving up after 2 attempts")
+		assert.Equal(t, 2, calls)
+	})
+}
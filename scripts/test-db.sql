@@ -5,6 +5,9 @@ USE alarket;
 
 SHOW TABLES;
 
+-- Show applied migrations
+SELECT version, name, applied_at FROM schema_migrations FINAL WHERE applied = 1 ORDER BY version;
+
 -- Show table schemas
 DESCRIBE trades;
 